@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cachePrefix string
+	cacheLimit  int
+	cacheYes    bool
+	cacheJSON   bool
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and maintain the embedding cache",
+	Long: `
+Inspect and maintain the binary store that caches embeddings across runs and root folders.
+
+The store lives in ~/.gitcury/embeddings. Vectors are appended as they are computed and the
+store is compacted automatically once most of it holds replaced or deleted vectors. Several
+GitCury processes can use it at the same time.
+
+Subcommands:
+• stats   : Show entry counts, sizes and backends.
+• list    : List cached entries, newest first.
+• compact : Rewrite the store without replaced and deleted vectors.
+• migrate : Import JSON caches left by earlier versions.
+• clear   : Delete cached embeddings.
+
+Keys:
+• file:<root hash>:<path> : Embedding of a changed file's diff.
+• hunk:<backend>:<hash>   : Embedding of one normalized diff hunk, shared by all root folders.
+
+Examples:
+• Show what the cache holds:
+	gitcury cache stats
+
+• List the hunk embeddings:
+	gitcury cache list --prefix hunk:
+
+• Store new vectors as int8, then rewrite the existing ones:
+	gitcury config clustering set --key cached_vector_encoding --value int8
+	gitcury cache compact
+
+[NOTICE]: JSON caches are imported automatically on first use and renamed to *.migrated.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.Help(); err != nil {
+			utils.Error("Failed to show help: " + err.Error())
+		}
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show entry counts, sizes and backends",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		stats, err := core.CacheStats()
+		if err != nil {
+			utils.Error("Error reading the embedding cache: " + err.Error())
+			return
+		}
+		if cacheJSON {
+			utils.Print(utils.ToJSON(stats))
+			return
+		}
+		utils.Print(core.FormatCacheStats(stats))
+	},
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached entries, newest first",
+	Long: `
+List the entries of the embedding cache, newest first.
+
+Options:
+• --prefix <key> : Only list keys starting with this prefix, e.g. "file:" or "hunk:local:".
+• --limit <n>    : List at most n entries (default 50, 0 for all).
+• --json         : Print the entries as JSON.
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		entries, err := core.ListCache(cachePrefix, cacheLimit)
+		if err != nil {
+			utils.Error("Error reading the embedding cache: " + err.Error())
+			return
+		}
+		if cacheJSON {
+			utils.Print(utils.ToJSON(entries))
+			return
+		}
+		if len(entries) == 0 {
+			utils.Info("No cached embeddings.")
+			return
+		}
+		utils.Print(core.FormatCacheEntries(entries))
+	},
+}
+
+var cacheCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Rewrite the store without replaced and deleted vectors",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		stats, err := core.CompactCache()
+		if err != nil {
+			utils.Error("Error compacting the embedding cache: " + err.Error())
+			return
+		}
+		if cacheJSON {
+			utils.Print(utils.ToJSON(stats))
+			return
+		}
+		utils.Success(fmt.Sprintf("✅ Embedding cache compacted: %d entries", stats.Entries))
+	},
+}
+
+var cacheMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Import JSON caches left by earlier versions",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		migration, err := core.MigrateCache()
+		if err != nil {
+			utils.Error("Error migrating the embedding cache: " + err.Error())
+			return
+		}
+		if cacheJSON {
+			utils.Print(utils.ToJSON(migration))
+			return
+		}
+		if migration.Files == 0 {
+			utils.Info("No JSON caches left to import.")
+			return
+		}
+		utils.Success(fmt.Sprintf("✅ Imported %d vectors from %d JSON caches", migration.Vectors, migration.Files))
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete cached embeddings",
+	Long: `
+Delete cached embeddings. They are computed again when next needed.
+
+Options:
+• --prefix <key> : Only delete keys starting with this prefix.
+• --yes          : Delete without asking for confirmation.
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		what := "all cached embeddings"
+		if cachePrefix != "" {
+			what = "cached embeddings starting with '" + cachePrefix + "'"
+		}
+		if !cacheYes && !utils.ConfirmAction("Delete "+what+"?", false) {
+			utils.Info("Nothing deleted.")
+			return
+		}
+
+		removed, err := core.ClearCache(cachePrefix)
+		if err != nil {
+			utils.Error("Error clearing the embedding cache: " + err.Error())
+			return
+		}
+		utils.Success(fmt.Sprintf("✅ Deleted %d cached embeddings", removed))
+	},
+}
+
+func init() {
+	cacheCmd.PersistentFlags().BoolVar(&cacheJSON, "json", false, "Print JSON")
+	cacheListCmd.Flags().StringVar(&cachePrefix, "prefix", "", "Only list keys starting with this prefix")
+	cacheListCmd.Flags().IntVarP(&cacheLimit, "limit", "n", 50, "List at most this many entries (0 for all)")
+	cacheClearCmd.Flags().StringVar(&cachePrefix, "prefix", "", "Only delete keys starting with this prefix")
+	cacheClearCmd.Flags().BoolVarP(&cacheYes, "yes", "y", false, "Delete without asking for confirmation")
+
+	cacheCmd.AddCommand(cacheStatsCmd, cacheListCmd, cacheCompactCmd, cacheMigrateCmd, cacheClearCmd)
+
+	for _, command := range []*cobra.Command{cacheStatsCmd, cacheListCmd, cacheCompactCmd, cacheMigrateCmd, cacheClearCmd} {
+		utils.AddStatsPostRunToCommand(command)
+	}
+
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	clustersRoot string
+	clustersJSON bool
+
+	clustersEvalMethods []string
+	clustersEvalPresets []string
+	clustersEvalBackend string
+	clustersEvalNum     int
+	clustersEvalOutput  string
+	clustersEvalHistory int
+
+	clustersBenchmarkScaling bool
+)
+
+var clustersCmd = &cobra.Command{
+	Use:   "clusters",
+	Short: "Inspect how changed files are grouped",
+	Long: `
+Inspect the clusters that 'getmsgs --group' puts files into.
+
+Subcommands:
+• explain : Show why files were grouped together.
+• eval : Measure how well each clustering method recovers known groupings.
+• benchmark : Time the clustering of the current changes.
+
+Examples:
+• Explain the grouping of every root folder:
+	gitcury clusters explain
+
+• Compare the clustering methods and presets:
+	gitcury clusters eval
+
+• Time clustering, including large synthetic change sets:
+	gitcury clusters benchmark --scaling
+`,
+}
+
+var clustersExplainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Show why files were grouped together",
+	Long: `
+Show why files share a generated commit message.
+
+For each cluster recorded by the last 'getmsgs --group' run this shows:
+• The clustering method that produced it and its confidence.
+• The rule that matched, such as "same directory", "test/impl pair" or "similar changes".
+• Each file's similarity to the centroid of the cluster.
+
+Options:
+• --root <folder> : Only explain this root folder.
+• --json : Print the clusters as JSON.
+
+Examples:
+• Explain the grouping of a root folder:
+	gitcury clusters explain --root my-folder
+
+[NOTICE]: Clusters are kept with the generated messages and cleared when they are committed.
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		var folders []string
+		if clustersRoot != "" {
+			folders = append(folders, clustersRoot)
+		}
+
+		explanations := core.ExplainClusters(folders...)
+		if clustersJSON {
+			utils.Print(utils.ToJSON(explanations))
+			return
+		}
+		if len(explanations) == 0 {
+			utils.Info("No clusters recorded. Run 'gitcury getmsgs --group' first.")
+			return
+		}
+		utils.Print(core.FormatClusterExplanations(explanations))
+	},
+}
+
+var clustersEvalCmd = &cobra.Command{
+	Use:   "eval [fixture...]",
+	Short: "Measure how well each clustering method recovers known groupings",
+	Long: `
+Measure how well the clustering methods and presets group changes.
+
+A fixture describes a change set and the commits it ideally splits into. Each fixture is set
+up in a temporary repository and its changes are grouped by every clustering method on its own
+and by every preset of 'gitcury config clustering preset'. For each grouping this reports:
+• The adjusted Rand index (ARI) against the ideal commits: 1 is perfect, about 0 is random.
+• The purity: the share of files in a cluster mostly made of their own ideal commit.
+• The number of commits compared with the ideal number.
+
+Without fixture arguments a few built-in fixtures are used. Arguments are fixture files or
+directories of *.json fixtures, e.g. ones captured with 'clusters eval capture'.
+
+Fixture format:
+  {"name": "rename", "history": [["api/users.go", "store/users.go"]],
+   "changes": [{"path": "api/users.go", "before": "...", "after": "...", "group": "refactor: rename"}]}
+  A change without "before" adds the file, one without "after" deletes it. "history" lists the
+  files of earlier commits, oldest first, for the history method.
+
+Subcommands:
+• capture : Record a fixture from commits that were already made.
+
+Options:
+• --method <names> : Only run these methods (directory, pattern, dependency, history, semantic, cached).
+• --preset <names> : Only run these presets (speed, balanced, quality).
+• --backend <name> : Embedding backend: local, http or gemini (default: local, which works offline).
+• --num <n> : Number of clusters to ask for (default: 0, clustering decides as in 'getmsgs --group').
+• --json : Print the report as JSON.
+
+Examples:
+• Evaluate everything on the built-in fixtures:
+	gitcury clusters eval
+
+• Compare two methods on captured fixtures:
+	gitcury clusters eval fixtures/ --method directory,semantic
+
+[NOTICE]: The gemini backend sends the fixtures' diffs to the Gemini API.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		report, err := core.EvaluateClustering(args, git.EvalOptions{
+			Methods: clustersEvalMethods,
+			Presets: clustersEvalPresets,
+			Backend: clustersEvalBackend,
+			Target:  clustersEvalNum,
+		})
+		if err != nil {
+			utils.Error("Error evaluating clustering: " + utils.ToUserFriendlyMessage(err))
+			return
+		}
+		if clustersJSON {
+			utils.Print(utils.ToJSON(report))
+			return
+		}
+		utils.Print(core.FormatEvalReport(report))
+	},
+}
+
+var clustersBenchmarkCmd = &cobra.Command{
+	Use:   "benchmark",
+	Short: "Time the clustering of the current changes",
+	Long: `
+Time how long clustering the changed files of a root folder takes.
+
+The changes are clustered without a target and with 3, 5 and 8 clusters. For each run this
+reports the time taken, the number of clusters and a quality score of the grouping.
+
+Options:
+• --root <folder> : Root folder to benchmark (default: the only configured root folder).
+• --scaling : Also time the algorithms behind the semantic methods on synthetic change sets
+  of 250 to 2,000 files and fit how their time grows with the number of files.
+• --json : Print the results as JSON.
+
+Examples:
+• Benchmark the current changes:
+	gitcury clusters benchmark
+
+• Include the synthetic scaling runs:
+	gitcury clusters benchmark --scaling
+
+[NOTICE]: --scaling takes a while; the exact algorithms are quadratic in the number of files.
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		result, err := core.BenchmarkClustering(clustersRoot, clustersBenchmarkScaling)
+		if err != nil {
+			utils.Error("Error benchmarking clustering: " + utils.ToUserFriendlyMessage(err))
+			return
+		}
+		if clustersJSON {
+			utils.Print(utils.ToJSON(result))
+			return
+		}
+		for _, benchmark := range result.Benchmarks {
+			utils.Info(fmt.Sprintf("%-22s %3d cluster(s)  %8v  score %.3f", benchmark.TestName,
+				benchmark.ActualClusters, benchmark.ExecutionTime.Round(time.Microsecond), benchmark.ConfidenceScore))
+		}
+		utils.Print(result.Summary)
+	},
+}
+
+var clustersEvalCaptureCmd = &cobra.Command{
+	Use:   "capture <range>",
+	Short: "Record a fixture from commits that were already made",
+	Long: `
+Record an evaluation fixture from existing history.
+
+The commits of the range are replayed as one combined change, and each file's ideal group is
+the last commit in the range that changed it. Earlier commits are recorded as history for the
+co-change method. Binary files and files over 256 KB are left out.
+
+Range:
+• <from>..<to> : The commits after <from> up to <to>, e.g. v1.2.0..v1.3.0.
+• <from> : Shorthand for <from>..HEAD.
+
+Options:
+• --output <file> : Fixture file to write (required).
+• --root <folder> : Repository to read (default: the only configured root folder).
+• --history <n> : Commits before the range kept as history (default: 200, 0 for none).
+
+Examples:
+• Capture the last five commits:
+	gitcury clusters eval capture HEAD~5 --output fixtures/last-five.json
+
+[NOTICE]: Fixtures contain the full before and after content of the changed files.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if clustersEvalOutput == "" {
+			utils.Error("Missing --output: choose the fixture file to write")
+			return
+		}
+
+		fixture, err := core.CaptureEvalFixture(clustersRoot, args[0], clustersEvalOutput, clustersEvalHistory)
+		if err != nil {
+			utils.Error("Error capturing fixture: " + utils.ToUserFriendlyMessage(err))
+			return
+		}
+		groups := make(map[string]bool)
+		for _, change := range fixture.Changes {
+			groups[change.Group] = true
+		}
+		utils.Success(fmt.Sprintf("✅ Captured %d file(s) in %d commit(s) with %d history commit(s) to %s",
+			len(fixture.Changes), len(groups), len(fixture.History), clustersEvalOutput))
+	},
+}
+
+func init() {
+	clustersExplainCmd.Flags().StringVarP(&clustersRoot, "root", "r", "", "Only explain this root folder")
+	clustersCmd.PersistentFlags().BoolVar(&clustersJSON, "json", false, "Print JSON")
+
+	clustersEvalCmd.Flags().StringSliceVar(&clustersEvalMethods, "method", nil, "Only run these clustering methods")
+	clustersEvalCmd.Flags().StringSliceVar(&clustersEvalPresets, "preset", nil, "Only run these presets")
+	clustersEvalCmd.Flags().StringVar(&clustersEvalBackend, "backend", "local", "Embedding backend of the semantic methods")
+	clustersEvalCmd.Flags().IntVarP(&clustersEvalNum, "num", "n", 0, "Number of clusters to ask for (0 lets clustering decide)")
+	clustersEvalCaptureCmd.Flags().StringVarP(&clustersEvalOutput, "output", "o", "", "Fixture file to write")
+	clustersEvalCaptureCmd.Flags().StringVarP(&clustersRoot, "root", "r", "", "Repository to read")
+	clustersEvalCaptureCmd.Flags().IntVar(&clustersEvalHistory, "history", 200, "Commits before the range kept as history")
+
+	clustersBenchmarkCmd.Flags().StringVarP(&clustersRoot, "root", "r", "", "Root folder to benchmark")
+	clustersBenchmarkCmd.Flags().BoolVar(&clustersBenchmarkScaling, "scaling", false, "Also time the algorithms on synthetic change sets up to 2,000 files")
+
+	clustersEvalCmd.AddCommand(clustersEvalCaptureCmd)
+	clustersCmd.AddCommand(clustersExplainCmd)
+	clustersCmd.AddCommand(clustersEvalCmd)
+	clustersCmd.AddCommand(clustersBenchmarkCmd)
+
+	utils.AddStatsPostRunToCommand(clustersExplainCmd)
+	utils.AddStatsPostRunToCommand(clustersEvalCmd)
+	utils.AddStatsPostRunToCommand(clustersBenchmarkCmd)
+
+	rootCmd.AddCommand(clustersCmd)
+}
@@ -235,6 +235,7 @@ import (
 	"github.com/lakshyajain-0291/gitcury/config"
 	"github.com/lakshyajain-0291/gitcury/core"
 	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
 	"os"
 	"time"
 
@@ -245,6 +246,19 @@ var (
 	sealDateTime string
 	sealAllFlag  bool
 	folderName   string
+	stagedOnly   bool
+	noVerify     bool
+	signCommits  bool
+	signingKey   string
+
+	scheduleStart        string
+	scheduleEnd          string
+	scheduleSpread       string
+	scheduleWorkingHours string
+	scheduleWeekdays     bool
+	scheduleSeed         int64
+	schedulePreview      bool
+	scheduleYes          bool
 )
 
 var commitCmd = &cobra.Command{
@@ -259,15 +273,33 @@ Aliases:
 Options:
 • --all : Commit all changes with autogenerated messages.
 • --root <folder> : Commit changes in a specific root folder with autogenerated messages.
+• --staged-only : Commit the index exactly as staged, using the message from 'getmsgs --staged-only'.
+• --no-verify : Skip pre-commit and commit-msg hooks.
+• --sign : Sign the commits (uses the configured signing key unless --signing-key is given).
+• --signing-key <key> : Sign with the given GPG key ID or SSH key path.
 
 Examples:
 • Commit all changes:
 	gitcury commit --all
 
+• Commit only what is already staged:
+	gitcury commit --root my-folder --staged-only
+
+• Commit signed, with an SSH key:
+	gitcury commit --all --sign --signing-key ~/.ssh/id_ed25519.pub
+
+Signing and hook behaviour can also be set per root folder in the "commit" config section:
+	"commit": {"sign": true, "signingFormat": "ssh", "signingKey": "~/.ssh/id_ed25519.pub",
+	           "roots": {"/path/to/repo": {"noVerify": true}}}
+
+If a hook rejects a commit, its output is shown and the groups already committed are
+removed from the output, so running the command again only retries the failed group.
+
 • Commit changes in a folder:
 	gitcury commit --root my-folder
 
 [NOTICE]: Ensure the commit messages are generated before committing.
+[NOTICE]: When an identity profile is configured for a root folder, it is used as author and committer.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Start tracking this command if stats are enabled
@@ -277,9 +309,15 @@ Examples:
 
 		// Use our SafeExecute function to add panic recovery
 		err := utils.SafeExecute("CommitChanges", func() error {
+			env := commitOptionsEnv()
+
+			if stagedOnly {
+				return commitStaged(env...)
+			}
+
 			if sealAllFlag {
 				utils.Info("Committing all changes across root folders...")
-				err := core.CommitAllRoots()
+				err := core.CommitAllRoots(env...)
 				if err != nil {
 					return utils.NewGitError(
 						"Failed to commit all changes",
@@ -303,7 +341,7 @@ Examples:
 				}
 
 				utils.Info("Committing changes in folder: " + folderName)
-				err := core.CommitOneRoot(folderName)
+				err := core.CommitOneRoot(folderName, env...)
 				if err != nil {
 					return utils.NewGitError(
 						"Failed to commit changes in folder",
@@ -333,15 +371,83 @@ Examples:
 	},
 }
 
+// commitOptionsEnv turns the signing and hook flags into the environment overrides understood
+// by the commit configuration. It returns no slice when no flag was given.
+func commitOptionsEnv() [][]string {
+	var overrides []string
+	if noVerify {
+		overrides = append(overrides, config.NoVerifyEnv+"=1")
+	}
+	if signCommits {
+		overrides = append(overrides, config.SignEnv+"=1")
+	}
+	if signingKey != "" {
+		overrides = append(overrides, config.SigningKeyEnv+"="+signingKey)
+	}
+
+	if len(overrides) == 0 {
+		return nil
+	}
+	return [][]string{overrides}
+}
+
+// commitStaged handles the --staged-only mode of the commit command
+func commitStaged(env ...[]string) error {
+	if sealAllFlag {
+		utils.Info("Committing staged changes across root folders...")
+		if err := core.CommitAllStagedRoots(env...); err != nil {
+			return utils.NewGitError(
+				"Failed to commit staged changes",
+				err,
+				map[string]interface{}{
+					"operation": "CommitAllStagedRoots",
+				},
+			)
+		}
+		utils.Success("✅ Staged changes committed successfully.")
+		return nil
+	}
+
+	if folderName != "" {
+		if _, err := os.Stat(folderName); os.IsNotExist(err) {
+			return utils.NewValidationError(
+				"Root folder does not exist",
+				err,
+				map[string]interface{}{
+					"folderName": folderName,
+				},
+			)
+		}
+
+		utils.Info("Committing staged changes in folder: " + folderName)
+		return core.CommitStagedRoot(folderName, env...)
+	}
+
+	return utils.NewValidationError(
+		"You must specify either --all or --root flag",
+		nil,
+		map[string]interface{}{
+			"availableFlags": []string{"--all", "--root"},
+		},
+	)
+}
+
 var withDateCmd = &cobra.Command{
 	Use:   "with-date",
 	Short: "Commit changes with a specified timestamp",
 	Long: `
-Commit changes with a specific date and time.
+Commit changes with a specific date and time, or spread the commits across a time window.
 
 Options:
 • --all : Commit all changes with the given timestamp.
 • --root <folder> : Commit changes in a specific folder with the given timestamp.
+• --start <datetime> --end <datetime> : Spread the commits across a window instead of one timestamp.
+• --spread <even|realistic> : Equal gaps between commits, or irregular bursts like real work (default: even).
+• --working-hours <HH:MM-HH:MM> : Only place commits within these hours of the day.
+• --weekdays : Skip Saturdays and Sundays.
+• --seed <n> : Reproduce a realistic spread.
+• --preview : Show the commit timeline without committing.
+• --yes : Commit without confirming the timeline.
 
 Examples:
 • Commit all changes with a timestamp:
@@ -350,12 +456,20 @@ Examples:
 • Commit changes in a folder with a timestamp:
 	gitcury commit with-date --datetime "2025-01-01T12:00:00" --root my-folder
 
+• Spread the commits over a week of office hours:
+	gitcury commit with-date --all --start 2025-01-06 --end 2025-01-10T18:00:00 --spread realistic --working-hours 09:00-18:00 --weekdays
+
 [NOTICE]: Ensure the date and time format is 'YYYY-MM-DDTHH:MM:SS'.
+[NOTICE]: Window dates are local time and may omit the time of day. Commits keep their planned order and must come after each root folder's current HEAD.
 [NOTICE]: The system date and time will not be changed; only the commit date and time will be set.
 [NOTICE]: Use with caution, as this may affect commit history and collaboration.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		err := utils.SafeExecute("CommitWithDate", func() error {
+			if scheduleStart != "" || scheduleEnd != "" {
+				return commitOnSchedule()
+			}
+
 			if sealDateTime == "" {
 				return utils.NewValidationError(
 					"Datetime parameter is required",
@@ -413,6 +527,9 @@ Examples:
 				"GIT_AUTHOR_DATE="+formattedDateTime,
 				"GIT_COMMITTER_DATE="+formattedDateTime,
 			)
+			for _, overrides := range commitOptionsEnv() {
+				env = append(env, overrides...)
+			}
 
 			// Execute commit logic
 			if sealAllFlag {
@@ -473,11 +590,127 @@ Examples:
 	},
 }
 
+// commitOnSchedule spreads the commits across the --start/--end window
+func commitOnSchedule() error {
+	if sealDateTime != "" {
+		return utils.NewValidationError(
+			"--datetime cannot be combined with --start/--end",
+			nil,
+			map[string]interface{}{
+				"suggestion": "Use --datetime for a single timestamp or --start/--end for a window",
+			},
+		)
+	}
+	if !sealAllFlag && folderName == "" {
+		return utils.NewValidationError(
+			"You must specify either --all or --root flag",
+			nil,
+			map[string]interface{}{
+				"availableFlags": []string{"--all", "--root"},
+			},
+		)
+	}
+
+	start, err := parseScheduleDate("start", scheduleStart)
+	if err != nil {
+		return err
+	}
+	end, err := parseScheduleDate("end", scheduleEnd)
+	if err != nil {
+		return err
+	}
+	opts := core.ScheduleOptions{
+		Start:        start,
+		End:          end,
+		Spread:       scheduleSpread,
+		WeekdaysOnly: scheduleWeekdays,
+		Seed:         scheduleSeed,
+	}
+	if scheduleWorkingHours != "" {
+		if opts.WorkStart, opts.WorkEnd, err = core.ParseWorkingHours(scheduleWorkingHours); err != nil {
+			return err
+		}
+	}
+
+	var folders []string
+	if !sealAllFlag {
+		if _, err := os.Stat(folderName); os.IsNotExist(err) {
+			return utils.NewValidationError(
+				"Root folder does not exist",
+				err,
+				map[string]interface{}{
+					"folderName": folderName,
+					"suggestion": "Check the folder path and try again",
+				},
+			)
+		}
+		folders = append(folders, folderName)
+	}
+
+	timeline, err := core.PlanSchedule(opts, folders...)
+	if err != nil {
+		return err
+	}
+	utils.Print(core.FormatSchedule(timeline))
+	if schedulePreview {
+		return nil
+	}
+	if !scheduleYes && !utils.ConfirmAction("Commit with this timeline?", false) {
+		utils.Info("Scheduled commit cancelled.")
+		return nil
+	}
+
+	var env []string
+	for _, overrides := range commitOptionsEnv() {
+		env = append(env, overrides...)
+	}
+	if err := core.CommitOnSchedule(timeline, env); err != nil {
+		return err
+	}
+	utils.Success(fmt.Sprintf("✅ Committed %d change(s) across the scheduled window.", len(timeline)))
+	return nil
+}
+
+// parseScheduleDate parses a window boundary in local time, with or without the time of day
+func parseScheduleDate(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, utils.NewValidationError(
+			"Both --start and --end are required for a commit window",
+			nil,
+			map[string]interface{}{
+				"missing": "--" + name,
+			},
+		)
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, utils.NewValidationError(
+		"Invalid --"+name+" date",
+		nil,
+		map[string]interface{}{
+			"providedValue":  value,
+			"requiredFormat": "YYYY-MM-DD[THH:MM[:SS]]",
+			"example":        "2025-01-06T09:00:00",
+		},
+	)
+}
+
 func init() {
 	// Add flags for the with-date subcommand
 	withDateCmd.Flags().StringVarP(&sealDateTime, "datetime", "t", "", "Specify the commit date and time in 'YYYY-MM-DDTHH:MM:SS' format")
 	withDateCmd.Flags().BoolVarP(&sealAllFlag, "all", "a", false, "Commit all changes with autogenerated messages")
 	withDateCmd.Flags().StringVarP(&folderName, "root", "r", "", "Commit changes in the specified root folder with autogenerated messages")
+	withDateCmd.Flags().StringVar(&scheduleStart, "start", "", "Start of the window to spread the commits across")
+	withDateCmd.Flags().StringVar(&scheduleEnd, "end", "", "End of the window to spread the commits across")
+	withDateCmd.Flags().StringVar(&scheduleSpread, "spread", core.SpreadEven, "How commits are spread across the window: even or realistic")
+	withDateCmd.Flags().StringVar(&scheduleWorkingHours, "working-hours", "", "Only place commits within these hours, e.g. 09:00-18:00")
+	withDateCmd.Flags().BoolVar(&scheduleWeekdays, "weekdays", false, "Skip Saturdays and Sundays")
+	withDateCmd.Flags().Int64Var(&scheduleSeed, "seed", 0, "Seed for a reproducible realistic spread")
+	withDateCmd.Flags().BoolVar(&schedulePreview, "preview", false, "Show the commit timeline without committing")
+	withDateCmd.Flags().BoolVarP(&scheduleYes, "yes", "y", false, "Commit without confirming the timeline")
 
 	// Add the with-date subcommand to the seal command
 	commitCmd.AddCommand(withDateCmd)
@@ -485,6 +718,10 @@ func init() {
 	// Add flags to the main seal command
 	commitCmd.Flags().BoolVarP(&sealAllFlag, "all", "a", false, "Commit all changes with autogenerated messages")
 	commitCmd.Flags().StringVarP(&folderName, "root", "r", "", "Commit changes in the specified root folder with autogenerated messages")
+	commitCmd.Flags().BoolVar(&stagedOnly, "staged-only", false, "Commit only the staged changes, leaving the index untouched")
+	commitCmd.PersistentFlags().BoolVar(&noVerify, "no-verify", false, "Skip pre-commit and commit-msg hooks")
+	commitCmd.PersistentFlags().BoolVar(&signCommits, "sign", false, "Sign commits with GPG or SSH")
+	commitCmd.PersistentFlags().StringVar(&signingKey, "signing-key", "", "Key ID or SSH key path used for signing (implies --sign)")
 
 	// Add stats tracking to the commit command
 	utils.AddStatsPostRunToCommand(commitCmd)
@@ -346,8 +346,8 @@ Aliases:
 
 Configuration Keys:
 • GEMINI_API_KEY (Required): API key for Gemini service
-• root_folders (Optional): Comma-separated list of root folder paths
-• numFilesToCommit (Optional): Max number of files per commit (default: 5)
+• root_folders (Optional): Comma-separated list of root folder paths; globs such as ~/work/* are expanded at runtime
+• numFilesToCommit (Optional): Max number of files per commit (default: 5); with --group only used when clustering cluster_count_auto is off
 • app_name (Optional): Application name (default: "GitCury")
 • version (Optional): Application version (default: "1.0.0")
 • log_level (Optional): Logging level (default: "info")
@@ -422,6 +422,9 @@ Examples:
 			utils.Info("════════════════════════════════════════")
 		}
 
+		// Forge tokens are never shown
+		config.MaskForgeTokens(conf)
+
 		// Display config in a user-friendly format
 		b, _ := json.MarshalIndent(conf, "", "  ")
 		utils.Print(string(b))
@@ -565,12 +568,14 @@ Manage clustering configuration for grouping files in commits.
 Clustering Methods:
 • directory: Group files by directory structure (fastest)
 • pattern: Group files by file patterns and extensions  
+• dependency: Group files that reference each other's changed symbols (Go imports and identifiers, import statements elsewhere; off by default)
+• history: Group files that often changed together in past commits (mined from git log and cached per root folder; off by default)
 • semantic: Group files using embeddings for semantic similarity (slowest and most accurate)																															
 
 Presets:
 • speed: Directory-only clustering for maximum speed
 • balanced: Smart multi-layered approach (default)
-• quality: Semantic-first clustering for best results
+• quality: Semantic-first clustering for best results, with dependency and history clustering
 
 Examples:
 • View clustering configuration:
@@ -603,6 +608,12 @@ Examples:
 		if clusteringConfig.Methods.Pattern.Enabled {
 			utils.Info(fmt.Sprintf("   • Pattern (weight: %.1f)", clusteringConfig.Methods.Pattern.Weight))
 		}
+		if clusteringConfig.Methods.Dependency.Enabled {
+			utils.Info(fmt.Sprintf("   • Dependency (weight: %.1f)", clusteringConfig.Methods.Dependency.Weight))
+		}
+		if clusteringConfig.Methods.History.Enabled {
+			utils.Info(fmt.Sprintf("   • History (weight: %.1f)", clusteringConfig.Methods.History.Weight))
+		}
 		if clusteringConfig.Methods.Cached.Enabled {
 			utils.Info(fmt.Sprintf("   • Cached (weight: %.1f)", clusteringConfig.Methods.Cached.Weight))
 		}
@@ -631,6 +642,11 @@ Available Keys:
 • max_processing_time: Maximum time in seconds for clustering
 • adaptive_optimization: Enable/disable adaptive optimization (true/false)
 • performance_mode: Performance preference (speed/balanced/quality)
+• scalable_min_files: Changed files at which semantic clustering switches to mini-batch K-means and LSH-based DBSCAN (default: 200, 0 to never switch)
+• cluster_count_auto: Choose the number of commits for 'getmsgs --group' when --num is not given, instead of using numFilesToCommit (default: true)
+• cluster_count_min: Fewest clusters the automatic choice considers, 1 to allow a single commit (default: 1)
+• cluster_count_max: Most clusters the automatic choice considers (default: 12)
+• cluster_count_criterion: How the number is chosen: silhouette, elbow, gap or vote (default: vote)
 
 Method-specific Keys:
 • directory_enabled: Enable directory clustering (true/false)
@@ -643,6 +659,25 @@ Method-specific Keys:
 • pattern_confidence_threshold: Confidence threshold for pattern method
 • pattern_similarity_threshold: Similarity threshold for pattern method
 
+• dependency_enabled: Enable import/call graph clustering (true/false, default false)
+• dependency_weight: Weight for dependency method (0.0-1.0)
+• dependency_confidence_threshold: Confidence threshold for dependency method
+
+• history_enabled: Enable co-change history clustering (true/false, default false)
+• history_weight: Share of the co-change signal when scoring cluster similarity (0.0-1.0)
+• history_max_commits: Commits mined from git log per update
+• history_half_life_days: Age in days at which a past commit counts half
+• history_confidence_threshold: Confidence threshold for history method
+• history_similarity_threshold: Co-change similarity needed to group two files
+
+• cached_vector_encoding: How the embedding cache stores vectors: float32 (exact) or int8 (4x smaller)
+
+• semantic_backend: Embedding backend: gemini (API), local (offline hashed n-grams) or http (local embedding server)
+• semantic_endpoint: Embedding server URL for the http backend (default: http://localhost:11434/api/embed)
+• semantic_model: Model requested from the http backend (default: nomic-embed-text)
+• semantic_dimensions: Vector size of the local backend (default: 512)
+• semantic_embed_input: What is embedded per file: file (its whole diff) or hunks (each changed hunk, cached by hunk hash)
+
 Examples:
 • Set global similarity threshold:
 	gitcury config clustering set --key similarity_threshold --value 0.7
@@ -653,6 +688,9 @@ Examples:
 	gitcury config clustering set --key cached_enabled --value false
 	gitcury config clustering set --key semantic_enabled --value false
 
+• Use semantic grouping without the Gemini API:
+	gitcury config clustering set --key semantic_backend --value local
+
 • Set performance mode:
 	gitcury config clustering set --key performance_mode --value speed
 `,
@@ -702,6 +740,7 @@ balanced (default):
 
 quality:
   • Semantic clustering first
+  • Dependency and co-change history clustering
   • Higher similarity thresholds
   • Better grouping quality
   • Best for smaller repositories
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	describeRoot    string
+	describeFormat  string
+	describeJSON    bool
+	describeVersion string
+	describeOutput  string
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <range>",
+	Short: "Generate a PR description or changelog entry for a commit range",
+	Long: `
+Describe the commits between two refs with AI: a pull request description (summary,
+motivation, risk, test notes) or a Keep a Changelog entry.
+
+Range:
+• <from>..<to> : Commits reachable from <to> but not <from>, e.g. main..HEAD.
+• <from>...<to> : Commits since the merge base of both refs.
+• <ref> : Shorthand for <ref>..HEAD.
+
+Options:
+• --root <folder> : Root folder to describe (default: the only configured root folder).
+• --format <pr|changelog> : What to generate (default: pr).
+• --release <version> : Version heading for changelog entries (default: Unreleased).
+• --json : Print JSON, including the commits and files that were described.
+• --output <file> : Write the result to a file instead of stdout.
+
+Examples:
+• Describe the current branch for a pull request:
+	gitcury describe main..HEAD --root my-folder
+
+• Draft the changelog entry for a release:
+	gitcury describe v1.2.0..HEAD --format changelog --release 1.3.0
+
+Prompt size and style live in the "describe" config section:
+	"describe": {"maxPromptChars": 60000, "maxOutputTokens": 1024, "instructions": "Mention ticket IDs"}
+
+[NOTICE]: Diffs are trimmed to fit maxPromptChars; commit messages are always sent in full.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		utils.Info("📝 Describing " + args[0] + "...")
+		description, err := core.DescribeRange(describeRoot, args[0], describeFormat, describeVersion)
+		if err != nil {
+			utils.Error("Error describing range: " + utils.ToUserFriendlyMessage(err))
+			return
+		}
+
+		result := description.Markdown()
+		if describeJSON {
+			result = utils.ToJSON(description)
+		}
+
+		if describeOutput == "" {
+			utils.Print(result)
+			return
+		}
+		if err := os.WriteFile(describeOutput, []byte(result+"\n"), 0644); err != nil {
+			utils.Error("Failed to write '" + describeOutput + "': " + err.Error())
+			return
+		}
+		utils.Success("✅ Description written to " + describeOutput)
+	},
+}
+
+func init() {
+	describeCmd.Flags().StringVarP(&describeRoot, "root", "r", "", "Root folder to describe")
+	describeCmd.Flags().StringVarP(&describeFormat, "format", "f", git.DescribePullRequest, "Output kind: pr or changelog")
+	describeCmd.Flags().StringVar(&describeVersion, "release", "", "Version heading for changelog entries")
+	describeCmd.Flags().BoolVar(&describeJSON, "json", false, "Print JSON instead of Markdown")
+	describeCmd.Flags().StringVarP(&describeOutput, "output", "o", "", "Write the result to a file")
+
+	utils.AddStatsPostRunToCommand(describeCmd)
+
+	rootCmd.AddCommand(describeCmd)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	hooksRootFolder string
+	hooksForce      bool
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage the prepare-commit-msg git hook",
+	Long: `
+Manage the prepare-commit-msg git hook.
+
+The hook generates a message for the staged changes whenever you commit from
+git or your IDE, and pre-fills the commit message editor with it. Merges,
+squashes, amends and messages given with -m are left untouched.
+
+Subcommands:
+• install : Install the hook into the configured root folders.
+• uninstall : Remove the hook from the configured root folders.
+
+Options:
+• --root <folder> : Only act on the specified root folder.
+• --force : Replace an existing hook (install only); it is kept as a backup.
+
+Examples:
+• Install the hook in all root folders:
+	gitcury hooks install
+
+• Install the hook in a single folder:
+	gitcury hooks install --root my-folder
+
+• Remove the hook:
+	gitcury hooks uninstall
+
+[NOTICE]: core.hooksPath is respected when locating the hooks directory.
+[NOTICE]: Set GITCURY_SKIP_HOOK=1 to skip the hook for a single commit.
+[NOTICE]: Generation gives up after hook_timeout_seconds (default 30) so commits are never blocked.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.Help(); err != nil {
+			utils.Error("Failed to show help: " + err.Error())
+		}
+	},
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the prepare-commit-msg hook",
+	Run: func(cmd *cobra.Command, args []string) {
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:hooks-" + cmd.Name())
+		}
+
+		var folders []string
+		if hooksRootFolder != "" {
+			folders = append(folders, hooksRootFolder)
+		}
+
+		if err := core.InstallHooks(hooksForce, folders...); err != nil {
+			utils.Error(utils.ToUserFriendlyMessage(err))
+		}
+	},
+}
+
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the prepare-commit-msg hook",
+	Run: func(cmd *cobra.Command, args []string) {
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:hooks-" + cmd.Name())
+		}
+
+		var folders []string
+		if hooksRootFolder != "" {
+			folders = append(folders, hooksRootFolder)
+		}
+
+		if err := core.UninstallHooks(folders...); err != nil {
+			utils.Error(utils.ToUserFriendlyMessage(err))
+		}
+	},
+}
+
+// hooksPrepareCommitMsgCmd is the entry point called by the installed hook.
+// Git runs hooks from the top of the work tree, so the current directory is the repository.
+var hooksPrepareCommitMsgCmd = &cobra.Command{
+	Use:    git.PrepareCommitMsgHook + " <message-file> [source] [sha]",
+	Short:  "Fill the commit message file for the staged changes (used by the git hook)",
+	Hidden: true,
+	Args:   cobra.RangeArgs(1, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Keep the terminal quiet while git is waiting on the hook
+		utils.SetLogLevel("error")
+
+		source := ""
+		if len(args) > 1 {
+			source = args[1]
+		}
+
+		dir, err := os.Getwd()
+		if err != nil {
+			utils.Error("Failed to determine repository directory: " + err.Error())
+			return
+		}
+
+		done := make(chan error, 1)
+		go func() {
+			done <- git.PrepareCommitMsg(dir, args[0], source)
+		}()
+
+		// Never block the commit; the user can still type a message
+		select {
+		case err := <-done:
+			if err != nil {
+				utils.Error("GitCury could not generate a commit message: " + utils.ToUserFriendlyMessage(err))
+			}
+		case <-time.After(hookTimeout()):
+			utils.Error("GitCury timed out generating a commit message; leaving the message untouched.")
+		}
+	},
+}
+
+// hookTimeout returns how long the hook may spend generating a message
+func hookTimeout() time.Duration {
+	timeout := 30 * time.Second
+	if value, ok := config.Get("hook_timeout_seconds").(float64); ok && value > 0 {
+		timeout = time.Duration(value * float64(time.Second))
+	}
+	return timeout
+}
+
+func init() {
+	hooksCmd.PersistentFlags().StringVarP(&hooksRootFolder, "root", "r", "", "Only act on the specified root folder")
+	hooksInstallCmd.Flags().BoolVarP(&hooksForce, "force", "f", false, "Replace an existing prepare-commit-msg hook, keeping it as a backup")
+
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUninstallCmd)
+	hooksCmd.AddCommand(hooksPrepareCommitMsgCmd)
+
+	utils.AddStatsPostRunToCommand(hooksInstallCmd)
+	utils.AddStatsPostRunToCommand(hooksUninstallCmd)
+
+	rootCmd.AddCommand(hooksCmd)
+}
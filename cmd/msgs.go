@@ -169,6 +169,9 @@ var (
 	allFlag            bool
 	groupFlag          bool
 	customInstructions string
+	stagedOnlyMsgs     bool
+	reviewClusters     bool
+	clusterPlanPath    string
 )
 
 var getMsgsCmd = &cobra.Command{
@@ -183,8 +186,11 @@ Aliases:
 Options:
 • --all : Generate commit messages for all changed files in all root folders.
 • --root <folder> : Generate commit messages for changed files in a specific root folder.
-• --num <number> : Limit the number of files per commit (overrides config).
+• --num <number> : Limit the number of files per commit (overrides config). With --group and without --num the number of commits is chosen from the changes; turn this off with 'gitcury config clustering set --key cluster_count_auto --value false'.
 • --group : Group commit messages by file type.
+• --staged-only : Generate a single message for exactly what is staged; commit it with 'commit --staged-only'.
+• --review : With --group, review the proposed clusters (move files, split, merge, rename) before messages are generated.
+• --cluster-plan <file> : With --group, rearrange the proposed clusters as described in a JSON plan file.
 • --help : Display this help message.
 
 Examples:
@@ -200,6 +206,19 @@ Examples:
 • Generate messages for a specific folder with grouping:
 	gitcury getmsgs --root my-folder --num 5 --group
 
+• Review the clusters before any messages are generated:
+	gitcury getmsgs --root my-folder --group --review
+
+• Apply a cluster plan without prompts, e.g. in scripts:
+	gitcury getmsgs --root my-folder --group --cluster-plan plan.json
+
+  plan.json: {"folders": [{"rootFolder": "/path/to/my-folder",
+              "clusters": [{"name": "api", "files": ["api/handler.go", "api/handler_test.go"]}]}]}
+  Files are relative to the root folder; files the plan leaves out keep their proposed cluster.
+
+• Generate one message for the staged changes of a folder:
+	gitcury getmsgs --root my-folder --staged-only
+
 • Generate messages with custom instructions:
 	gitcury getmsgs --all --instructions "Don't add keywords like 'feat' or others in front of commit msgs and make humanize msgs"
 
@@ -232,11 +251,26 @@ Examples:
 			}()
 		}
 
+		if reviewClusters || clusterPlanPath != "" {
+			if !groupFlag {
+				utils.Error("--review and --cluster-plan require --group.")
+				return
+			}
+			removeReview, err := core.ReviewClusters(clusterPlanPath)
+			if err != nil {
+				utils.Error("Error loading the cluster plan: " + utils.ToUserFriendlyMessage(err))
+				return
+			}
+			defer removeReview()
+		}
+
 		if allFlag {
 			utils.Info("Generating messages for all root folders...")
 			var err error
 
-			if groupFlag {
+			if stagedOnlyMsgs {
+				err = core.GetAllStagedMsgs()
+			} else if groupFlag {
 				err = core.GroupAndGetAllMsgs(numFiles)
 			} else {
 				err = core.GetAllMsgs(numFiles)
@@ -254,7 +288,9 @@ Examples:
 			utils.Info("Generating messages for folder: " + rootFolderName)
 
 			var err error
-			if groupFlag {
+			if stagedOnlyMsgs {
+				err = core.GetStagedMsgsForRootFolder(rootFolderName)
+			} else if groupFlag {
 				err = core.GroupAndGetMsgsForRootFolder(rootFolderName, numFiles)
 			} else {
 				err = core.GetMsgsForRootFolder(rootFolderName, numFiles)
@@ -284,6 +320,9 @@ func init() {
 	getMsgsCmd.Flags().StringVarP(&rootFolderName, "root", "r", "", "Specify a root folder for localized message generation")
 	getMsgsCmd.Flags().BoolVarP(&allFlag, "all", "a", false, "Generate messages for all changed files across all root folders")
 	getMsgsCmd.Flags().BoolVarP(&groupFlag, "group", "g", false, "Group commit messages by file type")
+	getMsgsCmd.Flags().BoolVar(&stagedOnlyMsgs, "staged-only", false, "Generate one message for the staged changes only")
+	getMsgsCmd.Flags().BoolVar(&reviewClusters, "review", false, "Review the proposed clusters before generating messages (with --group)")
+	getMsgsCmd.Flags().StringVar(&clusterPlanPath, "cluster-plan", "", "Apply a JSON cluster plan instead of reviewing interactively (with --group)")
 	getMsgsCmd.Flags().StringVarP(&customInstructions, "instructions", "i", "", "Custom instructions for commit message generation (not saved to config)")
 
 	// Add stats tracking to the getmsgs command
@@ -124,6 +124,7 @@ package cmd
 import (
 	"github.com/lakshyajain-0291/gitcury/config"
 	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/interfaces"
 	"github.com/lakshyajain-0291/gitcury/utils"
 
 	"github.com/spf13/cobra"
@@ -133,6 +134,7 @@ var (
 	targetFolder string
 	deployAll    bool
 	targetBranch string
+	pushOptions  interfaces.PushOptions
 )
 
 var pushCmd = &cobra.Command{
@@ -147,13 +149,40 @@ Aliases:
 Options:
 • --all : Push all changes across all root folders.
 • --root <folder> : Push changes in a specific root folder.
+• --branch <name> : Branch to push (default: the current branch).
+• --remote <name> : Remote to push to (default: the branch's upstream, then push.remote, then origin).
+• --set-upstream : Record the pushed branch as upstream.
+• --force-with-lease : Overwrite the remote branch if it has not moved since you last fetched it.
+• --rebase : pull --rebase first when the branch is behind its remote.
+• --pr : Create or update a pull request (merge request on GitLab) after pushing.
+• --base <branch> : Target branch of the pull request (default: forge.baseBranch, then the repository default).
 
 Examples:
-• Push all changes:
-	gitcury push --all --branch main
+• Push the current branch of every root folder:
+	gitcury push --all
 
 • Push changes in a folder:
 	gitcury push --root my-folder --branch dev
+
+• Publish a new branch and track it:
+	gitcury push --root my-folder --set-upstream
+
+• Push and open or refresh a pull request:
+	gitcury push --root my-folder -u --pr --base main
+
+Per-root settings live in the "push" config section:
+	"push": {"remote": "origin", "pullRebase": true, "protectedBranches": ["main", "release/*"],
+	         "protectedPolicy": "refuse", "roots": {"/path/to/repo": {"remote": "upstream"}}}
+
+Pull requests are configured in the "forge" section (GitHub, GitLab and Gitea are supported):
+	"forge": {"enabled": true, "provider": "gitea", "baseUrl": "https://git.example.com/api/v1",
+	          "tokenEnv": "MY_FORGE_TOKEN", "baseBranch": "main", "draft": false}
+The provider and API URL are detected from the remote URL when not set. The token is read from
+tokenEnv, forge.token, GITCURY_FORGE_TOKEN or GITHUB_TOKEN/GH_TOKEN, GITLAB_TOKEN, GITEA_TOKEN.
+
+[NOTICE]: Pushing to a protected branch is refused (or confirmed with protectedPolicy "confirm").
+[NOTICE]: A branch that is behind its remote is not pushed unless --rebase or --force-with-lease is given.
+[NOTICE]: Pull request updates only replace the GitCury section of the body; manual edits are kept.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Start tracking this command if stats are enabled
@@ -164,7 +193,7 @@ Examples:
 		if deployAll {
 			utils.Info("Pushing all changes to the remote repository...")
 
-			err := core.PushAllRoots(targetBranch)
+			err := core.PushAllRoots(targetBranch, pushOptions)
 			if err != nil {
 				utils.Error("Error pushing all changes: " + err.Error())
 				return
@@ -174,7 +203,7 @@ Examples:
 		} else if targetFolder != "" {
 			utils.Info("Pushing changes from folder: " + targetFolder)
 
-			err := core.PushOneRoot(targetFolder, targetBranch)
+			err := core.PushOneRoot(targetFolder, targetBranch, pushOptions)
 			if err != nil {
 				utils.Error("Error pushing changes from folder '" + targetFolder + "': " + err.Error())
 				return
@@ -191,6 +220,12 @@ func init() {
 	pushCmd.Flags().BoolVarP(&deployAll, "all", "a", false, "Push all changes to the remote repository")
 	pushCmd.Flags().StringVarP(&targetFolder, "root", "r", "", "Push changes from the specified folder to the remote repository")
 	pushCmd.Flags().StringVarP(&targetBranch, "branch", "b", "", "Specify the branch to push to (default: current branch)")
+	pushCmd.Flags().StringVar(&pushOptions.Remote, "remote", "", "Remote to push to (default: upstream or configured remote)")
+	pushCmd.Flags().BoolVarP(&pushOptions.SetUpstream, "set-upstream", "u", false, "Set the upstream of the pushed branch")
+	pushCmd.Flags().BoolVar(&pushOptions.ForceWithLease, "force-with-lease", false, "Overwrite the remote branch if it has not moved")
+	pushCmd.Flags().BoolVar(&pushOptions.Rebase, "rebase", false, "pull --rebase first when the branch is behind")
+	pushCmd.Flags().BoolVar(&pushOptions.PullRequest, "pr", false, "Create or update a pull request after pushing")
+	pushCmd.Flags().StringVar(&pushOptions.Base, "base", "", "Target branch of the pull request")
 
 	// Add stats tracking to the push command
 	utils.AddStatsPostRunToCommand(pushCmd)
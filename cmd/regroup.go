@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	regroupRoot     string
+	regroupClusters int
+	regroupYes      bool
+	regroupDryRun   bool
+)
+
+var regroupCmd = &cobra.Command{
+	Use:   "regroup <range>",
+	Short: "Squash local commits and regroup them into semantic commits",
+	Long: `
+Turn a messy series of local commits into clean history.
+
+The commits in the range are reset (their changes stay in the working tree), the combined
+changes are clustered into logical groups, a message is generated for each group and the
+groups are committed again. The proposed history is shown before anything is rewritten.
+
+Range:
+• <base>..HEAD : The commits after <base>, e.g. HEAD~5..HEAD or @{u}..HEAD.
+• <base> : Shorthand for <base>..HEAD.
+
+Subcommands:
+• undo : Restore the commits from before the last regroup.
+
+Options:
+• --root <folder> : Root folder to act on (default: the only configured root folder).
+• --num <n> : Number of groups to create (default: decided by clustering).
+• --yes : Apply the proposed history without asking.
+• --dry-run : Only show the proposed history.
+
+Examples:
+• Regroup everything not yet pushed:
+	gitcury regroup @{u}..HEAD
+
+• Preview a regroup into three commits:
+	gitcury regroup HEAD~8 --num 3 --dry-run
+
+• Undo the last regroup:
+	gitcury regroup undo
+
+[NOTICE]: The working tree must have no uncommitted changes to tracked files.
+[NOTICE]: Commits that are reachable from a remote-tracking branch are never rewritten.
+[NOTICE]: HEAD is saved under refs/gitcury/backups/regroup/ before rewriting.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		if err := core.RegroupCommits(regroupRoot, args[0], regroupClusters, regroupYes, regroupDryRun, commitOptionsEnv()...); err != nil {
+			utils.Error("Error regrouping commits: " + utils.ToUserFriendlyMessage(err))
+			return
+		}
+	},
+}
+
+var regroupUndoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Restore the commits from before the last regroup",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := core.UndoRegroup(regroupRoot); err != nil {
+			utils.Error("Error undoing regroup: " + utils.ToUserFriendlyMessage(err))
+			return
+		}
+	},
+}
+
+func init() {
+	regroupCmd.PersistentFlags().StringVarP(&regroupRoot, "root", "r", "", "Root folder to act on")
+	regroupCmd.Flags().IntVarP(&regroupClusters, "num", "n", 0, "Number of groups to create (0 lets clustering decide)")
+	regroupCmd.Flags().BoolVarP(&regroupYes, "yes", "y", false, "Apply the proposed history without asking")
+	regroupCmd.Flags().BoolVar(&regroupDryRun, "dry-run", false, "Only show the proposed history")
+
+	regroupCmd.AddCommand(regroupUndoCmd)
+
+	utils.AddStatsPostRunToCommand(regroupCmd)
+
+	rootCmd.AddCommand(regroupCmd)
+}
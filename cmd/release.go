@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	releaseRootFolder string
+	releaseBump       string
+	releaseJSON       bool
+	releaseNotesFile  string
+	releaseYes        bool
+)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Plan versions and release notes from conventional commits",
+	Long: `
+Plan the next semantic version and release notes from conventional commits.
+
+Commits since the last version tag are classified from their subjects and trailers:
+• feat : minor bump
+• fix, perf : patch bump
+• type! or a "BREAKING CHANGE:" trailer : major bump (minor before 1.0.0)
+Other types are listed in the notes but do not trigger a release.
+
+Subcommands:
+• plan : Show the proposed version and release notes.
+• tag : Create an annotated tag with the release notes as its message.
+
+Options:
+• --root <folder> : Only act on the specified root folder.
+• --bump <major|minor|patch> : Override the computed bump.
+• --json : Print the plan as JSON (plan only).
+• --notes <file> : Write the release notes to a file (plan only).
+• --yes : Tag without asking for confirmation (tag only).
+
+Examples:
+• Preview the next release of every root folder:
+	gitcury release plan
+
+• Tag a release in one folder:
+	gitcury release tag --root my-folder
+
+Tagging is configured in the "release" config section:
+	"release": {"tagPrefix": "v", "initialVersion": "0.1.0", "signTags": true}
+
+[NOTICE]: Tags are created locally; push them with git push --follow-tags.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmd.Help(); err != nil {
+			utils.Error("Failed to show help: " + err.Error())
+		}
+	},
+}
+
+var releasePlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show the proposed next version and release notes",
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		plans, err := core.PlanReleases(releaseBump, releaseFolders()...)
+		if err != nil {
+			utils.Error("Error planning release: " + err.Error())
+		}
+		if len(plans) == 0 {
+			return
+		}
+
+		if releaseJSON {
+			utils.Print(utils.ToJSON(plans))
+			return
+		}
+
+		var notes []string
+		for _, plan := range plans {
+			from := plan.PreviousTag
+			if from == "" {
+				from = "(no tag) " + plan.PreviousVersion
+			}
+			utils.Info("📦 " + plan.Directory + ": " + from + " → " + plan.NextTag + " [" + plan.Bump + "]")
+			notes = append(notes, plan.Notes)
+		}
+		rendered := strings.Join(notes, "\n")
+
+		if releaseNotesFile != "" {
+			if err := os.WriteFile(releaseNotesFile, []byte(rendered), 0644); err != nil {
+				utils.Error("Failed to write '" + releaseNotesFile + "': " + err.Error())
+				return
+			}
+			utils.Success("✅ Release notes written to " + releaseNotesFile)
+			return
+		}
+		utils.Print(rendered)
+	},
+}
+
+var releaseTagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Create annotated release tags",
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		plans, err := core.PlanReleases(releaseBump, releaseFolders()...)
+		if err != nil {
+			utils.Error("Error planning release: " + err.Error())
+			return
+		}
+
+		var details []string
+		for _, plan := range plans {
+			if plan.Bump != git.BumpNone {
+				details = append(details, plan.Directory+": "+plan.NextTag+" ("+plan.Bump+", "+
+					strconv.Itoa(len(plan.Commits))+" commits)")
+			}
+		}
+		if len(details) == 0 {
+			utils.Info("Nothing to release.")
+			return
+		}
+		if !releaseYes && !utils.ConfirmActionWithDetails("Create these release tags?", details, true) {
+			utils.Info("Tagging cancelled.")
+			return
+		}
+
+		if err := core.TagReleases(plans); err != nil {
+			utils.Error("Error tagging release: " + err.Error())
+			return
+		}
+		utils.Success("✅ Release tags created. Push them with: git push --follow-tags")
+	},
+}
+
+// releaseFolders returns the root folder selected with --root, if any
+func releaseFolders() []string {
+	if releaseRootFolder == "" {
+		return nil
+	}
+	return []string{releaseRootFolder}
+}
+
+func init() {
+	releaseCmd.PersistentFlags().StringVarP(&releaseRootFolder, "root", "r", "", "Only act on the specified root folder")
+	releaseCmd.PersistentFlags().StringVar(&releaseBump, "bump", "", "Override the computed bump: major, minor or patch")
+	releasePlanCmd.Flags().BoolVar(&releaseJSON, "json", false, "Print the plan as JSON")
+	releasePlanCmd.Flags().StringVar(&releaseNotesFile, "notes", "", "Write the release notes to a file")
+	releaseTagCmd.Flags().BoolVarP(&releaseYes, "yes", "y", false, "Tag without asking for confirmation")
+
+	releaseCmd.AddCommand(releasePlanCmd)
+	releaseCmd.AddCommand(releaseTagCmd)
+
+	utils.AddStatsPostRunToCommand(releasePlanCmd)
+	utils.AddStatsPostRunToCommand(releaseTagCmd)
+
+	rootCmd.AddCommand(releaseCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	rewordRoot   string
+	rewordYes    bool
+	rewordDryRun bool
+)
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword <range>",
+	Short: "Regenerate the messages of unpushed commits",
+	Long: `
+Regenerate the messages of existing local commits from their diffs and rewrite them.
+
+Each commit in the range is shown with its old and new message for approval; approved
+messages are applied with a non-interactive rebase. The tree of every commit is unchanged.
+
+Range:
+• <base>..HEAD : The commits after <base>, e.g. HEAD~3..HEAD or @{u}..HEAD.
+• <base> : Shorthand for <base>..HEAD.
+
+Options:
+• --root <folder> : Root folder to act on (default: the only configured root folder).
+• --yes : Accept every new message without asking.
+• --dry-run : Only show the proposed messages.
+
+Examples:
+• Clean up the last three wip commits:
+	gitcury reword HEAD~3
+
+• Reword everything not yet pushed:
+	gitcury reword @{u}..HEAD --root my-folder
+
+[NOTICE]: Commits that are reachable from a remote-tracking branch are never rewritten.
+[NOTICE]: HEAD is saved under refs/gitcury/backups/reword/ before rewriting; restore it with git reset --hard <ref>.
+[NOTICE]: Hooks are not run when amending the messages.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		if err := core.RewordCommits(rewordRoot, args[0], rewordYes, rewordDryRun); err != nil {
+			utils.Error("Error rewording commits: " + utils.ToUserFriendlyMessage(err))
+			return
+		}
+	},
+}
+
+func init() {
+	rewordCmd.Flags().StringVarP(&rewordRoot, "root", "r", "", "Root folder to act on")
+	rewordCmd.Flags().BoolVarP(&rewordYes, "yes", "y", false, "Accept every new message without asking")
+	rewordCmd.Flags().BoolVar(&rewordDryRun, "dry-run", false, "Only show the proposed messages")
+
+	utils.AddStatsPostRunToCommand(rewordCmd)
+
+	rootCmd.AddCommand(rewordCmd)
+}
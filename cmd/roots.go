@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	rootsDepth int
+	rootsYes   bool
+	rootsJSON  bool
+)
+
+var rootsCmd = &cobra.Command{
+	Use:   "roots",
+	Short: "Discover and list root folders",
+	Long: `
+Manage the repositories GitCury works on.
+
+Besides plain paths, root_folders may contain globs such as ~/work/*. They are expanded
+every time a command runs, so new repositories in the workspace are picked up automatically.
+
+Subcommands:
+• discover <dir> : Find git repositories in a workspace and add them to root_folders.
+• list : Show the configured root folders and what the globs currently match.
+
+Examples:
+• Find repositories up to two levels below ~/work:
+	gitcury roots discover ~/work --depth 2
+
+• Use every repository directly below ~/work as a root folder:
+	gitcury config set --key root_folders --value "~/work/*"
+`,
+}
+
+var rootsDiscoverCmd = &cobra.Command{
+	Use:   "discover <dir>",
+	Short: "Find git repositories in a workspace and add them as root folders",
+	Long: `
+Walk a workspace to find git repositories, show which have pending changes and choose
+which ones to add to root_folders.
+
+Options:
+• --depth <n> : How many directory levels to search (default: roots.maxDepth, 3).
+• --yes : Add every new repository without asking.
+• --json : Print the repositories as JSON without changing the configuration.
+
+Examples:
+• Discover repositories in the current directory:
+	gitcury roots discover .
+
+• Add all repositories below ~/work:
+	gitcury roots discover ~/work --yes
+
+[NOTICE]: vendor, node_modules and directories matching the roots.ignore globs are skipped.
+[NOTICE]: Repositories are not searched for further repositories; see the repositories settings.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		roots, err := core.DiscoverRoots(args[0], rootsDepth)
+		if err != nil {
+			utils.Error("Error discovering repositories: " + utils.ToUserFriendlyMessage(err))
+			return
+		}
+
+		if rootsJSON {
+			utils.Print(utils.ToJSON(roots))
+			return
+		}
+		if len(roots) == 0 {
+			utils.Info("No git repositories found in " + args[0])
+			return
+		}
+
+		utils.Print(core.FormatDiscoveredRoots(roots))
+		if added := core.AddDiscoveredRoots(roots, rootsYes); len(added) == 0 {
+			utils.Info("No root folders added.")
+		}
+	},
+}
+
+var rootsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the configured root folders",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var lines []string
+		for _, entry := range config.GetRootFolderEntries() {
+			if !config.IsRootPattern(entry) {
+				lines = append(lines, entry)
+				continue
+			}
+			matches := config.ExpandRootFolder(entry)
+			lines = append(lines, fmt.Sprintf("%s (%d repositories)", entry, len(matches)))
+			for _, match := range matches {
+				lines = append(lines, "  "+match)
+			}
+		}
+
+		if rootsJSON {
+			utils.Print(utils.ToJSON(config.GetRootFolderEntries()))
+			return
+		}
+		utils.Print(strings.Join(lines, "\n"))
+	},
+}
+
+func init() {
+	rootsDiscoverCmd.Flags().IntVarP(&rootsDepth, "depth", "d", 0, "Directory levels to search (default: roots.maxDepth)")
+	rootsDiscoverCmd.Flags().BoolVarP(&rootsYes, "yes", "y", false, "Add every new repository without asking")
+	rootsCmd.PersistentFlags().BoolVar(&rootsJSON, "json", false, "Print JSON")
+
+	rootsCmd.AddCommand(rootsDiscoverCmd)
+	rootsCmd.AddCommand(rootsListCmd)
+
+	utils.AddStatsPostRunToCommand(rootsDiscoverCmd)
+
+	rootCmd.AddCommand(rootsCmd)
+}
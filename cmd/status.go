@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusRoot string
+	statusJSON bool
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state of every root folder",
+	Long: `
+Show a dashboard of all root folders before generating or committing messages.
+
+For each root folder (and each submodule or nested repository committed separately) the
+dashboard shows:
+• The branch and how far it is ahead of and behind its upstream.
+• The number of staged, modified, untracked and conflicted files.
+• How many generated messages are waiting in the output store.
+• The last GitCury operation that can still be undone.
+• The result of the repository health check (e.g. a stale index.lock).
+
+Options:
+• --root <folder> : Only show this root folder.
+• --json : Print the status as JSON.
+
+Examples:
+• Show all root folders:
+	gitcury status
+
+• Feed the status into another tool:
+	gitcury status --json | jq '.[] | select(.conflicted > 0)'
+
+[NOTICE]: The repositories are inspected concurrently; nothing is fetched from remotes.
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		var folders []string
+		if statusRoot != "" {
+			folders = append(folders, statusRoot)
+		}
+
+		statuses, err := core.CollectStatus(folders...)
+		if err != nil {
+			utils.Error("Error collecting status: " + utils.ToUserFriendlyMessage(err))
+			return
+		}
+
+		if statusJSON {
+			utils.Print(utils.ToJSON(statuses))
+			return
+		}
+		utils.Print(core.FormatStatusTable(statuses))
+	},
+}
+
+func init() {
+	statusCmd.Flags().StringVarP(&statusRoot, "root", "r", "", "Only show this root folder")
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Print the status as JSON")
+
+	utils.AddStatsPostRunToCommand(statusCmd)
+
+	rootCmd.AddCommand(statusCmd)
+}
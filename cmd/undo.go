@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	undoRoot  string
+	undoForce bool
+	undoYes   bool
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the last GitCury commit operation",
+	Long: `
+Undo the last commit operation made by GitCury.
+
+Every commit run is recorded in a journal inside each repository's git directory: the HEAD
+before the run, the commits it created, the generated messages and whether the commits were
+pushed. Undoing resets the branch to the recorded HEAD, keeps the changes in the working tree
+and restores the generated messages so they can be edited and committed again.
+
+Without --root, the latest run is undone in every root folder it touched, so a
+'gitcury commit --all' across several repositories is reverted as a whole.
+
+Options:
+• --root <folder> : Only undo the latest operation of this root folder.
+• --force : Also undo commits that were already pushed.
+• --yes : Undo without asking for confirmation.
+
+Examples:
+• Undo the last commit run in all root folders:
+	gitcury undo
+
+• Undo the last operation of a single repository:
+	gitcury undo --root /path/to/repo
+
+[NOTICE]: An operation can only be undone while HEAD is still its last commit.
+[NOTICE]: After undoing pushed commits with --force the remote needs a force push.
+[NOTICE]: The undone commits are kept under refs/gitcury/backups/undo/.
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Start tracking this command if stats are enabled
+		if utils.IsStatsEnabled() {
+			utils.StartOperation("Command:" + cmd.Name())
+		}
+
+		if err := core.UndoLastOperation(undoRoot, undoForce, undoYes); err != nil {
+			utils.Error("Error undoing operation: " + utils.ToUserFriendlyMessage(err))
+			return
+		}
+	},
+}
+
+func init() {
+	undoCmd.Flags().StringVarP(&undoRoot, "root", "r", "", "Root folder to undo the latest operation of")
+	undoCmd.Flags().BoolVarP(&undoForce, "force", "f", false, "Also undo commits that were already pushed")
+	undoCmd.Flags().BoolVarP(&undoYes, "yes", "y", false, "Undo without asking for confirmation")
+
+	utils.AddStatsPostRunToCommand(undoCmd)
+
+	rootCmd.AddCommand(undoCmd)
+}
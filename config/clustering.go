@@ -11,19 +11,22 @@ import (
 type ClusteringConfig struct {
 	DefaultMethod                 string             `json:"defaultMethod"`
 	EnableFallbackMethods         bool               `json:"enableFallbackMethods"`
-	MaxFilesForSemanticClustering int                `json:"maxFilesForSemanticClustering"`
+	MaxFilesForSemanticClustering int                `json:"maxFilesForSemanticClustering"` // Sampling cut-over, not a limit; see ScalableClusteringMinFiles
 	ConfidenceThresholds          map[string]float64 `json:"confidenceThresholds"`
 	SimilarityThresholds          map[string]float64 `json:"similarityThresholds"`
 	Methods                       ClusteringMethods  `json:"methods"`
 	Performance                   PerformanceConfig  `json:"performance"`
+	ClusterCount                  ClusterCountConfig `json:"clusterCount"`
 }
 
 // ClusteringMethods holds method-specific configurations
 type ClusteringMethods struct {
-	Directory DirectoryConfig `json:"directory"`
-	Pattern   PatternConfig   `json:"pattern"`
-	Cached    CachedConfig    `json:"cached"`
-	Semantic  SemanticConfig  `json:"semantic"`
+	Directory  DirectoryConfig  `json:"directory"`
+	Pattern    PatternConfig    `json:"pattern"`
+	Dependency DependencyConfig `json:"dependency"`
+	History    HistoryConfig    `json:"history"`
+	Cached     CachedConfig     `json:"cached"`
+	Semantic   SemanticConfig   `json:"semantic"`
 }
 
 // DirectoryConfig holds directory-based clustering settings
@@ -38,12 +41,29 @@ type PatternConfig struct {
 	Weight  float64 `json:"weight"`
 }
 
+// DependencyConfig holds import and call graph clustering settings
+type DependencyConfig struct {
+	Enabled bool    `json:"enabled"`
+	Weight  float64 `json:"weight"`
+}
+
+// HistoryConfig holds co-change history clustering settings
+type HistoryConfig struct {
+	Enabled        bool    `json:"enabled"`
+	Weight         float64 `json:"weight"`         // Share of the co-change signal in hybrid scoring
+	MaxCommits     int     `json:"maxCommits"`     // Commits mined when the history is first read
+	HalfLifeDays   int     `json:"halfLifeDays"`   // Age at which a commit counts half
+	MaxCommitFiles int     `json:"maxCommitFiles"` // Larger commits (mass renames, formatting) are skipped
+	MaxFiles       int     `json:"maxFiles"`       // Files kept in the co-change matrix
+}
+
 // CachedConfig holds cached embedding clustering settings
 type CachedConfig struct {
 	Enabled          bool    `json:"enabled"`
 	Weight           float64 `json:"weight"`
 	MinCacheHitRatio float64 `json:"minCacheHitRatio"`
-	MaxCacheAge      int     `json:"maxCacheAge"` // hours
+	MaxCacheAge      int     `json:"maxCacheAge"`    // hours
+	VectorEncoding   string  `json:"vectorEncoding"` // "float32" or "int8" in the embedding store
 }
 
 // SemanticConfig holds semantic clustering settings
@@ -53,32 +73,117 @@ type SemanticConfig struct {
 	RateLimitDelay          int     `json:"rateLimitDelay"` // milliseconds
 	MaxConcurrentEmbeddings int     `json:"maxConcurrentEmbeddings"`
 	EmbeddingTimeout        int     `json:"embeddingTimeout"` // seconds
+	Backend                 string  `json:"backend"`          // "gemini", "local" or "http"
+	Endpoint                string  `json:"endpoint"`         // Embedding server URL for the http backend
+	Model                   string  `json:"model"`            // Model requested from the http backend
+	Dimensions              int     `json:"dimensions"`       // Vector size of the local backend
+	EmbedInput              string  `json:"embedInput"`       // "file" embeds the file's diff, "hunks" each changed hunk
 }
 
+// Embedding backends selectable in clustering.methods.semantic.backend
+const (
+	GeminiEmbeddingBackend = "gemini" // text-embedding-004 through the Gemini API
+	LocalEmbeddingBackend  = "local"  // Hashed n-gram vectors computed in process
+	HTTPEmbeddingBackend   = "http"   // A local embedding server, e.g. Ollama or an OpenAI-compatible one
+)
+
+// Vector encodings of the embedding store, selectable in clustering.methods.cached.vectorEncoding
+const (
+	Float32VectorEncoding = "float32" // Exact vectors, 4 bytes per dimension
+	Int8VectorEncoding    = "int8"    // Quantized vectors, 1 byte per dimension
+)
+
+// What is embedded for each changed file, selectable in clustering.methods.semantic.embedInput
+const (
+	EmbedFileDiff  = "file"  // The file's diff as a whole, cached by file content
+	EmbedDiffHunks = "hunks" // Each normalized hunk, cached by hunk hash and averaged per file
+)
+
 // PerformanceConfig holds performance-related settings
 type PerformanceConfig struct {
 	PreferSpeed          bool `json:"preferSpeed"`
 	MaxProcessingTime    int  `json:"maxProcessingTime"` // seconds
 	EnableBenchmarking   bool `json:"enableBenchmarking"`
 	AdaptiveOptimization bool `json:"adaptiveOptimization"`
+
+	// Change sets with at least this many files use mini-batch K-means and LSH-based DBSCAN
+	// instead of the quadratic algorithms; 0 never switches. The semantic methods embed every
+	// file, so maxFilesForSemanticClustering does not cap them: it only chose when to sample,
+	// and the sampling layer is disabled.
+	ScalableClusteringMinFiles int `json:"scalableClusteringMinFiles"`
 }
 
+// ClusterCountConfig holds how the number of clusters is chosen when none is given
+type ClusterCountConfig struct {
+	Auto        bool   `json:"auto"`        // Choose the number of commits instead of using numFilesToCommit
+	MinClusters int    `json:"minClusters"` // 1 allows keeping the whole change in one commit
+	MaxClusters int    `json:"maxClusters"`
+	Criterion   string `json:"criterion"` // "silhouette", "elbow", "gap" or "vote"
+}
+
+// Criteria choosing the number of clusters, selectable in clustering.clusterCount.criterion
+const (
+	SilhouetteCriterion = "silhouette" // Highest average silhouette
+	ElbowCriterion      = "elbow"      // Bend of the inertia curve
+	GapCriterion        = "gap"        // Gap statistic against uniform reference data
+	VoteCriterion       = "vote"       // The k most of the three agree on
+)
+
 // ClusteringMethod represents available clustering methods
 type ClusteringMethod string
 
 const (
-	DirectoryMethod ClusteringMethod = "directory"
-	PatternMethod   ClusteringMethod = "pattern"
-	CachedMethod    ClusteringMethod = "cached"
-	SemanticMethod  ClusteringMethod = "semantic"
-	AutoMethod      ClusteringMethod = "auto" // Uses the smart multi-layered approach
+	DirectoryMethod  ClusteringMethod = "directory"
+	PatternMethod    ClusteringMethod = "pattern"
+	DependencyMethod ClusteringMethod = "dependency" // Files that reference each other's changed symbols
+	HistoryMethod    ClusteringMethod = "history"    // Files that changed together in past commits
+	CachedMethod     ClusteringMethod = "cached"
+	SemanticMethod   ClusteringMethod = "semantic"
+	AutoMethod       ClusteringMethod = "auto" // Uses the smart multi-layered approach
 )
 
+// ClusteringPresets are the names accepted by ApplyClusteringPreset
+var ClusteringPresets = []string{"speed", "balanced", "quality"}
+
+// clusteringOverride, when set, is returned by GetClusteringConfig instead of the stored
+// configuration
+var clusteringOverride *ClusteringConfig
+
+// OverrideClusteringConfig makes GetClusteringConfig return config without saving it, e.g. to
+// evaluate a preset. Passing nil restores the stored configuration.
+func OverrideClusteringConfig(config *ClusteringConfig) {
+	mu.Lock()
+	defer mu.Unlock()
+	if config == nil {
+		clusteringOverride = nil
+		return
+	}
+	clusteringOverride = config.clone()
+}
+
+// clone returns a copy of config that shares no maps with it
+func (config *ClusteringConfig) clone() *ClusteringConfig {
+	copied := *config
+	copied.ConfidenceThresholds = make(map[string]float64, len(config.ConfidenceThresholds))
+	for method, threshold := range config.ConfidenceThresholds {
+		copied.ConfidenceThresholds[method] = threshold
+	}
+	copied.SimilarityThresholds = make(map[string]float64, len(config.SimilarityThresholds))
+	for method, threshold := range config.SimilarityThresholds {
+		copied.SimilarityThresholds[method] = threshold
+	}
+	return &copied
+}
+
 // GetClusteringConfig retrieves the clustering configuration
 func GetClusteringConfig() *ClusteringConfig {
 	mu.RLock()
 	defer mu.RUnlock()
 
+	if clusteringOverride != nil {
+		return clusteringOverride.clone()
+	}
+
 	clusteringSettings, exists := settings["clustering"]
 	if !exists {
 		log.Println("[Config]: Clustering configuration not found, using defaults")
@@ -116,18 +221,22 @@ func GetClusteringConfig() *ClusteringConfig {
 
 	// Parse confidence thresholds
 	config.ConfidenceThresholds = parseFloatMap(clusteringMap, "confidenceThresholds", map[string]float64{
-		"directory": 0.8,
-		"pattern":   0.7,
-		"cached":    0.6,
-		"semantic":  0.5,
+		"directory":  0.8,
+		"pattern":    0.7,
+		"dependency": 0.6,
+		"history":    0.5,
+		"cached":     0.6,
+		"semantic":   0.5,
 	})
 
 	// Parse similarity thresholds
 	config.SimilarityThresholds = parseFloatMap(clusteringMap, "similarityThresholds", map[string]float64{
-		"directory": 0.7,
-		"pattern":   0.6,
-		"cached":    0.5,
-		"semantic":  0.4,
+		"directory":  0.7,
+		"pattern":    0.6,
+		"dependency": 0.5,
+		"history":    0.3,
+		"cached":     0.5,
+		"semantic":   0.4,
 	})
 
 	// Parse methods configuration
@@ -144,6 +253,13 @@ func GetClusteringConfig() *ClusteringConfig {
 		config.Performance = getDefaultPerformanceConfig()
 	}
 
+	// Parse cluster count configuration
+	if countMap, ok := clusteringMap["clusterCount"].(map[string]interface{}); ok {
+		config.ClusterCount = parseClusterCountConfig(countMap)
+	} else {
+		config.ClusterCount = getDefaultClusterCountConfig()
+	}
+
 	return config
 }
 
@@ -166,11 +282,24 @@ func SetClusteringConfig(config *ClusteringConfig) error {
 				"enabled": config.Methods.Pattern.Enabled,
 				"weight":  config.Methods.Pattern.Weight,
 			},
+			"dependency": map[string]interface{}{
+				"enabled": config.Methods.Dependency.Enabled,
+				"weight":  config.Methods.Dependency.Weight,
+			},
+			"history": map[string]interface{}{
+				"enabled":        config.Methods.History.Enabled,
+				"weight":         config.Methods.History.Weight,
+				"maxCommits":     config.Methods.History.MaxCommits,
+				"halfLifeDays":   config.Methods.History.HalfLifeDays,
+				"maxCommitFiles": config.Methods.History.MaxCommitFiles,
+				"maxFiles":       config.Methods.History.MaxFiles,
+			},
 			"cached": map[string]interface{}{
 				"enabled":          config.Methods.Cached.Enabled,
 				"weight":           config.Methods.Cached.Weight,
 				"minCacheHitRatio": config.Methods.Cached.MinCacheHitRatio,
 				"maxCacheAge":      config.Methods.Cached.MaxCacheAge,
+				"vectorEncoding":   config.Methods.Cached.VectorEncoding,
 			},
 			"semantic": map[string]interface{}{
 				"enabled":                 config.Methods.Semantic.Enabled,
@@ -178,13 +307,25 @@ func SetClusteringConfig(config *ClusteringConfig) error {
 				"rateLimitDelay":          config.Methods.Semantic.RateLimitDelay,
 				"maxConcurrentEmbeddings": config.Methods.Semantic.MaxConcurrentEmbeddings,
 				"embeddingTimeout":        config.Methods.Semantic.EmbeddingTimeout,
+				"backend":                 config.Methods.Semantic.Backend,
+				"endpoint":                config.Methods.Semantic.Endpoint,
+				"model":                   config.Methods.Semantic.Model,
+				"dimensions":              config.Methods.Semantic.Dimensions,
+				"embedInput":              config.Methods.Semantic.EmbedInput,
 			},
 		},
 		"performance": map[string]interface{}{
-			"preferSpeed":          config.Performance.PreferSpeed,
-			"maxProcessingTime":    config.Performance.MaxProcessingTime,
-			"enableBenchmarking":   config.Performance.EnableBenchmarking,
-			"adaptiveOptimization": config.Performance.AdaptiveOptimization,
+			"preferSpeed":                config.Performance.PreferSpeed,
+			"maxProcessingTime":          config.Performance.MaxProcessingTime,
+			"enableBenchmarking":         config.Performance.EnableBenchmarking,
+			"adaptiveOptimization":       config.Performance.AdaptiveOptimization,
+			"scalableClusteringMinFiles": config.Performance.ScalableClusteringMinFiles,
+		},
+		"clusterCount": map[string]interface{}{
+			"auto":        config.ClusterCount.Auto,
+			"minClusters": config.ClusterCount.MinClusters,
+			"maxClusters": config.ClusterCount.MaxClusters,
+			"criterion":   config.ClusterCount.Criterion,
 		},
 	}
 
@@ -226,6 +367,37 @@ func SetClusteringConfigByKey(key, value string) error {
 		} else {
 			return fmt.Errorf("invalid boolean value for adaptive_optimization: %s", value)
 		}
+	case "scalable_min_files":
+		if intVal, err := parseInt(value); err == nil && intVal >= 0 {
+			configCopy.Performance.ScalableClusteringMinFiles = intVal
+		} else {
+			return fmt.Errorf("invalid non-negative integer value for scalable_min_files: %s", value)
+		}
+	case "cluster_count_auto":
+		if boolVal, err := parseBool(value); err == nil {
+			configCopy.ClusterCount.Auto = boolVal
+		} else {
+			return fmt.Errorf("invalid boolean value for cluster_count_auto: %s", value)
+		}
+	case "cluster_count_min":
+		if intVal, err := parseInt(value); err == nil && intVal >= 1 && intVal <= configCopy.ClusterCount.MaxClusters {
+			configCopy.ClusterCount.MinClusters = intVal
+		} else {
+			return fmt.Errorf("invalid value for cluster_count_min: %s (must be between 1 and cluster_count_max)", value)
+		}
+	case "cluster_count_max":
+		if intVal, err := parseInt(value); err == nil && intVal >= configCopy.ClusterCount.MinClusters {
+			configCopy.ClusterCount.MaxClusters = intVal
+		} else {
+			return fmt.Errorf("invalid value for cluster_count_max: %s (must be at least cluster_count_min)", value)
+		}
+	case "cluster_count_criterion":
+		switch value {
+		case SilhouetteCriterion, ElbowCriterion, GapCriterion, VoteCriterion:
+			configCopy.ClusterCount.Criterion = value
+		default:
+			return fmt.Errorf("invalid cluster count criterion: %s (valid: silhouette, elbow, gap, vote)", value)
+		}
 	case "performance_mode":
 		switch value {
 		case "speed":
@@ -293,6 +465,64 @@ func SetClusteringConfigByKey(key, value string) error {
 			return fmt.Errorf("invalid float value for pattern_similarity_threshold: %s", value)
 		}
 
+	// Dependency method settings
+	case "dependency_enabled":
+		if boolVal, err := parseBool(value); err == nil {
+			configCopy.Methods.Dependency.Enabled = boolVal
+		} else {
+			return fmt.Errorf("invalid boolean value for dependency_enabled: %s", value)
+		}
+	case "dependency_weight":
+		if floatVal, err := parseFloat(value); err == nil {
+			configCopy.Methods.Dependency.Weight = floatVal
+		} else {
+			return fmt.Errorf("invalid float value for dependency_weight: %s", value)
+		}
+	case "dependency_confidence_threshold":
+		if floatVal, err := parseFloat(value); err == nil {
+			configCopy.ConfidenceThresholds["dependency"] = floatVal
+		} else {
+			return fmt.Errorf("invalid float value for dependency_confidence_threshold: %s", value)
+		}
+
+	// History method settings
+	case "history_enabled":
+		if boolVal, err := parseBool(value); err == nil {
+			configCopy.Methods.History.Enabled = boolVal
+		} else {
+			return fmt.Errorf("invalid boolean value for history_enabled: %s", value)
+		}
+	case "history_weight":
+		if floatVal, err := parseFloat(value); err == nil {
+			configCopy.Methods.History.Weight = floatVal
+		} else {
+			return fmt.Errorf("invalid float value for history_weight: %s", value)
+		}
+	case "history_max_commits":
+		if intVal, err := parseInt(value); err == nil {
+			configCopy.Methods.History.MaxCommits = intVal
+		} else {
+			return fmt.Errorf("invalid integer value for history_max_commits: %s", value)
+		}
+	case "history_half_life_days":
+		if intVal, err := parseInt(value); err == nil {
+			configCopy.Methods.History.HalfLifeDays = intVal
+		} else {
+			return fmt.Errorf("invalid integer value for history_half_life_days: %s", value)
+		}
+	case "history_confidence_threshold":
+		if floatVal, err := parseFloat(value); err == nil {
+			configCopy.ConfidenceThresholds["history"] = floatVal
+		} else {
+			return fmt.Errorf("invalid float value for history_confidence_threshold: %s", value)
+		}
+	case "history_similarity_threshold":
+		if floatVal, err := parseFloat(value); err == nil {
+			configCopy.SimilarityThresholds["history"] = floatVal
+		} else {
+			return fmt.Errorf("invalid float value for history_similarity_threshold: %s", value)
+		}
+
 	// Cached method settings
 	case "cached_enabled":
 		if boolVal, err := parseBool(value); err == nil {
@@ -318,6 +548,13 @@ func SetClusteringConfigByKey(key, value string) error {
 		} else {
 			return fmt.Errorf("invalid float value for cached_similarity_threshold: %s", value)
 		}
+	case "cached_vector_encoding":
+		switch value {
+		case Float32VectorEncoding, Int8VectorEncoding:
+			configCopy.Methods.Cached.VectorEncoding = value
+		default:
+			return fmt.Errorf("invalid vector encoding: %s (use float32 or int8)", value)
+		}
 	case "cached_delay_ms":
 		if _, err := parseInt(value); err == nil {
 			// Note: This could be added to CachedConfig if needed
@@ -357,6 +594,30 @@ func SetClusteringConfigByKey(key, value string) error {
 		} else {
 			return fmt.Errorf("invalid integer value for semantic_rate_limit_delay: %s", value)
 		}
+	case "semantic_backend":
+		switch value {
+		case GeminiEmbeddingBackend, LocalEmbeddingBackend, HTTPEmbeddingBackend:
+			configCopy.Methods.Semantic.Backend = value
+		default:
+			return fmt.Errorf("invalid embedding backend: %s (use gemini, local or http)", value)
+		}
+	case "semantic_embed_input":
+		switch value {
+		case EmbedFileDiff, EmbedDiffHunks:
+			configCopy.Methods.Semantic.EmbedInput = value
+		default:
+			return fmt.Errorf("invalid embed input: %s (use file or hunks)", value)
+		}
+	case "semantic_endpoint":
+		configCopy.Methods.Semantic.Endpoint = value
+	case "semantic_model":
+		configCopy.Methods.Semantic.Model = value
+	case "semantic_dimensions":
+		if intVal, err := parseInt(value); err == nil && intVal > 0 {
+			configCopy.Methods.Semantic.Dimensions = intVal
+		} else {
+			return fmt.Errorf("invalid integer value for semantic_dimensions: %s", value)
+		}
 
 	default:
 		return fmt.Errorf("unknown clustering configuration key: %s", key)
@@ -368,20 +629,26 @@ func SetClusteringConfigByKey(key, value string) error {
 
 // ApplyClusteringPreset applies a predefined clustering configuration preset
 func ApplyClusteringPreset(presetName string) error {
-	var config *ClusteringConfig
+	config, err := GetClusteringPreset(presetName)
+	if err != nil {
+		return err
+	}
+
+	return SetClusteringConfig(config)
+}
 
+// GetClusteringPreset returns the configuration of a preset without applying it
+func GetClusteringPreset(presetName string) (*ClusteringConfig, error) {
 	switch presetName {
 	case "speed":
-		config = GetSpeedOptimizedConfig()
+		return GetSpeedOptimizedConfig(), nil
 	case "balanced":
-		config = GetBalancedConfig()
+		return GetBalancedConfig(), nil
 	case "quality":
-		config = GetQualityOptimizedConfig()
+		return GetQualityOptimizedConfig(), nil
 	default:
-		return fmt.Errorf("unknown preset: %s (valid presets: speed, balanced, quality)", presetName)
+		return nil, fmt.Errorf("unknown preset: %s (valid presets: speed, balanced, quality)", presetName)
 	}
-
-	return SetClusteringConfig(config)
 }
 
 // Helper functions for parsing configuration
@@ -429,6 +696,30 @@ func parseMethodsConfig(methodsMap map[string]interface{}) ClusteringMethods {
 		methods.Pattern = PatternConfig{Enabled: true, Weight: 0.8}
 	}
 
+	// Parse dependency config
+	if dependencyMap, ok := methodsMap["dependency"].(map[string]interface{}); ok {
+		methods.Dependency = DependencyConfig{
+			Enabled: getBoolOrDefault(dependencyMap, "enabled", false),
+			Weight:  getFloatOrDefault(dependencyMap, "weight", 0.9),
+		}
+	} else {
+		methods.Dependency = DependencyConfig{Enabled: false, Weight: 0.9}
+	}
+
+	// Parse history config
+	if historyMap, ok := methodsMap["history"].(map[string]interface{}); ok {
+		methods.History = HistoryConfig{
+			Enabled:        getBoolOrDefault(historyMap, "enabled", false),
+			Weight:         getFloatOrDefault(historyMap, "weight", 0.3),
+			MaxCommits:     getIntOrDefault(historyMap, "maxCommits", 1000),
+			HalfLifeDays:   getIntOrDefault(historyMap, "halfLifeDays", 90),
+			MaxCommitFiles: getIntOrDefault(historyMap, "maxCommitFiles", 30),
+			MaxFiles:       getIntOrDefault(historyMap, "maxFiles", 2000),
+		}
+	} else {
+		methods.History = getDefaultHistoryConfig()
+	}
+
 	// Parse cached config
 	if cachedMap, ok := methodsMap["cached"].(map[string]interface{}); ok {
 		methods.Cached = CachedConfig{
@@ -436,11 +727,10 @@ func parseMethodsConfig(methodsMap map[string]interface{}) ClusteringMethods {
 			Weight:           getFloatOrDefault(cachedMap, "weight", 0.6),
 			MinCacheHitRatio: getFloatOrDefault(cachedMap, "minCacheHitRatio", 0.4),
 			MaxCacheAge:      getIntOrDefault(cachedMap, "maxCacheAge", 24),
+			VectorEncoding:   getStringOrDefault(cachedMap, "vectorEncoding", Float32VectorEncoding),
 		}
 	} else {
-		methods.Cached = CachedConfig{
-			Enabled: true, Weight: 0.6, MinCacheHitRatio: 0.4, MaxCacheAge: 24,
-		}
+		methods.Cached = getDefaultCachedConfig()
 	}
 
 	// Parse semantic config
@@ -451,12 +741,14 @@ func parseMethodsConfig(methodsMap map[string]interface{}) ClusteringMethods {
 			RateLimitDelay:          getIntOrDefault(semanticMap, "rateLimitDelay", 2000),
 			MaxConcurrentEmbeddings: getIntOrDefault(semanticMap, "maxConcurrentEmbeddings", 1),
 			EmbeddingTimeout:        getIntOrDefault(semanticMap, "embeddingTimeout", 30),
+			Backend:                 getStringOrDefault(semanticMap, "backend", GeminiEmbeddingBackend),
+			Endpoint:                getStringOrDefault(semanticMap, "endpoint", "http://localhost:11434/api/embed"),
+			Model:                   getStringOrDefault(semanticMap, "model", "nomic-embed-text"),
+			Dimensions:              getIntOrDefault(semanticMap, "dimensions", 512),
+			EmbedInput:              getStringOrDefault(semanticMap, "embedInput", EmbedFileDiff),
 		}
 	} else {
-		methods.Semantic = SemanticConfig{
-			Enabled: true, Weight: 0.4, RateLimitDelay: 2000,
-			MaxConcurrentEmbeddings: 1, EmbeddingTimeout: 30,
-		}
+		methods.Semantic = getDefaultSemanticConfig()
 	}
 
 	return methods
@@ -468,6 +760,18 @@ func parsePerformanceConfig(perfMap map[string]interface{}) PerformanceConfig {
 		MaxProcessingTime:    getIntOrDefault(perfMap, "maxProcessingTime", 60),
 		EnableBenchmarking:   getBoolOrDefault(perfMap, "enableBenchmarking", false),
 		AdaptiveOptimization: getBoolOrDefault(perfMap, "adaptiveOptimization", true),
+
+		ScalableClusteringMinFiles: getIntOrDefault(perfMap, "scalableClusteringMinFiles", 200),
+	}
+}
+
+func parseClusterCountConfig(countMap map[string]interface{}) ClusterCountConfig {
+	defaults := getDefaultClusterCountConfig()
+	return ClusterCountConfig{
+		Auto:        getBoolOrDefault(countMap, "auto", defaults.Auto),
+		MinClusters: getIntOrDefault(countMap, "minClusters", defaults.MinClusters),
+		MaxClusters: getIntOrDefault(countMap, "maxClusters", defaults.MaxClusters),
+		Criterion:   getStringOrDefault(countMap, "criterion", defaults.Criterion),
 	}
 }
 
@@ -506,33 +810,58 @@ func getDefaultClusteringConfig() *ClusteringConfig {
 		EnableFallbackMethods:         true,
 		MaxFilesForSemanticClustering: 10,
 		ConfidenceThresholds: map[string]float64{
-			"directory": 0.8,
-			"pattern":   0.7,
-			"cached":    0.6,
-			"semantic":  0.5,
+			"directory":  0.8,
+			"pattern":    0.7,
+			"dependency": 0.6,
+			"history":    0.5,
+			"cached":     0.6,
+			"semantic":   0.5,
 		},
 		SimilarityThresholds: map[string]float64{
-			"directory": 0.7,
-			"pattern":   0.6,
-			"cached":    0.5,
-			"semantic":  0.4,
+			"directory":  0.7,
+			"pattern":    0.6,
+			"dependency": 0.5,
+			"history":    0.3,
+			"cached":     0.5,
+			"semantic":   0.4,
 		},
-		Methods:     getDefaultMethodsConfig(),
-		Performance: getDefaultPerformanceConfig(),
+		Methods:      getDefaultMethodsConfig(),
+		Performance:  getDefaultPerformanceConfig(),
+		ClusterCount: getDefaultClusterCountConfig(),
 	}
 }
 
 func getDefaultMethodsConfig() ClusteringMethods {
 	return ClusteringMethods{
-		Directory: DirectoryConfig{Enabled: true, Weight: 1.0},
-		Pattern:   PatternConfig{Enabled: true, Weight: 0.8},
-		Cached: CachedConfig{
-			Enabled: true, Weight: 0.6, MinCacheHitRatio: 0.4, MaxCacheAge: 24,
-		},
-		Semantic: SemanticConfig{
-			Enabled: true, Weight: 0.4, RateLimitDelay: 2000,
-			MaxConcurrentEmbeddings: 1, EmbeddingTimeout: 30,
-		},
+		Directory:  DirectoryConfig{Enabled: true, Weight: 1.0},
+		Pattern:    PatternConfig{Enabled: true, Weight: 0.8},
+		Dependency: DependencyConfig{Enabled: false, Weight: 0.9}, // Opt-in: parses every changed Go file
+		History:    getDefaultHistoryConfig(),
+		Cached:     getDefaultCachedConfig(),
+		Semantic:   getDefaultSemanticConfig(),
+	}
+}
+
+func getDefaultCachedConfig() CachedConfig {
+	return CachedConfig{
+		Enabled: true, Weight: 0.6, MinCacheHitRatio: 0.4, MaxCacheAge: 24,
+		VectorEncoding: Float32VectorEncoding,
+	}
+}
+
+func getDefaultSemanticConfig() SemanticConfig {
+	return SemanticConfig{
+		Enabled: true, Weight: 0.4, RateLimitDelay: 2000,
+		MaxConcurrentEmbeddings: 1, EmbeddingTimeout: 30,
+		Backend: GeminiEmbeddingBackend, Endpoint: "http://localhost:11434/api/embed",
+		Model: "nomic-embed-text", Dimensions: 512, EmbedInput: EmbedFileDiff,
+	}
+}
+
+func getDefaultHistoryConfig() HistoryConfig {
+	return HistoryConfig{
+		Enabled: false, Weight: 0.3, MaxCommits: 1000, HalfLifeDays: 90, // Opt-in: mines git log per root folder
+		MaxCommitFiles: 30, MaxFiles: 2000,
 	}
 }
 
@@ -542,9 +871,15 @@ func getDefaultPerformanceConfig() PerformanceConfig {
 		MaxProcessingTime:    60,
 		EnableBenchmarking:   false,
 		AdaptiveOptimization: true,
+
+		ScalableClusteringMinFiles: 200,
 	}
 }
 
+func getDefaultClusterCountConfig() ClusterCountConfig {
+	return ClusterCountConfig{Auto: true, MinClusters: 1, MaxClusters: 12, Criterion: VoteCriterion}
+}
+
 // Configuration presets for different use cases
 
 // GetSpeedOptimizedConfig returns configuration optimized for speed
@@ -575,6 +910,11 @@ func GetQualityOptimizedConfig() *ClusteringConfig {
 	config.Methods.Semantic.Enabled = true
 	config.Methods.Semantic.Weight = 1.0
 	config.Methods.Semantic.RateLimitDelay = 1000 // Faster API calls if possible
+
+	// Slower methods that can link files across directories
+	config.Methods.Dependency.Enabled = true
+	config.Methods.History.Enabled = true
+
 	config.Performance.PreferSpeed = false
 	config.Performance.MaxProcessingTime = 120 // Longer timeout for quality
 	config.Performance.EnableBenchmarking = true
@@ -621,6 +961,10 @@ func IsMethodEnabled(method ClusteringMethod) bool {
 		return config.Methods.Directory.Enabled
 	case PatternMethod:
 		return config.Methods.Pattern.Enabled
+	case DependencyMethod:
+		return config.Methods.Dependency.Enabled
+	case HistoryMethod:
+		return config.Methods.History.Enabled
 	case CachedMethod:
 		return config.Methods.Cached.Enabled
 	case SemanticMethod:
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CommitConfig holds the options used when GitCury runs `git commit` in a root folder.
+// It is read from the "commit" section of the configuration; entries under "commit.roots"
+// keyed by root folder path override the global values for that folder.
+type CommitConfig struct {
+	NoVerify      bool   `json:"noVerify"`      // Skip pre-commit and commit-msg hooks
+	Sign          bool   `json:"sign"`          // Sign commits (-S)
+	SigningKey    string `json:"signingKey"`    // Key ID, or public key path for SSH signing
+	SigningFormat string `json:"signingFormat"` // openpgp, ssh or x509; empty keeps gpg.format
+}
+
+// Environment variables that override the commit configuration for a single run
+const (
+	NoVerifyEnv   = "GITCURY_NO_VERIFY"
+	SignEnv       = "GITCURY_SIGN"
+	SigningKeyEnv = "GITCURY_SIGNING_KEY"
+	// OperationIDEnv ties the journal entries of all root folders of one run together
+	OperationIDEnv = "GITCURY_OPERATION_ID"
+	// CommitDatesEnv holds comma-separated RFC 3339 dates, one per commit of a batch in order
+	CommitDatesEnv = "GITCURY_COMMIT_DATES"
+)
+
+// GetCommitConfig returns the commit options for rootFolder. Overrides passed in env
+// (as KEY=VALUE pairs) or set in the process environment take precedence over the config file.
+func GetCommitConfig(rootFolder string, env ...[]string) CommitConfig {
+	section := GetRootSection("commit", rootFolder)
+
+	commitConfig := CommitConfig{
+		NoVerify:      getBoolOrDefault(section, "noVerify", false),
+		Sign:          getBoolOrDefault(section, "sign", false),
+		SigningKey:    getStringOrDefault(section, "signingKey", ""),
+		SigningFormat: getStringOrDefault(section, "signingFormat", ""),
+	}
+
+	overrides := make(map[string]string)
+	for _, key := range []string{NoVerifyEnv, SignEnv, SigningKeyEnv} {
+		if value, ok := os.LookupEnv(key); ok {
+			overrides[key] = value
+		}
+	}
+	if len(env) > 0 {
+		for _, pair := range env[0] {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) == 2 && strings.HasPrefix(parts[0], "GITCURY_") {
+				overrides[parts[0]] = parts[1]
+			}
+		}
+	}
+
+	if value, ok := overrides[NoVerifyEnv]; ok {
+		commitConfig.NoVerify = isTruthy(value)
+	}
+	if value, ok := overrides[SignEnv]; ok {
+		commitConfig.Sign = isTruthy(value)
+	}
+	if value, ok := overrides[SigningKeyEnv]; ok && value != "" {
+		commitConfig.SigningKey = value
+		commitConfig.Sign = true
+	}
+
+	return commitConfig
+}
+
+// NewOperationID returns an identifier for a run that touches one or more root folders
+func NewOperationID() string {
+	return time.Now().Format("20060102-150405.000000000")
+}
+
+// GetOperationID returns the operation ID passed in env or the process environment, or a
+// new one when none is set
+func GetOperationID(env ...[]string) string {
+	if len(env) > 0 {
+		for _, pair := range env[0] {
+			if value, ok := strings.CutPrefix(pair, OperationIDEnv+"="); ok && value != "" {
+				return value
+			}
+		}
+	}
+	if value := os.Getenv(OperationIDEnv); value != "" {
+		return value
+	}
+	return NewOperationID()
+}
+
+// GetRootSection returns the named configuration section with the overrides for rootFolder
+// (from the section's "roots" map) merged on top. Missing sections yield an empty map.
+func GetRootSection(name, rootFolder string) map[string]interface{} {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	merged := make(map[string]interface{})
+	section, ok := settings[name].(map[string]interface{})
+	if !ok {
+		return merged
+	}
+
+	for key, value := range section {
+		if key != "roots" {
+			merged[key] = value
+		}
+	}
+
+	roots, ok := section["roots"].(map[string]interface{})
+	if !ok || rootFolder == "" {
+		return merged
+	}
+
+	cleanRoot := filepath.Clean(rootFolder)
+	for path, override := range roots {
+		if filepath.Clean(path) != cleanRoot {
+			continue
+		}
+		if overrideMap, ok := override.(map[string]interface{}); ok {
+			for key, value := range overrideMap {
+				merged[key] = value
+			}
+		}
+	}
+
+	return merged
+}
+
+// isTruthy interprets common boolean spellings used in environment variables
+func isTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package config
+
+// DescribeConfig holds the settings for describing commit ranges. It is read from the
+// "describe" section of the configuration; "describe.roots" entries override values per root folder.
+type DescribeConfig struct {
+	MaxPromptChars  int    `json:"maxPromptChars"`  // Character budget for commit messages and diffs sent to the model
+	MaxOutputTokens int    `json:"maxOutputTokens"` // Maximum tokens in the generated description
+	Instructions    string `json:"instructions"`    // Extra guidance appended to the prompt, e.g. house style
+}
+
+// GetDescribeConfig returns the describe settings for rootFolder
+func GetDescribeConfig(rootFolder string) DescribeConfig {
+	section := GetRootSection("describe", rootFolder)
+
+	return DescribeConfig{
+		MaxPromptChars:  getIntOrDefault(section, "maxPromptChars", 60000),
+		MaxOutputTokens: getIntOrDefault(section, "maxOutputTokens", 1024),
+		Instructions:    getStringOrDefault(section, "instructions", ""),
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+)
+
+// ForgeConfig holds the settings for pull request creation after a push. It is read from the
+// "forge" section of the configuration; "forge.roots" entries override values per root folder.
+type ForgeConfig struct {
+	Enabled    bool   `json:"enabled"`    // Create or update a pull request after every push
+	Provider   string `json:"provider"`   // github, gitlab or gitea; detected from the remote host when empty
+	BaseURL    string `json:"baseUrl"`    // API base URL, e.g. https://git.example.com/api/v1
+	Token      string `json:"token"`      // API token; prefer TokenEnv or the provider's environment variable
+	TokenEnv   string `json:"tokenEnv"`   // Name of the environment variable holding the token
+	BaseBranch string `json:"baseBranch"` // Target branch; the repository default branch when empty
+	Draft      bool   `json:"draft"`      // Open new pull requests as drafts
+}
+
+// Forge providers
+const (
+	ForgeGitHub = "github"
+	ForgeGitLab = "gitlab"
+	ForgeGitea  = "gitea"
+)
+
+// forgeTokenEnvs are the conventional token variables per provider, checked in order
+var forgeTokenEnvs = map[string][]string{
+	ForgeGitHub: {"GITCURY_FORGE_TOKEN", "GITHUB_TOKEN", "GH_TOKEN"},
+	ForgeGitLab: {"GITCURY_FORGE_TOKEN", "GITLAB_TOKEN"},
+	ForgeGitea:  {"GITCURY_FORGE_TOKEN", "GITEA_TOKEN"},
+}
+
+// GetForgeConfig returns the forge settings for rootFolder
+func GetForgeConfig(rootFolder string) ForgeConfig {
+	section := GetRootSection("forge", rootFolder)
+
+	return ForgeConfig{
+		Enabled:    getBoolOrDefault(section, "enabled", false),
+		Provider:   getStringOrDefault(section, "provider", ""),
+		BaseURL:    getStringOrDefault(section, "baseUrl", ""),
+		Token:      getStringOrDefault(section, "token", ""),
+		TokenEnv:   getStringOrDefault(section, "tokenEnv", ""),
+		BaseBranch: getStringOrDefault(section, "baseBranch", ""),
+		Draft:      getBoolOrDefault(section, "draft", false),
+	}
+}
+
+// ResolveToken returns the API token for provider: an explicit tokenEnv first, then the
+// configured token, then the provider's conventional environment variables.
+func (f ForgeConfig) ResolveToken(provider string) string {
+	if f.TokenEnv != "" {
+		if token := os.Getenv(f.TokenEnv); token != "" {
+			return token
+		}
+	}
+	if f.Token != "" {
+		return f.Token
+	}
+	for _, name := range forgeTokenEnvs[provider] {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// MaskForgeTokens replaces the forge tokens in conf, a copy of the settings as returned by
+// GetAll, so the configuration can be shown without them. The forge section is copied first;
+// the settings themselves are not changed.
+func MaskForgeTokens(conf map[string]interface{}) {
+	section, ok := conf["forge"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	masked := maskToken(section)
+	if roots, ok := section["roots"].(map[string]interface{}); ok {
+		maskedRoots := make(map[string]interface{}, len(roots))
+		for path, override := range roots {
+			if overrideMap, ok := override.(map[string]interface{}); ok {
+				maskedRoots[path] = maskToken(overrideMap)
+			} else {
+				maskedRoots[path] = override
+			}
+		}
+		masked["roots"] = maskedRoots
+	}
+	conf["forge"] = masked
+}
+
+// maskToken returns a copy of section with its token hidden
+func maskToken(section map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(section))
+	for key, value := range section {
+		copied[key] = value
+	}
+	if token, ok := section["token"].(string); ok && token != "" {
+		if len(token) > 10 {
+			copied["token"] = token[:4] + "..." + " (configured)"
+		} else {
+			copied["token"] = "... (configured)"
+		}
+	}
+	return copied
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"github.com/lakshyajain-0291/gitcury/utils"
+)
+
+// Identity is the author and committer identity used for commits in a root folder
+type Identity struct {
+	Profile      string   `json:"profile"` // Name of the profile it came from, if any
+	Name         string   `json:"name"`
+	Email        string   `json:"email"`
+	SigningKey   string   `json:"signingKey"`   // Signs commits with this key when set
+	EmailDomains []string `json:"emailDomains"` // Allowed email domains for the root folder
+}
+
+// GetIdentity returns the identity for rootFolder. It is read from the "identity" section:
+// "profiles" defines named identities, "profile" selects one and name/email/signingKey set
+// values directly; "identity.roots" entries override both per root folder. An "emailDomain"
+// (string or list) restricts which email addresses may commit in the root folder.
+// The returned error is a configuration problem, such as an unknown profile.
+func GetIdentity(rootFolder string) (Identity, error) {
+	section := GetRootSection("identity", rootFolder)
+
+	identity := Identity{
+		Profile: getStringOrDefault(section, "profile", ""),
+	}
+
+	if identity.Profile != "" {
+		profiles, _ := section["profiles"].(map[string]interface{})
+		profile, ok := profiles[identity.Profile].(map[string]interface{})
+		if !ok {
+			return identity, utils.NewConfigError(
+				"Unknown identity profile",
+				nil,
+				map[string]interface{}{
+					"profile":    identity.Profile,
+					"rootFolder": rootFolder,
+					"suggestion": "Define it under identity.profiles",
+				},
+			)
+		}
+		identity.Name = getStringOrDefault(profile, "name", "")
+		identity.Email = getStringOrDefault(profile, "email", "")
+		identity.SigningKey = getStringOrDefault(profile, "signingKey", "")
+	}
+
+	// Values set directly take precedence over the profile
+	identity.Name = getStringOrDefault(section, "name", identity.Name)
+	identity.Email = getStringOrDefault(section, "email", identity.Email)
+	identity.SigningKey = getStringOrDefault(section, "signingKey", identity.SigningKey)
+
+	switch domains := section["emailDomain"].(type) {
+	case string:
+		if domains != "" {
+			identity.EmailDomains = []string{domains}
+		}
+	case []interface{}:
+		for _, domain := range domains {
+			if value, ok := domain.(string); ok && value != "" {
+				identity.EmailDomains = append(identity.EmailDomains, value)
+			}
+		}
+	}
+
+	return identity, nil
+}
+
+// IsSet reports whether the identity overrides anything from git config
+func (identity Identity) IsSet() bool {
+	return identity.Name != "" || identity.Email != "" || identity.SigningKey != ""
+}
@@ -0,0 +1,46 @@
+package config
+
+// PushConfig holds the options used when GitCury pushes a root folder. It is read from the
+// "push" section of the configuration; "push.roots" entries override values per root folder.
+type PushConfig struct {
+	Remote            string   `json:"remote"`            // Remote used when the branch has no upstream
+	SetUpstream       bool     `json:"setUpstream"`       // Set the upstream when pushing a branch without one
+	ForceWithLease    bool     `json:"forceWithLease"`    // Allow rewriting the remote branch if it has not moved
+	PullRebase        bool     `json:"pullRebase"`        // pull --rebase first when the branch is behind
+	FetchBeforePush   bool     `json:"fetchBeforePush"`   // Fetch to compute ahead/behind before pushing
+	ProtectedBranches []string `json:"protectedBranches"` // Branch names or globs that must not be pushed to
+	ProtectedPolicy   string   `json:"protectedPolicy"`   // "refuse" (default) or "confirm"
+}
+
+// Protected branch policies
+const (
+	ProtectedPolicyRefuse  = "refuse"
+	ProtectedPolicyConfirm = "confirm"
+)
+
+// GetPushConfig returns the push options for rootFolder
+func GetPushConfig(rootFolder string) PushConfig {
+	section := GetRootSection("push", rootFolder)
+
+	pushConfig := PushConfig{
+		Remote:          getStringOrDefault(section, "remote", "origin"),
+		SetUpstream:     getBoolOrDefault(section, "setUpstream", false),
+		ForceWithLease:  getBoolOrDefault(section, "forceWithLease", false),
+		PullRebase:      getBoolOrDefault(section, "pullRebase", false),
+		FetchBeforePush: getBoolOrDefault(section, "fetchBeforePush", true),
+		ProtectedPolicy: getStringOrDefault(section, "protectedPolicy", ProtectedPolicyRefuse),
+	}
+
+	switch branches := section["protectedBranches"].(type) {
+	case []interface{}:
+		for _, branch := range branches {
+			if name, ok := branch.(string); ok && name != "" {
+				pushConfig.ProtectedBranches = append(pushConfig.ProtectedBranches, name)
+			}
+		}
+	case []string:
+		pushConfig.ProtectedBranches = append(pushConfig.ProtectedBranches, branches...)
+	}
+
+	return pushConfig
+}
@@ -0,0 +1,25 @@
+package config
+
+// ReleaseConfig holds the settings for release planning and tagging. It is read from the
+// "release" section of the configuration; "release.roots" entries override values per root folder.
+type ReleaseConfig struct {
+	TagPrefix      string `json:"tagPrefix"`      // Prefix of version tags, e.g. "v" for v1.2.3
+	InitialVersion string `json:"initialVersion"` // Version assumed when the repository has no version tag
+	SignTags       bool   `json:"signTags"`       // Create signed tags; also enabled by commit.sign
+}
+
+// GetReleaseConfig returns the release settings for rootFolder
+func GetReleaseConfig(rootFolder string) ReleaseConfig {
+	section := GetRootSection("release", rootFolder)
+
+	prefix := "v"
+	if value, ok := section["tagPrefix"].(string); ok {
+		prefix = value // An empty prefix is valid
+	}
+
+	return ReleaseConfig{
+		TagPrefix:      prefix,
+		InitialVersion: getStringOrDefault(section, "initialVersion", "0.0.0"),
+		SignTags:       getBoolOrDefault(section, "signTags", false),
+	}
+}
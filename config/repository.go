@@ -0,0 +1,21 @@
+package config
+
+// RepositoryConfig controls how repositories inside a root folder are treated. It is read from
+// the "repositories" section of the configuration; "repositories.roots" entries override values
+// per root folder.
+type RepositoryConfig struct {
+	Submodules  bool `json:"submodules"`  // Commit changes inside initialised submodules as separate units
+	NestedRepos bool `json:"nestedRepos"` // Commit changes inside nested (untracked) repositories as separate units
+	MaxDepth    int  `json:"maxDepth"`    // How deep submodules and nested repositories are searched
+}
+
+// GetRepositoryConfig returns the repository settings for rootFolder
+func GetRepositoryConfig(rootFolder string) RepositoryConfig {
+	section := GetRootSection("repositories", rootFolder)
+
+	return RepositoryConfig{
+		Submodules:  getBoolOrDefault(section, "submodules", false),
+		NestedRepos: getBoolOrDefault(section, "nestedRepos", false),
+		MaxDepth:    getIntOrDefault(section, "maxDepth", 3),
+	}
+}
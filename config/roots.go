@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RootsConfig holds the settings for root folder discovery and glob expansion. It is read
+// from the "roots" section of the configuration.
+type RootsConfig struct {
+	Ignore   []string `json:"ignore"`   // Globs of directories never treated as root folders
+	MaxDepth int      `json:"maxDepth"` // Default depth of 'roots discover'
+}
+
+// skippedDirectories are never searched for repositories
+var skippedDirectories = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// GetRootsConfig returns the root folder discovery settings
+func GetRootsConfig() RootsConfig {
+	section := GetRootSection("roots", "")
+
+	rootsConfig := RootsConfig{
+		MaxDepth: getIntOrDefault(section, "maxDepth", 3),
+	}
+	if ignore, ok := section["ignore"].([]interface{}); ok {
+		for _, pattern := range ignore {
+			if value, ok := pattern.(string); ok && value != "" {
+				rootsConfig.Ignore = append(rootsConfig.Ignore, value)
+			}
+		}
+	}
+	return rootsConfig
+}
+
+// IsIgnoredDirectory reports whether path is skipped by discovery: vendor and dependency
+// directories, and anything matching an ignore glob by name or by full path
+func (rootsConfig RootsConfig) IsIgnoredDirectory(path string) bool {
+	name := filepath.Base(path)
+	if skippedDirectories[name] {
+		return true
+	}
+	for _, pattern := range rootsConfig.Ignore {
+		pattern = ExpandHome(pattern)
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory
+func ExpandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(os.Getenv("HOME"), strings.TrimPrefix(path, "~"))
+	}
+	return path
+}
+
+// IsRootPattern reports whether a root_folders entry is a glob such as ~/work/*
+func IsRootPattern(entry string) bool {
+	return strings.ContainsAny(entry, "*?[")
+}
+
+// ExpandRootFolder returns the repositories matched by a root_folders entry. Plain paths are
+// returned as-is; globs are expanded at runtime to the matching git repositories.
+func ExpandRootFolder(entry string) []string {
+	if !IsRootPattern(entry) {
+		return []string{entry}
+	}
+
+	matches, err := filepath.Glob(ExpandHome(entry))
+	if err != nil {
+		return nil
+	}
+
+	rootsConfig := GetRootsConfig()
+	var folders []string
+	for _, match := range matches {
+		if rootsConfig.IsIgnoredDirectory(match) {
+			continue
+		}
+		// A .git directory, or a .git file for worktrees and submodules
+		if _, err := os.Stat(filepath.Join(match, ".git")); err == nil {
+			folders = append(folders, match)
+		}
+	}
+	sort.Strings(folders)
+	return folders
+}
+
+// GetRootFolderEntries returns root_folders as configured, before glob expansion
+func GetRootFolderEntries() []string {
+	var entries []string
+	switch rootFolders := Get("root_folders").(type) {
+	case []string:
+		entries = append(entries, rootFolders...)
+	case []interface{}:
+		for _, rootFolder := range rootFolders {
+			if value, ok := rootFolder.(string); ok {
+				entries = append(entries, value)
+			}
+		}
+	}
+	return entries
+}
+
+// AddRootFolders appends folders to root_folders, skipping ones that are already configured.
+// It returns the folders that were added.
+func AddRootFolders(folders []string) []string {
+	entries := GetRootFolderEntries()
+	existing := make(map[string]bool)
+	for _, entry := range entries {
+		for _, folder := range ExpandRootFolder(entry) {
+			existing[filepath.Clean(ExpandHome(folder))] = true
+		}
+	}
+
+	var added []string
+	for _, folder := range folders {
+		if clean := filepath.Clean(folder); !existing[clean] {
+			existing[clean] = true
+			entries = append(entries, folder)
+			added = append(added, folder)
+		}
+	}
+	if len(added) > 0 {
+		Set("root_folders", entries)
+	}
+	return added
+}
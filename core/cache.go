@@ -0,0 +1,146 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/embeddings"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CacheMigration reports the JSON caches imported into the embedding store
+type CacheMigration struct {
+	Files   int `json:"files"`
+	Vectors int `json:"vectors"`
+}
+
+// CacheStats describes the embedding store
+func CacheStats() (embeddings.StoreStats, error) {
+	store, err := git.OpenEmbeddingStore()
+	if err != nil {
+		return embeddings.StoreStats{}, err
+	}
+	return store.Stats()
+}
+
+// ListCache returns the entries of the embedding store whose key starts with prefix, newest
+// first. A positive limit keeps only that many.
+func ListCache(prefix string, limit int) ([]embeddings.StoredVector, error) {
+	store, err := git.OpenEmbeddingStore()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := store.Entries(prefix)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Updated.After(entries[j].Updated) })
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// CompactCache rewrites the embedding store without overwritten and deleted vectors
+func CompactCache() (embeddings.StoreStats, error) {
+	store, err := git.OpenEmbeddingStore()
+	if err != nil {
+		return embeddings.StoreStats{}, err
+	}
+	if err := store.Compact(); err != nil {
+		return embeddings.StoreStats{}, err
+	}
+	return store.Stats()
+}
+
+// MigrateCache imports JSON caches of earlier versions that are still around
+func MigrateCache() (CacheMigration, error) {
+	store, err := git.OpenEmbeddingStore()
+	if err != nil {
+		return CacheMigration{}, err
+	}
+	files, vectors, err := git.MigrateJSONCaches(store)
+	return CacheMigration{Files: files, Vectors: vectors}, err
+}
+
+// ClearCache deletes the entries whose key starts with prefix, or all entries without one,
+// and compacts the store. It returns the number of entries deleted.
+func ClearCache(prefix string) (int, error) {
+	store, err := git.OpenEmbeddingStore()
+	if err != nil {
+		return 0, err
+	}
+	keys, err := store.Keys(prefix)
+	if err != nil {
+		return 0, err
+	}
+	if err := store.Delete(keys...); err != nil {
+		return 0, err
+	}
+	if err := store.Compact(); err != nil {
+		return len(keys), err
+	}
+	utils.Debug(fmt.Sprintf("[CACHE]: Cleared %d embeddings from %s", len(keys), store.Dir()))
+	return len(keys), nil
+}
+
+// FormatCacheStats renders the embedding store statistics
+func FormatCacheStats(stats embeddings.StoreStats) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\n🗄️  Embedding store: %s\n", stats.Path))
+	builder.WriteString(fmt.Sprintf("   entries: %d · encoding: %s\n", stats.Entries, stats.Encoding))
+
+	live := 0.0
+	if stats.DataBytes > 0 {
+		live = float64(stats.LiveBytes) / float64(stats.DataBytes) * 100
+	}
+	builder.WriteString(fmt.Sprintf("   data: %s (%.0f%% live) · index: %s\n",
+		formatBytes(stats.DataBytes), live, formatBytes(stats.IndexBytes)))
+
+	for _, group := range []struct {
+		title  string
+		counts map[string]int
+	}{{"by kind", stats.ByKind}, {"by backend", stats.ByBackend}} {
+		if len(group.counts) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(group.counts))
+		for name := range group.counts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		parts := make([]string, 0, len(names))
+		for _, name := range names {
+			parts = append(parts, fmt.Sprintf("%s %d", name, group.counts[name]))
+		}
+		builder.WriteString(fmt.Sprintf("   %s: %s\n", group.title, strings.Join(parts, " · ")))
+	}
+
+	if !stats.NewestUpdate.IsZero() {
+		builder.WriteString(fmt.Sprintf("   written: %s to %s\n",
+			stats.OldestUpdate.Format(time.DateTime), stats.NewestUpdate.Format(time.DateTime)))
+	}
+	return builder.String()
+}
+
+// FormatCacheEntries renders store entries one per line
+func FormatCacheEntries(entries []embeddings.StoredVector) string {
+	var builder strings.Builder
+	for _, entry := range entries {
+		builder.WriteString(fmt.Sprintf("%s  %-22s %s\n", entry.Updated.Format(time.DateTime), entry.Backend, entry.Key))
+	}
+	return builder.String()
+}
+
+func formatBytes(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/output"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ClusterExplanation is the recorded grouping of one root folder
+type ClusterExplanation struct {
+	RootFolder string           `json:"rootFolder"`
+	Clusters   []output.Cluster `json:"clusters"`
+}
+
+// ExplainClusters returns the clusters recorded by the last grouped message generation.
+// Without folders every root folder in the output store is included.
+func ExplainClusters(folders ...string) []ClusterExplanation {
+	if len(folders) == 0 {
+		for _, folder := range output.GetAll().Folders {
+			folders = append(folders, folder.Name)
+		}
+	}
+
+	var explanations []ClusterExplanation
+	for _, folder := range folders {
+		if clusters := output.GetClusters(folder); len(clusters) > 0 {
+			explanations = append(explanations, ClusterExplanation{RootFolder: folder, Clusters: clusters})
+		}
+	}
+	return explanations
+}
+
+// FormatClusterExplanations renders why files were grouped together, with each file's
+// similarity to the centroid of its cluster
+func FormatClusterExplanations(explanations []ClusterExplanation) string {
+	var builder strings.Builder
+	for _, explanation := range explanations {
+		builder.WriteString(fmt.Sprintf("\n📂 %s — %d cluster(s)\n", explanation.RootFolder, len(explanation.Clusters)))
+
+		for i, cluster := range explanation.Clusters {
+			builder.WriteString(fmt.Sprintf("\n  #%d %s\n", i+1, strings.SplitN(cluster.Message, "\n", 2)[0]))
+			builder.WriteString(fmt.Sprintf("     method: %s · rule: %s · confidence: %.2f · similarity: %.2f\n",
+				cluster.Method, cluster.Rule, cluster.Confidence, cluster.Similarity))
+
+			files := append([]string{}, cluster.Files...)
+			sort.SliceStable(files, func(a, b int) bool {
+				return cluster.FileSimilarity[files[a]] > cluster.FileSimilarity[files[b]]
+			})
+			for _, file := range files {
+				name, err := filepath.Rel(explanation.RootFolder, file)
+				if err != nil {
+					name = file
+				}
+				similarity, ok := cluster.FileSimilarity[file]
+				if !ok {
+					builder.WriteString(fmt.Sprintf("     %-10s  -    %s\n", "", name))
+					continue
+				}
+				builder.WriteString(fmt.Sprintf("     %s %.2f  %s\n", similarityBar(similarity), similarity, name))
+			}
+		}
+	}
+	return builder.String()
+}
+
+// similarityBar draws a similarity between 0 and 1 as a ten character bar
+func similarityBar(similarity float64) string {
+	filled := int(similarity*10 + 0.5)
+	if filled < 0 {
+		filled = 0
+	}
+	if filled > 10 {
+		filled = 10
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", 10-filled)
+}
@@ -32,6 +32,16 @@ func CommitAllRoots(env ...[]string) error {
 		return nil
 	}
 
+	// A bad identity in one root folder must not leave the others half committed
+	for _, folder := range rootFolders {
+		if err := git.ValidateIdentity(folder.Name); err != nil {
+			utils.Error("Invalid commit identity for folder '" + folder.Name + "' - " + err.Error())
+			return err
+		}
+	}
+
+	env = withOperationID(env)
+
 	// Determine optimal worker count based on available folders
 	workerCount := 3
 	if len(rootFolders) < workerCount {
@@ -109,6 +119,11 @@ func CommitAllRoots(env ...[]string) error {
 		)
 	}
 
+	if err := commitSubmoduleBumps(committedFolderNames(rootFolders), env...); err != nil {
+		utils.Error("Failed to record submodule updates - " + err.Error())
+		return err
+	}
+
 	output.Clear()
 	utils.Success("✅ Batch commit completed successfully. Output cleared.")
 	return nil
@@ -147,6 +162,11 @@ func CommitOneRoot(rootFolderName string, env ...[]string) error {
 		)
 	}
 
+	if err := commitSubmoduleBumps([]string{rootFolderName}, env...); err != nil {
+		utils.Error("Failed to record submodule updates - " + err.Error())
+		return err
+	}
+
 	utils.Success("✅ Batch commit completed successfully for root folder: " + rootFolderName)
 	return nil
 }
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+)
+
+// DescribeRange generates a pull request description or changelog entry for a commit range
+// of rootFolderName. When no root folder is given, the only configured root folder is used.
+func DescribeRange(rootFolderName, rangeSpec, format, version string) (*git.RangeDescription, error) {
+	rootFolderName, err := resolveRootFolder(rootFolderName)
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Debug("📂 Describing " + rangeSpec + " in root folder: " + rootFolderName)
+	description, err := git.DescribeRange(rootFolderName, rangeSpec, format, version)
+	if err != nil {
+		utils.Error("❌ Failed to describe range '" + rangeSpec + "' in folder '" + rootFolderName + "' - " + err.Error())
+		return nil, err
+	}
+	return description, nil
+}
+
+// resolveRootFolder returns rootFolderName, or the only configured root folder when it is empty
+func resolveRootFolder(rootFolderName string) (string, error) {
+	if rootFolderName != "" {
+		return rootFolderName, nil
+	}
+
+	folders, err := configuredRootFolders()
+	if err != nil {
+		return "", err
+	}
+	if len(folders) != 1 {
+		return "", utils.NewValidationError(
+			"Multiple root folders are configured; choose one with --root",
+			nil,
+			map[string]interface{}{
+				"rootFolders": folders,
+			},
+		)
+	}
+	return folders[0], nil
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EvaluateClustering scores the clustering methods and presets of options against the fixtures
+// at paths (files or directories of *.json), or against the built-in fixtures without paths
+func EvaluateClustering(paths []string, options git.EvalOptions) (*git.EvalReport, error) {
+	fixtures := git.BuiltinEvalFixtures()
+	if len(paths) > 0 {
+		var err error
+		if fixtures, err = git.LoadEvalFixtures(paths...); err != nil {
+			return nil, err
+		}
+	}
+
+	utils.Info(fmt.Sprintf("🧪 Evaluating clustering on %d fixture(s)...", len(fixtures)))
+	return git.RunEvaluation(fixtures, options)
+}
+
+// CaptureEvalFixture replays the commits of rangeSpec in a root folder as one combined change
+// and writes it to outputPath as a fixture, grouped the way the commits were made
+func CaptureEvalFixture(rootFolderName, rangeSpec, outputPath string, historyCommits int) (*git.EvalFixture, error) {
+	rootFolderName, err := resolveRootFolder(rootFolderName)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture, err := git.CaptureEvalFixture(rootFolderName, rangeSpec, historyCommits)
+	if err != nil {
+		return nil, err
+	}
+	fixture.Name = strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath))
+	if err := git.SaveEvalFixture(fixture, outputPath); err != nil {
+		return nil, err
+	}
+	return fixture, nil
+}
+
+// BenchmarkClustering times the clustering of a root folder's changed files. With scaling, the
+// algorithms behind the semantic methods are also timed on synthetic change sets of up to
+// 2,000 files; those runs only add ScalingExponents and are kept out of the method results.
+func BenchmarkClustering(rootFolderName string, scaling bool) (git.BenchmarkResult, error) {
+	rootFolderName, err := resolveRootFolder(rootFolderName)
+	if err != nil {
+		return git.BenchmarkResult{}, err
+	}
+
+	changedFiles, err := git.GetAllChangedFiles(rootFolderName)
+	if err != nil {
+		return git.BenchmarkResult{}, err
+	}
+
+	git.ResetBenchmarks()
+	utils.Info(fmt.Sprintf("⏱️ Benchmarking clustering of %d changed file(s)...", len(changedFiles)))
+	result := git.RunClusteringPerformanceTest(changedFiles, rootFolderName)
+
+	if scaling {
+		utils.Info(fmt.Sprintf("⏱️ Timing the clustering algorithms on synthetic change sets of %v files...", git.DefaultScalingSizes))
+		git.ResetBenchmarks()
+		scalingResult := git.RunScalingBenchmarks(git.DefaultScalingSizes)
+		result.ScalingExponents = scalingResult.ScalingExponents
+		result.Summary += git.ScalingSummary(scalingResult.ScalingExponents)
+	}
+	return result, nil
+}
+
+// FormatEvalReport renders the results of each fixture and the averages per method and preset
+func FormatEvalReport(report *git.EvalReport) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\n🧪 Clustering evaluation (embeddings: %s)\n", report.Backend))
+
+	fixture := ""
+	for _, result := range report.Results {
+		if result.Fixture != fixture {
+			fixture = result.Fixture
+			builder.WriteString(fmt.Sprintf("\n📂 %s — %d file(s), %d ideal commit(s)\n", fixture, result.Files, result.IdealCommits))
+			builder.WriteString(fmt.Sprintf("   %-18s %7s %7s %8s %10s\n", "strategy", "ARI", "purity", "commits", "time"))
+		}
+		if result.Error != "" {
+			builder.WriteString(fmt.Sprintf("   %-18s failed: %s\n", result.Strategy, result.Error))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("   %-18s %7.2f %7.2f %8d %10s\n",
+			result.Strategy, result.ARI, result.Purity, result.Commits, result.Duration.Round(time.Microsecond*10)))
+	}
+
+	builder.WriteString("\n📊 Averages over all fixtures\n")
+	builder.WriteString(fmt.Sprintf("   %-18s %7s %7s %13s %10s\n", "strategy", "ARI", "purity", "commits off", "time"))
+	for _, summary := range report.Summaries {
+		line := fmt.Sprintf("   %-18s %7.2f %7.2f %13.2f %10s", summary.Strategy, summary.MeanARI, summary.MeanPurity,
+			summary.CommitError, summary.Duration.Round(time.Microsecond*10))
+		if summary.Failures > 0 {
+			line += fmt.Sprintf("  (%d failed)", summary.Failures)
+		}
+		builder.WriteString(line + "\n")
+	}
+	builder.WriteString("\nARI is 1 for the ideal grouping and about 0 for a random one; purity is the share of files\n" +
+		"in a cluster mostly made of their own ideal commit.\n")
+	return builder.String()
+}
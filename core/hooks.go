@@ -0,0 +1,101 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"strings"
+)
+
+// configuredRootFolders returns the root folders from the configuration
+func configuredRootFolders() ([]string, error) {
+	rootFolders, ok := config.Get("root_folders").([]interface{})
+	if !ok {
+		utils.Error("Invalid or missing root_folders configuration.")
+		return nil, fmt.Errorf("invalid or missing root_folders configuration")
+	}
+
+	var folders []string
+	for _, rootFolder := range expandRootFolders(rootFolders) {
+		folder, ok := rootFolder.(string)
+		if !ok {
+			utils.Error("Invalid root folder type.")
+			continue
+		}
+		folders = append(folders, folder)
+	}
+	return folders, nil
+}
+
+// InstallHooks installs the prepare-commit-msg hook into each of the given root folders.
+// When no folders are given, every configured root folder is used.
+func InstallHooks(force bool, folders ...string) error {
+	if len(folders) == 0 {
+		var err error
+		if folders, err = configuredRootFolders(); err != nil {
+			return err
+		}
+	}
+
+	var errors []string
+	for _, folder := range folders {
+		hookPath, err := git.InstallPrepareCommitMsgHook(folder, force)
+		if err != nil {
+			utils.Error("Failed to install hook in '" + folder + "' - " + err.Error())
+			errors = append(errors, fmt.Sprintf("Folder: %s, Error: %s", folder, err.Error()))
+			continue
+		}
+		utils.Success("✅ Hook installed: " + hookPath)
+	}
+
+	if len(errors) > 0 {
+		return utils.NewGitError(
+			fmt.Sprintf("%d errors occurred while installing hooks", len(errors)),
+			fmt.Errorf("%s", strings.Join(errors, "; ")),
+			map[string]interface{}{
+				"errorCount": len(errors),
+				"errors":     errors,
+			},
+		)
+	}
+	return nil
+}
+
+// UninstallHooks removes the GitCury prepare-commit-msg hook from each of the given root folders.
+// When no folders are given, every configured root folder is used.
+func UninstallHooks(folders ...string) error {
+	if len(folders) == 0 {
+		var err error
+		if folders, err = configuredRootFolders(); err != nil {
+			return err
+		}
+	}
+
+	var errors []string
+	for _, folder := range folders {
+		hookPath, err := git.UninstallPrepareCommitMsgHook(folder)
+		if err != nil {
+			utils.Error("Failed to uninstall hook in '" + folder + "' - " + err.Error())
+			errors = append(errors, fmt.Sprintf("Folder: %s, Error: %s", folder, err.Error()))
+			continue
+		}
+		if hookPath == "" {
+			utils.Info("No GitCury hook installed in " + folder)
+			continue
+		}
+		utils.Success("✅ Hook removed: " + hookPath)
+	}
+
+	if len(errors) > 0 {
+		return utils.NewGitError(
+			fmt.Sprintf("%d errors occurred while uninstalling hooks", len(errors)),
+			fmt.Errorf("%s", strings.Join(errors, "; ")),
+			map[string]interface{}{
+				"errorCount": len(errors),
+				"errors":     errors,
+			},
+		)
+	}
+	return nil
+}
@@ -32,6 +32,7 @@ func GetAllMsgs(numFiles ...int) error {
 		utils.Error("Invalid or missing root_folders configuration.")
 		return fmt.Errorf("invalid or missing root_folders configuration")
 	}
+	rootFolders = expandCommitUnits(expandRootFolders(rootFolders))
 
 	var rootFolderWg sync.WaitGroup
 	var mu sync.Mutex
@@ -139,20 +140,8 @@ func GetMsgsForRootFolder(folder string, numFiles ...int) error {
 	if len(numFiles) > 0 && numFiles[0] > 0 {
 		utils.Debug("Using provided number of files to commit: " + strconv.Itoa(numFiles[0]))
 		numFilesToCommit = numFiles[0]
-	} else {
-		if configValue := config.Get("numFilesToCommit"); configValue != "" {
-			if configValueFloat, ok := configValue.(float64); ok {
-				utils.Debug("Using config value for numFilesToCommit: " + strconv.FormatFloat(configValueFloat, 'f', -1, 64))
-				numFilesToCommit = int(configValueFloat)
-			} else if configValueStr, ok := configValue.(string); ok {
-				if parsedValue, err := strconv.Atoi(configValueStr); err == nil {
-					utils.Debug("Using config value for numFilesToCommit from string: " + configValueStr)
-					numFilesToCommit = parsedValue
-				} else {
-					utils.Error("Invalid string value for numFilesToCommit: " + configValueStr)
-				}
-			}
-		}
+	} else if configured, ok := configuredNumFilesToCommit(); ok {
+		numFilesToCommit = configured
 	}
 
 	utils.Debug("Preparing commit messages for " + strconv.Itoa(numFilesToCommit) + " files in folder: " + folder)
@@ -201,6 +190,46 @@ func GetMsgsForRootFolder(folder string, numFiles ...int) error {
 	return nil
 }
 
+// configuredNumFilesToCommit returns numFilesToCommit from the config and whether it is set
+func configuredNumFilesToCommit() (int, bool) {
+	switch configValue := config.Get("numFilesToCommit").(type) {
+	case float64:
+		utils.Debug("Using config value for numFilesToCommit: " + strconv.FormatFloat(configValue, 'f', -1, 64))
+		return int(configValue), configValue > 0
+	case int:
+		utils.Debug("Using config value for numFilesToCommit: " + strconv.Itoa(configValue))
+		return configValue, configValue > 0
+	case string:
+		parsedValue, err := strconv.Atoi(configValue)
+		if err != nil {
+			utils.Error("Invalid string value for numFilesToCommit: " + configValue)
+			return 0, false
+		}
+		utils.Debug("Using config value for numFilesToCommit from string: " + configValue)
+		return parsedValue, parsedValue > 0
+	}
+	return 0, false
+}
+
+// GroupClusterCount returns the number of groups 'getmsgs --group' splits changes into: the
+// provided number, 0 to choose it from the changes when clustering.clusterCount.auto is set,
+// or numFilesToCommit. Only --num overrides the automatic choice, as every config written by
+// the loader has a numFilesToCommit.
+func GroupClusterCount(numFiles ...int) int {
+	if len(numFiles) > 0 && numFiles[0] > 0 {
+		utils.Debug("Using provided number of files to commit: " + strconv.Itoa(numFiles[0]))
+		return numFiles[0]
+	}
+	if config.GetClusteringConfig().ClusterCount.Auto {
+		utils.Debug("Choosing the number of commits from the changes")
+		return 0
+	}
+	if configured, ok := configuredNumFilesToCommit(); ok {
+		return configured
+	}
+	return 0 // When 0, uses DBSCAN to create automatic clusters
+}
+
 func GroupAndGetAllMsgs(numFiles ...int) error {
 	// Start creative loader for grouped processing
 	utils.StartCreativeLoader("Analyzing repository for grouped processing", utils.BrailleAnimation)
@@ -214,26 +243,9 @@ func GroupAndGetAllMsgs(numFiles ...int) error {
 		utils.Error("Invalid or missing root_folders configuration.")
 		return fmt.Errorf("invalid or missing root_folders configuration")
 	}
+	rootFolders = expandCommitUnits(expandRootFolders(rootFolders))
 
-	clusters := 0 // Default value
-	if len(numFiles) > 0 && numFiles[0] > 0 {
-		utils.Debug("Using provided number of files to commit: " + strconv.Itoa(numFiles[0]))
-		clusters = numFiles[0]
-	} else {
-		if configValue := config.Get("numFilesToCommit"); configValue != "" {
-			if configValueFloat, ok := configValue.(float64); ok {
-				utils.Debug("Using config value for numFilesToCommit: " + strconv.FormatFloat(configValueFloat, 'f', -1, 64))
-				clusters = int(configValueFloat)
-			} else if configValueStr, ok := configValue.(string); ok {
-				if parsedValue, err := strconv.Atoi(configValueStr); err == nil {
-					utils.Debug("Using config value for numFilesToCommit from string: " + configValueStr)
-					clusters = parsedValue
-				} else {
-					utils.Error("Invalid string value for numFilesToCommit: " + configValueStr)
-				}
-			}
-		}
-	}
+	clusters := GroupClusterCount(numFiles...)
 
 	var rootFolderWg sync.WaitGroup
 	var mu sync.Mutex
@@ -316,25 +328,7 @@ func GroupAndGetMsgsForRootFolder(folder string, numFiles ...int) error {
 	utils.StartCreativeLoader(fmt.Sprintf("Clustering files in folder: %s", folder), utils.BrailleAnimation)
 	utils.UpdateCreativeLoaderPhase("clustering")
 
-	clusters := 0 // Default value (When 0, uses DBSCAN to create automatic clusters)
-	if len(numFiles) > 0 && numFiles[0] > 0 {
-		utils.Debug("Using provided number of files to commit: " + strconv.Itoa(numFiles[0]))
-		clusters = numFiles[0]
-	} else {
-		if configValue := config.Get("numFilesToCommit"); configValue != "" {
-			if configValueFloat, ok := configValue.(float64); ok {
-				utils.Debug("Using config value for numFilesToCommit: " + strconv.FormatFloat(configValueFloat, 'f', -1, 64))
-				clusters = int(configValueFloat)
-			} else if configValueStr, ok := configValue.(string); ok {
-				if parsedValue, err := strconv.Atoi(configValueStr); err == nil {
-					utils.Debug("Using config value for numFilesToCommit from string: " + configValueStr)
-					clusters = parsedValue
-				} else {
-					utils.Error("Invalid string value for numFilesToCommit: " + configValueStr)
-				}
-			}
-		}
-	}
+	clusters := GroupClusterCount(numFiles...)
 
 	utils.Debug("Preparing commit messages for " + strconv.Itoa(clusters) + " files in folder: " + folder)
 
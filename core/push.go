@@ -2,12 +2,14 @@ package core
 
 import (
 	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/forge"
+	"github.com/lakshyajain-0291/gitcury/interfaces"
 	"github.com/lakshyajain-0291/gitcury/utils"
 	"fmt"
 	"sync"
 )
 
-func PushAllRoots(branchName string) error {
+func PushAllRoots(branchName string, opts ...interfaces.PushOptions) error {
 	rootFolders, ok := config.Get("root_folders").([]interface{})
 	if !ok {
 		utils.Error("❌ Invalid or missing root_folders configuration", "config")
@@ -25,7 +27,7 @@ func PushAllRoots(branchName string) error {
 	var mu sync.Mutex
 	var errors []string
 
-	for _, rootFolder := range rootFolders {
+	for _, rootFolder := range expandRootFolders(rootFolders) {
 		rootFolderStr, ok := rootFolder.(string)
 		if !ok {
 			utils.Error("⚠️ Invalid root folder type", "config")
@@ -38,7 +40,7 @@ func PushAllRoots(branchName string) error {
 			defer rootFolderWg.Done()
 			utils.Debug("📂 Root folder to push: " + folder)
 
-			err := GitRunnerInstance.ProgressPushBranch(folder, branchName)
+			err := GitRunnerInstance.ProgressPushBranch(folder, branchName, opts...)
 			if err != nil {
 				// Extract file information if available in the error
 				fileInfo := folder
@@ -53,6 +55,13 @@ func PushAllRoots(branchName string) error {
 				return
 			}
 			utils.Success("✅ Successfully pushed branch for folder: " + folder)
+
+			if err := syncPullRequest(folder, branchName, opts...); err != nil {
+				utils.Error("❌ Failed to create or update the pull request for folder '"+folder+"' - "+err.Error(), folder)
+				mu.Lock()
+				errors = append(errors, fmt.Sprintf("Folder: %s, Pull request, Error: %s", folder, err.Error()))
+				mu.Unlock()
+			}
 		}(rootFolderStr)
 	}
 
@@ -75,10 +84,10 @@ func PushAllRoots(branchName string) error {
 	return nil
 }
 
-func PushOneRoot(rootFolderName, branchName string) error {
+func PushOneRoot(rootFolderName, branchName string, opts ...interfaces.PushOptions) error {
 	utils.Debug("📂 Targeting root folder for push: " + rootFolderName)
 
-	err := GitRunnerInstance.ProgressPushBranch(rootFolderName, branchName)
+	err := GitRunnerInstance.ProgressPushBranch(rootFolderName, branchName, opts...)
 	if err != nil {
 		// Extract file information if available in the error
 		fileInfo := rootFolderName
@@ -99,5 +108,30 @@ func PushOneRoot(rootFolderName, branchName string) error {
 	}
 
 	utils.Success("✅ Push operation for root folder '" + rootFolderName + "' completed successfully")
+
+	if err := syncPullRequest(rootFolderName, branchName, opts...); err != nil {
+		utils.Error("❌ Failed to create or update the pull request for folder '"+rootFolderName+"' - "+err.Error(), rootFolderName)
+		return utils.NewAPIError(
+			"Branch was pushed but the pull request could not be created or updated",
+			err,
+			map[string]interface{}{
+				"folder": rootFolderName,
+				"branch": branchName,
+			},
+			rootFolderName,
+		)
+	}
 	return nil
 }
+
+// syncPullRequest creates or updates the pull request for a pushed branch when requested
+// with --pr or enabled in the forge configuration
+func syncPullRequest(rootFolderName, branchName string, opts ...interfaces.PushOptions) error {
+	requested := len(opts) > 0 && opts[0].PullRequest
+	if !requested && !config.GetForgeConfig(rootFolderName).Enabled {
+		return nil
+	}
+
+	_, err := forge.SyncPullRequest(rootFolderName, branchName, opts...)
+	return err
+}
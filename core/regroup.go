@@ -0,0 +1,82 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"strings"
+)
+
+// RegroupCommits turns the local commits of rangeSpec into clustered commits with generated
+// messages. The proposed history is previewed and applied only when approved (or approveAll);
+// with dryRun the original commits are always restored.
+func RegroupCommits(rootFolderName, rangeSpec string, numClusters int, approveAll, dryRun bool, env ...[]string) error {
+	rootFolderName, err := resolveRootFolder(rootFolderName)
+	if err != nil {
+		return err
+	}
+
+	utils.Info("🧩 Regrouping " + rangeSpec + " in " + rootFolderName + "...")
+	plan, err := git.PrepareRegroup(rootFolderName, rangeSpec, numClusters)
+	if err != nil {
+		utils.Error("❌ Failed to prepare regroup for folder '" + rootFolderName + "' - " + err.Error())
+		return err
+	}
+
+	utils.Print(formatRegroupPreview(plan))
+
+	if dryRun || (!approveAll && !utils.ConfirmAction("Rewrite the branch with this history?", false)) {
+		if err := git.CancelRegroup(plan); err != nil {
+			return utils.NewGitError(
+				"Failed to restore the original commits",
+				err,
+				map[string]interface{}{
+					"directory":  rootFolderName,
+					"suggestion": "Restore them with: git reset --mixed " + plan.BackupRef,
+				},
+			)
+		}
+		utils.Info("Original commits kept.")
+		return nil
+	}
+
+	if err := git.ApplyRegroup(plan, env...); err != nil {
+		return err
+	}
+	utils.Success("✅ Branch regrouped. Undo with: gitcury regroup undo --root " + rootFolderName)
+	return nil
+}
+
+// UndoRegroup restores the commits saved by the last regroup of a root folder
+func UndoRegroup(rootFolderName string) error {
+	rootFolderName, err := resolveRootFolder(rootFolderName)
+	if err != nil {
+		return err
+	}
+
+	ref, err := git.UndoRegroup(rootFolderName)
+	if err != nil {
+		utils.Error("❌ Failed to undo regroup for folder '" + rootFolderName + "' - " + err.Error())
+		return err
+	}
+	utils.Success("✅ Restored the commits saved in " + ref)
+	return nil
+}
+
+// formatRegroupPreview renders the original and proposed history side by side
+func formatRegroupPreview(plan *git.RegroupPlan) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\nCurrent history (%d commits):\n", len(plan.Original)))
+	for _, commit := range plan.Original {
+		builder.WriteString("  " + commit + "\n")
+	}
+
+	builder.WriteString(fmt.Sprintf("\nProposed history (%d commits):\n", len(plan.Commits)))
+	for i, commit := range plan.Commits {
+		builder.WriteString(fmt.Sprintf("  %d. %s\n", i+1, commit.Message))
+		for _, file := range commit.Files {
+			builder.WriteString("       " + file + "\n")
+		}
+	}
+	return builder.String()
+}
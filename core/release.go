@@ -0,0 +1,74 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"errors"
+	"fmt"
+)
+
+// PlanReleases proposes the next release of each root folder. When no folders are given,
+// every configured root folder is used.
+func PlanReleases(bumpOverride string, folders ...string) ([]*git.ReleasePlan, error) {
+	if len(folders) == 0 {
+		var err error
+		if folders, err = configuredRootFolders(); err != nil {
+			return nil, err
+		}
+	}
+
+	var plans []*git.ReleasePlan
+	var failures []string
+	for _, folder := range folders {
+		plan, err := git.PlanRelease(folder, bumpOverride)
+		if errors.Is(err, git.ErrNothingToRelease) {
+			utils.Info("⏭️ No releasable changes in '" + folder + "', skipping")
+			continue
+		}
+		if err != nil {
+			utils.Error("❌ Failed to plan release for folder '" + folder + "' - " + err.Error())
+			failures = append(failures, fmt.Sprintf("Folder: %s, Error: %s", folder, err.Error()))
+			continue
+		}
+		plans = append(plans, plan)
+	}
+
+	if len(failures) > 0 {
+		return plans, utils.NewGitError(
+			"One or more errors occurred while planning releases",
+			fmt.Errorf("multiple release errors"),
+			map[string]interface{}{
+				"errors": failures,
+			},
+		)
+	}
+	return plans, nil
+}
+
+// TagReleases creates the annotated tags of plans, skipping folders without releasable changes
+func TagReleases(plans []*git.ReleasePlan) error {
+	var errors []string
+	for _, plan := range plans {
+		if plan.Bump == git.BumpNone {
+			utils.Info("⏭️ No releasable changes in '" + plan.Directory + "', skipping")
+			continue
+		}
+		if err := git.CreateReleaseTag(plan.Directory, plan); err != nil {
+			utils.Error("❌ Failed to tag folder '" + plan.Directory + "' - " + err.Error())
+			errors = append(errors, fmt.Sprintf("Folder: %s, Error: %s", plan.Directory, err.Error()))
+			continue
+		}
+		utils.Success("🏷️ Tagged '" + plan.Directory + "' as " + plan.NextTag)
+	}
+
+	if len(errors) > 0 {
+		return utils.NewGitError(
+			"One or more errors occurred while tagging releases",
+			fmt.Errorf("multiple tag errors"),
+			map[string]interface{}{
+				"errors": errors,
+			},
+		)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// expandCommitUnits adds the submodules and nested repositories of each root folder that are
+// enabled in the repositories configuration. Each unit gets its own messages and commits.
+func expandCommitUnits(rootFolders []interface{}) []interface{} {
+	var units []interface{}
+	seen := make(map[string]bool)
+	for _, rootFolder := range rootFolders {
+		folder, ok := rootFolder.(string)
+		if !ok {
+			units = append(units, rootFolder)
+			continue
+		}
+		for _, unit := range git.DiscoverCommitUnits(folder) {
+			if !seen[unit] {
+				seen[unit] = true
+				units = append(units, unit)
+			}
+		}
+	}
+	return units
+}
+
+// commitSubmoduleBumps records the new commits of committed submodules in their superprojects.
+// Deeper submodules go first so each bump is picked up by the next level up.
+func commitSubmoduleBumps(committed []string, env ...[]string) error {
+	env = bumpCommitEnv(env)
+	pending := append([]string{}, committed...)
+	done := make(map[string]bool)
+
+	for len(pending) > 0 {
+		sort.Slice(pending, func(i, j int) bool { return len(pending[i]) > len(pending[j]) })
+		submodule := pending[0]
+		pending = pending[1:]
+		if done[submodule] {
+			continue
+		}
+		done[submodule] = true
+
+		super := git.Superproject(submodule)
+		if super == "" || !config.GetRepositoryConfig(super).Submodules || !git.SubmodulePointerChanged(super, submodule) {
+			continue
+		}
+
+		message, err := git.GenCommitMessage([]string{submodule}, super)
+		if err != nil {
+			return utils.NewGitError(
+				"Failed to generate the submodule update message",
+				err,
+				map[string]interface{}{
+					"superproject": super,
+					"submodule":    submodule,
+				},
+			)
+		}
+
+		folder := output.Folder{Name: super, Files: []output.FileEntry{{Name: submodule, Message: message}}}
+		if err := GitRunnerInstance.ProgressCommitBatch(outputToInterface(folder), env...); err != nil {
+			return utils.NewGitError(
+				"Failed to commit the submodule update",
+				err,
+				map[string]interface{}{
+					"superproject": super,
+					"submodule":    submodule,
+				},
+			)
+		}
+		relative, _ := filepath.Rel(super, submodule)
+		utils.Success("✅ Recorded the new commits of submodule '" + relative + "' in " + super)
+
+		// The superproject may itself be a submodule whose pointer just moved
+		pending = append(pending, super)
+	}
+	return nil
+}
+
+// bumpCommitEnv gives each bump commit the last date of a commit schedule, which lists one
+// date per commit of the submodule and would not match the single bump commit
+func bumpCommitEnv(env [][]string) [][]string {
+	if len(env) == 0 {
+		return env
+	}
+
+	prefix := config.CommitDatesEnv + "="
+	pairs := make([]string, 0, len(env[0]))
+	for _, pair := range env[0] {
+		if strings.HasPrefix(pair, prefix) {
+			dates := strings.Split(strings.TrimPrefix(pair, prefix), ",")
+			pair = prefix + dates[len(dates)-1]
+		}
+		pairs = append(pairs, pair)
+	}
+	return [][]string{pairs}
+}
+
+// committedFolderNames returns the names of folders that had files to commit
+func committedFolderNames(folders []output.Folder) []string {
+	var names []string
+	for _, folder := range folders {
+		if len(folder.Files) > 0 {
+			names = append(names, folder.Name)
+		}
+	}
+	return names
+}
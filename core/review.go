@@ -0,0 +1,219 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Review actions offered for the proposed clusters
+const (
+	reviewAccept = "Accept and generate messages"
+	reviewMove   = "Move a file to another cluster"
+	reviewSplit  = "Split files out of a cluster"
+	reviewMerge  = "Merge two clusters"
+	reviewRename = "Rename a cluster"
+	reviewCancel = "Cancel"
+)
+
+// reviewMu keeps the reviews of root folders processed concurrently from interleaving
+var reviewMu sync.Mutex
+
+// ReviewClusters adds a review step to grouped message generation: the interactive review,
+// or the cluster plan at planPath when given. The returned function removes it again.
+func ReviewClusters(planPath string) (func(), error) {
+	review := git.ClusterReview(ReviewClustersInteractively)
+	if planPath != "" {
+		plan, err := LoadClusterPlan(planPath)
+		if err != nil {
+			return nil, err
+		}
+		review = ClusterPlanReview(plan)
+	}
+
+	git.SetClusterReview(review)
+	return func() { git.SetClusterReview(nil) }, nil
+}
+
+// ReviewClustersInteractively shows the proposed clusters of a root folder and lets the user
+// move files between clusters, split, merge and rename them before messages are generated
+func ReviewClustersInteractively(rootFolder string, clusters []git.FileCluster) ([]git.FileCluster, error) {
+	reviewMu.Lock()
+	defer reviewMu.Unlock()
+
+	for {
+		utils.Print(FormatProposedClusters(rootFolder, clusters))
+
+		actions := []string{reviewAccept, reviewMove, reviewSplit, reviewMerge, reviewRename, reviewCancel}
+		action, _ := utils.PromptForSelection("What would you like to do?", actions, 0)
+
+		switch action {
+		case reviewAccept:
+			return clusters, nil
+
+		case reviewMove:
+			var files, options []string
+			for i, cluster := range clusters {
+				for _, file := range cluster.Files {
+					files = append(files, file)
+					options = append(options, fmt.Sprintf("%s (cluster %d)", relativeName(rootFolder, file), i+1))
+				}
+			}
+			_, fileIndex := utils.PromptForSelection("Which file?", options, 0)
+			to := selectCluster("Move it to which cluster?", clusters, true)
+			clusters = git.MoveFile(clusters, files[fileIndex], to, rootFolder)
+
+		case reviewSplit:
+			index := selectCluster("Split which cluster?", clusters, false)
+			if len(clusters[index].Files) < 2 {
+				utils.Warning("A cluster needs at least two files to be split.")
+				continue
+			}
+			var split []string
+			for len(split) < len(clusters[index].Files)-1 {
+				options := []string{"Done"}
+				var candidates []string
+				for _, file := range clusters[index].Files {
+					if !containsString(split, file) {
+						candidates = append(candidates, file)
+						options = append(options, relativeName(rootFolder, file))
+					}
+				}
+				_, choice := utils.PromptForSelection(fmt.Sprintf("Pick a file for the new cluster (%d picked)", len(split)), options, 0)
+				if choice == 0 {
+					break
+				}
+				split = append(split, candidates[choice-1])
+			}
+			clusters = git.SplitCluster(clusters, index, split, rootFolder)
+
+		case reviewMerge:
+			if len(clusters) < 2 {
+				utils.Warning("There is only one cluster.")
+				continue
+			}
+			into := selectCluster("Merge into which cluster?", clusters, false)
+			from := selectCluster("Merge which cluster into it?", clusters, false)
+			if from == into {
+				utils.Warning("Pick two different clusters to merge.")
+				continue
+			}
+			clusters = git.MergeClusters(clusters, into, from, rootFolder)
+
+		case reviewRename:
+			index := selectCluster("Rename which cluster?", clusters, false)
+			clusters = git.RenameCluster(clusters, index, utils.PromptForInput("New name", clusters[index].Name))
+
+		default:
+			return nil, utils.NewUserError(
+				"Cluster review cancelled",
+				nil,
+				map[string]interface{}{
+					"rootFolder": rootFolder,
+				},
+			)
+		}
+	}
+}
+
+// selectCluster asks for a cluster, optionally offering to start a new one
+func selectCluster(message string, clusters []git.FileCluster, allowNew bool) int {
+	var options []string
+	for i, cluster := range clusters {
+		options = append(options, fmt.Sprintf("Cluster %d: %s", i+1, clusterLabel(cluster)))
+	}
+	if allowNew {
+		options = append(options, "New cluster")
+	}
+	_, index := utils.PromptForSelection(message, options, 0)
+	return index
+}
+
+// clusterLabel names a cluster by its review name, or by its rule and size
+func clusterLabel(cluster git.FileCluster) string {
+	if cluster.Name != "" {
+		return fmt.Sprintf("%s (%d files)", cluster.Name, len(cluster.Files))
+	}
+	return fmt.Sprintf("%s (%d files)", cluster.Rule, len(cluster.Files))
+}
+
+// FormatProposedClusters renders the clusters under review
+func FormatProposedClusters(rootFolder string, clusters []git.FileCluster) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\n🧩 Proposed clusters for %s\n", rootFolder))
+	for i, cluster := range clusters {
+		builder.WriteString(fmt.Sprintf("\n  Cluster %d: %s\n", i+1, clusterLabel(cluster)))
+		for _, file := range cluster.Files {
+			builder.WriteString("     • " + relativeName(rootFolder, file) + "\n")
+		}
+	}
+	return builder.String()
+}
+
+// relativeName shows file relative to its root folder
+func relativeName(rootFolder, file string) string {
+	if name, err := filepath.Rel(rootFolder, file); err == nil {
+		return name
+	}
+	return file
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadClusterPlan reads a cluster plan JSON file
+func LoadClusterPlan(path string) (*git.ClusterPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, utils.NewValidationError("Failed to read the cluster plan", err, map[string]interface{}{"path": path})
+	}
+
+	var plan git.ClusterPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, utils.NewValidationError(
+			"Invalid cluster plan",
+			err,
+			map[string]interface{}{
+				"path":       path,
+				"suggestion": `Use {"folders": [{"rootFolder": "...", "clusters": [{"name": "...", "files": ["..."]}]}]}`,
+			},
+		)
+	}
+	return &plan, nil
+}
+
+// ClusterPlanReview returns a review step that applies plan instead of asking the user.
+// Root folders the plan does not mention keep their proposed clusters.
+func ClusterPlanReview(plan *git.ClusterPlan) git.ClusterReview {
+	return func(rootFolder string, clusters []git.FileCluster) ([]git.FileCluster, error) {
+		for _, folder := range plan.Folders {
+			if sameFolder(folder.RootFolder, rootFolder) {
+				utils.Info(fmt.Sprintf("Applying the cluster plan to %s", rootFolder))
+				return git.ApplyClusterPlan(clusters, folder.Clusters, rootFolder), nil
+			}
+		}
+		return clusters, nil
+	}
+}
+
+// sameFolder reports whether two root folder paths point to the same directory
+func sameFolder(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+)
+
+// RewordCommits regenerates the messages of the unpushed commits in rangeSpec, asks for
+// approval of each one (unless approveAll) and rewrites the approved ones. With dryRun only
+// the proposed messages are shown.
+func RewordCommits(rootFolderName, rangeSpec string, approveAll, dryRun bool) error {
+	rootFolderName, err := resolveRootFolder(rootFolderName)
+	if err != nil {
+		return err
+	}
+
+	utils.Info("🔍 Generating new messages for " + rangeSpec + " in " + rootFolderName + "...")
+	plan, err := git.PlanReword(rootFolderName, rangeSpec)
+	if err != nil {
+		utils.Error("❌ Failed to prepare reword for folder '" + rootFolderName + "' - " + err.Error())
+		return err
+	}
+
+	for i := range plan.Commits {
+		commit := &plan.Commits[i]
+		utils.Print(fmt.Sprintf("\n[%d/%d] %s (%d file(s))\n  old: %s\n  new: %s",
+			i+1, len(plan.Commits), commit.ShortHash, len(commit.Files), commit.OldMessage, commit.NewMessage))
+
+		if dryRun || commit.NewMessage == commit.OldMessage {
+			continue
+		}
+		if approveAll {
+			commit.Approved = true
+			continue
+		}
+
+		_, choice := utils.PromptForSelection("Reword this commit?", []string{
+			"Use the new message",
+			"Keep the original message",
+			"Edit the new message",
+		}, 0)
+		switch choice {
+		case 0:
+			commit.Approved = true
+		case 2:
+			commit.NewMessage = utils.PromptForInput("New message", commit.NewMessage)
+			commit.Approved = true
+		}
+	}
+
+	if dryRun {
+		utils.Info("Dry run: no commits were changed.")
+		return nil
+	}
+
+	backupRef, err := git.ApplyReword(rootFolderName, plan)
+	if err != nil {
+		if backupRef != "" {
+			utils.Error("❌ Reword failed; the original branch is saved as " + backupRef)
+		}
+		return err
+	}
+	if backupRef == "" {
+		utils.Info("No messages were changed.")
+		return nil
+	}
+
+	utils.Success("✅ Commits reworded. Undo with: git reset --hard " + backupRef)
+	return nil
+}
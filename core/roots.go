@@ -0,0 +1,109 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DiscoveredRoot is a repository found while scanning a workspace
+type DiscoveredRoot struct {
+	Path       string `json:"path"`
+	Pending    int    `json:"pending"`    // Changed, staged and untracked paths
+	Configured bool   `json:"configured"` // Already a root folder, directly or through a glob
+}
+
+// expandRootFolders expands glob entries of root_folders such as ~/work/* into the git
+// repositories they currently match
+func expandRootFolders(rootFolders []interface{}) []interface{} {
+	var expanded []interface{}
+	for _, rootFolder := range rootFolders {
+		entry, ok := rootFolder.(string)
+		if !ok || !config.IsRootPattern(entry) {
+			expanded = append(expanded, rootFolder)
+			continue
+		}
+
+		matches := config.ExpandRootFolder(entry)
+		utils.Debug(fmt.Sprintf("[ROOTS]: %s matched %d repositories", entry, len(matches)))
+		for _, match := range matches {
+			expanded = append(expanded, match)
+		}
+	}
+	return expanded
+}
+
+// DiscoverRoots scans dir up to depth levels deep for git repositories. depth <= 0 uses the
+// configured roots.maxDepth.
+func DiscoverRoots(dir string, depth int) ([]DiscoveredRoot, error) {
+	rootsConfig := config.GetRootsConfig()
+	if depth <= 0 {
+		depth = rootsConfig.MaxDepth
+	}
+
+	repositories, err := git.FindRepositories(dir, depth, rootsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	configured := make(map[string]bool)
+	for _, entry := range config.GetRootFolderEntries() {
+		for _, folder := range config.ExpandRootFolder(entry) {
+			if abs, err := filepath.Abs(config.ExpandHome(folder)); err == nil {
+				configured[abs] = true
+			}
+		}
+	}
+
+	roots := make([]DiscoveredRoot, 0, len(repositories))
+	for _, repository := range repositories {
+		pending, err := git.PendingChanges(repository)
+		if err != nil {
+			utils.Debug("[ROOTS]: Could not read status of " + repository + ": " + err.Error())
+		}
+		roots = append(roots, DiscoveredRoot{
+			Path:       repository,
+			Pending:    pending,
+			Configured: configured[repository],
+		})
+	}
+	return roots, nil
+}
+
+// FormatDiscoveredRoots renders discovered repositories as a table
+func FormatDiscoveredRoots(roots []DiscoveredRoot) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\n%-8s %-10s %s\n", "PENDING", "STATUS", "REPOSITORY"))
+	for _, root := range roots {
+		status := "new"
+		if root.Configured {
+			status = "configured"
+		}
+		builder.WriteString(fmt.Sprintf("%-8d %-10s %s\n", root.Pending, status, root.Path))
+	}
+	return builder.String()
+}
+
+// AddDiscoveredRoots asks which of the new repositories to add to root_folders; with
+// approveAll every new repository is added. It returns the added folders.
+func AddDiscoveredRoots(roots []DiscoveredRoot, approveAll bool) []string {
+	var selected []string
+	for _, root := range roots {
+		if root.Configured {
+			continue
+		}
+		prompt := fmt.Sprintf("Add %s (%d pending change(s))?", root.Path, root.Pending)
+		if approveAll || utils.ConfirmAction(prompt, root.Pending > 0) {
+			selected = append(selected, root.Path)
+		}
+	}
+
+	added := config.AddRootFolders(selected)
+	for _, folder := range added {
+		utils.Success("✅ Added root folder: " + folder)
+	}
+	return added
+}
@@ -0,0 +1,329 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Spreads supported by ScheduleOptions
+const (
+	SpreadEven      = "even"      // Equal gaps, first commit at the start and last at the end
+	SpreadRealistic = "realistic" // Irregular gaps with bursts of commits and quiet stretches
+)
+
+// ScheduleOptions describes the time window that a batch of commits is spread across
+type ScheduleOptions struct {
+	Start        time.Time
+	End          time.Time
+	Spread       string
+	WorkStart    int   // Start of working hours in minutes after midnight
+	WorkEnd      int   // End of working hours; 0 together with WorkStart means any time of day
+	WeekdaysOnly bool  // Skip Saturdays and Sundays
+	Seed         int64 // Seed of the realistic spread; 0 picks a random one
+}
+
+// ScheduledCommit is one entry of the commit timeline
+type ScheduledCommit struct {
+	Root    string    `json:"root"`
+	Message string    `json:"message"`
+	Files   []string  `json:"files"`
+	Date    time.Time `json:"date"`
+}
+
+// interval is a stretch of the window in which commits may be placed
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+// ParseWorkingHours parses working hours such as "09:00-18:00" into minutes after midnight
+func ParseWorkingHours(value string) (int, int, error) {
+	invalid := func(cause error) error {
+		return utils.NewValidationError(
+			"Invalid working hours",
+			cause,
+			map[string]interface{}{
+				"providedValue":  value,
+				"requiredFormat": "HH:MM-HH:MM",
+				"example":        "09:00-18:00",
+			},
+		)
+	}
+
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 2 {
+		return 0, 0, invalid(nil)
+	}
+	var minutes [2]int
+	for i, part := range parts {
+		clock, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return 0, 0, invalid(err)
+		}
+		minutes[i] = clock.Hour()*60 + clock.Minute()
+	}
+	if minutes[1] <= minutes[0] {
+		return 0, 0, invalid(fmt.Errorf("working hours must end after they start"))
+	}
+	return minutes[0], minutes[1], nil
+}
+
+// availableIntervals returns the parts of the window that fall within working hours
+func (opts ScheduleOptions) availableIntervals() []interval {
+	location := opts.Start.Location()
+	var intervals []interval
+	day := time.Date(opts.Start.Year(), opts.Start.Month(), opts.Start.Day(), 0, 0, 0, 0, location)
+	for !day.After(opts.End) {
+		next := day.AddDate(0, 0, 1)
+		if opts.WeekdaysOnly && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			day = next
+			continue
+		}
+
+		from, to := day, next
+		if opts.WorkStart != 0 || opts.WorkEnd != 0 {
+			from = day.Add(time.Duration(opts.WorkStart) * time.Minute)
+			to = day.Add(time.Duration(opts.WorkEnd) * time.Minute)
+		}
+		if from.Before(opts.Start) {
+			from = opts.Start
+		}
+		if to.After(opts.End) {
+			to = opts.End
+		}
+		if to.After(from) {
+			intervals = append(intervals, interval{start: from, end: to})
+		}
+		day = next
+	}
+	return intervals
+}
+
+// scheduleOffsets places n commits within total, in increasing order
+func (opts ScheduleOptions) scheduleOffsets(n int, total time.Duration) []time.Duration {
+	offsets := make([]time.Duration, n)
+	switch {
+	case n == 1:
+		if opts.Spread == SpreadRealistic {
+			offsets[0] = total / 2
+		}
+	case opts.Spread == SpreadRealistic:
+		seed := opts.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		rng := rand.New(rand.NewSource(seed)) //nolint:gosec // Non-cryptographic use, commit timing
+
+		// Log-normal gaps give runs of quick commits separated by longer breaks
+		gaps := make([]float64, n+1)
+		sum := 0.0
+		for i := range gaps {
+			gaps[i] = math.Exp(rng.NormFloat64() * 1.2)
+			sum += gaps[i]
+		}
+		elapsed := 0.0
+		for i := 0; i < n; i++ {
+			elapsed += gaps[i]
+			offsets[i] = time.Duration(elapsed / sum * float64(total))
+		}
+	default:
+		for i := range offsets {
+			offsets[i] = time.Duration(int64(total) / int64(n-1) * int64(i))
+		}
+	}
+
+	// Git stores whole seconds; keep every commit strictly after the previous one
+	for i := range offsets {
+		offsets[i] = offsets[i].Truncate(time.Second)
+		if i > 0 && offsets[i] <= offsets[i-1] {
+			offsets[i] = offsets[i-1] + time.Second
+		}
+	}
+	return offsets
+}
+
+// scheduleDates returns n increasing commit dates within the window
+func (opts ScheduleOptions) scheduleDates(n int) ([]time.Time, error) {
+	if !opts.End.After(opts.Start) {
+		return nil, utils.NewValidationError(
+			"The end of the commit window must be after its start",
+			nil,
+			map[string]interface{}{
+				"start": opts.Start.Format(time.RFC3339),
+				"end":   opts.End.Format(time.RFC3339),
+			},
+		)
+	}
+	if opts.Spread != SpreadEven && opts.Spread != SpreadRealistic {
+		return nil, utils.NewValidationError(
+			"Unknown commit spread",
+			nil,
+			map[string]interface{}{
+				"spread":    opts.Spread,
+				"supported": []string{SpreadEven, SpreadRealistic},
+			},
+		)
+	}
+
+	intervals := opts.availableIntervals()
+	var total time.Duration
+	for _, available := range intervals {
+		total += available.end.Sub(available.start)
+	}
+	if total < time.Duration(n)*time.Second {
+		return nil, utils.NewValidationError(
+			"The commit window has no room for the commits within working hours",
+			nil,
+			map[string]interface{}{
+				"start":      opts.Start.Format(time.RFC3339),
+				"end":        opts.End.Format(time.RFC3339),
+				"commits":    n,
+				"suggestion": "Widen the window or the working hours",
+			},
+		)
+	}
+
+	dates := make([]time.Time, n)
+	current, passed := 0, time.Duration(0)
+	for i, offset := range opts.scheduleOffsets(n, total) {
+		for current < len(intervals)-1 && offset-passed > intervals[current].end.Sub(intervals[current].start) {
+			passed += intervals[current].end.Sub(intervals[current].start)
+			current++
+		}
+		dates[i] = intervals[current].start.Add(offset - passed)
+	}
+	return dates, nil
+}
+
+// PlanSchedule spreads the commits of the given root folders across the window. Commits
+// keep the order CommitBatch creates them in, root folder by root folder. Without folders
+// every root folder with generated messages is scheduled.
+func PlanSchedule(opts ScheduleOptions, folders ...string) ([]ScheduledCommit, error) {
+	var rootFolders []output.Folder
+	if len(folders) == 0 {
+		rootFolders = output.GetAll().Folders
+	} else {
+		for _, folder := range folders {
+			rootFolders = append(rootFolders, output.GetFolder(folder))
+		}
+	}
+
+	var timeline []ScheduledCommit
+	for _, folder := range rootFolders {
+		for _, group := range git.CommitGroups(folder) {
+			timeline = append(timeline, ScheduledCommit{Root: folder.Name, Message: group.Message, Files: group.Files})
+		}
+	}
+	if len(timeline) == 0 {
+		return nil, utils.NewValidationError(
+			"No generated commit messages to schedule",
+			nil,
+			map[string]interface{}{
+				"suggestion": "Run 'gitcury getmsgs' first",
+			},
+		)
+	}
+
+	dates, err := opts.scheduleDates(len(timeline))
+	if err != nil {
+		return nil, err
+	}
+	for i := range timeline {
+		timeline[i].Date = dates[i]
+	}
+
+	if err := validateScheduleOrder(timeline); err != nil {
+		return nil, err
+	}
+	return timeline, nil
+}
+
+// validateScheduleOrder checks that every root folder's commits come after its current HEAD,
+// so that the history stays in chronological order
+func validateScheduleOrder(timeline []ScheduledCommit) error {
+	checked := make(map[string]bool)
+	for _, commit := range timeline {
+		if checked[commit.Root] {
+			continue
+		}
+		checked[commit.Root] = true
+
+		headTime, ok, err := git.HeadCommitTime(commit.Root)
+		if err != nil {
+			return err
+		}
+		if ok && !commit.Date.After(headTime) {
+			return utils.NewValidationError(
+				"Scheduled commits would be dated before the commit they build on",
+				nil,
+				map[string]interface{}{
+					"folder":      commit.Root,
+					"headDate":    headTime.Format(time.RFC3339),
+					"firstCommit": commit.Date.Format(time.RFC3339),
+					"suggestion":  "Start the window after " + headTime.Format("2006-01-02T15:04:05"),
+				},
+			)
+		}
+	}
+	return nil
+}
+
+// FormatSchedule renders the commit timeline
+func FormatSchedule(timeline []ScheduledCommit) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\n📅 Commit timeline: %d commit(s) from %s to %s\n\n",
+		len(timeline), timeline[0].Date.Format("Mon 2006-01-02 15:04"), timeline[len(timeline)-1].Date.Format("Mon 2006-01-02 15:04")))
+	builder.WriteString(fmt.Sprintf("%4s  %-25s %-7s %-20s %5s  %s\n", "#", "DATE", "GAP", "ROOT", "FILES", "MESSAGE"))
+
+	for i, commit := range timeline {
+		gap := "-"
+		if i > 0 {
+			gap = "+" + formatAge(commit.Date.Sub(timeline[i-1].Date))
+		}
+		message := strings.SplitN(commit.Message, "\n", 2)[0]
+		builder.WriteString(fmt.Sprintf("%4d  %-25s %-7s %-20s %5d  %s\n", i+1, commit.Date.Format("Mon 2006-01-02 15:04:05"),
+			gap, truncate(filepath.Base(commit.Root), 20), len(commit.Files), truncate(message, 72)))
+	}
+	return builder.String()
+}
+
+// CommitOnSchedule commits the timeline, dating each commit as planned. Root folders are
+// committed one after another; they share one operation ID so 'gitcury undo' reverts them together.
+func CommitOnSchedule(timeline []ScheduledCommit, env ...[]string) error {
+	var roots []string
+	dates := make(map[string][]string)
+	for _, commit := range timeline {
+		if _, ok := dates[commit.Root]; !ok {
+			roots = append(roots, commit.Root)
+		}
+		dates[commit.Root] = append(dates[commit.Root], commit.Date.Format(time.RFC3339))
+	}
+
+	for _, root := range roots {
+		if err := git.ValidateIdentity(root); err != nil {
+			utils.Error("Invalid commit identity for folder '" + root + "' - " + err.Error())
+			return err
+		}
+	}
+
+	if last := timeline[len(timeline)-1].Date; last.After(time.Now()) {
+		utils.Warning("Some commits are dated in the future. This may cause issues with Git history.")
+	}
+
+	env = withOperationID(env)
+	for _, root := range roots {
+		overrides := append(append([]string{}, env[0]...), config.CommitDatesEnv+"="+strings.Join(dates[root], ","))
+		if err := CommitOneRoot(root, overrides); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"strings"
+)
+
+// GetStagedMsgsForRootFolder generates a single commit message describing exactly what is
+// staged in folder. The message is stored for every staged file so that the regular output
+// commands keep working, and replaces any previously generated messages for the folder.
+func GetStagedMsgsForRootFolder(folder string) error {
+	if folder == "" {
+		utils.Error("Root folder is empty.")
+		return fmt.Errorf("root folder is empty")
+	}
+
+	utils.StartCreativeLoader(fmt.Sprintf("Analyzing staged changes: %s", folder), utils.ProcessingAnimation)
+	utils.UpdateCreativeLoaderPhase("generating")
+
+	message, stagedFiles, err := GitRunnerInstance.GenStagedCommitMessage(folder)
+	if err != nil {
+		utils.StopCreativeLoader()
+		utils.ShowCompletionMessage("Staged message generation failed", false)
+		utils.Error("Failed to generate staged commit message for folder '" + folder + "' - " + err.Error())
+		return err
+	}
+
+	// Messages left over from a regular run would be committed separately otherwise
+	output.RemoveFolder(folder)
+	for _, file := range stagedFiles {
+		output.Set(file, folder, message)
+	}
+
+	utils.StopCreativeLoader()
+	utils.ShowCompletionMessage(fmt.Sprintf("Staged commit message generated for folder: %s", folder), true)
+	utils.Success(fmt.Sprintf("Generated one message for %d staged file(s) in %s", len(stagedFiles), folder))
+
+	output.SaveToFile()
+	return nil
+}
+
+// GetAllStagedMsgs runs GetStagedMsgsForRootFolder for every configured root folder, with
+// globs expanded and commit units added as for the other --all runs. Folders with nothing
+// staged are skipped.
+func GetAllStagedMsgs() error {
+	configured, err := configuredRootFolders()
+	if err != nil {
+		return err
+	}
+
+	var errors []string
+	for _, unit := range expandCommitUnits(toInterfaceSlice(configured)) {
+		folder, ok := unit.(string)
+		if !ok {
+			continue
+		}
+
+		if err := GetStagedMsgsForRootFolder(folder); err != nil {
+			if structErr, ok := err.(*utils.StructuredError); ok && structErr.Type == utils.ValidationError {
+				utils.Debug("Skipping folder without staged changes: " + folder)
+				continue
+			}
+			errors = append(errors, fmt.Sprintf("Folder: %s, Error: %s", folder, err.Error()))
+		}
+	}
+
+	if len(errors) > 0 {
+		utils.Debug("Errors encountered: " + strings.Join(errors, "; "))
+		return fmt.Errorf("one or more errors occurred while preparing staged commit messages")
+	}
+
+	return nil
+}
+
+// CommitStagedRoot commits the index of rootFolderName as-is using its staged-only message
+func CommitStagedRoot(rootFolderName string, env ...[]string) error {
+	rootFolder := output.GetFolder(rootFolderName)
+	if len(rootFolder.Files) == 0 {
+		utils.Error("Root folder '"+rootFolderName+"' not found or contains no files.", rootFolderName)
+		return utils.NewValidationError(
+			"Root folder not found or has no files",
+			nil,
+			map[string]interface{}{
+				"folderName": rootFolderName,
+				"suggestion": "Run 'gitcury getmsgs --staged-only' first",
+			},
+			rootFolderName,
+		)
+	}
+
+	if err := GitRunnerInstance.CommitStaged(outputToInterface(rootFolder), env...); err != nil {
+		utils.Error("Failed to commit staged changes for folder '"+rootFolderName+"' - "+err.Error(), rootFolderName)
+		return utils.NewGitError(
+			"Failed to commit staged changes for folder",
+			err,
+			map[string]interface{}{
+				"folder": rootFolderName,
+			},
+			rootFolderName,
+		)
+	}
+
+	utils.Success("✅ Staged changes committed successfully for root folder: " + rootFolderName)
+	return nil
+}
+
+// CommitAllStagedRoots commits the index of every root folder that has a staged-only message
+func CommitAllStagedRoots(env ...[]string) error {
+	rootFolders := output.GetAll().Folders
+	if len(rootFolders) == 0 {
+		utils.Warning("No root folders with staged messages to commit")
+		return nil
+	}
+
+	env = withOperationID(env)
+	var errors []string
+	for _, folder := range rootFolders {
+		if len(folder.Files) == 0 {
+			continue
+		}
+		if err := CommitStagedRoot(folder.Name, env...); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+
+	if len(errors) > 0 {
+		return utils.NewGitError(
+			fmt.Sprintf("%d errors occurred during staged commit", len(errors)),
+			fmt.Errorf("multiple commit errors"),
+			map[string]interface{}{
+				"errorCount": len(errors),
+				"errors":     errors,
+			},
+		)
+	}
+
+	return nil
+}
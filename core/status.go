@@ -0,0 +1,154 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/interfaces"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RootStatus is one row of the status dashboard
+type RootStatus struct {
+	interfaces.RepositoryStatus
+	PendingMessages int               `json:"pendingMessages"` // Generated messages waiting in the output store
+	LastOperation   *git.JournalEntry `json:"lastOperation,omitempty"`
+	Health          string            `json:"health"` // "ok" or the health check failure
+	Error           string            `json:"error,omitempty"`
+}
+
+// CollectStatus gathers the status of the given root folders concurrently, in the given
+// order. Without folders every configured root folder (and its commit units) is used.
+func CollectStatus(folders ...string) ([]RootStatus, error) {
+	if len(folders) == 0 {
+		configured, err := configuredRootFolders()
+		if err != nil {
+			return nil, err
+		}
+		for _, unit := range expandCommitUnits(toInterfaceSlice(configured)) {
+			if folder, ok := unit.(string); ok {
+				folders = append(folders, folder)
+			}
+		}
+	}
+
+	statuses := make([]RootStatus, len(folders))
+	var wg sync.WaitGroup
+	for i, folder := range folders {
+		wg.Add(1)
+		go func(i int, folder string) {
+			defer wg.Done()
+			statuses[i] = collectRootStatus(folder)
+		}(i, folder)
+	}
+	wg.Wait()
+
+	return statuses, nil
+}
+
+// collectRootStatus gathers the dashboard row for one root folder
+func collectRootStatus(folder string) RootStatus {
+	status := RootStatus{
+		RepositoryStatus: interfaces.RepositoryStatus{Directory: folder},
+		PendingMessages:  len(output.GetFolder(folder).Files),
+		Health:           "ok",
+	}
+
+	if err := git.CheckRepositoryHealth(folder); err != nil {
+		status.Health = utils.ToUserFriendlyMessage(err)
+	}
+
+	repositoryStatus, err := GitRunnerInstance.RepositoryStatus(folder)
+	if err != nil {
+		status.Error = utils.ToUserFriendlyMessage(err)
+		return status
+	}
+	status.RepositoryStatus = repositoryStatus
+
+	if entry, err := git.LastJournalEntry(folder); err == nil {
+		status.LastOperation = entry
+	}
+	return status
+}
+
+// toInterfaceSlice converts folder names to the form stored in root_folders
+func toInterfaceSlice(values []string) []interface{} {
+	converted := make([]interface{}, len(values))
+	for i, value := range values {
+		converted[i] = value
+	}
+	return converted
+}
+
+// FormatStatusTable renders the status dashboard as a table
+func FormatStatusTable(statuses []RootStatus) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("\n%-24s %-20s %-9s %6s %6s %6s %6s %5s  %-22s %s\n",
+		"ROOT", "BRANCH", "↑/↓", "STAGED", "MOD", "UNTR", "CONFL", "MSGS", "LAST OPERATION", "HEALTH"))
+
+	for _, status := range statuses {
+		name := filepath.Base(status.Directory)
+		if status.Error != "" {
+			builder.WriteString(fmt.Sprintf("%-24s ❌ %s\n", truncate(name, 24), status.Error))
+			continue
+		}
+
+		branch := status.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		aheadBehind := "-"
+		if status.Upstream != "" {
+			aheadBehind = fmt.Sprintf("%d/%d", status.Ahead, status.Behind)
+		}
+		conflicted := fmt.Sprint(status.Conflicted)
+		if status.Conflicted > 0 {
+			conflicted = "⚠️ " + conflicted
+		}
+
+		lastOperation := "-"
+		if entry := status.LastOperation; entry != nil {
+			lastOperation = fmt.Sprintf("%s %s ago", entry.Operation, formatAge(time.Since(entry.Time)))
+			if entry.Pushed {
+				lastOperation += " ↑"
+			}
+		}
+
+		health := "✅"
+		if status.Health != "ok" {
+			health = "⚠️ " + status.Health
+		}
+
+		builder.WriteString(fmt.Sprintf("%-24s %-20s %-9s %6d %6d %6d %6s %5d  %-22s %s\n",
+			truncate(name, 24), truncate(branch, 20), aheadBehind, status.Staged, status.Modified,
+			status.Untracked, conflicted, status.PendingMessages, lastOperation, health))
+	}
+	return builder.String()
+}
+
+// formatAge renders a duration the way people say it, e.g. 5m, 3h or 2d
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return fmt.Sprintf("%ds", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
+}
+
+// truncate shortens value to at most width characters
+func truncate(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	return string(runes[:width-1]) + "…"
+}
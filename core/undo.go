@@ -0,0 +1,104 @@
+package core
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+)
+
+// withOperationID adds a shared operation ID to the commit overrides so that the journal
+// entries of every root folder committed in one run can be undone together
+func withOperationID(env [][]string) [][]string {
+	var overrides []string
+	if len(env) > 0 {
+		overrides = append(overrides, env[0]...)
+	}
+	overrides = append(overrides, config.OperationIDEnv+"="+config.NewOperationID())
+	return [][]string{overrides}
+}
+
+// UndoLastOperation undoes the most recent GitCury operation. With a root folder only its
+// latest operation is undone; otherwise the latest run is undone in every root folder it
+// touched. Generated messages are restored into the output store.
+func UndoLastOperation(rootFolderName string, force, approveAll bool) error {
+	var folders []string
+	if rootFolderName != "" {
+		folders = []string{rootFolderName}
+	} else {
+		var err error
+		if folders, err = configuredRootFolders(); err != nil {
+			return err
+		}
+	}
+
+	entries := make(map[string]*git.JournalEntry)
+	latestID := ""
+	for _, folder := range folders {
+		entry, err := git.LastJournalEntry(folder)
+		if err != nil {
+			utils.Warning("⚠️ Could not read the operation journal of " + folder + ": " + err.Error())
+			continue
+		}
+		if entry == nil {
+			continue
+		}
+		entries[folder] = entry
+		if entry.ID > latestID {
+			latestID = entry.ID
+		}
+	}
+
+	var targets []*git.JournalEntry
+	for _, folder := range folders {
+		if entry, ok := entries[folder]; ok && (rootFolderName != "" || entry.ID == latestID) {
+			targets = append(targets, entry)
+		}
+	}
+	if len(targets) == 0 {
+		utils.Info("Nothing to undo.")
+		return nil
+	}
+
+	var details []string
+	for _, entry := range targets {
+		detail := fmt.Sprintf("%s: %s on %s, %d commit(s) from %s",
+			entry.Directory, entry.Operation, entry.Branch, len(entry.Commits), entry.Time.Format("2006-01-02 15:04:05"))
+		if entry.Pushed {
+			detail += " (already pushed)"
+		}
+		details = append(details, detail)
+	}
+	if !approveAll && !utils.ConfirmActionWithDetails("Undo this operation?", details, false) {
+		utils.Info("Undo cancelled.")
+		return nil
+	}
+
+	var errors []string
+	for _, entry := range targets {
+		backupRef, err := git.UndoJournalEntry(entry.Directory, *entry, force)
+		if err != nil {
+			utils.Error("❌ Failed to undo the last operation in '" + entry.Directory + "' - " + err.Error())
+			errors = append(errors, fmt.Sprintf("Folder: %s, Error: %s", entry.Directory, err.Error()))
+			continue
+		}
+
+		for _, message := range entry.Messages {
+			output.Set(message.Name, entry.Directory, message.Message)
+		}
+		utils.Success(fmt.Sprintf("✅ Undid %d commit(s) in %s (saved as %s)", len(entry.Commits), entry.Directory, backupRef))
+	}
+	output.SaveToFile()
+
+	if len(errors) > 0 {
+		return utils.NewGitError(
+			"One or more operations could not be undone",
+			fmt.Errorf("multiple undo errors"),
+			map[string]interface{}{
+				"errors": errors,
+			},
+		)
+	}
+	return nil
+}
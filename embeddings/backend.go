@@ -0,0 +1,60 @@
+package embeddings
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/interfaces"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"sync"
+)
+
+var (
+	backendMu       sync.RWMutex
+	backendOverride interfaces.EmbeddingBackend
+)
+
+// SetBackend replaces the configured embedding backend, e.g. with a deterministic one in tests.
+// Passing nil restores the backend selected in clustering.methods.semantic.backend.
+func SetBackend(backend interfaces.EmbeddingBackend) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	backendOverride = backend
+}
+
+// CurrentBackend returns the embedding backend in use
+func CurrentBackend() interfaces.EmbeddingBackend {
+	backendMu.RLock()
+	override := backendOverride
+	backendMu.RUnlock()
+	if override != nil {
+		return override
+	}
+
+	semantic := config.GetClusteringConfig().Methods.Semantic
+	switch semantic.Backend {
+	case config.LocalEmbeddingBackend:
+		return NewLocalBackend(semantic.Dimensions)
+	case config.HTTPEmbeddingBackend:
+		return NewHTTPBackend(semantic.Endpoint, semantic.Model, semantic.EmbeddingTimeout)
+	case config.GeminiEmbeddingBackend, "":
+		return GeminiBackend{}
+	default:
+		utils.Warning("[EMBEDDINGS]: Unknown embedding backend '" + semantic.Backend + "', using gemini")
+		return GeminiBackend{}
+	}
+}
+
+// BackendName returns the name of the embedding backend in use
+func BackendName() string {
+	return CurrentBackend().Name()
+}
+
+// IsRateLimited reports whether the backend in use is a metered remote API, so callers should
+// pace their requests
+func IsRateLimited() bool {
+	return BackendName() == config.GeminiEmbeddingBackend
+}
+
+// GenerateEmbedding embeds text with the configured backend
+func GenerateEmbedding(text string) ([]float32, error) {
+	return CurrentBackend().Embed(text)
+}
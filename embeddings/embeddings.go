@@ -35,7 +35,16 @@ func checkCircuitBreaker() bool {
 	return false
 }
 
-func GenerateEmbedding(text string) ([]float32, error) {
+// GeminiBackend embeds text with text-embedding-004 through the Gemini API
+type GeminiBackend struct{}
+
+// Name identifies the backend
+func (GeminiBackend) Name() string {
+	return config.GeminiEmbeddingBackend
+}
+
+// Embed calls the Gemini API, trying each configured key in turn
+func (GeminiBackend) Embed(text string) ([]float32, error) {
 	// Check circuit breaker first
 	if checkCircuitBreaker() {
 		return nil, utils.NewAPIError(
@@ -260,13 +269,29 @@ func AutoCluster(data [][]float32, k int, maxIter int) ([]int, error) {
 		)
 	}
 
-	if k > 0 {
-		return KMeans(data, k, maxIter)
+	// Without a target the number of clusters is chosen, unless that is switched off for DBSCAN
+	if k <= 0 && config.GetClusteringConfig().ClusterCount.Auto {
+		labels, _, err := ChooseClusters(data)
+		return labels, err
 	}
 
 	// DBSCAN Parameters
 	eps := float32(0.5)     // radius threshold for neighborhood
 	minPts := 4             // minimum neighbors to form a cluster
+
+	// Large change sets use the sub-quadratic algorithms
+	if UseScalableClustering(len(data)) {
+		if k > 0 {
+			return MiniBatchKMeans(data, k, 0, maxIter*10)
+		}
+		labels, _ := ScalableDBSCAN(data, float64(eps), minPts)
+		return labels, nil
+	}
+
+	if k > 0 {
+		return KMeans(data, k, maxIter)
+	}
+
 	n := len(data)
 	labels := make([]int, n)
 	visited := make([]bool, n)
@@ -566,6 +591,8 @@ func HierarchicalClustering(data [][]float32, maxClusters int, threshold float32
 	distances := make([][]float32, n)
 	for i := 0; i < n; i++ {
 		distances[i] = make([]float32, n)
+	}
+	for i := 0; i < n; i++ {
 		for j := i + 1; j < n; j++ {
 			dist := float32(euclideanDistance(data[i], data[j]))
 			distances[i][j] = dist
@@ -645,6 +672,10 @@ func HierarchicalClustering(data [][]float32, maxClusters int, threshold float32
 
 // Adaptive clustering that chooses between K-means and hierarchical based on data characteristics
 func AdaptiveClustering(data [][]float32, targetClusters int, maxIter int) ([]int, error) {
+	if targetClusters <= 0 && len(data) > 0 {
+		targetClusters = ChooseClusterCount(data).K
+	}
+
 	if len(data) < 10 {
 		// For small datasets, use hierarchical clustering
 		return HierarchicalClustering(data, targetClusters, 0.5)
@@ -706,6 +737,7 @@ type ClusteringMetrics struct {
 	Iterations     int           `json:"iterations,omitempty"`
 	Silhouette     float64       `json:"silhouetteScore,omitempty"`
 	Inertia        float64       `json:"inertia,omitempty"`
+	Comparisons    int           `json:"comparisons,omitempty"` // Distance computations of neighbor searches
 }
 
 // Global metrics collection
@@ -886,6 +918,38 @@ func HierarchicalWithMetrics(data [][]float32, maxClusters int, threshold float3
 	return labels, metrics, err
 }
 
+// MiniBatchKMeansWithMetrics performs mini-batch K-means with performance monitoring. The
+// quadratic silhouette score is left out; inertia is linear in the data.
+func MiniBatchKMeansWithMetrics(data [][]float32, k int, maxIter int) ([]int, ClusteringMetrics, error) {
+	startTime := time.Now()
+	labels, err := MiniBatchKMeans(data, k, 0, maxIter)
+	duration := time.Since(startTime)
+
+	metrics := ClusteringMetrics{
+		Algorithm:      "MiniBatchKMeans",
+		DataPoints:     len(data),
+		TargetClusters: k,
+		Duration:       duration,
+		Iterations:     maxIter,
+	}
+
+	if err == nil && len(labels) > 0 {
+		clusterSet := make(map[int]bool)
+		for _, label := range labels {
+			clusterSet[label] = true
+		}
+		metrics.ActualClusters = len(clusterSet)
+		metrics.Inertia = CalculateInertia(data, labels)
+	}
+
+	clusteringMetrics = append(clusteringMetrics, metrics)
+
+	utils.Debug(fmt.Sprintf("[EMBEDDINGS]: Mini-batch K-means metrics - Duration: %v, Clusters: %d, Inertia: %.3f",
+		duration, metrics.ActualClusters, metrics.Inertia))
+
+	return labels, metrics, err
+}
+
 // GetClusteringMetrics returns all collected clustering metrics
 func GetClusteringMetrics() []ClusteringMetrics {
 	return clusteringMetrics
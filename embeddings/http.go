@@ -0,0 +1,103 @@
+package embeddings
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPBackend embeds text through an embedding server, such as Ollama's /api/embed or an
+// OpenAI-compatible /v1/embeddings endpoint. It posts {"model", "input"} and accepts any of the
+// "embeddings", "data[].embedding" or "embedding" response shapes.
+type HTTPBackend struct {
+	Endpoint string
+	Model    string
+	client   *http.Client
+}
+
+// NewHTTPBackend returns a backend calling endpoint, giving up on a request after timeoutSeconds
+func NewHTTPBackend(endpoint, model string, timeoutSeconds int) HTTPBackend {
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = 30
+	}
+	return HTTPBackend{
+		Endpoint: endpoint,
+		Model:    model,
+		client:   &http.Client{Timeout: time.Duration(timeoutSeconds) * time.Second},
+	}
+}
+
+// Name identifies the backend
+func (backend HTTPBackend) Name() string {
+	return config.HTTPEmbeddingBackend + ":" + backend.Model
+}
+
+// embeddingResponse covers the response shapes of common embedding servers
+type embeddingResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+	Embedding  []float32   `json:"embedding"`
+	Data       []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error interface{} `json:"error"`
+}
+
+// Embed posts text to the embedding server
+func (backend HTTPBackend) Embed(text string) ([]float32, error) {
+	context := map[string]interface{}{
+		"endpoint": backend.Endpoint,
+		"model":    backend.Model,
+	}
+	if backend.Endpoint == "" {
+		context["suggestion"] = "gitcury config clustering set --key semantic_endpoint --value http://localhost:11434/api/embed"
+		return nil, utils.NewConfigError("No embedding server endpoint configured", nil, context)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"model": backend.Model, "input": text})
+	if err != nil {
+		return nil, utils.NewSystemError("Failed to encode the embedding request", err, context)
+	}
+
+	client := backend.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Post(backend.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, utils.NewAPIError("Embedding server is not reachable", err, context)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, utils.NewAPIError("Failed to read the embedding server response", err, context)
+	}
+	if response.StatusCode != http.StatusOK {
+		context["status"] = response.StatusCode
+		context["response"] = string(data)
+		return nil, utils.NewAPIError(fmt.Sprintf("Embedding server returned %s", response.Status), nil, context)
+	}
+
+	var decoded embeddingResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, utils.NewAPIError("Embedding server returned invalid JSON", err, context)
+	}
+
+	switch {
+	case len(decoded.Embeddings) > 0 && len(decoded.Embeddings[0]) > 0:
+		return decoded.Embeddings[0], nil
+	case len(decoded.Data) > 0 && len(decoded.Data[0].Embedding) > 0:
+		return decoded.Data[0].Embedding, nil
+	case len(decoded.Embedding) > 0:
+		return decoded.Embedding, nil
+	}
+	if decoded.Error != nil {
+		context["error"] = decoded.Error
+	}
+	return nil, utils.NewAPIError("Embedding server returned no embedding", nil, context)
+}
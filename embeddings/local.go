@@ -0,0 +1,130 @@
+package embeddings
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// localStopTokens are too common in code and diffs to say anything about a change
+var localStopTokens = map[string]bool{
+	"the": true, "a": true, "an": true, "and": true, "or": true, "of": true, "to": true, "in": true,
+	"is": true, "if": true, "else": true, "for": true, "return": true, "func": true, "function": true,
+	"var": true, "let": true, "const": true, "def": true, "err": true, "nil": true, "null": true,
+	"true": true, "false": true, "this": true, "self": true, "new": true, "int": true, "string": true,
+}
+
+// LocalBackend embeds text without any network access: tokens and identifier parts of the diff
+// plus character trigrams are hashed into a fixed-size vector, weighted by sublinear term
+// frequency and L2-normalised. Equal text always gives the same vector.
+type LocalBackend struct {
+	Dimensions int
+}
+
+// NewLocalBackend returns a local backend producing vectors of the given size (512 when not positive)
+func NewLocalBackend(dimensions int) LocalBackend {
+	if dimensions <= 0 {
+		dimensions = 512
+	}
+	return LocalBackend{Dimensions: dimensions}
+}
+
+// Name identifies the backend and its vector size, so vectors of another size are not reused
+func (backend LocalBackend) Name() string {
+	return config.LocalEmbeddingBackend + ":" + strconv.Itoa(backend.Dimensions)
+}
+
+// Embed computes the hashed n-gram vector of text
+func (backend LocalBackend) Embed(text string) ([]float32, error) {
+	counts := make(map[string]float64)
+	for _, token := range diffTokens(text) {
+		if localStopTokens[token] {
+			continue
+		}
+		counts["w:"+token]++
+		if len(token) > 3 {
+			padded := "^" + token + "$"
+			for i := 0; i+3 <= len(padded); i++ {
+				counts["c:"+padded[i:i+3]] += 0.5
+			}
+		}
+	}
+
+	vector := make([]float32, backend.Dimensions)
+	for feature, count := range counts {
+		hasher := fnv.New64a()
+		hasher.Write([]byte(feature)) //nolint:errcheck // Writes to a hash never fail
+		sum := hasher.Sum64()
+		// The sign bit keeps colliding features from only ever adding up
+		weight := 1 + math.Log(count)
+		if sum&(1<<63) != 0 {
+			weight = -weight
+		}
+		vector[sum%uint64(backend.Dimensions)] += float32(weight)
+	}
+
+	norm := 0.0
+	for _, value := range vector {
+		norm += float64(value) * float64(value)
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vector {
+			vector[i] = float32(float64(vector[i]) / norm)
+		}
+	}
+	return vector, nil
+}
+
+// diffTokens splits text into lower-case words. Diff headers are skipped, and identifiers also
+// contribute their camelCase and snake_case parts.
+func diffTokens(text string) []string {
+	var tokens []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "diff --git") || strings.HasPrefix(line, "index ") ||
+			strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "@@") {
+			continue
+		}
+
+		words := strings.FieldsFunc(line, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+		})
+		for _, word := range words {
+			lower := strings.ToLower(word)
+			tokens = append(tokens, lower)
+			if parts := identifierParts(word); len(parts) > 1 {
+				tokens = append(tokens, parts...)
+			}
+		}
+	}
+	return tokens
+}
+
+// identifierParts splits camelCase, PascalCase and snake_case identifiers into lower-case parts
+func identifierParts(identifier string) []string {
+	var parts []string
+	var current []rune
+	runes := []rune(identifier)
+	flush := func() {
+		if len(current) > 1 {
+			parts = append(parts, strings.ToLower(string(current)))
+		}
+		current = current[:0]
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_':
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))):
+			flush()
+		}
+		current = append(current, r)
+	}
+	flush()
+	return parts
+}
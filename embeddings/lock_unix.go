@@ -0,0 +1,40 @@
+//go:build !windows
+
+package embeddings
+
+import (
+	"os"
+	"syscall"
+)
+
+// storeLock is an advisory lock on a file, shared between processes
+type storeLock struct {
+	file *os.File
+}
+
+// lockFile blocks until it holds a shared or exclusive flock on path
+func lockFile(path string, exclusive bool) (*storeLock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600) //nolint:gosec // Store path controlled by application
+	if err != nil {
+		return nil, err
+	}
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if err := syscall.Flock(int(file.Fd()), how); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &storeLock{file: file}, nil
+}
+
+// unlock releases the lock; releasing twice is harmless
+func (lock *storeLock) unlock() {
+	if lock == nil || lock.file == nil {
+		return
+	}
+	syscall.Flock(int(lock.file.Fd()), syscall.LOCK_UN) //nolint:errcheck // Closing the file releases the lock anyway
+	lock.file.Close()
+	lock.file = nil
+}
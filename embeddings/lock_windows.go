@@ -0,0 +1,42 @@
+//go:build windows
+
+package embeddings
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// storeLock is a byte-range lock on a file, shared between processes
+type storeLock struct {
+	file *os.File
+}
+
+// lockFile blocks until it holds a shared or exclusive lock on path
+func lockFile(path string, exclusive bool) (*storeLock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600) //nolint:gosec // Store path controlled by application
+	if err != nil {
+		return nil, err
+	}
+	flags := uint32(0)
+	if exclusive {
+		flags = windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+	overlapped := new(windows.Overlapped)
+	if err := windows.LockFileEx(windows.Handle(file.Fd()), flags, 0, 1, 0, overlapped); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &storeLock{file: file}, nil
+}
+
+// unlock releases the lock; releasing twice is harmless
+func (lock *storeLock) unlock() {
+	if lock == nil || lock.file == nil {
+		return
+	}
+	windows.UnlockFileEx(windows.Handle(lock.file.Fd()), 0, 1, 0, new(windows.Overlapped)) //nolint:errcheck // Closing the file releases the lock anyway
+	lock.file.Close()
+	lock.file = nil
+}
@@ -0,0 +1,361 @@
+package embeddings
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Noise is the DBSCAN label of points that belong to no cluster
+const Noise = -1
+
+// scalableSeed makes the scalable algorithms deterministic, so the same change set is always
+// grouped the same way
+const scalableSeed = 0x67c0
+
+// UseScalableClustering reports whether n points are enough to switch from the quadratic
+// algorithms to the scalable ones
+func UseScalableClustering(n int) bool {
+	minPoints := config.GetClusteringConfig().Performance.ScalableClusteringMinFiles
+	return minPoints > 0 && n >= minPoints
+}
+
+// MiniBatchKMeans clusters data into k clusters, updating the centroids from random batches of
+// batchSize points instead of the whole data set in each of maxIter iterations. The cost is
+// O(k·d·(batchSize·maxIter + n)) rather than O(k·d·n·maxIter).
+func MiniBatchKMeans(data [][]float32, k, batchSize, maxIter int) ([]int, error) {
+	if k <= 0 || len(data) == 0 {
+		return nil, utils.NewValidationError(
+			"Invalid parameters for mini-batch KMeans clustering",
+			nil,
+			map[string]interface{}{
+				"k":             k,
+				"dataPoints":    len(data),
+				"maxIterations": maxIter,
+			},
+		)
+	}
+	if len(data) < k {
+		return nil, utils.NewValidationError(
+			"Number of clusters cannot exceed data points",
+			nil,
+			map[string]interface{}{
+				"k":          k,
+				"dataPoints": len(data),
+			},
+		)
+	}
+	if batchSize <= 0 {
+		batchSize = 256
+	}
+
+	n := len(data)
+	rng := rand.New(rand.NewSource(scalableSeed)) //nolint:gosec // Non-cryptographic use, batch sampling
+
+	// Seed from a sample; k-means++ over every point would be the slowest step
+	sampleSize := n
+	if limit := 3 * batchSize; sampleSize > limit && limit >= k {
+		sampleSize = limit
+	}
+	sample := make([][]float32, sampleSize)
+	for i, index := range rng.Perm(n)[:sampleSize] {
+		sample[i] = data[index]
+	}
+	centroids := seedCentroids(sample, k, rng)
+
+	counts := make([]int, k)
+	batch := make([]int, batchSize)
+	nearest := make([]int, batchSize)
+	for iter := 0; iter < maxIter; iter++ {
+		for i := range batch {
+			batch[i] = rng.Intn(n)
+			nearest[i] = closestCentroid(data[batch[i]], centroids)
+		}
+
+		// Each centroid moves towards its points with a learning rate of 1/points seen
+		shift := 0.0
+		for i, index := range batch {
+			c := nearest[i]
+			counts[c]++
+			rate := float32(1) / float32(counts[c])
+			for j, value := range data[index] {
+				delta := rate * (value - centroids[c][j])
+				centroids[c][j] += delta
+				shift += float64(delta * delta)
+			}
+		}
+		if iter > 0 && math.Sqrt(shift/float64(batchSize)) < 1e-4 {
+			utils.Debug(fmt.Sprintf("[EMBEDDINGS]: Mini-batch K-means converged after %d iterations", iter+1))
+			break
+		}
+	}
+
+	labels := make([]int, n)
+	for i, point := range data {
+		labels[i] = closestCentroid(point, centroids)
+	}
+	return compactLabels(labels), nil
+}
+
+// seedCentroids picks k centroids with k-means++, keeping each point's distance to its nearest
+// centroid so every round only compares against the newest one
+func seedCentroids(data [][]float32, k int, rng *rand.Rand) [][]float32 {
+	n := len(data)
+	centroids := make([][]float32, 0, k)
+	pick := func(index int) {
+		centroid := make([]float32, len(data[index]))
+		copy(centroid, data[index])
+		centroids = append(centroids, centroid)
+	}
+	pick(rng.Intn(n))
+
+	distances := make([]float64, n)
+	for i := range distances {
+		distances[i] = math.MaxFloat64
+	}
+	for len(centroids) < k {
+		newest := centroids[len(centroids)-1]
+		total := 0.0
+		for i, point := range data {
+			distance := euclideanDistance(point, newest)
+			if distance*distance < distances[i] {
+				distances[i] = distance * distance
+			}
+			total += distances[i]
+		}
+
+		next := rng.Intn(n)
+		if total > 0 {
+			target := rng.Float64() * total
+			for i, distance := range distances {
+				if target -= distance; target <= 0 {
+					next = i
+					break
+				}
+			}
+		}
+		pick(next)
+	}
+	return centroids
+}
+
+// NeighborIndex finds close points with random-projection locality-sensitive hashing. Each of
+// several tables hashes a point by the signs of its projections on random hyperplanes, so points
+// at a small angle usually share a bucket in some table. Only a window of the bucket around a
+// point is compared, which bounds the work per point even when a bucket holds a whole cluster.
+type NeighborIndex struct {
+	data    [][]float32
+	buckets [][][]int // Table -> bucket -> points, in index order
+	bucket  [][]int   // Table -> point -> bucket
+	offset  [][]int   // Table -> point -> position in its bucket
+	window  int
+
+	Comparisons int // Distance computations made building neighbor graphs
+}
+
+// Defaults of the neighbor index; enough tables that close pairs are rarely missed in all of them
+const (
+	lshTables = 8
+	lshWindow = 16
+)
+
+// NewNeighborIndex hashes data into lshTables tables of about log2(n/8) bits each
+func NewNeighborIndex(data [][]float32) *NeighborIndex {
+	n := len(data)
+	index := &NeighborIndex{data: data, window: lshWindow}
+	if n == 0 {
+		return index
+	}
+
+	bits := 1
+	for (n>>bits) > 8 && bits < 16 {
+		bits++
+	}
+	dim := len(data[0])
+	rng := rand.New(rand.NewSource(scalableSeed)) //nolint:gosec // Non-cryptographic use, random projections
+
+	for table := 0; table < lshTables; table++ {
+		planes := make([][]float32, bits)
+		for b := range planes {
+			planes[b] = make([]float32, dim)
+			for j := range planes[b] {
+				planes[b][j] = float32(rng.NormFloat64())
+			}
+		}
+
+		byHash := make(map[uint32][]int)
+		for i, point := range data {
+			hash := uint32(0)
+			for b, plane := range planes {
+				dot := float32(0)
+				for j := 0; j < dim && j < len(point); j++ {
+					dot += point[j] * plane[j]
+				}
+				if dot >= 0 {
+					hash |= 1 << b
+				}
+			}
+			byHash[hash] = append(byHash[hash], i)
+		}
+
+		hashes := make([]uint32, 0, len(byHash))
+		for hash := range byHash {
+			hashes = append(hashes, hash)
+		}
+		sort.Slice(hashes, func(a, b int) bool { return hashes[a] < hashes[b] })
+
+		buckets := make([][]int, len(hashes))
+		bucketOf := make([]int, n)
+		offsetOf := make([]int, n)
+		for b, hash := range hashes {
+			buckets[b] = byHash[hash]
+			for position, point := range buckets[b] {
+				bucketOf[point] = b
+				offsetOf[point] = position
+			}
+		}
+		index.buckets = append(index.buckets, buckets)
+		index.bucket = append(index.bucket, bucketOf)
+		index.offset = append(index.offset, offsetOf)
+	}
+	return index
+}
+
+// neighbors returns the points within Euclidean distance eps of point i, excluding i itself.
+// seen is scratch space of one int per point, zero before the first call.
+func (index *NeighborIndex) neighbors(i int, eps float64, seen []int) []int {
+	var neighbors []int
+	stamp := i + 1
+	seen[i] = stamp
+	for table := range index.buckets {
+		members := index.buckets[table][index.bucket[table][i]]
+		position := index.offset[table][i]
+		from, to := position-index.window, position+index.window+1
+		if from < 0 {
+			from = 0
+		}
+		if to > len(members) {
+			to = len(members)
+		}
+		for _, candidate := range members[from:to] {
+			if seen[candidate] == stamp {
+				continue
+			}
+			seen[candidate] = stamp
+			index.Comparisons++
+			if euclideanDistance(index.data[i], index.data[candidate]) <= eps {
+				neighbors = append(neighbors, candidate)
+			}
+		}
+	}
+	return neighbors
+}
+
+// NeighborGraph returns, for every point, the points within distance eps found through the
+// index. The graph is symmetric.
+func (index *NeighborIndex) NeighborGraph(eps float64) [][]int {
+	n := len(index.data)
+	graph := make([][]int, n)
+	seen := make([]int, n)
+	linked := make(map[[2]int]bool)
+	for i := 0; i < n; i++ {
+		for _, j := range index.neighbors(i, eps, seen) {
+			edge := [2]int{i, j}
+			if j < i {
+				edge = [2]int{j, i}
+			}
+			if !linked[edge] {
+				linked[edge] = true
+				graph[i] = append(graph[i], j)
+				graph[j] = append(graph[j], i)
+			}
+		}
+	}
+	return graph
+}
+
+// DBSCANGraph runs DBSCAN over a neighbor graph: points with at least minPts-1 neighbors are
+// core points, clusters are the core points reachable from each other plus their neighbors,
+// and the remaining points are labelled Noise
+func DBSCANGraph(graph [][]int, minPts int) []int {
+	labels := make([]int, len(graph))
+	for i := range labels {
+		labels[i] = Noise
+	}
+	isCore := func(i int) bool { return len(graph[i])+1 >= minPts }
+
+	cluster := 0
+	for start := range graph {
+		if labels[start] != Noise || !isCore(start) {
+			continue
+		}
+		labels[start] = cluster
+		queue := []int{start}
+		for len(queue) > 0 {
+			point := queue[0]
+			queue = queue[1:]
+			if !isCore(point) {
+				continue // Border points join the cluster without extending it
+			}
+			for _, neighbor := range graph[point] {
+				if labels[neighbor] == Noise {
+					labels[neighbor] = cluster
+					queue = append(queue, neighbor)
+				}
+			}
+		}
+		cluster++
+	}
+	return labels
+}
+
+// ScalableDBSCAN clusters data with DBSCAN over the LSH neighbor graph, in close to O(n) distance
+// computations instead of the O(n²) of a full neighborhood scan. Noise points get a cluster of
+// their own, so every point has a label.
+func ScalableDBSCAN(data [][]float32, eps float64, minPts int) ([]int, ClusteringMetrics) {
+	startTime := time.Now()
+	index := NewNeighborIndex(data)
+	labels := DBSCANGraph(index.NeighborGraph(eps), minPts)
+
+	next := 0
+	for _, label := range labels {
+		if label >= next {
+			next = label + 1
+		}
+	}
+	for i, label := range labels {
+		if label == Noise {
+			labels[i] = next
+			next++
+		}
+	}
+
+	metrics := ClusteringMetrics{
+		Algorithm:      "LSH-DBSCAN",
+		DataPoints:     len(data),
+		ActualClusters: next,
+		Duration:       time.Since(startTime),
+		Comparisons:    index.Comparisons,
+	}
+	clusteringMetrics = append(clusteringMetrics, metrics)
+
+	utils.Debug(fmt.Sprintf("[EMBEDDINGS]: LSH-DBSCAN: %d points -> %d clusters with %d distance computations in %v",
+		len(data), next, index.Comparisons, metrics.Duration))
+	return labels, metrics
+}
+
+// compactLabels renumbers labels to 0..k-1 in order of first appearance
+func compactLabels(labels []int) []int {
+	renumbered := make(map[int]int)
+	for i, label := range labels {
+		if _, ok := renumbered[label]; !ok {
+			renumbered[label] = len(renumbered)
+		}
+		labels[i] = renumbered[label]
+	}
+	return labels
+}
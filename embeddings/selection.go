@@ -0,0 +1,304 @@
+package embeddings
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Limits of the cluster count sweep. Larger data sets are swept on a sample and clustered in
+// full with the chosen k.
+const (
+	maxSelectionPoints = 200
+	gapReferences      = 5    // Uniform reference data sets per k for the gap statistic
+	weakSilhouette     = 0.25 // Below this average silhouette the data has no substantial structure
+	selectionRestarts  = 2
+	selectionMaxIter   = 15
+)
+
+// KCandidate scores one number of clusters
+type KCandidate struct {
+	K          int     `json:"k"`
+	Silhouette float64 `json:"silhouette"` // 0 for k = 1
+	Inertia    float64 `json:"inertia"`
+	Gap        float64 `json:"gap"`
+	GapError   float64 `json:"gapError"` // Standard error of the reference dispersion
+}
+
+// KSelection records how the number of clusters was chosen
+type KSelection struct {
+	K           int          `json:"k"`
+	Criterion   string       `json:"criterion"` // Criterion that decided: silhouette, elbow, gap or vote
+	MinK        int          `json:"minK"`
+	MaxK        int          `json:"maxK"`
+	Points      int          `json:"points"`
+	Sampled     int          `json:"sampled,omitempty"` // Points the sweep ran on, when sampled
+	SilhouetteK int          `json:"silhouetteK"`
+	ElbowK      int          `json:"elbowK"`
+	GapK        int          `json:"gapK"`
+	Candidates  []KCandidate `json:"candidates"`
+}
+
+// SelectK sweeps k from minK to maxK (clamped to the data) and picks the number of clusters by
+// criterion:
+//   - silhouette: the k with the highest average silhouette; 1 when minK is 1 and even the best
+//     silhouette is weak
+//   - elbow: the k where the inertia curve bends most (kneedle)
+//   - gap: the smallest k whose gap statistic is within one standard error of the next k's
+//   - vote: the k most criteria agree on, the silhouette's choice on a three-way tie
+func SelectK(data [][]float32, minK, maxK int, criterion string) KSelection {
+	n := len(data)
+	if minK < 1 {
+		minK = 1
+	}
+	if maxK > n-1 {
+		maxK = n - 1 // Every point in its own cluster says nothing
+	}
+	selection := KSelection{MinK: minK, MaxK: maxK, Points: n, Criterion: criterion}
+	if maxK <= minK {
+		selection.K = clampInt(minK, 1, n)
+		selection.Criterion = "bounds"
+		selection.SilhouetteK, selection.ElbowK, selection.GapK = selection.K, selection.K, selection.K
+		return selection
+	}
+
+	rng := rand.New(rand.NewSource(scalableSeed)) //nolint:gosec // Non-cryptographic use, sampling
+	sample := data
+	if n > maxSelectionPoints {
+		sample = make([][]float32, maxSelectionPoints)
+		for i, index := range rng.Perm(n)[:maxSelectionPoints] {
+			sample[i] = data[index]
+		}
+		selection.Sampled = maxSelectionPoints
+		if maxK > maxSelectionPoints-1 {
+			maxK = maxSelectionPoints - 1
+			selection.MaxK = maxK
+		}
+	}
+
+	references := make([][][]float32, gapReferences)
+	for b := range references {
+		references[b] = uniformReference(sample, rng)
+	}
+
+	for k := minK; k <= maxK; k++ {
+		labels := fitKMeans(sample, k, selectionRestarts, rng)
+		candidate := KCandidate{K: k, Inertia: CalculateInertia(sample, labels)}
+		if k > 1 {
+			candidate.Silhouette = CalculateSilhouetteScore(sample, labels)
+		}
+
+		logs := make([]float64, gapReferences)
+		mean := 0.0
+		for b, reference := range references {
+			logs[b] = math.Log(math.Max(CalculateInertia(reference, fitKMeans(reference, k, 1, rng)), 1e-12))
+			mean += logs[b]
+		}
+		mean /= gapReferences
+		deviation := 0.0
+		for _, value := range logs {
+			deviation += (value - mean) * (value - mean)
+		}
+		candidate.Gap = mean - math.Log(math.Max(candidate.Inertia, 1e-12))
+		candidate.GapError = math.Sqrt(deviation/gapReferences) * math.Sqrt(1+1.0/gapReferences)
+		selection.Candidates = append(selection.Candidates, candidate)
+	}
+
+	selection.SilhouetteK = silhouetteChoice(selection.Candidates, minK)
+	selection.ElbowK = elbowChoice(selection.Candidates)
+	selection.GapK = gapChoice(selection.Candidates)
+
+	switch criterion {
+	case config.SilhouetteCriterion:
+		selection.K = selection.SilhouetteK
+	case config.ElbowCriterion:
+		selection.K = selection.ElbowK
+	case config.GapCriterion:
+		selection.K = selection.GapK
+	default:
+		selection.Criterion = config.VoteCriterion
+		selection.K = selection.SilhouetteK
+		if selection.ElbowK == selection.GapK {
+			selection.K = selection.ElbowK
+		}
+	}
+	return selection
+}
+
+// ChooseClusterCount picks the number of clusters of data within the configured bounds and
+// records the decision in the command statistics
+func ChooseClusterCount(data [][]float32) KSelection {
+	settings := config.GetClusteringConfig().ClusterCount
+	selection := SelectK(data, settings.MinClusters, settings.MaxClusters, settings.Criterion)
+
+	utils.Debug(fmt.Sprintf("[EMBEDDINGS]: Chose %d clusters for %d points by %s (silhouette %d, elbow %d, gap %d, bounds %d-%d)",
+		selection.K, selection.Points, selection.Criterion, selection.SilhouetteK, selection.ElbowK, selection.GapK,
+		selection.MinK, selection.MaxK))
+
+	silhouette := 0.0
+	for _, candidate := range selection.Candidates {
+		if candidate.K == selection.K {
+			silhouette = candidate.Silhouette
+		}
+	}
+	utils.RecordClusterCount(utils.ClusterCountInfo{
+		Points:      selection.Points,
+		K:           selection.K,
+		Criterion:   selection.Criterion,
+		MinK:        selection.MinK,
+		MaxK:        selection.MaxK,
+		SilhouetteK: selection.SilhouetteK,
+		ElbowK:      selection.ElbowK,
+		GapK:        selection.GapK,
+		Silhouette:  silhouette,
+	})
+	return selection
+}
+
+// ChooseClusters clusters data into the number of clusters ChooseClusterCount picks
+func ChooseClusters(data [][]float32) ([]int, KSelection, error) {
+	if len(data) == 0 {
+		return nil, KSelection{}, utils.NewValidationError("Data points are required for clustering", nil, nil)
+	}
+	selection := ChooseClusterCount(data)
+
+	if UseScalableClustering(len(data)) {
+		labels, err := MiniBatchKMeans(data, selection.K, 0, 200)
+		return labels, selection, err
+	}
+	rng := rand.New(rand.NewSource(scalableSeed)) //nolint:gosec // Non-cryptographic use, clustering initialization
+	return fitKMeans(data, selection.K, selectionRestarts+1, rng), selection, nil
+}
+
+// fitKMeans runs Lloyd's K-means from k-means++ seeds and keeps the restart with the lowest
+// inertia
+func fitKMeans(data [][]float32, k, restarts int, rng *rand.Rand) []int {
+	var best []int
+	bestInertia := math.MaxFloat64
+	for restart := 0; restart < restarts; restart++ {
+		centroids := seedCentroids(data, k, rng)
+		labels := make([]int, len(data))
+		for iter := 0; iter < selectionMaxIter; iter++ {
+			changed := false
+			for i, point := range data {
+				if label := closestCentroid(point, centroids); label != labels[i] || iter == 0 {
+					changed = changed || label != labels[i]
+					labels[i] = label
+				}
+			}
+			if iter > 0 && !changed {
+				break
+			}
+			centroids = labelCentroids(data, labels, centroids)
+		}
+		if inertia := CalculateInertia(data, labels); inertia < bestInertia {
+			best, bestInertia = labels, inertia
+		}
+	}
+	return best
+}
+
+// labelCentroids returns the mean of each label's points; empty labels keep their centroid
+func labelCentroids(data [][]float32, labels []int, previous [][]float32) [][]float32 {
+	centroids := make([][]float32, len(previous))
+	counts := make([]int, len(previous))
+	for c := range centroids {
+		centroids[c] = make([]float32, len(previous[c]))
+	}
+	for i, point := range data {
+		counts[labels[i]]++
+		for j, value := range point {
+			centroids[labels[i]][j] += value
+		}
+	}
+	for c := range centroids {
+		if counts[c] == 0 {
+			copy(centroids[c], previous[c])
+			continue
+		}
+		for j := range centroids[c] {
+			centroids[c][j] /= float32(counts[c])
+		}
+	}
+	return centroids
+}
+
+// uniformReference draws as many points as data uniformly from its bounding box
+func uniformReference(data [][]float32, rng *rand.Rand) [][]float32 {
+	dim := len(data[0])
+	low := make([]float32, dim)
+	high := make([]float32, dim)
+	copy(low, data[0])
+	copy(high, data[0])
+	for _, point := range data {
+		for j, value := range point {
+			low[j] = float32(math.Min(float64(low[j]), float64(value)))
+			high[j] = float32(math.Max(float64(high[j]), float64(value)))
+		}
+	}
+
+	reference := make([][]float32, len(data))
+	for i := range reference {
+		reference[i] = make([]float32, dim)
+		for j := range reference[i] {
+			reference[i][j] = low[j] + rng.Float32()*(high[j]-low[j])
+		}
+	}
+	return reference
+}
+
+func silhouetteChoice(candidates []KCandidate, minK int) int {
+	best := candidates[0]
+	for _, candidate := range candidates {
+		if candidate.K > 1 && (best.K == 1 || candidate.Silhouette > best.Silhouette) {
+			best = candidate
+		}
+	}
+	if minK == 1 && best.Silhouette < weakSilhouette {
+		return 1
+	}
+	return best.K
+}
+
+// elbowChoice normalizes the inertia curve to the unit square and returns the k farthest below
+// the line from its first to its last point
+func elbowChoice(candidates []KCandidate) int {
+	first, last := candidates[0], candidates[len(candidates)-1]
+	spanK := float64(last.K - first.K)
+	spanInertia := first.Inertia - last.Inertia
+	if spanK <= 0 || spanInertia <= 0 {
+		return first.K
+	}
+
+	choice, farthest := first.K, 0.0
+	for _, candidate := range candidates {
+		x := float64(candidate.K-first.K) / spanK
+		y := (first.Inertia - candidate.Inertia) / spanInertia
+		if distance := y - x; distance > farthest {
+			choice, farthest = candidate.K, distance
+		}
+	}
+	return choice
+}
+
+// gapChoice is Tibshirani's rule: the smallest k with Gap(k) >= Gap(k+1) - s(k+1)
+func gapChoice(candidates []KCandidate) int {
+	for i := 0; i+1 < len(candidates); i++ {
+		if candidates[i].Gap >= candidates[i+1].Gap-candidates[i+1].GapError {
+			return candidates[i].K
+		}
+	}
+	return candidates[len(candidates)-1].K
+}
+
+func clampInt(value, low, high int) int {
+	if value < low {
+		return low
+	}
+	if value > high {
+		return high
+	}
+	return value
+}
@@ -0,0 +1,848 @@
+package embeddings
+
+import (
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VectorEncoding is how vectors are written to a VectorStore
+type VectorEncoding byte
+
+const (
+	tombstoneEncoding VectorEncoding = 0 // Marks a deleted key
+	Float32Vectors    VectorEncoding = 1 // 4 bytes per dimension, exact
+	Int8Vectors       VectorEncoding = 2 // 1 byte per dimension plus a scale, about 1% error
+)
+
+// String names the encoding as in the configuration
+func (encoding VectorEncoding) String() string {
+	switch encoding {
+	case Float32Vectors:
+		return "float32"
+	case Int8Vectors:
+		return "int8"
+	default:
+		return "deleted"
+	}
+}
+
+// ParseVectorEncoding reads "float32" or "int8"; anything else is float32
+func ParseVectorEncoding(value string) VectorEncoding {
+	if strings.EqualFold(value, "int8") {
+		return Int8Vectors
+	}
+	return Float32Vectors
+}
+
+const (
+	storeDataFile  = "vectors.dat"
+	storeIndexFile = "vectors.idx"
+	storeLockFile  = "vectors.lock"
+	storeVersion   = 1
+
+	// The store is compacted once it is at least this big and mostly dead records
+	compactMinBytes   = 1 << 20
+	compactDeadRatio  = 0.5
+	storeHeaderLength = 4 + 2 + 8 // Magic, version, generation
+)
+
+var (
+	dataMagic  = []byte("GCVD")
+	indexMagic = []byte("GCVI")
+)
+
+// StoredVector is one entry of a VectorStore
+type StoredVector struct {
+	Key     string    `json:"key"`
+	Vector  []float32 `json:"-"`
+	Backend string    `json:"backend"` // Embedding backend that produced the vector
+	Meta    string    `json:"meta"`    // Caller data, e.g. the content hash the vector belongs to
+	Updated time.Time `json:"updated"`
+}
+
+// StoreStats describes a VectorStore for `gitcury cache stats`
+type StoreStats struct {
+	Path         string         `json:"path"`
+	Encoding     string         `json:"encoding"`
+	Entries      int            `json:"entries"`
+	ByKind       map[string]int `json:"byKind"`    // Entries per key prefix, e.g. "file" or "hunk"
+	ByBackend    map[string]int `json:"byBackend"` // Entries per embedding backend
+	DataBytes    int64          `json:"dataBytes"`
+	LiveBytes    int64          `json:"liveBytes"`
+	IndexBytes   int64          `json:"indexBytes"`
+	Generation   uint64         `json:"generation"` // Changes with every compaction
+	OldestUpdate time.Time      `json:"oldestUpdate"`
+	NewestUpdate time.Time      `json:"newestUpdate"`
+}
+
+// indexEntry locates the newest record of a key in the data file
+type indexEntry struct {
+	offset  int64
+	length  uint32
+	backend string
+	updated int64
+}
+
+// VectorStore is an append-only binary store of embedding vectors shared by all root folders
+// and GitCury processes. Records are appended to vectors.dat and located through vectors.idx;
+// overwritten and deleted records stay behind until the store is compacted. Every operation
+// holds a lock on vectors.lock, so concurrent processes see each other's writes.
+//
+// Record layout, little endian: uint32 length of the rest, uint16+key, uint16+backend,
+// uint16+meta, int64 updated (unix nanoseconds), uint8 encoding, uint32 dimensions, a float32
+// scale for int8 vectors, the vector, and a CRC-32 of everything after the length.
+type VectorStore struct {
+	dir      string
+	encoding VectorEncoding
+
+	mu          sync.Mutex
+	generation  uint64
+	index       map[string]indexEntry
+	indexedTo   int64 // Data offset covered by the index
+	indexSize   int64 // Bytes of the index file already read
+	liveBytes   int64
+	initialized bool
+}
+
+// OpenVectorStore opens or creates the store in dir. New vectors are written with encoding.
+func OpenVectorStore(dir string, encoding VectorEncoding) (*VectorStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, utils.NewSystemError("Failed to create the embedding store directory", err, map[string]interface{}{"path": dir})
+	}
+	store := &VectorStore{dir: dir, encoding: encoding, index: make(map[string]indexEntry)}
+	err := store.withLock(true, func() error { return nil })
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Dir returns the directory of the store
+func (store *VectorStore) Dir() string {
+	return store.dir
+}
+
+func (store *VectorStore) path(name string) string {
+	return filepath.Join(store.dir, name)
+}
+
+// withLock runs fn holding the store lock, after catching up with writes of other processes
+func (store *VectorStore) withLock(exclusive bool, fn func() error) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	lock, err := lockFile(store.path(storeLockFile), exclusive)
+	if err != nil {
+		return utils.NewSystemError("Failed to lock the embedding store", err, map[string]interface{}{"path": store.dir})
+	}
+	defer func() { lock.unlock() }()
+
+	// Creating or repairing the files needs the exclusive lock
+	if err := store.refresh(exclusive); err != nil {
+		if exclusive {
+			return err
+		}
+		lock.unlock()
+		upgraded, lockErr := lockFile(store.path(storeLockFile), true)
+		if lockErr != nil {
+			return utils.NewSystemError("Failed to lock the embedding store", lockErr, map[string]interface{}{"path": store.dir})
+		}
+		lock = upgraded
+		if err := store.refresh(true); err != nil {
+			return err
+		}
+	}
+	return fn()
+}
+
+// errStoreNeedsRepair is returned by refresh when only a writer may fix the files
+var errStoreNeedsRepair = errors.New("embedding store needs repair")
+
+// refresh brings the in-memory index up to date with the files. With canWrite it creates
+// missing files, rebuilds a stale index and truncates a torn last record.
+func (store *VectorStore) refresh(canWrite bool) error {
+	dataInfo, err := os.Stat(store.path(storeDataFile))
+	if os.IsNotExist(err) {
+		if !canWrite {
+			return errStoreNeedsRepair
+		}
+		return store.create()
+	} else if err != nil {
+		return utils.NewSystemError("Failed to read the embedding store", err, map[string]interface{}{"path": store.dir})
+	}
+
+	generation, err := readStoreHeader(store.path(storeDataFile), dataMagic)
+	if err != nil {
+		if !canWrite {
+			return errStoreNeedsRepair
+		}
+		utils.Warning("[EMBEDDINGS.STORE]: The embedding store is damaged and will be recreated: " + err.Error())
+		return store.create()
+	}
+
+	if !store.initialized || generation != store.generation {
+		// First use, or another process compacted the store
+		store.generation = generation
+		store.index = make(map[string]indexEntry)
+		store.indexedTo = storeHeaderLength
+		store.indexSize = storeHeaderLength
+		store.liveBytes = 0
+		store.initialized = true
+
+		indexGeneration, err := readStoreHeader(store.path(storeIndexFile), indexMagic)
+		if err != nil || indexGeneration != generation {
+			if !canWrite {
+				store.initialized = false
+				return errStoreNeedsRepair
+			}
+			if err := store.rebuildIndex(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := store.readIndex(); err != nil {
+		return err
+	}
+
+	// Records appended without index entries, e.g. after a crash
+	if dataInfo.Size() > store.indexedTo {
+		if !canWrite {
+			return errStoreNeedsRepair
+		}
+		return store.indexTail()
+	}
+	return nil
+}
+
+// create writes empty data and index files with a new generation
+func (store *VectorStore) create() error {
+	generation := uint64(time.Now().UnixNano())
+	for name, magic := range map[string][]byte{storeDataFile: dataMagic, storeIndexFile: indexMagic} {
+		if err := os.WriteFile(store.path(name), storeHeader(magic, generation), 0600); err != nil {
+			return utils.NewSystemError("Failed to create the embedding store", err, map[string]interface{}{"path": store.path(name)})
+		}
+	}
+	store.generation = generation
+	store.index = make(map[string]indexEntry)
+	store.indexedTo = storeHeaderLength
+	store.indexSize = storeHeaderLength
+	store.liveBytes = 0
+	store.initialized = true
+	return nil
+}
+
+func storeHeader(magic []byte, generation uint64) []byte {
+	header := make([]byte, storeHeaderLength)
+	copy(header, magic)
+	binary.LittleEndian.PutUint16(header[4:], storeVersion)
+	binary.LittleEndian.PutUint64(header[6:], generation)
+	return header
+}
+
+// readStoreHeader returns the generation of a store file, checking its magic and version
+func readStoreHeader(path string, magic []byte) (uint64, error) {
+	file, err := os.Open(path) //nolint:gosec // Store path controlled by application
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	header := make([]byte, storeHeaderLength)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return 0, fmt.Errorf("%s: truncated header", filepath.Base(path))
+	}
+	if !bytes.Equal(header[:4], magic) {
+		return 0, fmt.Errorf("%s: not an embedding store file", filepath.Base(path))
+	}
+	if version := binary.LittleEndian.Uint16(header[4:]); version != storeVersion {
+		return 0, fmt.Errorf("%s: unsupported version %d", filepath.Base(path), version)
+	}
+	return binary.LittleEndian.Uint64(header[6:]), nil
+}
+
+// readIndex applies the index entries written since the last read
+func (store *VectorStore) readIndex() error {
+	file, err := os.Open(store.path(storeIndexFile))
+	if err != nil {
+		return utils.NewSystemError("Failed to read the embedding store index", err, map[string]interface{}{"path": store.dir})
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(store.indexSize, io.SeekStart); err != nil {
+		return utils.NewSystemError("Failed to read the embedding store index", err, nil)
+	}
+	reader := bufio.NewReader(file)
+	for {
+		entry, key, tombstone, size, err := readIndexEntry(reader)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			// A torn entry at the end is rewritten by the next writer from the data file
+			return nil
+		}
+		store.applyIndexEntry(key, entry, tombstone)
+		store.indexSize += size
+		if end := entry.offset + int64(entry.length); end > store.indexedTo {
+			store.indexedTo = end
+		}
+	}
+}
+
+func (store *VectorStore) applyIndexEntry(key string, entry indexEntry, tombstone bool) {
+	if old, ok := store.index[key]; ok {
+		store.liveBytes -= int64(old.length)
+	}
+	if tombstone {
+		delete(store.index, key)
+		return
+	}
+	store.index[key] = entry
+	store.liveBytes += int64(entry.length)
+}
+
+// Index entry layout: uint16+key, uint16+backend, uint64 offset, uint32 length, int64 updated,
+// uint8 flags (1 = deleted)
+func encodeIndexEntry(key string, entry indexEntry, tombstone bool) []byte {
+	var buffer bytes.Buffer
+	writeString(&buffer, key)
+	writeString(&buffer, entry.backend)
+	binary.Write(&buffer, binary.LittleEndian, uint64(entry.offset)) //nolint:errcheck // Writes to a buffer never fail
+	binary.Write(&buffer, binary.LittleEndian, entry.length)         //nolint:errcheck
+	binary.Write(&buffer, binary.LittleEndian, entry.updated)        //nolint:errcheck
+	flags := byte(0)
+	if tombstone {
+		flags = 1
+	}
+	buffer.WriteByte(flags)
+	return buffer.Bytes()
+}
+
+func readIndexEntry(reader *bufio.Reader) (indexEntry, string, bool, int64, error) {
+	var entry indexEntry
+	key, keySize, err := readString(reader)
+	if err != nil {
+		return entry, "", false, 0, err
+	}
+	backend, backendSize, err := readString(reader)
+	if err != nil {
+		return entry, "", false, 0, io.ErrUnexpectedEOF
+	}
+	fixed := make([]byte, 8+4+8+1)
+	if _, err := io.ReadFull(reader, fixed); err != nil {
+		return entry, "", false, 0, io.ErrUnexpectedEOF
+	}
+	entry.backend = backend
+	entry.offset = int64(binary.LittleEndian.Uint64(fixed))
+	entry.length = binary.LittleEndian.Uint32(fixed[8:])
+	entry.updated = int64(binary.LittleEndian.Uint64(fixed[12:]))
+	return entry, key, fixed[20] == 1, keySize + backendSize + int64(len(fixed)), nil
+}
+
+func writeString(buffer *bytes.Buffer, value string) {
+	binary.Write(buffer, binary.LittleEndian, uint16(len(value))) //nolint:errcheck // Writes to a buffer never fail
+	buffer.WriteString(value)
+}
+
+func readString(reader io.Reader) (string, int64, error) {
+	var length uint16
+	if err := binary.Read(reader, binary.LittleEndian, &length); err != nil {
+		return "", 0, err
+	}
+	value := make([]byte, length)
+	if _, err := io.ReadFull(reader, value); err != nil {
+		return "", 0, io.ErrUnexpectedEOF
+	}
+	return string(value), int64(2 + length), nil
+}
+
+// encodeRecord serializes a vector as a data record, length prefix included
+func encodeRecord(vector StoredVector, encoding VectorEncoding) []byte {
+	var body bytes.Buffer
+	writeString(&body, vector.Key)
+	writeString(&body, vector.Backend)
+	writeString(&body, vector.Meta)
+	binary.Write(&body, binary.LittleEndian, vector.Updated.UnixNano()) //nolint:errcheck // Writes to a buffer never fail
+	body.WriteByte(byte(encoding))
+	binary.Write(&body, binary.LittleEndian, uint32(len(vector.Vector))) //nolint:errcheck
+
+	switch encoding {
+	case Float32Vectors:
+		binary.Write(&body, binary.LittleEndian, vector.Vector) //nolint:errcheck
+	case Int8Vectors:
+		maxAbs := float32(0)
+		for _, value := range vector.Vector {
+			if abs := float32(math.Abs(float64(value))); abs > maxAbs {
+				maxAbs = abs
+			}
+		}
+		scale := maxAbs / 127
+		binary.Write(&body, binary.LittleEndian, scale) //nolint:errcheck
+		for _, value := range vector.Vector {
+			quantized := 0.0
+			if scale > 0 {
+				quantized = math.Round(float64(value / scale))
+			}
+			body.WriteByte(byte(int8(quantized)))
+		}
+	}
+	binary.Write(&body, binary.LittleEndian, crc32.ChecksumIEEE(body.Bytes())) //nolint:errcheck
+
+	record := make([]byte, 4, 4+body.Len())
+	binary.LittleEndian.PutUint32(record, uint32(body.Len()))
+	return append(record, body.Bytes()...)
+}
+
+// decodeRecord parses a record body (without the length prefix)
+func decodeRecord(body []byte) (StoredVector, VectorEncoding, error) {
+	var vector StoredVector
+	if len(body) < 4 {
+		return vector, 0, errors.New("record too short")
+	}
+	content, checksum := body[:len(body)-4], binary.LittleEndian.Uint32(body[len(body)-4:])
+	if crc32.ChecksumIEEE(content) != checksum {
+		return vector, 0, errors.New("record checksum mismatch")
+	}
+
+	reader := bytes.NewReader(content)
+	var err error
+	if vector.Key, _, err = readString(reader); err != nil {
+		return vector, 0, err
+	}
+	if vector.Backend, _, err = readString(reader); err != nil {
+		return vector, 0, err
+	}
+	if vector.Meta, _, err = readString(reader); err != nil {
+		return vector, 0, err
+	}
+	var updated int64
+	var encoding byte
+	var dims uint32
+	if err := binary.Read(reader, binary.LittleEndian, &updated); err != nil {
+		return vector, 0, err
+	}
+	if encoding, err = reader.ReadByte(); err != nil {
+		return vector, 0, err
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &dims); err != nil {
+		return vector, 0, err
+	}
+	vector.Updated = time.Unix(0, updated)
+
+	switch VectorEncoding(encoding) {
+	case Float32Vectors:
+		vector.Vector = make([]float32, dims)
+		err = binary.Read(reader, binary.LittleEndian, vector.Vector)
+	case Int8Vectors:
+		var scale float32
+		if err = binary.Read(reader, binary.LittleEndian, &scale); err == nil {
+			quantized := make([]byte, dims)
+			if _, err = io.ReadFull(reader, quantized); err == nil {
+				vector.Vector = make([]float32, dims)
+				for i, value := range quantized {
+					vector.Vector[i] = float32(int8(value)) * scale
+				}
+			}
+		}
+	}
+	return vector, VectorEncoding(encoding), err
+}
+
+// indexTail indexes the records after indexedTo, truncating a torn record at the end
+func (store *VectorStore) indexTail() error {
+	file, err := os.OpenFile(store.path(storeDataFile), os.O_RDWR, 0600)
+	if err != nil {
+		return utils.NewSystemError("Failed to open the embedding store", err, map[string]interface{}{"path": store.dir})
+	}
+	defer file.Close()
+
+	offset := store.indexedTo
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return utils.NewSystemError("Failed to read the embedding store", err, nil)
+	}
+	reader := bufio.NewReader(file)
+	var entries bytes.Buffer
+	for {
+		length, body, err := readRecord(reader)
+		if err == io.EOF {
+			break
+		}
+		var vector StoredVector
+		var encoding VectorEncoding
+		if err == nil {
+			vector, encoding, err = decodeRecord(body)
+		}
+		if err != nil {
+			utils.Warning(fmt.Sprintf("[EMBEDDINGS.STORE]: Dropping a damaged record at offset %d: %v", offset, err))
+			if err := file.Truncate(offset); err != nil {
+				return utils.NewSystemError("Failed to repair the embedding store", err, nil)
+			}
+			break
+		}
+
+		entry := indexEntry{offset: offset, length: length, backend: vector.Backend, updated: vector.Updated.UnixNano()}
+		tombstone := encoding == tombstoneEncoding
+		store.applyIndexEntry(vector.Key, entry, tombstone)
+		entries.Write(encodeIndexEntry(vector.Key, entry, tombstone))
+		offset += int64(length)
+	}
+	store.indexedTo = offset
+
+	if entries.Len() == 0 {
+		return nil
+	}
+	return store.appendIndex(entries.Bytes())
+}
+
+// readRecord reads one length-prefixed record, returning its total length and body
+func readRecord(reader io.Reader) (uint32, []byte, error) {
+	var length uint32
+	if err := binary.Read(reader, binary.LittleEndian, &length); err != nil {
+		if err == io.EOF {
+			return 0, nil, io.EOF
+		}
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	if length > 64<<20 {
+		return 0, nil, errors.New("implausible record length")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	return 4 + length, body, nil
+}
+
+// rebuildIndex recreates the index file from the data file
+func (store *VectorStore) rebuildIndex() error {
+	if err := os.WriteFile(store.path(storeIndexFile), storeHeader(indexMagic, store.generation), 0600); err != nil {
+		return utils.NewSystemError("Failed to rebuild the embedding store index", err, map[string]interface{}{"path": store.dir})
+	}
+	store.index = make(map[string]indexEntry)
+	store.indexedTo = storeHeaderLength
+	store.indexSize = storeHeaderLength
+	store.liveBytes = 0
+	return store.indexTail()
+}
+
+func (store *VectorStore) appendIndex(entries []byte) error {
+	file, err := os.OpenFile(store.path(storeIndexFile), os.O_WRONLY, 0600)
+	if err != nil {
+		return utils.NewSystemError("Failed to write the embedding store index", err, map[string]interface{}{"path": store.dir})
+	}
+	defer file.Close()
+	// Entries go after the last complete one, overwriting what a crashed writer left behind
+	if err := file.Truncate(store.indexSize); err != nil {
+		return utils.NewSystemError("Failed to write the embedding store index", err, map[string]interface{}{"path": store.dir})
+	}
+	if _, err := file.WriteAt(entries, store.indexSize); err != nil {
+		return utils.NewSystemError("Failed to write the embedding store index", err, map[string]interface{}{"path": store.dir})
+	}
+	store.indexSize += int64(len(entries))
+	return nil
+}
+
+// appendRecords writes records and their index entries
+func (store *VectorStore) appendRecords(vectors []StoredVector, encoding VectorEncoding) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+	file, err := os.OpenFile(store.path(storeDataFile), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return utils.NewSystemError("Failed to write the embedding store", err, map[string]interface{}{"path": store.dir})
+	}
+	defer file.Close()
+
+	var records, entries bytes.Buffer
+	offset := store.indexedTo
+	type applied struct {
+		key       string
+		entry     indexEntry
+		tombstone bool
+	}
+	var pending []applied
+	for _, vector := range vectors {
+		recordEncoding := encoding
+		if vector.Vector == nil {
+			recordEncoding = tombstoneEncoding
+		}
+		record := encodeRecord(vector, recordEncoding)
+		entry := indexEntry{offset: offset, length: uint32(len(record)), backend: vector.Backend, updated: vector.Updated.UnixNano()}
+		records.Write(record)
+		entries.Write(encodeIndexEntry(vector.Key, entry, recordEncoding == tombstoneEncoding))
+		pending = append(pending, applied{vector.Key, entry, recordEncoding == tombstoneEncoding})
+		offset += int64(len(record))
+	}
+
+	if _, err := file.Write(records.Bytes()); err != nil {
+		return utils.NewSystemError("Failed to write the embedding store", err, map[string]interface{}{"path": store.dir})
+	}
+	for _, change := range pending {
+		store.applyIndexEntry(change.key, change.entry, change.tombstone)
+	}
+	store.indexedTo = offset
+	return store.appendIndex(entries.Bytes())
+}
+
+// readVectorAt reads the record of an index entry
+func (store *VectorStore) readVectorAt(file *os.File, entry indexEntry) (StoredVector, error) {
+	record := make([]byte, entry.length)
+	if _, err := file.ReadAt(record, entry.offset); err != nil {
+		return StoredVector{}, err
+	}
+	vector, _, err := decodeRecord(record[4:])
+	return vector, err
+}
+
+// Get returns the vector stored under key
+func (store *VectorStore) Get(key string) (StoredVector, bool, error) {
+	var vector StoredVector
+	found := false
+	err := store.withLock(false, func() error {
+		entry, ok := store.index[key]
+		if !ok {
+			return nil
+		}
+		file, err := os.Open(store.path(storeDataFile))
+		if err != nil {
+			return utils.NewSystemError("Failed to read the embedding store", err, map[string]interface{}{"path": store.dir})
+		}
+		defer file.Close()
+		if vector, err = store.readVectorAt(file, entry); err != nil {
+			utils.Warning(fmt.Sprintf("[EMBEDDINGS.STORE]: Unreadable vector for %s: %v", key, err))
+			return nil
+		}
+		found = true
+		return nil
+	})
+	return vector, found, err
+}
+
+// GetPrefix returns every vector whose key starts with prefix
+func (store *VectorStore) GetPrefix(prefix string) ([]StoredVector, error) {
+	var vectors []StoredVector
+	err := store.withLock(false, func() error {
+		file, err := os.Open(store.path(storeDataFile))
+		if err != nil {
+			return utils.NewSystemError("Failed to read the embedding store", err, map[string]interface{}{"path": store.dir})
+		}
+		defer file.Close()
+		for key, entry := range store.index {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if vector, err := store.readVectorAt(file, entry); err == nil {
+				vectors = append(vectors, vector)
+			}
+		}
+		return nil
+	})
+	sort.Slice(vectors, func(i, j int) bool { return vectors[i].Key < vectors[j].Key })
+	return vectors, err
+}
+
+// Put appends vectors, replacing earlier ones with the same key. The store is compacted when
+// most of it has become dead records.
+func (store *VectorStore) Put(vectors ...StoredVector) error {
+	for i := range vectors {
+		if vectors[i].Vector == nil {
+			vectors[i].Vector = []float32{} // nil marks deletions
+		}
+		if vectors[i].Updated.IsZero() {
+			vectors[i].Updated = time.Now()
+		}
+	}
+	return store.withLock(true, func() error {
+		if err := store.appendRecords(vectors, store.encoding); err != nil {
+			return err
+		}
+		return store.compactIfNeeded()
+	})
+}
+
+// Delete removes keys from the store
+func (store *VectorStore) Delete(keys ...string) error {
+	return store.withLock(true, func() error {
+		var tombstones []StoredVector
+		for _, key := range keys {
+			if _, ok := store.index[key]; ok {
+				tombstones = append(tombstones, StoredVector{Key: key, Updated: time.Now()})
+			}
+		}
+		return store.appendRecords(tombstones, store.encoding)
+	})
+}
+
+// Keys returns the stored keys starting with prefix, sorted
+func (store *VectorStore) Keys(prefix string) ([]string, error) {
+	var keys []string
+	err := store.withLock(false, func() error {
+		for key := range store.index {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+// Entries returns the metadata of the stored vectors starting with prefix, without the vectors
+func (store *VectorStore) Entries(prefix string) ([]StoredVector, error) {
+	var entries []StoredVector
+	err := store.withLock(false, func() error {
+		for key, entry := range store.index {
+			if strings.HasPrefix(key, prefix) {
+				entries = append(entries, StoredVector{Key: key, Backend: entry.backend, Updated: time.Unix(0, entry.updated)})
+			}
+		}
+		return nil
+	})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, err
+}
+
+// Prune deletes the least recently written vectors under prefix beyond maxEntries
+func (store *VectorStore) Prune(prefix string, maxEntries int) (int, error) {
+	removed := 0
+	err := store.withLock(true, func() error {
+		var keys []string
+		for key := range store.index {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) <= maxEntries {
+			return nil
+		}
+		sort.Slice(keys, func(i, j int) bool { return store.index[keys[i]].updated > store.index[keys[j]].updated })
+
+		var tombstones []StoredVector
+		for _, key := range keys[maxEntries:] {
+			tombstones = append(tombstones, StoredVector{Key: key, Updated: time.Now()})
+		}
+		removed = len(tombstones)
+		if err := store.appendRecords(tombstones, store.encoding); err != nil {
+			return err
+		}
+		return store.compactIfNeeded()
+	})
+	return removed, err
+}
+
+// Stats describes the store
+func (store *VectorStore) Stats() (StoreStats, error) {
+	stats := StoreStats{
+		Path:      store.dir,
+		Encoding:  store.encoding.String(),
+		ByKind:    make(map[string]int),
+		ByBackend: make(map[string]int),
+	}
+	err := store.withLock(false, func() error {
+		stats.Entries = len(store.index)
+		stats.LiveBytes = store.liveBytes
+		stats.DataBytes = store.indexedTo
+		stats.IndexBytes = store.indexSize
+		stats.Generation = store.generation
+		for key, entry := range store.index {
+			kind := key
+			if i := strings.Index(key, ":"); i >= 0 {
+				kind = key[:i]
+			}
+			stats.ByKind[kind]++
+			stats.ByBackend[entry.backend]++
+			updated := time.Unix(0, entry.updated)
+			if stats.OldestUpdate.IsZero() || updated.Before(stats.OldestUpdate) {
+				stats.OldestUpdate = updated
+			}
+			if updated.After(stats.NewestUpdate) {
+				stats.NewestUpdate = updated
+			}
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// Compact rewrites the store with only its live vectors, in the configured encoding
+func (store *VectorStore) Compact() error {
+	return store.withLock(true, store.compact)
+}
+
+func (store *VectorStore) compactIfNeeded() error {
+	if store.indexedTo < compactMinBytes || float64(store.liveBytes) > float64(store.indexedTo)*(1-compactDeadRatio) {
+		return nil
+	}
+	utils.Debug(fmt.Sprintf("[EMBEDDINGS.STORE]: Compacting the embedding store: %d live of %d bytes", store.liveBytes, store.indexedTo))
+	return store.compact()
+}
+
+// compact writes the live records to new files and renames them over the old ones. The data
+// file is renamed first; a reader seeing the new data file with the old index rebuilds it.
+func (store *VectorStore) compact() error {
+	source, err := os.Open(store.path(storeDataFile))
+	if err != nil {
+		return utils.NewSystemError("Failed to read the embedding store", err, map[string]interface{}{"path": store.dir})
+	}
+	defer source.Close()
+
+	keys := make([]string, 0, len(store.index))
+	for key := range store.index {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return store.index[keys[i]].offset < store.index[keys[j]].offset })
+
+	generation := uint64(time.Now().UnixNano())
+	if generation <= store.generation {
+		generation = store.generation + 1
+	}
+	var data, index bytes.Buffer
+	data.Write(storeHeader(dataMagic, generation))
+	index.Write(storeHeader(indexMagic, generation))
+	newIndex := make(map[string]indexEntry, len(keys))
+	for _, key := range keys {
+		vector, err := store.readVectorAt(source, store.index[key])
+		if err != nil {
+			utils.Warning(fmt.Sprintf("[EMBEDDINGS.STORE]: Dropping unreadable vector %s while compacting: %v", key, err))
+			continue
+		}
+		record := encodeRecord(vector, store.encoding)
+		entry := indexEntry{offset: int64(data.Len()), length: uint32(len(record)), backend: vector.Backend, updated: vector.Updated.UnixNano()}
+		data.Write(record)
+		index.Write(encodeIndexEntry(key, entry, false))
+		newIndex[key] = entry
+	}
+
+	for name, content := range map[string][]byte{storeDataFile + ".tmp": data.Bytes(), storeIndexFile + ".tmp": index.Bytes()} {
+		if err := os.WriteFile(store.path(name), content, 0600); err != nil {
+			return utils.NewSystemError("Failed to compact the embedding store", err, map[string]interface{}{"path": store.path(name)})
+		}
+	}
+	source.Close()
+	for _, name := range []string{storeDataFile, storeIndexFile} {
+		if err := os.Rename(store.path(name+".tmp"), store.path(name)); err != nil {
+			return utils.NewSystemError("Failed to compact the embedding store", err, map[string]interface{}{"path": store.path(name)})
+		}
+	}
+
+	store.generation = generation
+	store.index = newIndex
+	store.indexedTo = int64(data.Len())
+	store.indexSize = int64(index.Len())
+	store.liveBytes = int64(data.Len()) - storeHeaderLength
+	return nil
+}
@@ -0,0 +1,327 @@
+package forge
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/interfaces"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Markers delimit the generated part of a pull request body so updates keep manual edits
+const (
+	bodyStartMarker = "<!-- gitcury:start -->"
+	bodyEndMarker   = "<!-- gitcury:end -->"
+)
+
+// RemoteInfo identifies a repository on a forge, parsed from a git remote URL
+type RemoteInfo struct {
+	Host  string // Host name without user or port, e.g. github.com
+	Owner string // Owner or namespace; GitLab groups may contain slashes
+	Repo  string // Repository name without .git
+}
+
+// Project returns the full owner/repo path
+func (r RemoteInfo) Project() string {
+	return r.Owner + "/" + r.Repo
+}
+
+// ParseRemoteURL parses https, ssh:// and scp-like (git@host:owner/repo.git) remote URLs
+func ParseRemoteURL(remoteURL string) (RemoteInfo, error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+	var host, repoPath string
+
+	if strings.Contains(remoteURL, "://") {
+		parsed, err := url.Parse(remoteURL)
+		if err != nil {
+			return RemoteInfo{}, err
+		}
+		host = parsed.Hostname()
+		repoPath = parsed.Path
+	} else if at := strings.Index(remoteURL, ":"); at > 0 {
+		// scp-like syntax: [user@]host:owner/repo.git
+		host = remoteURL[:at]
+		if idx := strings.LastIndex(host, "@"); idx >= 0 {
+			host = host[idx+1:]
+		}
+		repoPath = remoteURL[at+1:]
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	slash := strings.LastIndex(repoPath, "/")
+	if host == "" || slash <= 0 || slash == len(repoPath)-1 {
+		return RemoteInfo{}, fmt.Errorf("unsupported remote URL: %q", remoteURL)
+	}
+
+	return RemoteInfo{
+		Host:  host,
+		Owner: repoPath[:slash],
+		Repo:  repoPath[slash+1:],
+	}, nil
+}
+
+// DetectProvider guesses the forge provider from the remote host
+func DetectProvider(host string) string {
+	host = strings.ToLower(host)
+	switch {
+	case strings.Contains(host, "github"):
+		return config.ForgeGitHub
+	case strings.Contains(host, "gitlab"):
+		return config.ForgeGitLab
+	case strings.Contains(host, "gitea"), strings.Contains(host, "codeberg"), strings.Contains(host, "forgejo"):
+		return config.ForgeGitea
+	}
+	return ""
+}
+
+// DefaultBaseURL returns the conventional API root of provider on host
+func DefaultBaseURL(provider, host string) string {
+	switch provider {
+	case config.ForgeGitHub:
+		if host == "github.com" {
+			return "https://api.github.com"
+		}
+		return "https://" + host + "/api/v3"
+	case config.ForgeGitLab:
+		return "https://" + host + "/api/v4"
+	case config.ForgeGitea:
+		return "https://" + host + "/api/v1"
+	}
+	return ""
+}
+
+// NewForge creates a forge client for provider. baseURL must be the API root.
+func NewForge(provider, baseURL string, remote RemoteInfo, token string) (interfaces.Forge, error) {
+	switch provider {
+	case config.ForgeGitHub:
+		return NewGitHub(baseURL, remote.Owner, remote.Repo, token, nil), nil
+	case config.ForgeGitLab:
+		return NewGitLab(baseURL, remote.Project(), token, nil), nil
+	case config.ForgeGitea:
+		return NewGitea(baseURL, remote.Owner, remote.Repo, token, nil), nil
+	}
+	return nil, utils.NewConfigError(
+		"Unknown forge provider",
+		nil,
+		map[string]interface{}{
+			"provider":   provider,
+			"host":       remote.Host,
+			"suggestion": "Set forge.provider to github, gitlab or gitea",
+		},
+	)
+}
+
+// New creates the forge for the repository in rootFolder from the URL of remote and the forge configuration
+func New(rootFolder, remote string) (interfaces.Forge, error) {
+	forgeConfig := config.GetForgeConfig(rootFolder)
+
+	remoteURL, err := git.RunGitCmd(rootFolder, nil, "remote", "get-url", remote)
+	if err != nil {
+		return nil, utils.NewGitError(
+			"Failed to read remote URL",
+			err,
+			map[string]interface{}{
+				"directory": rootFolder,
+				"remote":    remote,
+			},
+		)
+	}
+
+	info, err := ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, utils.NewConfigError(
+			"Cannot determine the forge repository from the remote URL",
+			err,
+			map[string]interface{}{
+				"directory": rootFolder,
+				"remote":    remote,
+			},
+		)
+	}
+
+	provider := strings.ToLower(forgeConfig.Provider)
+	if provider == "" {
+		provider = DetectProvider(info.Host)
+	}
+	baseURL := forgeConfig.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL(provider, info.Host)
+	}
+
+	token := forgeConfig.ResolveToken(provider)
+	if token == "" {
+		utils.Warning("⚠️ No forge token found; set forge.tokenEnv or the provider's token environment variable")
+	}
+
+	utils.Debug(fmt.Sprintf("[FORGE]: Using %s at %s for %s", provider, baseURL, info.Project()))
+	return NewForge(provider, baseURL, info, token)
+}
+
+// Commit is a pushed commit used to describe a pull request
+type Commit struct {
+	Hash    string
+	Subject string
+	Body    string
+}
+
+// CommitsBetween returns the non-merge commits reachable from head but not base, oldest first
+func CommitsBetween(dir, base, head string) ([]Commit, error) {
+	output, err := git.RunGitCmd(dir, nil, "log", "--reverse", "--no-merges", "--format=%h%x1f%s%x1f%b%x1e", base+".."+head)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x1f", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		commit := Commit{Hash: fields[0], Subject: fields[1]}
+		if len(fields) == 3 {
+			commit.Body = strings.TrimSpace(fields[2])
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// PullRequestTitle derives a title from the commits: the subject of a single commit,
+// otherwise the branch name made readable
+func PullRequestTitle(branch string, commits []Commit) string {
+	if len(commits) == 1 {
+		return commits[0].Subject
+	}
+	name := branch
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	name = strings.NewReplacer("-", " ", "_", " ").Replace(name)
+	if name == "" {
+		return branch
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// PullRequestBody renders the generated section of a pull request body from the commits
+func PullRequestBody(commits []Commit) string {
+	var builder strings.Builder
+	builder.WriteString(bodyStartMarker + "\n")
+	if len(commits) == 1 && commits[0].Body != "" {
+		builder.WriteString(commits[0].Body + "\n\n")
+	}
+	builder.WriteString("### Commits\n\n")
+	for _, commit := range commits {
+		builder.WriteString("- " + commit.Subject + " (" + commit.Hash + ")\n")
+	}
+	builder.WriteString(bodyEndMarker)
+	return builder.String()
+}
+
+// MergeBody replaces the generated section of an existing body, keeping anything written around it
+func MergeBody(existing, generated string) string {
+	start := strings.Index(existing, bodyStartMarker)
+	end := strings.Index(existing, bodyEndMarker)
+	if start < 0 || end < start {
+		if strings.TrimSpace(existing) == "" {
+			return generated
+		}
+		return strings.TrimRight(existing, "\n") + "\n\n" + generated
+	}
+	return existing[:start] + generated + existing[end+len(bodyEndMarker):]
+}
+
+// SyncPullRequest creates or updates the pull request for the branch pushed from dir.
+// The title is only set on creation; updates refresh the generated part of the body.
+func SyncPullRequest(dir, branch string, opts ...interfaces.PushOptions) (*interfaces.PullRequest, error) {
+	plan, err := git.ResolvePushPlan(dir, branch, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := New(dir, plan.Remote)
+	if err != nil {
+		return nil, err
+	}
+	return SyncPullRequestWith(client, dir, plan, opts...)
+}
+
+// SyncPullRequestWith creates or updates the pull request for plan using client
+func SyncPullRequestWith(client interfaces.Forge, dir string, plan git.PushPlan, opts ...interfaces.PushOptions) (*interfaces.PullRequest, error) {
+	forgeConfig := config.GetForgeConfig(dir)
+
+	base := forgeConfig.BaseBranch
+	if len(opts) > 0 && opts[0].Base != "" {
+		base = opts[0].Base
+	}
+	if base == "" {
+		defaultBranch, err := client.DefaultBranch()
+		if err != nil {
+			return nil, err
+		}
+		base = defaultBranch
+	}
+	if base == plan.RemoteBranch {
+		return nil, utils.NewValidationError(
+			"The pushed branch is the pull request base",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"branch":     plan.RemoteBranch,
+				"suggestion": "Push a feature branch, or pass --base",
+			},
+		)
+	}
+
+	baseRef := plan.Remote + "/" + base
+	if _, stderr, err := git.RunGitCmdWithOutput(dir, nil, "fetch", "--quiet", plan.Remote, base); err != nil {
+		utils.Debug("[FORGE]: Fetch of " + baseRef + " failed: " + strings.TrimSpace(stderr))
+	}
+	commits, err := CommitsBetween(dir, baseRef, plan.LocalBranch)
+	if err != nil {
+		return nil, utils.NewGitError(
+			"Failed to list commits for the pull request",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+				"base":      baseRef,
+				"head":      plan.LocalBranch,
+			},
+		)
+	}
+	if len(commits) == 0 {
+		utils.Info("[FORGE]: No commits between " + baseRef + " and " + plan.LocalBranch + "; skipping pull request")
+		return nil, nil
+	}
+
+	generated := PullRequestBody(commits)
+	existing, err := client.FindPullRequest(plan.RemoteBranch, base)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		existing.Body = MergeBody(existing.Body, generated)
+		updated, err := client.UpdatePullRequest(*existing)
+		if err != nil {
+			return nil, err
+		}
+		utils.Success(fmt.Sprintf("🔁 Updated %s pull request #%d: %s", client.Name(), updated.Number, updated.URL))
+		return updated, nil
+	}
+
+	created, err := client.CreatePullRequest(interfaces.PullRequest{
+		Title: PullRequestTitle(plan.RemoteBranch, commits),
+		Body:  generated,
+		Head:  plan.RemoteBranch,
+		Base:  base,
+		Draft: forgeConfig.Draft,
+	})
+	if err != nil {
+		return nil, err
+	}
+	utils.Success(fmt.Sprintf("🆕 Opened %s pull request #%d: %s", client.Name(), created.Number, created.URL))
+	return created, nil
+}
@@ -0,0 +1,131 @@
+package forge
+
+import (
+	"github.com/lakshyajain-0291/gitcury/interfaces"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Gitea talks to the Gitea (and Forgejo) REST API
+type Gitea struct {
+	client *client
+	owner  string
+	repo   string
+}
+
+type giteaPull struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Draft   bool   `json:"draft"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// NewGitea creates a Gitea forge for owner/repo. baseURL is the API root, e.g. https://gitea.com/api/v1
+func NewGitea(baseURL, owner, repo, token string, httpClient *http.Client) *Gitea {
+	authValue := ""
+	if token != "" {
+		authValue = "token " + token
+	}
+	return &Gitea{
+		client: newClient(baseURL, "Authorization", authValue, httpClient),
+		owner:  owner,
+		repo:   repo,
+	}
+}
+
+// Name returns the provider name
+func (g *Gitea) Name() string {
+	return "Gitea"
+}
+
+func (g *Gitea) repoPath() string {
+	return "/repos/" + url.PathEscape(g.owner) + "/" + url.PathEscape(g.repo)
+}
+
+// DefaultBranch returns the default branch of the repository
+func (g *Gitea) DefaultBranch() (string, error) {
+	var repository struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := g.client.do(http.MethodGet, g.repoPath(), nil, &repository); err != nil {
+		return "", err
+	}
+	return repository.DefaultBranch, nil
+}
+
+// FindPullRequest returns the open pull request from head into base, if any.
+// Gitea has no head/base filter on the list endpoint, so open pulls are filtered here.
+func (g *Gitea) FindPullRequest(head, base string) (*interfaces.PullRequest, error) {
+	for page := 1; ; page++ {
+		var pulls []giteaPull
+		path := fmt.Sprintf("%s/pulls?state=open&limit=50&page=%d", g.repoPath(), page)
+		if err := g.client.do(http.MethodGet, path, nil, &pulls); err != nil {
+			return nil, err
+		}
+		for _, pull := range pulls {
+			if pull.Head.Ref == head && pull.Base.Ref == base {
+				return pull.toPullRequest(), nil
+			}
+		}
+		if len(pulls) < 50 {
+			return nil, nil
+		}
+	}
+}
+
+// CreatePullRequest opens a new pull request
+func (g *Gitea) CreatePullRequest(pr interfaces.PullRequest) (*interfaces.PullRequest, error) {
+	title := pr.Title
+	if pr.Draft {
+		// Gitea marks work in progress through the title prefix
+		title = "WIP: " + title
+	}
+
+	request := map[string]interface{}{
+		"title": title,
+		"body":  pr.Body,
+		"head":  pr.Head,
+		"base":  pr.Base,
+	}
+
+	var created giteaPull
+	if err := g.client.do(http.MethodPost, g.repoPath()+"/pulls", request, &created); err != nil {
+		return nil, err
+	}
+	return created.toPullRequest(), nil
+}
+
+// UpdatePullRequest updates the title and body of an existing pull request
+func (g *Gitea) UpdatePullRequest(pr interfaces.PullRequest) (*interfaces.PullRequest, error) {
+	request := map[string]interface{}{
+		"title": pr.Title,
+		"body":  pr.Body,
+	}
+
+	var updated giteaPull
+	path := fmt.Sprintf("%s/pulls/%d", g.repoPath(), pr.Number)
+	if err := g.client.do(http.MethodPatch, path, request, &updated); err != nil {
+		return nil, err
+	}
+	return updated.toPullRequest(), nil
+}
+
+func (p giteaPull) toPullRequest() *interfaces.PullRequest {
+	return &interfaces.PullRequest{
+		Number: p.Number,
+		URL:    p.HTMLURL,
+		Title:  p.Title,
+		Body:   p.Body,
+		Head:   p.Head.Ref,
+		Base:   p.Base.Ref,
+		Draft:  p.Draft,
+	}
+}
@@ -0,0 +1,123 @@
+package forge
+
+import (
+	"github.com/lakshyajain-0291/gitcury/interfaces"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GitHub talks to the GitHub REST API (github.com or GitHub Enterprise)
+type GitHub struct {
+	client *client
+	owner  string
+	repo   string
+}
+
+type githubPull struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Draft   bool   `json:"draft"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// NewGitHub creates a GitHub forge for owner/repo. baseURL is the API root, e.g. https://api.github.com
+func NewGitHub(baseURL, owner, repo, token string, httpClient *http.Client) *GitHub {
+	authValue := ""
+	if token != "" {
+		authValue = "Bearer " + token
+	}
+	return &GitHub{
+		client: newClient(baseURL, "Authorization", authValue, httpClient),
+		owner:  owner,
+		repo:   repo,
+	}
+}
+
+// Name returns the provider name
+func (g *GitHub) Name() string {
+	return "GitHub"
+}
+
+func (g *GitHub) repoPath() string {
+	return "/repos/" + url.PathEscape(g.owner) + "/" + url.PathEscape(g.repo)
+}
+
+// DefaultBranch returns the default branch of the repository
+func (g *GitHub) DefaultBranch() (string, error) {
+	var repository struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := g.client.do(http.MethodGet, g.repoPath(), nil, &repository); err != nil {
+		return "", err
+	}
+	return repository.DefaultBranch, nil
+}
+
+// FindPullRequest returns the open pull request from head into base, if any
+func (g *GitHub) FindPullRequest(head, base string) (*interfaces.PullRequest, error) {
+	query := url.Values{}
+	query.Set("state", "open")
+	query.Set("head", g.owner+":"+head)
+	query.Set("base", base)
+
+	var pulls []githubPull
+	if err := g.client.do(http.MethodGet, g.repoPath()+"/pulls?"+query.Encode(), nil, &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+	return pulls[0].toPullRequest(), nil
+}
+
+// CreatePullRequest opens a new pull request
+func (g *GitHub) CreatePullRequest(pr interfaces.PullRequest) (*interfaces.PullRequest, error) {
+	request := map[string]interface{}{
+		"title": pr.Title,
+		"body":  pr.Body,
+		"head":  pr.Head,
+		"base":  pr.Base,
+		"draft": pr.Draft,
+	}
+
+	var created githubPull
+	if err := g.client.do(http.MethodPost, g.repoPath()+"/pulls", request, &created); err != nil {
+		return nil, err
+	}
+	return created.toPullRequest(), nil
+}
+
+// UpdatePullRequest updates the title and body of an existing pull request
+func (g *GitHub) UpdatePullRequest(pr interfaces.PullRequest) (*interfaces.PullRequest, error) {
+	request := map[string]interface{}{
+		"title": pr.Title,
+		"body":  pr.Body,
+	}
+
+	var updated githubPull
+	path := fmt.Sprintf("%s/pulls/%d", g.repoPath(), pr.Number)
+	if err := g.client.do(http.MethodPatch, path, request, &updated); err != nil {
+		return nil, err
+	}
+	return updated.toPullRequest(), nil
+}
+
+func (p githubPull) toPullRequest() *interfaces.PullRequest {
+	return &interfaces.PullRequest{
+		Number: p.Number,
+		URL:    p.HTMLURL,
+		Title:  p.Title,
+		Body:   p.Body,
+		Head:   p.Head.Ref,
+		Base:   p.Base.Ref,
+		Draft:  p.Draft,
+	}
+}
@@ -0,0 +1,121 @@
+package forge
+
+import (
+	"github.com/lakshyajain-0291/gitcury/interfaces"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GitLab talks to the GitLab REST API (v4). Merge requests are exposed as pull requests.
+type GitLab struct {
+	client  *client
+	project string // Full project path, e.g. group/subgroup/repo
+}
+
+type gitlabMergeRequest struct {
+	IID          int    `json:"iid"`
+	WebURL       string `json:"web_url"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Draft        bool   `json:"draft"`
+}
+
+// draftPrefix marks a merge request as draft on GitLab
+const draftPrefix = "Draft: "
+
+// NewGitLab creates a GitLab forge for the project path. baseURL is the API root, e.g. https://gitlab.com/api/v4
+func NewGitLab(baseURL, project, token string, httpClient *http.Client) *GitLab {
+	return &GitLab{
+		client:  newClient(baseURL, "PRIVATE-TOKEN", token, httpClient),
+		project: project,
+	}
+}
+
+// Name returns the provider name
+func (g *GitLab) Name() string {
+	return "GitLab"
+}
+
+func (g *GitLab) projectPath() string {
+	return "/projects/" + url.PathEscape(g.project)
+}
+
+// DefaultBranch returns the default branch of the project
+func (g *GitLab) DefaultBranch() (string, error) {
+	var project struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := g.client.do(http.MethodGet, g.projectPath(), nil, &project); err != nil {
+		return "", err
+	}
+	return project.DefaultBranch, nil
+}
+
+// FindPullRequest returns the open merge request from head into base, if any
+func (g *GitLab) FindPullRequest(head, base string) (*interfaces.PullRequest, error) {
+	query := url.Values{}
+	query.Set("state", "opened")
+	query.Set("source_branch", head)
+	query.Set("target_branch", base)
+
+	var requests []gitlabMergeRequest
+	if err := g.client.do(http.MethodGet, g.projectPath()+"/merge_requests?"+query.Encode(), nil, &requests); err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	return requests[0].toPullRequest(), nil
+}
+
+// CreatePullRequest opens a new merge request
+func (g *GitLab) CreatePullRequest(pr interfaces.PullRequest) (*interfaces.PullRequest, error) {
+	title := pr.Title
+	if pr.Draft && !strings.HasPrefix(title, draftPrefix) {
+		title = draftPrefix + title
+	}
+
+	request := map[string]interface{}{
+		"title":         title,
+		"description":   pr.Body,
+		"source_branch": pr.Head,
+		"target_branch": pr.Base,
+	}
+
+	var created gitlabMergeRequest
+	if err := g.client.do(http.MethodPost, g.projectPath()+"/merge_requests", request, &created); err != nil {
+		return nil, err
+	}
+	return created.toPullRequest(), nil
+}
+
+// UpdatePullRequest updates the title and description of an existing merge request
+func (g *GitLab) UpdatePullRequest(pr interfaces.PullRequest) (*interfaces.PullRequest, error) {
+	request := map[string]interface{}{
+		"title":       pr.Title,
+		"description": pr.Body,
+	}
+
+	var updated gitlabMergeRequest
+	path := fmt.Sprintf("%s/merge_requests/%d", g.projectPath(), pr.Number)
+	if err := g.client.do(http.MethodPut, path, request, &updated); err != nil {
+		return nil, err
+	}
+	return updated.toPullRequest(), nil
+}
+
+func (m gitlabMergeRequest) toPullRequest() *interfaces.PullRequest {
+	return &interfaces.PullRequest{
+		Number: m.IID,
+		URL:    m.WebURL,
+		Title:  m.Title,
+		Body:   m.Description,
+		Head:   m.SourceBranch,
+		Base:   m.TargetBranch,
+		Draft:  m.Draft,
+	}
+}
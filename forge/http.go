@@ -0,0 +1,104 @@
+package forge
+
+import (
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBody bounds how much of an error response is kept for error messages
+const maxErrorBody = 512
+
+// client performs authenticated JSON requests against a forge API. The token is only ever
+// written to request headers; it never appears in logs or returned errors.
+type client struct {
+	baseURL    string
+	authHeader string
+	authValue  string
+	httpClient *http.Client
+}
+
+func newClient(baseURL, authHeader, authValue string, httpClient *http.Client) *client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		authHeader: authHeader,
+		authValue:  authValue,
+		httpClient: httpClient,
+	}
+}
+
+// do sends a request with an optional JSON body and decodes a JSON response into out
+func (c *client) do(method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	url := c.baseURL + path
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.authValue != "" {
+		req.Header.Set(c.authHeader, c.authValue)
+	}
+
+	utils.Debug("[FORGE]: " + method + " " + url)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return utils.NewAPIError(
+			"Forge request failed",
+			err,
+			map[string]interface{}{
+				"method": method,
+				"url":    url,
+			},
+		)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		context := map[string]interface{}{
+			"method":   method,
+			"url":      url,
+			"status":   resp.StatusCode,
+			"response": strings.TrimSpace(string(responseBody)),
+		}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			context["suggestion"] = "Check that the forge token is set and has permission to manage pull requests"
+		}
+		return utils.NewAPIError(fmt.Sprintf("Forge API returned %d", resp.StatusCode), nil, context)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return utils.NewAPIError(
+			"Failed to decode forge response",
+			err,
+			map[string]interface{}{
+				"method": method,
+				"url":    url,
+			},
+		)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"os"
+	"strings"
+	"time"
+)
+
+// BackupRefPrefix is the namespace of the refs GitCury creates before rewriting history
+const BackupRefPrefix = "refs/gitcury/backups/"
+
+// CreateBackupRef records HEAD of dir under refs/gitcury/backups/<operation>/<timestamp> so
+// that a rewrite can be undone with git reset --hard <ref>. It returns the ref name.
+func CreateBackupRef(dir, operation string) (string, error) {
+	head, err := RunGitCmd(dir, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return "", utils.NewGitError(
+			"Failed to resolve HEAD for the backup ref",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+			},
+		)
+	}
+
+	ref := BackupRefPrefix + operation + "/" + time.Now().Format("20060102-150405.000000000")
+	if _, err := RunGitCmd(dir, nil, "update-ref", "-m", "gitcury "+operation+" backup", ref, strings.TrimSpace(head)); err != nil {
+		return "", utils.NewGitError(
+			"Failed to create backup ref",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+				"ref":       ref,
+			},
+		)
+	}
+
+	utils.Debug("[GIT.BACKUP]: Saved " + strings.TrimSpace(head) + " as " + ref)
+	return ref, nil
+}
+
+// sameTree reports whether two commits have identical trees, i.e. a rewrite kept the content
+func sameTree(dir, a, b string) bool {
+	treeA, errA := RunGitCmd(dir, nil, "rev-parse", a+"^{tree}")
+	treeB, errB := RunGitCmd(dir, nil, "rev-parse", b+"^{tree}")
+	return errA == nil && errB == nil && strings.TrimSpace(treeA) == strings.TrimSpace(treeB)
+}
+
+// operationInProgress returns the name of a rebase, merge, cherry-pick or revert in progress in dir
+func operationInProgress(dir string) string {
+	for name, path := range map[string]string{
+		"rebase":      "rebase-merge",
+		"rebase (am)": "rebase-apply",
+		"merge":       "MERGE_HEAD",
+		"cherry-pick": "CHERRY_PICK_HEAD",
+		"revert":      "REVERT_HEAD",
+	} {
+		resolved, err := gitPath(dir, path)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(resolved); err == nil {
+			return name
+		}
+	}
+	return ""
+}
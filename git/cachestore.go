@@ -0,0 +1,138 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/embeddings"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Key prefixes of the embedding store. File embeddings belong to a root folder; hunk
+// embeddings only depend on the hunk and backend, so all root folders share them.
+const (
+	fileStorePrefix = "file:"
+	hunkStorePrefix = "hunk:"
+)
+
+var (
+	storeMu        sync.Mutex
+	embeddingStore = make(map[string]*embeddings.VectorStore) // Store directory -> open store
+)
+
+// embeddingStoreDir is where the binary embedding store lives
+func embeddingStoreDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".gitcury", "embeddings")
+}
+
+// OpenEmbeddingStore returns the embedding store shared by all root folders, writing vectors
+// in the configured encoding. JSON caches of earlier versions are imported on first use.
+func OpenEmbeddingStore() (*embeddings.VectorStore, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	dir := embeddingStoreDir()
+	encoding := embeddings.ParseVectorEncoding(config.GetClusteringConfig().Methods.Cached.VectorEncoding)
+	key := dir + "|" + encoding.String()
+	if store, ok := embeddingStore[key]; ok {
+		return store, nil
+	}
+
+	store, err := embeddings.OpenVectorStore(dir, encoding)
+	if err != nil {
+		return nil, err
+	}
+	embeddingStore[key] = store
+
+	if migrated, vectors, err := MigrateJSONCaches(store); err != nil {
+		utils.Warning("[GIT.CACHE]: Failed to import the JSON embedding caches: " + err.Error())
+	} else if migrated > 0 {
+		utils.Debug(fmt.Sprintf("[GIT.CACHE]: Imported %d vectors from %d JSON embedding caches", vectors, migrated))
+	}
+	return store, nil
+}
+
+// rootStoreKey is the key prefix of the file embeddings of rootFolder
+func rootStoreKey(rootFolder string) string {
+	hash := sha256.Sum256([]byte(rootFolder))
+	return fileStorePrefix + hex.EncodeToString(hash[:])[:12] + ":"
+}
+
+// MigrateJSONCaches imports the embedding_cache_*.json and hunk_cache_*.json files of earlier
+// versions into store and renames them to *.migrated. It returns the number of files and
+// vectors imported.
+func MigrateJSONCaches(store *embeddings.VectorStore) (int, int, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return 0, 0, utils.NewSystemError("Failed to find the home directory", err, nil)
+	}
+	cacheDir := filepath.Join(homeDir, ".gitcury")
+	fileCaches, _ := filepath.Glob(filepath.Join(cacheDir, "embedding_cache_*.json"))
+	hunkCaches, _ := filepath.Glob(filepath.Join(cacheDir, "hunk_cache_*.json"))
+
+	migrated, imported := 0, 0
+	for _, path := range append(fileCaches, hunkCaches...) {
+		vectors, err := readJSONCache(path)
+		if err != nil {
+			utils.Warning(fmt.Sprintf("[GIT.CACHE]: Skipping unreadable cache %s: %v", path, err))
+			continue
+		}
+		if err := store.Put(vectors...); err != nil {
+			return migrated, imported, err
+		}
+		if err := os.Rename(path, path+".migrated"); err != nil {
+			return migrated, imported, utils.NewSystemError("Failed to retire a migrated cache file", err, map[string]interface{}{
+				"path": path,
+			})
+		}
+		migrated++
+		imported += len(vectors)
+	}
+	return migrated, imported, nil
+}
+
+// readJSONCache converts a JSON file or hunk cache into store entries
+func readJSONCache(path string) ([]embeddings.StoredVector, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Cache file path controlled by application
+	if err != nil {
+		return nil, err
+	}
+
+	var vectors []embeddings.StoredVector
+	if strings.HasPrefix(filepath.Base(path), "hunk_cache_") {
+		var cache HunkEmbeddingCache
+		if err := json.Unmarshal(data, &cache); err != nil {
+			return nil, err
+		}
+		for key, entry := range cache.Entries {
+			if len(entry.Embedding) > 0 {
+				vectors = append(vectors, embeddings.StoredVector{
+					Key: hunkStorePrefix + key, Vector: entry.Embedding, Backend: entry.Backend, Updated: entry.LastUsed,
+				})
+			}
+		}
+		return vectors, nil
+	}
+
+	var cache EmbeddingCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, err
+	}
+	prefix := rootStoreKey(cache.RootFolder)
+	for filePath, entry := range cache.Embeddings {
+		if len(entry.Embedding) > 0 {
+			vectors = append(vectors, embeddings.StoredVector{
+				Key: prefix + filePath, Vector: entry.Embedding, Backend: entryBackend(&entry),
+				Meta: entry.ContentHash, Updated: entry.LastUpdated,
+			})
+		}
+	}
+	return vectors, nil
+}
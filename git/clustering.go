@@ -17,12 +17,17 @@ import (
 	"time"
 )
 
-// FileCluster represents a group of related files
+// FileCluster represents a group of related files and why they were grouped
 type FileCluster struct {
-	Files       []string  `json:"files"`
-	Similarity  float64   `json:"similarity"`
-	ClusterType string    `json:"clusterType"`
-	Created     time.Time `json:"created"`
+	Files          []string           `json:"files"`
+	Name           string             `json:"name,omitempty"` // Label given during review
+	Similarity     float64            `json:"similarity"`     // Mean similarity of the files to the cluster centroid
+	ClusterType    string             `json:"clusterType"`
+	Created        time.Time          `json:"created"`
+	Method         string             `json:"method"`                   // Clustering method that produced the cluster
+	Confidence     float64            `json:"confidence"`               // Confidence of the method in the clustering as a whole
+	Rule           string             `json:"rule"`                     // Rule that matched, e.g. "same directory"
+	FileSimilarity map[string]float64 `json:"fileSimilarity,omitempty"` // Similarity of each file to the centroid
 }
 
 // EmbeddingCache stores file embeddings to avoid regenerating them
@@ -30,6 +35,8 @@ type EmbeddingCache struct {
 	RootFolder  string                    `json:"rootFolder"`
 	Embeddings  map[string]FileCacheEntry `json:"embeddings"`
 	LastUpdated time.Time                 `json:"lastUpdated"`
+
+	stored map[string]time.Time // File -> LastUpdated of the entry in the embedding store
 }
 
 // FileCacheEntry stores file embedding with metadata
@@ -38,6 +45,7 @@ type FileCacheEntry struct {
 	Embedding   []float32 `json:"embedding"`
 	ContentHash string    `json:"contentHash"`
 	LastUpdated time.Time `json:"lastUpdated"`
+	Backend     string    `json:"backend,omitempty"` // Embedding backend that produced the vector; empty is gemini
 }
 
 // Enhanced caching structures for better performance
@@ -113,13 +121,13 @@ func (sm SimilarityMethod) String() string {
 
 // SmartClusterFiles groups files using multi-layered approach with configurable methods
 // When targetClusters is 0 or negative, it uses threshold-based clustering without limits
-func SmartClusterFiles(changedFiles []string, rootFolder string, targetClusters int) ([][]string, error) {
+func SmartClusterFiles(changedFiles []string, rootFolder string, targetClusters int) ([]FileCluster, error) {
 	if len(changedFiles) == 0 {
-		return [][]string{}, nil
+		return []FileCluster{}, nil
 	}
 
 	if len(changedFiles) == 1 {
-		return [][]string{changedFiles}, nil
+		return newFileClusters("single", [][]string{changedFiles}, 1.0, rootFolder, nil), nil
 	}
 
 	// Get clustering configuration
@@ -144,7 +152,31 @@ func SmartClusterFiles(changedFiles []string, rootFolder string, targetClusters
 
 	// Use multi-layered approach (auto method or with fallbacks enabled)
 
-	// Layer 1: Directory-based clustering
+	// Layer 1: Dependency-based clustering, which can connect files across directories
+	if config.IsMethodEnabled(config.DependencyMethod) {
+		depClusters, depConfidence := dependencyBasedClustering(changedFiles, rootFolder, targetClusters)
+		depThreshold := config.GetConfidenceThreshold(config.DependencyMethod)
+		depSimilarity := config.GetSimilarityThreshold(config.DependencyMethod)
+
+		if depConfidence >= depThreshold && (!useThresholdClustering || validateClusterSimilarity(depClusters, depSimilarity)) {
+			utils.Debug("[GIT.CLUSTER]: Dependency-based clustering successful with configured thresholds")
+			return depClusters, nil
+		}
+	}
+
+	// Layer 2: Co-change history, for files that changed together before
+	if config.IsMethodEnabled(config.HistoryMethod) {
+		historyClusters, historyConfidence := historyBasedClustering(changedFiles, rootFolder, targetClusters)
+		historyThreshold := config.GetConfidenceThreshold(config.HistoryMethod)
+		historySimilarity := config.GetSimilarityThreshold(config.HistoryMethod)
+
+		if historyConfidence >= historyThreshold && (!useThresholdClustering || validateClusterSimilarity(historyClusters, historySimilarity)) {
+			utils.Debug("[GIT.CLUSTER]: History-based clustering successful with configured thresholds")
+			return historyClusters, nil
+		}
+	}
+
+	// Layer 3: Directory-based clustering
 	if config.IsMethodEnabled(config.DirectoryMethod) {
 		dirClusters, dirConfidence := directoryBasedClustering(changedFiles, rootFolder, targetClusters)
 		dirThreshold := config.GetConfidenceThreshold(config.DirectoryMethod)
@@ -152,11 +184,11 @@ func SmartClusterFiles(changedFiles []string, rootFolder string, targetClusters
 
 		if dirConfidence >= dirThreshold && (!useThresholdClustering || validateClustersByThreshold(dirClusters, rootFolder, dirSimilarity)) {
 			utils.Debug("[GIT.CLUSTER]: Directory-based clustering successful with configured thresholds")
-			return dirClusters, nil
+			return newFileClusters(string(config.DirectoryMethod), dirClusters, dirConfidence, rootFolder, nil), nil
 		}
 	}
 
-	// Layer 2: Pattern-based clustering
+	// Layer 4: Pattern-based clustering
 	if config.IsMethodEnabled(config.PatternMethod) {
 		patternClusters, patternConfidence := patternBasedClustering(changedFiles, targetClusters)
 		patternThreshold := config.GetConfidenceThreshold(config.PatternMethod)
@@ -164,36 +196,36 @@ func SmartClusterFiles(changedFiles []string, rootFolder string, targetClusters
 
 		if patternConfidence >= patternThreshold && (!useThresholdClustering || validateClustersByThreshold(patternClusters, rootFolder, patternSimilarity)) {
 			utils.Debug("[GIT.CLUSTER]: Pattern-based clustering successful with configured thresholds")
-			return patternClusters, nil
+			return newFileClusters(string(config.PatternMethod), patternClusters, patternConfidence, rootFolder, nil), nil
 		}
 	}
 
-	// Layer 3: Cached embedding clustering
+	// Layer 5: Cached embedding clustering
 	if config.IsMethodEnabled(config.CachedMethod) {
-		cachedClusters, cachedConfidence, cacheHitRatio := cachedEmbeddingClustering(changedFiles, rootFolder, targetClusters)
+		cachedClusters, cachedConfidence, cacheHitRatio, cachedEmbeddings := cachedEmbeddingClustering(changedFiles, rootFolder, targetClusters)
 		cachedThreshold := config.GetConfidenceThreshold(config.CachedMethod)
 		cachedSimilarity := config.GetSimilarityThreshold(config.CachedMethod)
 		minCacheHitRatio := clusteringConfig.Methods.Cached.MinCacheHitRatio
 
 		if cachedConfidence >= cachedThreshold && cacheHitRatio >= minCacheHitRatio && (!useThresholdClustering || validateClustersByThreshold(cachedClusters, rootFolder, cachedSimilarity)) {
 			utils.Debug("[GIT.CLUSTER]: Cached embedding clustering successful with configured parameters")
-			return cachedClusters, nil
+			return newFileClusters(string(config.CachedMethod), cachedClusters, cachedConfidence, rootFolder, cachedEmbeddings), nil
 		}
 	}
 
-	// // Layer 4: Smart sampling for large file sets
+	// // Layer 6: Smart sampling for large file sets
 	// if config.IsMethodEnabled(config.SemanticMethod) && len(changedFiles) > clusteringConfig.MaxFilesForSemanticClustering {
 	// 	return smartSamplingClustering(changedFiles, rootFolder, targetClusters, useThresholdClustering)
 	// }
 
-	// Layer 5: Full semantic clustering (fallback)
+	// Layer 7: Full semantic clustering (fallback)
 	// if config.IsMethodEnabled(config.SemanticMethod) {
 	// 	return fullSemanticClustering(changedFiles, rootFolder, targetClusters, useThresholdClustering)
 	// }
 
 	// If all methods are disabled, fall back to single file clusters
 	utils.Warning("[GIT.CLUSTER]: All clustering methods disabled, using single file clusters")
-	return createSingleFileClusters(changedFiles), nil
+	return newFileClusters("single", createSingleFileClusters(changedFiles), 0.0, rootFolder, nil), nil
 }
 
 // validateClustersByThreshold checks if clusters meet similarity threshold requirements
@@ -222,8 +254,14 @@ func calculateClusterSimilarity(files []string, rootFolder string) float64 {
 	// Use file extension and directory similarity as proxy
 	extSimilarity := calculateExtensionSimilarity(files)
 	dirSimilarity := calculateDirectorySimilarity(files, rootFolder)
+	structural := (extSimilarity + dirSimilarity) / 2.0
 
-	return (extSimilarity + dirSimilarity) / 2.0
+	// Blend in how often the files changed together, when the history knows them
+	if cochange, ok := coChangeClusterSimilarity(files, rootFolder); ok {
+		weight := config.GetClusteringConfig().Methods.History.Weight
+		return structural*(1-weight) + cochange*weight
+	}
+	return structural
 }
 
 // calculateExtensionSimilarity calculates similarity based on file extensions
@@ -439,7 +477,12 @@ func findTestImplementationRelations(files []string) map[string]string {
 }
 
 // cachedEmbeddingClustering uses enhanced cached embeddings when available
-func cachedEmbeddingClustering(files []string, rootFolder string, targetClusters int) ([][]string, float64, float64) {
+// The embeddings used are returned so the clusters can be explained.
+func cachedEmbeddingClustering(files []string, rootFolder string, targetClusters int) ([][]string, float64, float64, map[string][]float32) {
+	if useHunkEmbeddings() {
+		return hunkEmbeddingClustering(files, rootFolder, targetClusters)
+	}
+
 	enhancedCache := NewEnhancedEmbeddingCache(rootFolder)
 	fileEmbeddings := make(map[string][]float32)
 	cacheHits := 0
@@ -461,7 +504,7 @@ func cachedEmbeddingClustering(files []string, rootFolder string, targetClusters
 	// If we don't have enough cached embeddings, return early
 	if cacheHitRatio < 0.3 {
 		enhancedCache.Save()
-		return createSingleFileClusters(files), 0.0, cacheHitRatio
+		return createSingleFileClusters(files), 0.0, cacheHitRatio, fileEmbeddings
 	}
 
 	// Generate embeddings for missing files with intelligent batching and rate limiting
@@ -495,12 +538,12 @@ func cachedEmbeddingClustering(files []string, rootFolder string, targetClusters
 			enhancedCache.PutEmbedding(file, embedding)
 			newEmbeddings++
 
-			// Use configurable adaptive delay
+			// Use configurable adaptive delay; local backends need none
 			delay := time.Duration(recentMisses*100) * time.Millisecond
 			if delay < rateLimitDelay {
 				delay = rateLimitDelay
 			}
-			if delay > 0 {
+			if delay > 0 && embeddings.IsRateLimited() {
 				time.Sleep(delay)
 			}
 		}
@@ -509,7 +552,7 @@ func cachedEmbeddingClustering(files []string, rootFolder string, targetClusters
 	// Perform clustering using available embeddings
 	if len(fileEmbeddings) < 2 {
 		enhancedCache.Save()
-		return createSingleFileClusters(files), 0.0, cacheHitRatio
+		return createSingleFileClusters(files), 0.0, cacheHitRatio, fileEmbeddings
 	}
 
 	clusters := performEmbeddingBasedClustering(fileEmbeddings, targetClusters)
@@ -522,7 +565,7 @@ func cachedEmbeddingClustering(files []string, rootFolder string, targetClusters
 	utils.Debug(fmt.Sprintf("[GIT.CLUSTER]: Enhanced cached embedding clustering: %d files -> %d clusters, cache hit ratio: %.2f, confidence: %.2f, final cache hit ratio: %.3f",
 		len(files), len(clusters), cacheHitRatio, confidence, finalStats.HitRatio))
 
-	return clusters, confidence, cacheHitRatio
+	return clusters, confidence, cacheHitRatio, fileEmbeddings
 }
 
 // smartSamplingClustering handles large file sets by sampling representative files
@@ -534,7 +577,7 @@ func smartSamplingClustering(files []string, rootFolder string, targetClusters i
 	representatives := selectRepresentativeFiles(files, sampleSize)
 
 	// Cluster representatives using embeddings
-	reprClusters, err := fullSemanticClustering(representatives, rootFolder, -1, true) // Always use threshold for sampling
+	reprClusters, _, err := fullSemanticClustering(representatives, rootFolder, -1, true) // Always use threshold for sampling
 	if err != nil {
 		return fallbackToPatterClustering(files, targetClusters), nil
 	}
@@ -554,7 +597,8 @@ func smartSamplingClustering(files []string, rootFolder string, targetClusters i
 }
 
 // fullSemanticClustering performs complete semantic analysis
-func fullSemanticClustering(files []string, rootFolder string, targetClusters int, useThresholdClustering bool) ([][]string, error) {
+// The embeddings used are returned so the clusters can be explained.
+func fullSemanticClustering(files []string, rootFolder string, targetClusters int, useThresholdClustering bool) ([][]string, map[string][]float32, error) {
 	utils.Debug(fmt.Sprintf("[GIT.CLUSTER]: Performing full semantic clustering for %d files", len(files)))
 
 	// Get clustering configuration for rate limiting
@@ -562,11 +606,18 @@ func fullSemanticClustering(files []string, rootFolder string, targetClusters in
 	rateLimitDelay := time.Duration(clusteringConfig.Methods.Semantic.RateLimitDelay) * time.Millisecond
 
 	fileEmbeddings := make(map[string][]float32)
+	filesToEmbed := files
+	if useHunkEmbeddings() {
+		cache := LoadHunkEmbeddingCache(rootFolder)
+		fileEmbeddings = hunkFileEmbeddings(files, cache)
+		cache.Save()
+		filesToEmbed = nil
+	}
 
 	// Generate embeddings for all files with configurable rate limiting
-	for i, file := range files {
-		// Rate limiting: add configurable delay between requests
-		if i > 0 {
+	for i, file := range filesToEmbed {
+		// Rate limiting: add configurable delay between requests to a metered API
+		if i > 0 && embeddings.IsRateLimited() {
 			time.Sleep(rateLimitDelay)
 		}
 
@@ -591,7 +642,7 @@ func fullSemanticClustering(files []string, rootFolder string, targetClusters in
 	}
 
 	if len(fileEmbeddings) < 2 {
-		return createSingleFileClusters(files), nil
+		return createSingleFileClusters(files), fileEmbeddings, nil
 	}
 
 	// Perform clustering using configured similarity threshold
@@ -606,11 +657,11 @@ func fullSemanticClustering(files []string, rootFolder string, targetClusters in
 	utils.Debug(fmt.Sprintf("[GIT.CLUSTER]: Full semantic clustering: %d files -> %d clusters",
 		len(files), len(clusters)))
 
-	return clusters, nil
+	return clusters, fileEmbeddings, nil
 }
 
 // executeSpecificMethod runs a single specific clustering method
-func executeSpecificMethod(files []string, rootFolder string, targetClusters int, methodName string, useThresholdClustering bool) ([][]string, error) {
+func executeSpecificMethod(files []string, rootFolder string, targetClusters int, methodName string, useThresholdClustering bool) ([]FileCluster, error) {
 	utils.Debug(fmt.Sprintf("[GIT.CLUSTER]: Executing specific method: %s", methodName))
 
 	switch methodName {
@@ -618,28 +669,52 @@ func executeSpecificMethod(files []string, rootFolder string, targetClusters int
 		if !config.IsMethodEnabled(config.DirectoryMethod) {
 			return nil, fmt.Errorf("directory clustering method is disabled")
 		}
-		clusters, _ := directoryBasedClustering(files, rootFolder, targetClusters)
-		return clusters, nil
+		clusters, confidence := directoryBasedClustering(files, rootFolder, targetClusters)
+		return newFileClusters(methodName, clusters, confidence, rootFolder, nil), nil
 
 	case "pattern":
 		if !config.IsMethodEnabled(config.PatternMethod) {
 			return nil, fmt.Errorf("pattern clustering method is disabled")
 		}
-		clusters, _ := patternBasedClustering(files, targetClusters)
+		clusters, confidence := patternBasedClustering(files, targetClusters)
+		return newFileClusters(methodName, clusters, confidence, rootFolder, nil), nil
+
+	case "dependency":
+		if !config.IsMethodEnabled(config.DependencyMethod) {
+			return nil, fmt.Errorf("dependency clustering method is disabled")
+		}
+		clusters, confidence := dependencyBasedClustering(files, rootFolder, targetClusters)
+		if confidence == 0 {
+			utils.Debug("[GIT.CLUSTER]: No changed file references another, each file is its own cluster")
+		}
 		return clusters, nil
 
-	// case "cached":
-	// 	if !config.IsMethodEnabled(config.CachedMethod) {
-	// 		return nil, fmt.Errorf("cached clustering method is disabled")
-	// 	}
-	// 	clusters, _, _ := cachedEmbeddingClustering(files, rootFolder, targetClusters)
-	// 	return clusters, nil
+	case "history":
+		if !config.IsMethodEnabled(config.HistoryMethod) {
+			return nil, fmt.Errorf("history clustering method is disabled")
+		}
+		clusters, confidence := historyBasedClustering(files, rootFolder, targetClusters)
+		if confidence == 0 {
+			utils.Debug("[GIT.CLUSTER]: No changed files changed together before, each file is its own cluster")
+		}
+		return clusters, nil
 
-	// case "semantic":
-	// 	if !config.IsMethodEnabled(config.SemanticMethod) {
-	// 		return nil, fmt.Errorf("semantic clustering method is disabled")
-	// 	}
-	// 	return fullSemanticClustering(files, rootFolder, targetClusters, useThresholdClustering)
+	case "cached":
+		if !config.IsMethodEnabled(config.CachedMethod) {
+			return nil, fmt.Errorf("cached clustering method is disabled")
+		}
+		clusters, confidence, _, fileEmbeddings := cachedEmbeddingClustering(files, rootFolder, targetClusters)
+		return newFileClusters(methodName, clusters, confidence, rootFolder, fileEmbeddings), nil
+
+	case "semantic":
+		if !config.IsMethodEnabled(config.SemanticMethod) {
+			return nil, fmt.Errorf("semantic clustering method is disabled")
+		}
+		clusters, fileEmbeddings, err := fullSemanticClustering(files, rootFolder, targetClusters, useThresholdClustering)
+		if err != nil {
+			return nil, err
+		}
+		return newFileClusters(methodName, clusters, calculateEmbeddingClusterConfidence(clusters, fileEmbeddings), rootFolder, fileEmbeddings), nil
 
 	default:
 		return nil, fmt.Errorf("unknown clustering method: %s", methodName)
@@ -660,6 +735,14 @@ func performThresholdBasedClustering(fileEmbeddings map[string][]float32, thresh
 		return createSingleFileClusters(files)
 	}
 
+	// Large change sets: DBSCAN over an LSH neighbor graph. With two points per core it links
+	// the same files as single-linkage clustering cut at the threshold, without all pairs.
+	if embeddings.UseScalableClustering(len(files)) {
+		files, embeddingVectors = sortedEmbeddings(fileEmbeddings)
+		labels, _ := embeddings.ScalableDBSCAN(embeddingVectors, threshold, 2)
+		return groupByLabel(files, labels)
+	}
+
 	// Use hierarchical clustering with threshold for better quality
 	labels, metrics, err := embeddings.HierarchicalWithMetrics(embeddingVectors, len(files), float32(threshold))
 	if err != nil {
@@ -737,9 +820,13 @@ func performOriginalThresholdClustering(fileEmbeddings map[string][]float32, thr
 	return clusters
 }
 
-// performEmbeddingBasedClustering performs K-means clustering on embeddings
+// performEmbeddingBasedClustering performs K-means clustering on embeddings. Without a target
+// the number of clusters is chosen from the embeddings.
 func performEmbeddingBasedClustering(fileEmbeddings map[string][]float32, targetClusters int) [][]string {
-	if targetClusters <= 0 {
+	if targetClusters <= 0 && config.GetClusteringConfig().ClusterCount.Auto {
+		_, vectors := sortedEmbeddings(fileEmbeddings)
+		targetClusters = embeddings.ChooseClusterCount(vectors).K
+	} else if targetClusters <= 0 {
 		targetClusters = int(math.Max(1, math.Min(float64(len(fileEmbeddings))/2, 5)))
 	}
 
@@ -754,9 +841,19 @@ func performEmbeddingBasedClustering(fileEmbeddings map[string][]float32, target
 	if len(vectors) <= targetClusters {
 		return createSingleFileClusters(files)
 	}
+	if targetClusters == 1 {
+		return [][]string{files}
+	}
 
-	// Use adaptive clustering for better performance
-	labels, metrics, err := embeddings.KMeansWithMetrics(vectors, targetClusters, 20)
+	// Use adaptive clustering for better performance; large change sets use mini-batches
+	kMeans := embeddings.KMeansWithMetrics
+	maxIter := 20
+	if embeddings.UseScalableClustering(len(vectors)) {
+		files, vectors = sortedEmbeddings(fileEmbeddings)
+		kMeans = embeddings.MiniBatchKMeansWithMetrics
+		maxIter = 200
+	}
+	labels, metrics, err := kMeans(vectors, targetClusters, maxIter)
 	if err != nil {
 		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: K-means clustering failed: %v", err))
 		return createSingleFileClusters(files)
@@ -780,6 +877,39 @@ func performEmbeddingBasedClustering(fileEmbeddings map[string][]float32, target
 	return clusters
 }
 
+// sortedEmbeddings returns the files and their embeddings in file order, so clustering that
+// depends on the order of the points gives the same result for the same change set
+func sortedEmbeddings(fileEmbeddings map[string][]float32) ([]string, [][]float32) {
+	files := make([]string, 0, len(fileEmbeddings))
+	for file := range fileEmbeddings {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	vectors := make([][]float32, len(files))
+	for i, file := range files {
+		vectors[i] = fileEmbeddings[file]
+	}
+	return files, vectors
+}
+
+// groupByLabel turns cluster labels into file clusters, ordered by label
+func groupByLabel(files []string, labels []int) [][]string {
+	clusterMap := make(map[int][]string)
+	for i, label := range labels {
+		clusterMap[label] = append(clusterMap[label], files[i])
+	}
+	order := make([]int, 0, len(clusterMap))
+	for label := range clusterMap {
+		order = append(order, label)
+	}
+	sort.Ints(order)
+	clusters := make([][]string, 0, len(order))
+	for _, label := range order {
+		clusters = append(clusters, clusterMap[label])
+	}
+	return clusters
+}
+
 // LRU Cache implementation for embedding management
 func NewLRUCache(capacity int) *LRUCache {
 	head := &LRUNode{}
@@ -889,8 +1019,8 @@ func (ec *EnhancedEmbeddingCache) GetEmbedding(filePath string) (*FileCacheEntry
 	if entry, exists := ec.LRU.Get(filePath); exists {
 		// Check TTL
 		if time.Since(ec.LastAccess[filePath]) < ec.Config.TTL {
-			// Validate file hasn't changed
-			if currentHash := getFileContentHash(filePath); currentHash == entry.ContentHash {
+			// Validate file hasn't changed and the vector comes from the backend in use
+			if currentHash := getFileContentHash(filePath); currentHash == entry.ContentHash && entryBackend(entry) == embeddings.BackendName() {
 				ec.Stats.CacheHits++
 				ec.LastAccess[filePath] = time.Now()
 				ec.updateHitRatio()
@@ -913,6 +1043,7 @@ func (ec *EnhancedEmbeddingCache) PutEmbedding(filePath string, embedding []floa
 		Embedding:   embedding,
 		ContentHash: getFileContentHash(filePath),
 		LastUpdated: time.Now(),
+		Backend:     embeddings.BackendName(),
 	}
 
 	// Check if we need to evict due to size constraints
@@ -931,6 +1062,15 @@ func (ec *EnhancedEmbeddingCache) PutEmbedding(filePath string, embedding []floa
 	ec.enforceMemoryLimits()
 }
 
+// entryBackend returns the backend of a cache entry; entries from before backends were
+// recorded came from gemini
+func entryBackend(entry *FileCacheEntry) string {
+	if entry.Backend == "" {
+		return config.GeminiEmbeddingBackend
+	}
+	return entry.Backend
+}
+
 func (ec *EnhancedEmbeddingCache) updateHitRatio() {
 	if ec.Stats.TotalRequests > 0 {
 		ec.Stats.HitRatio = float64(ec.Stats.CacheHits) / float64(ec.Stats.TotalRequests)
@@ -1016,32 +1156,45 @@ func (ec *EnhancedEmbeddingCache) GetStats() CacheStats {
 	return ec.Stats
 }
 
-// Core cache management functions
+// saveEmbeddingCache writes the entries added since the cache was loaded to the embedding
+// store and deletes the evicted ones
 func saveEmbeddingCache(cache *EmbeddingCache) {
-	cacheFile := getCacheFilePath(cache.RootFolder)
-
-	// Ensure cache directory exists
-	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
-		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to create cache directory: %v", err))
-		return
-	}
-
-	data, err := json.Marshal(cache)
+	store, err := OpenEmbeddingStore()
 	if err != nil {
-		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to marshal cache: %v", err))
+		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to open the embedding store: %v", err))
 		return
 	}
 
-	if err := os.WriteFile(cacheFile, data, 0644); err != nil {
-		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to save cache file: %v", err))
+	prefix := rootStoreKey(cache.RootFolder)
+	var changed []embeddings.StoredVector
+	for filePath, entry := range cache.Embeddings {
+		if stored, ok := cache.stored[filePath]; (ok && stored.Equal(entry.LastUpdated)) || len(entry.Embedding) == 0 {
+			continue
+		}
+		changed = append(changed, embeddings.StoredVector{
+			Key: prefix + filePath, Vector: entry.Embedding, Backend: entryBackend(&entry),
+			Meta: entry.ContentHash, Updated: entry.LastUpdated,
+		})
+	}
+	var evicted []string
+	for filePath := range cache.stored {
+		if _, ok := cache.Embeddings[filePath]; !ok {
+			evicted = append(evicted, prefix+filePath)
+		}
 	}
-}
 
-func getCacheFilePath(rootFolder string) string {
-	homeDir, _ := os.UserHomeDir()
-	hasher := sha256.Sum256([]byte(rootFolder))
-	folderHash := hex.EncodeToString(hasher[:])[:12]
-	return filepath.Join(homeDir, ".gitcury", fmt.Sprintf("embedding_cache_%s.json", folderHash))
+	if err := store.Put(changed...); err != nil {
+		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to save embeddings: %v", err))
+		return
+	}
+	if err := store.Delete(evicted...); err != nil {
+		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to drop evicted embeddings: %v", err))
+		return
+	}
+	cache.stored = make(map[string]time.Time, len(cache.Embeddings))
+	for filePath, entry := range cache.Embeddings {
+		cache.stored[filePath] = entry.LastUpdated
+	}
 }
 
 func getFileContentHash(filePath string) string {
@@ -1053,39 +1206,39 @@ func getFileContentHash(filePath string) string {
 	return hex.EncodeToString(hasher[:])
 }
 
-// Helper functions for embedding cache management
+// loadEmbeddingCache reads the file embeddings of rootFolder from the embedding store
 func loadEmbeddingCache(rootFolder string) *EmbeddingCache {
-	cacheFile := getCacheFilePath(rootFolder)
-
-	if _, err := os.Stat(cacheFile); os.IsNotExist(err) {
-		return &EmbeddingCache{
-			RootFolder:  rootFolder,
-			Embeddings:  make(map[string]FileCacheEntry),
-			LastUpdated: time.Now(),
-		}
+	cache := &EmbeddingCache{
+		RootFolder:  rootFolder,
+		Embeddings:  make(map[string]FileCacheEntry),
+		LastUpdated: time.Now(),
+		stored:      make(map[string]time.Time),
 	}
 
-	data, err := os.ReadFile(cacheFile) //nolint:gosec // Cache file path controlled by application
+	store, err := OpenEmbeddingStore()
 	if err != nil {
-		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to read cache file: %v", err))
-		return &EmbeddingCache{
-			RootFolder:  rootFolder,
-			Embeddings:  make(map[string]FileCacheEntry),
-			LastUpdated: time.Now(),
-		}
+		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to open the embedding store: %v", err))
+		return cache
 	}
-
-	var cache EmbeddingCache
-	if err := json.Unmarshal(data, &cache); err != nil {
-		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to parse cache file: %v", err))
-		return &EmbeddingCache{
-			RootFolder:  rootFolder,
-			Embeddings:  make(map[string]FileCacheEntry),
-			LastUpdated: time.Now(),
+	prefix := rootStoreKey(rootFolder)
+	vectors, err := store.GetPrefix(prefix)
+	if err != nil {
+		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to read cached embeddings: %v", err))
+		return cache
+	}
+
+	for _, vector := range vectors {
+		filePath := strings.TrimPrefix(vector.Key, prefix)
+		cache.Embeddings[filePath] = FileCacheEntry{
+			FilePath:    filePath,
+			Embedding:   vector.Vector,
+			ContentHash: vector.Meta,
+			LastUpdated: vector.Updated,
+			Backend:     vector.Backend,
 		}
+		cache.stored[filePath] = vector.Updated
 	}
-
-	return &cache
+	return cache
 }
 
 func getCachedEmbedding(filePath string, cache *EmbeddingCache) (*FileCacheEntry, bool) { //nolint:unused // Future caching optimization
@@ -1675,6 +1828,7 @@ type ClusteringBenchmark struct {
 	SilhouetteScore  float64          `json:"silhouetteScore"`
 	MemoryUsage      int64            `json:"memoryUsage"`
 	ApiCalls         int              `json:"apiCalls"`
+	Comparisons      int              `json:"comparisons,omitempty"` // Distance computations, for neighbor searches
 	Timestamp        time.Time        `json:"timestamp"`
 }
 
@@ -1686,6 +1840,8 @@ type BenchmarkResult struct {
 	TotalApiCalls int                   `json:"totalApiCalls"`
 	BestMethod    string                `json:"bestMethod"`
 	Summary       string                `json:"summary"`
+
+	ScalingExponents map[string]float64 `json:"scalingExponents,omitempty"` // Method -> b of time ∝ nᵇ
 }
 
 // Global benchmark collector
@@ -1824,12 +1980,14 @@ func RunClusteringPerformanceTest(files []string, rootFolder string) BenchmarkRe
 			ActualClusters:  len(clusters),
 			Method:          config.method,
 			ExecutionTime:   executionTime,
-			ConfidenceScore: calculateOverallClusteringConfidence(clusters, files, rootFolder),
+			ConfidenceScore: calculateOverallClusteringConfidence(ClusterFileLists(clusters), files, rootFolder),
 		}
 
 		recordBenchmark(benchmark)
 	}
 
+	// The synthetic scaling sweep is separate (RunScalingBenchmarks, 'clusters benchmark --scaling')
+	// so timing a repository's changes stays cheap
 	return GetBenchmarkResults()
 }
 
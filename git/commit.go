@@ -0,0 +1,109 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// commitHooks are the hooks git runs during `git commit` that can reject a commit
+var commitHooks = []string{"pre-commit", "prepare-commit-msg", "commit-msg"}
+
+// BuildCommitArgs returns the git arguments for committing with message under commitConfig
+func BuildCommitArgs(commitConfig config.CommitConfig, message string) []string {
+	return append(commitOptionArgs(commitConfig), "-m", message)
+}
+
+// commitOptionArgs returns the git arguments for a commit under commitConfig up to its message,
+// so commits with a message file (such as reword's amends) get the same signing and hook settings
+func commitOptionArgs(commitConfig config.CommitConfig) []string {
+	var args []string
+
+	// gpg.format has to be set before the subcommand
+	if commitConfig.SigningFormat != "" {
+		args = append(args, "-c", "gpg.format="+commitConfig.SigningFormat)
+	}
+
+	args = append(args, "commit")
+
+	if commitConfig.Sign {
+		args = append(args, "-S"+commitConfig.SigningKey)
+	}
+	if commitConfig.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	return args
+}
+
+// activeCommitHooks lists the commit hooks that git will run in dir
+func activeCommitHooks(dir string) []string {
+	hooksDir, err := GetHooksDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var active []string
+	for _, hook := range commitHooks {
+		info, err := os.Stat(filepath.Join(hooksDir, hook))
+		if err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			active = append(active, hook)
+		}
+	}
+	return active
+}
+
+// runCommit commits the index of dir with message. On failure the returned StructuredError
+// carries git's stdout and stderr, which is where hook output ends up, and the hooks that ran.
+func runCommit(dir string, envMap map[string]string, commitConfig config.CommitConfig, message string, files []string) error {
+	args := BuildCommitArgs(commitConfig, message)
+
+	stdout, stderr, err := RunGitCmdWithOutput(dir, envMap, args...)
+	if err == nil {
+		utils.Debug("[GIT.COMMIT]: Commit created in " + dir)
+		return nil
+	}
+
+	context := map[string]interface{}{
+		"folder":  dir,
+		"message": message,
+		"files":   files,
+		"stdout":  strings.TrimSpace(stdout),
+		"stderr":  strings.TrimSpace(stderr),
+		"signed":  commitConfig.Sign,
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		context["exitCode"] = exitErr.ExitCode()
+	}
+
+	processedFile := ""
+	if len(files) > 0 {
+		processedFile = files[0]
+	}
+
+	errorMessage := "Failed to commit files"
+	if !commitConfig.NoVerify {
+		if hooks := activeCommitHooks(dir); len(hooks) > 0 {
+			context["hooks"] = hooks
+			context["suggestion"] = "Fix the issues reported by the hooks and re-run the commit; only the remaining groups will be committed. Use --no-verify to bypass hooks."
+			errorMessage = fmt.Sprintf("Commit rejected, possibly by a hook (%s)", strings.Join(hooks, ", "))
+		}
+	}
+	if commitConfig.Sign && strings.Contains(stderr, "sign") {
+		context["suggestion"] = "Check that the signing key is available, or disable signing for this root folder"
+		errorMessage = "Failed to sign commit"
+	}
+
+	// Show what git and the hooks printed, it is usually the only useful explanation
+	if output := strings.TrimSpace(stdout + "\n" + stderr); output != "" {
+		utils.Error("[GIT.COMMIT.FAIL]: " + errorMessage + ":\n" + output)
+	}
+
+	return utils.NewGitError(errorMessage, err, context, processedFile)
+}
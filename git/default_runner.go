@@ -46,6 +46,11 @@ func (d *DefaultGitRunner) Status(rootPaths []string) ([]interfaces.Folder, erro
 	return outputFoldersToInterface(folders), nil
 }
 
+// RepositoryStatus summarises the branch and working tree state of dir
+func (d *DefaultGitRunner) RepositoryStatus(dir string) (interfaces.RepositoryStatus, error) {
+	return GetRepositoryStatus(dir)
+}
+
 // ProcessOneFile processes a single file with the given commit message
 func (d *DefaultGitRunner) ProcessOneFile(filePath, commitMessage string, env ...[]string) error {
 	return ProcessOneFile(filePath, commitMessage, env...)
@@ -79,8 +84,8 @@ func (d *DefaultGitRunner) ProgressCommitBatch(folder interfaces.Folder, env ...
 }
 
 // ProgressPushBranch is an enhanced version with progress reporting
-func (d *DefaultGitRunner) ProgressPushBranch(rootFolderName string, branch string) error {
-	return ProgressPushBranch(rootFolderName, branch)
+func (d *DefaultGitRunner) ProgressPushBranch(rootFolderName string, branch string, opts ...interfaces.PushOptions) error {
+	return ProgressPushBranch(rootFolderName, branch, opts...)
 }
 
 // GetAllChangedFiles gets all changed files for a directory
@@ -98,6 +103,16 @@ func (d *DefaultGitRunner) BatchProcessWithEmbeddings(allChangedFiles []string,
 	return BatchProcessWithEmbeddings(allChangedFiles, rootFolder, numClusters, nil)
 }
 
+// GenStagedCommitMessage generates a single message for the staged changes of a repository
+func (d *DefaultGitRunner) GenStagedCommitMessage(dir string) (string, []string, error) {
+	return GenStagedCommitMessage(dir)
+}
+
+// CommitStaged commits the index as-is using the folder's generated message
+func (d *DefaultGitRunner) CommitStaged(folder interfaces.Folder, env ...[]string) error {
+	return CommitStaged(interfaceToOutput(folder), env...)
+}
+
 // Conversion functions between output and interface types
 func outputToInterface(folder output.Folder) interfaces.Folder {
 	var files []interfaces.FileEntry
@@ -0,0 +1,501 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RuleDependency marks clusters of files that reference each other's changed symbols
+const RuleDependency = "references changed symbols"
+
+// hunkHeaderPattern matches the new-file side of a unified diff hunk header
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// importPatterns find the modules a non-Go file imports, by extension
+var importPatterns = map[string][]*regexp.Regexp{
+	".js":  {regexp.MustCompile(`(?:from|import)\s*['"]([^'"]+)['"]`), regexp.MustCompile(`require\(\s*['"]([^'"]+)['"]\s*\)`)},
+	".jsx": {regexp.MustCompile(`(?:from|import)\s*['"]([^'"]+)['"]`), regexp.MustCompile(`require\(\s*['"]([^'"]+)['"]\s*\)`)},
+	".ts":  {regexp.MustCompile(`(?:from|import)\s*['"]([^'"]+)['"]`), regexp.MustCompile(`require\(\s*['"]([^'"]+)['"]\s*\)`)},
+	".tsx": {regexp.MustCompile(`(?:from|import)\s*['"]([^'"]+)['"]`), regexp.MustCompile(`require\(\s*['"]([^'"]+)['"]\s*\)`)},
+	".py":  {regexp.MustCompile(`^\s*from\s+([\w.]+)\s+import`), regexp.MustCompile(`^\s*import\s+([\w.]+)`)},
+	".java": {regexp.MustCompile(`^\s*import\s+(?:static\s+)?([\w.]+)\s*;`)},
+	".kt":  {regexp.MustCompile(`^\s*import\s+([\w.]+)`)},
+	".c":   {regexp.MustCompile(`^\s*#\s*include\s+"([^"]+)"`)},
+	".h":   {regexp.MustCompile(`^\s*#\s*include\s+"([^"]+)"`)},
+	".cpp": {regexp.MustCompile(`^\s*#\s*include\s+"([^"]+)"`)},
+	".hpp": {regexp.MustCompile(`^\s*#\s*include\s+"([^"]+)"`)},
+	".rb":  {regexp.MustCompile(`require_relative\s+['"]([^'"]+)['"]`)},
+}
+
+// scriptExtensions are tried when resolving extensionless JavaScript and TypeScript imports
+var scriptExtensions = []string{".ts", ".tsx", ".js", ".jsx", "/index.ts", "/index.js"}
+
+// goFileInfo is what dependency clustering knows about a changed Go file
+type goFileInfo struct {
+	importPath string
+	pkgName    string
+	imports    map[string]string // Name used in the file -> import path, for explicit names
+	importSet  map[string]bool   // Import paths
+	idents     map[string]bool   // Every identifier used in the file
+	selectors  map[string]bool   // Names used after a dot, e.g. methods and fields
+	qualified  map[string]bool   // "<import path>.<name>" references
+	changed    map[string]bool   // Top-level symbols whose declaration was changed
+	methods    map[string]bool   // Changed symbols that are methods
+}
+
+// dependencyBasedClustering groups files that reference each other's changed symbols.
+// Go files are parsed with go/parser; other languages are connected through their import
+// statements. Each connected component becomes a cluster.
+func dependencyBasedClustering(files []string, rootFolder string, targetClusters int) ([]FileCluster, float64) {
+	edges := dependencyEdges(files, rootFolder)
+
+	connected := 0
+	for _, neighbours := range edges {
+		if len(neighbours) > 0 {
+			connected++
+		}
+	}
+	components, componentOf := connectedComponents(files, edges)
+
+	merged := components
+	if targetClusters > 0 && len(components) > targetClusters {
+		merged = mergeClusters(components, targetClusters)
+	}
+
+	confidence := 0.0
+	if len(files) > 0 {
+		confidence = float64(connected) / float64(len(files))
+	}
+
+	clusters := newFileClusters(string(config.DependencyMethod), merged, confidence, rootFolder, nil)
+	for i := range clusters {
+		if len(clusters[i].Files) > 1 && isComponent(clusters[i].Files, componentOf) {
+			clusters[i].Rule = RuleDependency
+			clusters[i].FileSimilarity = dependencyDegreeSimilarity(clusters[i].Files, edges)
+			clusters[i].Similarity = 0
+			for _, similarity := range clusters[i].FileSimilarity {
+				clusters[i].Similarity += similarity / float64(len(clusters[i].FileSimilarity))
+			}
+		}
+	}
+
+	utils.Debug(fmt.Sprintf("[GIT.CLUSTER]: Dependency-based clustering: %d files -> %d clusters, %d connected, confidence: %.2f",
+		len(files), len(clusters), connected, confidence))
+	return clusters, confidence
+}
+
+// validateClusterSimilarity checks that every multi-file cluster is similar enough by its own
+// measure. Path similarity is not used, since dependencies and co-changes often cross directories.
+func validateClusterSimilarity(clusters []FileCluster, threshold float64) bool {
+	for _, cluster := range clusters {
+		if len(cluster.Files) > 1 && cluster.Similarity < threshold {
+			utils.Debug(fmt.Sprintf("[GIT.CLUSTER]: %s cluster failed threshold validation: %.2f < %.2f", cluster.Method, cluster.Similarity, threshold))
+			return false
+		}
+	}
+	return true
+}
+
+// connectedComponents groups files joined by edges, in the order the first file of each
+// component appears. It also returns the component index of every file.
+func connectedComponents(files []string, edges map[string]map[string]bool) ([][]string, map[string]int) {
+	parent := make(map[string]string, len(files))
+	var find func(file string) string
+	find = func(file string) string {
+		if parent[file] != file {
+			parent[file] = find(parent[file])
+		}
+		return parent[file]
+	}
+	for _, file := range files {
+		parent[file] = file
+	}
+	for file, neighbours := range edges {
+		for neighbour := range neighbours {
+			if _, ok := parent[neighbour]; ok {
+				parent[find(file)] = find(neighbour)
+			}
+		}
+	}
+
+	var components [][]string
+	index := make(map[string]int)
+	componentOf := make(map[string]int, len(files))
+	for _, file := range files {
+		root := find(file)
+		i, exists := index[root]
+		if !exists {
+			i = len(components)
+			index[root] = i
+			components = append(components, nil)
+		}
+		components[i] = append(components[i], file)
+		componentOf[file] = i
+	}
+	return components, componentOf
+}
+
+// isComponent reports whether files all belong to the same connected component
+func isComponent(files []string, componentOf map[string]int) bool {
+	for _, file := range files[1:] {
+		if componentOf[file] != componentOf[files[0]] {
+			return false
+		}
+	}
+	return true
+}
+
+// dependencyDegreeSimilarity scores each file by the share of the cluster it is directly
+// connected to
+func dependencyDegreeSimilarity(files []string, edges map[string]map[string]bool) map[string]float64 {
+	similarities := make(map[string]float64, len(files))
+	for _, file := range files {
+		linked := 0
+		for _, other := range files {
+			if other != file && (edges[file][other] || edges[other][file]) {
+				linked++
+			}
+		}
+		similarities[file] = float64(linked) / float64(len(files)-1)
+	}
+	return similarities
+}
+
+// dependencyEdges returns, for each file, the changed files it depends on
+func dependencyEdges(files []string, rootFolder string) map[string]map[string]bool {
+	edges := make(map[string]map[string]bool, len(files))
+	addEdge := func(from, to string) {
+		if from == to {
+			return
+		}
+		if edges[from] == nil {
+			edges[from] = make(map[string]bool)
+		}
+		edges[from][to] = true
+		if edges[to] == nil {
+			edges[to] = make(map[string]bool)
+		}
+		edges[to][from] = true
+	}
+
+	changed := make(map[string]string, len(files)) // Cleaned path -> file as given
+	goFiles := make(map[string]*goFileInfo)
+	modulePaths := make(map[string]string)
+	for _, file := range files {
+		changed[filepath.Clean(file)] = file
+		if strings.HasSuffix(file, ".go") {
+			if info := parseGoFile(file, rootFolder, modulePaths); info != nil {
+				goFiles[file] = info
+			}
+		}
+	}
+
+	// Go: connect files that use a changed symbol of another file
+	for from, user := range goFiles {
+		for to, owner := range goFiles {
+			if from != to && usesChangedSymbol(user, owner) {
+				addEdge(from, to)
+			}
+		}
+	}
+
+	// Other languages: connect files that import another changed file
+	for _, file := range files {
+		if strings.HasSuffix(file, ".go") {
+			continue
+		}
+		for _, imported := range resolveImports(file, rootFolder, changed) {
+			addEdge(file, imported)
+		}
+	}
+	return edges
+}
+
+// usesChangedSymbol reports whether user references a symbol changed in owner
+func usesChangedSymbol(user, owner *goFileInfo) bool {
+	samePackage := user.importPath == owner.importPath && user.pkgName == owner.pkgName
+	imports := user.importSet[owner.importPath]
+	if !samePackage && !imports {
+		return false
+	}
+
+	for symbol := range owner.changed {
+		switch {
+		case owner.methods[symbol]:
+			if user.selectors[symbol] {
+				return true
+			}
+		case samePackage:
+			if user.idents[symbol] {
+				return true
+			}
+		case user.qualified[owner.importPath+"."+symbol]:
+			return true
+		case user.qualified["."+owner.pkgName+"."+symbol]:
+			// Imported without an explicit name, so referenced by the package name
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseGoFile collects the imports, identifiers and changed declarations of a Go file
+func parseGoFile(file, rootFolder string, modulePaths map[string]string) *goFileInfo {
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		utils.Debug("[GIT.DEPENDENCY]: Could not parse " + file + ": " + err.Error())
+		return nil
+	}
+
+	info := &goFileInfo{
+		importPath: goImportPath(filepath.Dir(file), rootFolder, modulePaths),
+		pkgName:    parsed.Name.Name,
+		imports:    make(map[string]string),
+		importSet:  make(map[string]bool),
+		idents:     make(map[string]bool),
+		selectors:  make(map[string]bool),
+		qualified:  make(map[string]bool),
+		changed:    make(map[string]bool),
+		methods:    make(map[string]bool),
+	}
+
+	for _, spec := range parsed.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		info.importSet[path] = true
+		if spec.Name != nil {
+			info.imports[spec.Name.Name] = path
+		}
+	}
+
+	ast.Inspect(parsed, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.Ident:
+			info.idents[n.Name] = true
+		case *ast.SelectorExpr:
+			info.selectors[n.Sel.Name] = true
+			if pkg, ok := n.X.(*ast.Ident); ok {
+				if path, ok := info.imports[pkg.Name]; ok {
+					info.qualified[path+"."+n.Sel.Name] = true
+				} else {
+					// Resolved against the package name of the changed files later
+					info.qualified["."+pkg.Name+"."+n.Sel.Name] = true
+				}
+			}
+		}
+		return true
+	})
+
+	ranges := changedLineRanges(file, rootFolder)
+	changedDecl := func(node ast.Node) bool {
+		if ranges == nil {
+			return true
+		}
+		start, end := fset.Position(node.Pos()).Line, fset.Position(node.End()).Line
+		for _, lines := range ranges {
+			if lines[0] <= end && lines[1] >= start {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, decl := range parsed.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !changedDecl(d) {
+				continue
+			}
+			info.changed[d.Name.Name] = true
+			if d.Recv != nil {
+				info.methods[d.Name.Name] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if !changedDecl(spec) {
+					continue
+				}
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					info.changed[s.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						info.changed[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+	return info
+}
+
+// goImportPath derives the import path of the package in dir from the nearest go.mod at or
+// below rootFolder. Without a go.mod the path relative to the root folder is used.
+func goImportPath(dir, rootFolder string, modulePaths map[string]string) string {
+	for current := dir; ; current = filepath.Dir(current) {
+		module, known := modulePaths[current]
+		if !known {
+			module = readModulePath(filepath.Join(current, "go.mod"))
+			modulePaths[current] = module
+		}
+		if module != "" {
+			relative, err := filepath.Rel(current, dir)
+			if err != nil || relative == "." {
+				return module
+			}
+			return module + "/" + filepath.ToSlash(relative)
+		}
+		if filepath.Clean(current) == filepath.Clean(rootFolder) || filepath.Dir(current) == current {
+			break
+		}
+	}
+	relative, err := filepath.Rel(rootFolder, dir)
+	if err != nil {
+		return filepath.ToSlash(dir)
+	}
+	return filepath.ToSlash(relative)
+}
+
+// readModulePath returns the module path declared in a go.mod file, or ""
+func readModulePath(goMod string) string {
+	file, err := os.Open(goMod)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+		}
+	}
+	return ""
+}
+
+// changedLineRanges returns the line ranges of file changed since HEAD, or nil when the whole
+// file counts as changed (new, untracked or without history)
+func changedLineRanges(file, rootFolder string) [][2]int {
+	diff, _, err := RunGitCmdWithOutput(rootFolder, nil, "diff", "-U0", "HEAD", "--", file)
+	if err != nil || strings.TrimSpace(diff) == "" {
+		return nil
+	}
+
+	var ranges [][2]int
+	for _, line := range strings.Split(diff, "\n") {
+		match := hunkHeaderPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		start, _ := strconv.Atoi(match[1])
+		count := 1
+		if match[2] != "" {
+			count, _ = strconv.Atoi(match[2])
+		}
+		if count == 0 {
+			// A pure deletion sits between two lines; count the line after it as changed
+			ranges = append(ranges, [2]int{start, start + 1})
+			continue
+		}
+		ranges = append(ranges, [2]int{start, start + count - 1})
+	}
+	if len(ranges) == 0 {
+		return nil
+	}
+	return ranges
+}
+
+// resolveImports returns the changed files that a non-Go file imports
+func resolveImports(file, rootFolder string, changed map[string]string) []string {
+	patterns := importPatterns[strings.ToLower(filepath.Ext(file))]
+	if len(patterns) == 0 {
+		return nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	var resolved []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(content), "\n") {
+		for _, pattern := range patterns {
+			for _, match := range pattern.FindAllStringSubmatch(line, -1) {
+				for _, candidate := range importCandidates(file, rootFolder, match[1]) {
+					imported, ok := changed[filepath.Clean(candidate)]
+					if ok && imported != file && !seen[imported] {
+						seen[imported] = true
+						resolved = append(resolved, imported)
+					}
+				}
+			}
+		}
+	}
+	return resolved
+}
+
+// importCandidates lists the files an import specifier may refer to
+func importCandidates(file, rootFolder, specifier string) []string {
+	dir := filepath.Dir(file)
+	ext := strings.ToLower(filepath.Ext(file))
+
+	switch ext {
+	case ".js", ".jsx", ".ts", ".tsx":
+		if !strings.HasPrefix(specifier, ".") {
+			return nil // A package, not a file of this repository
+		}
+		base := filepath.Join(dir, specifier)
+		candidates := []string{base}
+		for _, suffix := range scriptExtensions {
+			candidates = append(candidates, base+suffix)
+		}
+		return candidates
+
+	case ".py":
+		level := len(specifier) - len(strings.TrimLeft(specifier, "."))
+		module := strings.ReplaceAll(strings.TrimLeft(specifier, "."), ".", string(filepath.Separator))
+		bases := []string{filepath.Join(rootFolder, module)}
+		if level > 0 {
+			base := dir
+			for i := 1; i < level; i++ {
+				base = filepath.Dir(base)
+			}
+			bases = []string{filepath.Join(base, module)}
+		}
+		var candidates []string
+		for _, base := range bases {
+			candidates = append(candidates, base+".py", filepath.Join(base, "__init__.py"))
+		}
+		return candidates
+
+	case ".java", ".kt":
+		path := strings.ReplaceAll(specifier, ".", string(filepath.Separator))
+		var candidates []string
+		for _, root := range []string{rootFolder, filepath.Join(rootFolder, "src", "main", "java"), filepath.Join(rootFolder, "src", "main", "kotlin")} {
+			candidates = append(candidates, filepath.Join(root, path+".java"), filepath.Join(root, path+".kt"))
+		}
+		return candidates
+
+	case ".rb":
+		base := filepath.Join(dir, specifier)
+		return []string{base, base + ".rb"}
+
+	default:
+		return []string{filepath.Join(dir, specifier), filepath.Join(rootFolder, specifier)}
+	}
+}
@@ -0,0 +1,507 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/di"
+	"github.com/lakshyajain-0291/gitcury/interfaces"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Describe output formats
+const (
+	DescribePullRequest = "pr"
+	DescribeChangelog   = "changelog"
+)
+
+// RangeCommit is a commit in a described range
+type RangeCommit struct {
+	Hash    string `json:"hash"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Subject string `json:"subject"`
+	Body    string `json:"body,omitempty"`
+}
+
+// RangeFile is a file changed across a described range
+type RangeFile struct {
+	Path      string `json:"path"`
+	Status    string `json:"status"` // A, M, D, R...
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Diff      string `json:"-"`
+	Truncated bool   `json:"truncated,omitempty"` // Diff was cut or omitted to fit the prompt budget
+}
+
+// CommitRange holds the commits and changes between two refs
+type CommitRange struct {
+	Directory string        `json:"directory"`
+	From      string        `json:"from"`
+	To        string        `json:"to"`
+	Commits   []RangeCommit `json:"commits"`
+	Files     []RangeFile   `json:"files"`
+}
+
+// PRDescription is a generated pull request description
+type PRDescription struct {
+	Title      string `json:"title"`
+	Summary    string `json:"summary"`
+	Motivation string `json:"motivation"`
+	Risk       string `json:"risk"`
+	TestNotes  string `json:"testNotes"`
+}
+
+// ChangelogEntry is a generated Keep-a-Changelog entry
+type ChangelogEntry struct {
+	Version    string   `json:"version"`
+	Date       string   `json:"date,omitempty"`
+	Added      []string `json:"added,omitempty"`
+	Changed    []string `json:"changed,omitempty"`
+	Deprecated []string `json:"deprecated,omitempty"`
+	Removed    []string `json:"removed,omitempty"`
+	Fixed      []string `json:"fixed,omitempty"`
+	Security   []string `json:"security,omitempty"`
+}
+
+// RangeDescription is the result of describing a commit range
+type RangeDescription struct {
+	Format      string          `json:"format"`
+	Range       *CommitRange    `json:"range"`
+	PullRequest *PRDescription  `json:"pullRequest,omitempty"`
+	Changelog   *ChangelogEntry `json:"changelog,omitempty"`
+}
+
+// ParseRange splits "from..to" or "from...to" into its refs. A single ref means ref..HEAD.
+// With three dots the range starts at the merge base, like git diff A...B.
+func ParseRange(spec string) (string, string, bool, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return "", "", false, fmt.Errorf("empty range")
+	}
+
+	symmetric := strings.Contains(spec, "...")
+	separator := ".."
+	if symmetric {
+		separator = "..."
+	}
+
+	parts := strings.SplitN(spec, separator, 2)
+	if len(parts) == 1 {
+		return parts[0], "HEAD", false, nil
+	}
+	from, to := parts[0], parts[1]
+	if from == "" {
+		return "", "", false, fmt.Errorf("range %q has no start ref", spec)
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+	return from, to, symmetric, nil
+}
+
+// CollectRange gathers the commits and diffs of spec in dir. Diffs are trimmed so that the
+// commit messages and diffs together stay within budget characters.
+func CollectRange(dir, spec string, budget int) (*CommitRange, error) {
+	from, to, symmetric, err := ParseRange(spec)
+	if err != nil {
+		return nil, utils.NewValidationError(
+			"Invalid commit range",
+			err,
+			map[string]interface{}{
+				"range":      spec,
+				"suggestion": "Use <from>..<to>, e.g. main..HEAD or v1.2.0..v1.3.0",
+			},
+		)
+	}
+
+	for _, ref := range []string{from, to} {
+		if _, _, err := RunGitCmdWithOutput(dir, nil, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+			return nil, utils.NewValidationError(
+				"Unknown ref in commit range",
+				err,
+				map[string]interface{}{
+					"directory": dir,
+					"ref":       ref,
+				},
+			)
+		}
+	}
+
+	if symmetric {
+		base, err := RunGitCmd(dir, nil, "merge-base", from, to)
+		if err != nil {
+			return nil, utils.NewGitError(
+				"Failed to find the merge base of the range",
+				err,
+				map[string]interface{}{
+					"directory": dir,
+					"range":     spec,
+				},
+			)
+		}
+		from = strings.TrimSpace(base)
+	}
+
+	commitRange := &CommitRange{Directory: dir, From: from, To: to}
+
+	logOutput, err := RunGitCmd(dir, nil, "log", "--reverse", "--no-merges",
+		"--format=%h%x1f%an%x1f%ad%x1f%s%x1f%b%x1e", "--date=short", from+".."+to)
+	if err != nil {
+		return nil, utils.NewGitError(
+			"Failed to list commits in range",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+				"range":     spec,
+			},
+		)
+	}
+	for _, record := range strings.Split(logOutput, "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x1f", 5)
+		if len(fields) < 4 {
+			continue
+		}
+		commit := RangeCommit{Hash: fields[0], Author: fields[1], Date: fields[2], Subject: fields[3]}
+		if len(fields) == 5 {
+			commit.Body = strings.TrimSpace(fields[4])
+		}
+		commitRange.Commits = append(commitRange.Commits, commit)
+	}
+
+	if len(commitRange.Commits) == 0 {
+		return nil, utils.NewValidationError(
+			"No commits in range",
+			nil,
+			map[string]interface{}{
+				"directory": dir,
+				"range":     spec,
+			},
+		)
+	}
+
+	commitRange.Files, err = rangeFiles(dir, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := budget
+	for _, commit := range commitRange.Commits {
+		remaining -= len(commit.Subject) + len(commit.Body)
+	}
+	fillRangeDiffs(dir, from, to, commitRange.Files, remaining)
+
+	utils.Debug(fmt.Sprintf("[GIT.DESCRIBE]: %s..%s has %d commit(s) touching %d file(s)",
+		from, to, len(commitRange.Commits), len(commitRange.Files)))
+	return commitRange, nil
+}
+
+// rangeFiles lists the files changed between from and to with their line counts
+func rangeFiles(dir, from, to string) ([]RangeFile, error) {
+	nameStatus, err := RunGitCmd(dir, nil, "diff", "--name-status", "-M", from, to)
+	if err != nil {
+		return nil, utils.NewGitError(
+			"Failed to list changed files in range",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+				"from":      from,
+				"to":        to,
+			},
+		)
+	}
+
+	var files []RangeFile
+	index := make(map[string]int)
+	for _, line := range strings.Split(nameStatus, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		path := fields[len(fields)-1] // Renames list the old and new path
+		index[path] = len(files)
+		files = append(files, RangeFile{Path: path, Status: fields[0][:1]})
+	}
+
+	numstat, err := RunGitCmd(dir, nil, "diff", "--numstat", "-M", from, to)
+	if err == nil {
+		for _, line := range strings.Split(numstat, "\n") {
+			fields := strings.Split(strings.TrimSpace(line), "\t")
+			if len(fields) < 3 {
+				continue
+			}
+			path := fields[len(fields)-1]
+			if strings.Contains(path, " => ") {
+				// Renames are shown as old => new, possibly inside braces
+				path = renamedPath(path)
+			}
+			if i, ok := index[path]; ok {
+				files[i].Additions, _ = strconv.Atoi(fields[0]) // "-" for binary files
+				files[i].Deletions, _ = strconv.Atoi(fields[1])
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// renamedPath returns the new path of a numstat rename such as "dir/{old => new}/file.go"
+func renamedPath(path string) string {
+	if open := strings.Index(path, "{"); open >= 0 {
+		if end := strings.Index(path[open:], "}"); end >= 0 {
+			inner := path[open+1 : open+end]
+			parts := strings.SplitN(inner, " => ", 2)
+			newPath := path[:open] + parts[len(parts)-1] + path[open+end+1:]
+			return strings.ReplaceAll(newPath, "//", "/")
+		}
+	}
+	parts := strings.SplitN(path, " => ", 2)
+	return parts[len(parts)-1]
+}
+
+// fillRangeDiffs loads file diffs within budget characters. Smaller diffs are loaded first so
+// that the budget left over from them goes to larger files; each diff is also capped at the
+// per-file size used for commit messages.
+func fillRangeDiffs(dir, from, to string, files []RangeFile, budget int) {
+	order := make([]int, len(files))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		fa, fb := files[order[a]], files[order[b]]
+		return fa.Additions+fa.Deletions < fb.Additions+fb.Deletions
+	})
+
+	for position, i := range order {
+		file := &files[i]
+		left := len(order) - position
+		if budget <= 0 {
+			file.Truncated = true
+			continue
+		}
+
+		diff, err := RunGitCmd(dir, nil, "diff", "-M", from, to, "--", file.Path)
+		if err != nil {
+			utils.Debug("[GIT.DESCRIBE]: Could not diff " + file.Path + ": " + err.Error())
+			file.Truncated = true
+			continue
+		}
+		diff = sanitizeUTF8(diff)
+
+		limit := budget / left
+		if optimal := getOptimalDiffSize(file.Path); limit > optimal {
+			limit = optimal
+		}
+		if len(diff) > limit {
+			diff = truncateAtRune(diff, limit) + "... [truncated]"
+			file.Truncated = true
+		}
+		file.Diff = diff
+		budget -= len(diff)
+	}
+}
+
+const describeBaseInstruction = `
+	You describe a range of git commits for reviewers and release notes.
+	Base every statement on the commit messages and diffs provided; do not invent changes.
+	Be concise and concrete. Write in plain English using Markdown only inside string values.
+	Return only JSON matching the requested schema.
+	`
+
+const describePullRequestSchema = `
+	Write a pull request description as JSON with these string keys:
+	• "title": imperative summary, at most 72 characters
+	• "summary": what changes, as a short paragraph or bullet list
+	• "motivation": why the change is needed
+	• "risk": what could break, affected areas, migration or rollout concerns
+	• "testNotes": how the change was or should be tested
+	`
+
+const describeChangelogSchema = `
+	Write a Keep a Changelog entry as JSON with the keys "added", "changed", "deprecated",
+	"removed", "fixed" and "security". Each key holds an array of short user-facing entries;
+	omit internal refactors and use an empty array when a section has no entries.
+	`
+
+// BuildDescribePrompt renders the system instruction and prompt for describing commitRange
+func BuildDescribePrompt(commitRange *CommitRange, format, instructions string) interfaces.GenerationRequest {
+	systemInstruction := describeBaseInstruction
+	if format == DescribeChangelog {
+		systemInstruction += describeChangelogSchema
+	} else {
+		systemInstruction += describePullRequestSchema
+	}
+	if instructions != "" {
+		systemInstruction += "\n\tADDITIONAL INSTRUCTIONS FROM USER:\n\t" + utils.SanitizeUserInstructions(instructions) + "\n"
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(fmt.Sprintf("Commits from %s to %s:\n\n", commitRange.From, commitRange.To))
+	for _, commit := range commitRange.Commits {
+		prompt.WriteString(fmt.Sprintf("- %s %s\n", commit.Hash, commit.Subject))
+		if commit.Body != "" {
+			prompt.WriteString("  " + strings.ReplaceAll(commit.Body, "\n", "\n  ") + "\n")
+		}
+	}
+
+	prompt.WriteString("\nChanged files:\n\n")
+	for _, file := range commitRange.Files {
+		prompt.WriteString(fmt.Sprintf("File: %s\nStatus: %s (+%d -%d)\n", file.Path, file.Status, file.Additions, file.Deletions))
+		if file.Diff != "" {
+			prompt.WriteString("Diff:\n" + file.Diff + "\n")
+		} else if file.Truncated {
+			prompt.WriteString("Diff: [omitted to fit the prompt budget]\n")
+		}
+		prompt.WriteString("\n")
+	}
+
+	return interfaces.GenerationRequest{
+		SystemInstruction: systemInstruction,
+		Prompt:            prompt.String(),
+		JSON:              true,
+	}
+}
+
+// DescribeRange asks the model for a pull request description or changelog entry of spec in dir
+func DescribeRange(dir, spec, format, version string) (*RangeDescription, error) {
+	if format != DescribePullRequest && format != DescribeChangelog {
+		return nil, utils.NewValidationError(
+			"Unknown describe format",
+			nil,
+			map[string]interface{}{
+				"format":     format,
+				"suggestion": "Use --format pr or --format changelog",
+			},
+		)
+	}
+
+	describeConfig := config.GetDescribeConfig(dir)
+	commitRange, err := CollectRange(dir, spec, describeConfig.MaxPromptChars)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := geminiAPIKey()
+	if err != nil {
+		return nil, utils.NewConfigError(
+			"Gemini API key not found",
+			err,
+			map[string]interface{}{
+				"suggestion": "gitcury config set --key GEMINI_API_KEY --value YOUR_API_KEY_HERE",
+			},
+		)
+	}
+
+	request := BuildDescribePrompt(commitRange, format, describeConfig.Instructions)
+	request.MaxOutputTokens = int32(describeConfig.MaxOutputTokens)
+
+	runner := di.GetGeminiRunner()
+	var response string
+	if runner != nil {
+		response, err = runner.GenerateText(request, apiKey)
+	} else {
+		response, err = utils.GenerateText(request, apiKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	description := &RangeDescription{Format: format, Range: commitRange}
+	payload := []byte(stripCodeFence(response))
+	if format == DescribeChangelog {
+		entry := &ChangelogEntry{}
+		err = json.Unmarshal(payload, entry)
+		entry.Version = version
+		entry.Date = time.Now().Format("2006-01-02")
+		if version == "" {
+			entry.Version = "Unreleased"
+			entry.Date = ""
+		}
+		description.Changelog = entry
+	} else {
+		description.PullRequest = &PRDescription{}
+		err = json.Unmarshal(payload, description.PullRequest)
+	}
+	if err != nil {
+		return nil, utils.NewAPIError(
+			"The model returned an unexpected description format",
+			err,
+			map[string]interface{}{
+				"format":   format,
+				"response": response,
+			},
+		)
+	}
+
+	return description, nil
+}
+
+// stripCodeFence removes a Markdown code fence the model may wrap JSON in
+func stripCodeFence(response string) string {
+	trimmed := strings.TrimSpace(response)
+	if !strings.HasPrefix(trimmed, "```") {
+		return trimmed
+	}
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	if newline := strings.Index(trimmed, "\n"); newline >= 0 {
+		trimmed = trimmed[newline+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(trimmed), "```"))
+}
+
+// Markdown renders the description as Markdown
+func (d *RangeDescription) Markdown() string {
+	var builder strings.Builder
+
+	if d.Changelog != nil {
+		entry := d.Changelog
+		if entry.Date != "" {
+			builder.WriteString(fmt.Sprintf("## [%s] - %s\n", entry.Version, entry.Date))
+		} else {
+			builder.WriteString(fmt.Sprintf("## [%s]\n", entry.Version))
+		}
+		sections := []struct {
+			name    string
+			entries []string
+		}{
+			{"Added", entry.Added},
+			{"Changed", entry.Changed},
+			{"Deprecated", entry.Deprecated},
+			{"Removed", entry.Removed},
+			{"Fixed", entry.Fixed},
+			{"Security", entry.Security},
+		}
+		for _, section := range sections {
+			if len(section.entries) == 0 {
+				continue
+			}
+			builder.WriteString("\n### " + section.name + "\n\n")
+			for _, item := range section.entries {
+				builder.WriteString("- " + item + "\n")
+			}
+		}
+		return builder.String()
+	}
+
+	if d.PullRequest != nil {
+		pr := d.PullRequest
+		builder.WriteString("# " + pr.Title + "\n")
+		sections := []struct{ name, text string }{
+			{"Summary", pr.Summary},
+			{"Motivation", pr.Motivation},
+			{"Risk", pr.Risk},
+			{"Test notes", pr.TestNotes},
+		}
+		for _, section := range sections {
+			if strings.TrimSpace(section.text) == "" {
+				continue
+			}
+			builder.WriteString("\n## " + section.name + "\n\n" + strings.TrimSpace(section.text) + "\n")
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,259 @@
+package git
+
+import "strings"
+
+// BuiltinEvalFixtures are small change sets of typical shapes, evaluated when no fixture files
+// are given
+func BuiltinEvalFixtures() []EvalFixture {
+	return []EvalFixture{
+		{
+			Name:        "feature-docs-deps",
+			Description: "A feature with its test, a documentation update and a dependency bump",
+			Changes: []FixtureChange{
+				{Path: "ratelimit/limiter.go", Group: "feat: add a token bucket rate limiter", After: fixtureText(
+					"package ratelimit",
+					"",
+					"// Limiter allows rate requests per second with bursts of up to burst",
+					"type Limiter struct {",
+					"\trate, burst, tokens float64",
+					"}",
+					"",
+					"func NewLimiter(rate, burst float64) *Limiter {",
+					"\treturn &Limiter{rate: rate, burst: burst, tokens: burst}",
+					"}",
+					"",
+					"func (l *Limiter) Allow() bool {",
+					"\tif l.tokens < 1 {",
+					"\t\treturn false",
+					"\t}",
+					"\tl.tokens--",
+					"\treturn true",
+					"}")},
+				{Path: "ratelimit/limiter_test.go", Group: "feat: add a token bucket rate limiter", After: fixtureText(
+					"package ratelimit",
+					"",
+					"import \"testing\"",
+					"",
+					"func TestLimiterAllowsBurst(t *testing.T) {",
+					"\tlimiter := NewLimiter(1, 2)",
+					"\tif !limiter.Allow() || !limiter.Allow() || limiter.Allow() {",
+					"\t\tt.Fatal(\"expected a burst of two requests\")",
+					"\t}",
+					"}")},
+				{Path: "server/middleware.go", Group: "feat: add a token bucket rate limiter", Before: fixtureText(
+					"package server",
+					"",
+					"import \"net/http\"",
+					"",
+					"func withLogging(next http.Handler) http.Handler {",
+					"\treturn next",
+					"}"), After: fixtureText(
+					"package server",
+					"",
+					"import (",
+					"\t\"net/http\"",
+					"",
+					"\t\"example.com/app/ratelimit\"",
+					")",
+					"",
+					"func withLogging(next http.Handler) http.Handler {",
+					"\treturn next",
+					"}",
+					"",
+					"func withRateLimit(limiter *ratelimit.Limiter, next http.Handler) http.Handler {",
+					"\treturn http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {",
+					"\t\tif !limiter.Allow() {",
+					"\t\t\thttp.Error(w, \"too many requests\", http.StatusTooManyRequests)",
+					"\t\t\treturn",
+					"\t\t}",
+					"\t\tnext.ServeHTTP(w, r)",
+					"\t})",
+					"}")},
+				{Path: "README.md", Group: "docs: describe the configuration file", Before: fixtureText(
+					"# App",
+					"",
+					"Run `app serve` to start the server."), After: fixtureText(
+					"# App",
+					"",
+					"Run `app serve` to start the server.",
+					"",
+					"See [configuration](docs/configuration.md) for the settings in `app.yaml`.")},
+				{Path: "docs/configuration.md", Group: "docs: describe the configuration file", After: fixtureText(
+					"# Configuration",
+					"",
+					"The server reads `app.yaml` from the working directory.",
+					"",
+					"- `listen`: address to listen on, `:8080` by default",
+					"- `log_level`: one of debug, info, warn and error")},
+				{Path: "go.mod", Group: "chore: update dependencies", Before: fixtureText(
+					"module example.com/app",
+					"",
+					"go 1.22",
+					"",
+					"require golang.org/x/net v0.20.0"), After: fixtureText(
+					"module example.com/app",
+					"",
+					"go 1.22",
+					"",
+					"require golang.org/x/net v0.24.0")},
+				{Path: "go.sum", Group: "chore: update dependencies", Before: fixtureText(
+					"golang.org/x/net v0.20.0 h1:aCL9BSgETF1k+blQaYUBx9hJ9LOGP3gAVemcZlf1Kpo=",
+					"golang.org/x/net v0.20.0/go.mod h1:z8BVo6PvndSri0LbOE3hAn0apkU+1YvI6E70E9jsnvY="), After: fixtureText(
+					"golang.org/x/net v0.24.0 h1:1PcaxkF854Fu3+lvBIx5SYn9wRlBzzcnHZSiaFFAb0w=",
+					"golang.org/x/net v0.24.0/go.mod h1:2Q7sJY5mzlzWjKtYUEXSlBWCdyaioyXzRB2RtU8KVE8=")},
+			},
+		},
+		{
+			Name:        "rename-across-layers",
+			Description: "A rename through the API and store layers next to an unrelated logging fix",
+			History: [][]string{
+				{"api/users.go", "store/users.go"},
+				{"api/users.go", "api/users_test.go", "store/users.go"},
+				{"logging/logger.go", "logging/logger_test.go"},
+			},
+			Changes: []FixtureChange{
+				{Path: "api/users.go", Group: "refactor: rename FetchUser to LoadUser", Before: fixtureText(
+					"package api",
+					"",
+					"func (h *Handler) user(id string) (*User, error) {",
+					"\treturn h.store.FetchUser(id)",
+					"}"), After: fixtureText(
+					"package api",
+					"",
+					"func (h *Handler) user(id string) (*User, error) {",
+					"\treturn h.store.LoadUser(id)",
+					"}")},
+				{Path: "api/users_test.go", Group: "refactor: rename FetchUser to LoadUser", Before: fixtureText(
+					"package api",
+					"",
+					"func (s *fakeStore) FetchUser(id string) (*User, error) {",
+					"\treturn s.users[id], nil",
+					"}"), After: fixtureText(
+					"package api",
+					"",
+					"func (s *fakeStore) LoadUser(id string) (*User, error) {",
+					"\treturn s.users[id], nil",
+					"}")},
+				{Path: "store/users.go", Group: "refactor: rename FetchUser to LoadUser", Before: fixtureText(
+					"package store",
+					"",
+					"// FetchUser reads a user by id",
+					"func (s *Store) FetchUser(id string) (*User, error) {",
+					"\treturn s.get(\"users\", id)",
+					"}"), After: fixtureText(
+					"package store",
+					"",
+					"// LoadUser reads a user by id",
+					"func (s *Store) LoadUser(id string) (*User, error) {",
+					"\treturn s.get(\"users\", id)",
+					"}")},
+				{Path: "logging/logger.go", Group: "fix: include the level in log lines", Before: fixtureText(
+					"package logging",
+					"",
+					"import \"fmt\"",
+					"",
+					"func format(level, message string) string {",
+					"\treturn fmt.Sprintf(\"%s\", message)",
+					"}"), After: fixtureText(
+					"package logging",
+					"",
+					"import \"fmt\"",
+					"",
+					"func format(level, message string) string {",
+					"\treturn fmt.Sprintf(\"[%s] %s\", level, message)",
+					"}")},
+				{Path: "logging/logger_test.go", Group: "fix: include the level in log lines", Before: fixtureText(
+					"package logging",
+					"",
+					"import \"testing\"",
+					"",
+					"func TestFormat(t *testing.T) {",
+					"\tif format(\"info\", \"started\") != \"started\" {",
+					"\t\tt.Fatal(\"unexpected format\")",
+					"\t}",
+					"}"), After: fixtureText(
+					"package logging",
+					"",
+					"import \"testing\"",
+					"",
+					"func TestFormat(t *testing.T) {",
+					"\tif format(\"info\", \"started\") != \"[info] started\" {",
+					"\t\tt.Fatal(\"unexpected format\")",
+					"\t}",
+					"}")},
+			},
+		},
+		{
+			Name:        "frontend-backend-ci",
+			Description: "A login form, an unrelated session fix on the server and a CI change",
+			History: [][]string{
+				{"web/src/Login.tsx", "web/src/login.css"},
+				{"server/session.go", "server/session_test.go"},
+			},
+			Changes: []FixtureChange{
+				{Path: "web/src/Login.tsx", Group: "feat: show password rules on the login form", Before: fixtureText(
+					"export function Login() {",
+					"  return <form className=\"login\"><input type=\"password\" /></form>;",
+					"}"), After: fixtureText(
+					"export function Login() {",
+					"  return (",
+					"    <form className=\"login\">",
+					"      <input type=\"password\" />",
+					"      <p className=\"login-rules\">At least 12 characters</p>",
+					"    </form>",
+					"  );",
+					"}")},
+				{Path: "web/src/login.css", Group: "feat: show password rules on the login form", Before: fixtureText(
+					".login {",
+					"  display: flex;",
+					"}"), After: fixtureText(
+					".login {",
+					"  display: flex;",
+					"}",
+					"",
+					".login-rules {",
+					"  color: #666;",
+					"  font-size: 0.8rem;",
+					"}")},
+				{Path: "server/session.go", Group: "fix: expire sessions after the idle timeout", Before: fixtureText(
+					"package server",
+					"",
+					"func (s *Session) Expired(now time.Time) bool {",
+					"\treturn now.After(s.Created.Add(s.MaxAge))",
+					"}"), After: fixtureText(
+					"package server",
+					"",
+					"func (s *Session) Expired(now time.Time) bool {",
+					"\treturn now.After(s.Created.Add(s.MaxAge)) || now.After(s.LastSeen.Add(s.IdleTimeout))",
+					"}")},
+				{Path: "server/session_test.go", Group: "fix: expire sessions after the idle timeout", Before: fixtureText(
+					"package server",
+					"",
+					"func TestSessionExpires(t *testing.T) {}"), After: fixtureText(
+					"package server",
+					"",
+					"func TestSessionExpires(t *testing.T) {}",
+					"",
+					"func TestIdleSessionExpires(t *testing.T) {",
+					"\tsession := &Session{LastSeen: epoch, IdleTimeout: time.Minute, MaxAge: time.Hour}",
+					"\tif !session.Expired(epoch.Add(2 * time.Minute)) {",
+					"\t\tt.Fatal(\"idle session did not expire\")",
+					"\t}",
+					"}")},
+				{Path: ".github/workflows/ci.yml", Group: "ci: run the tests on pull requests", Before: fixtureText(
+					"on:",
+					"  push:",
+					"    branches: [main]"), After: fixtureText(
+					"on:",
+					"  push:",
+					"    branches: [main]",
+					"  pull_request:")},
+			},
+		},
+	}
+}
+
+func fixtureText(lines ...string) *string {
+	text := strings.Join(lines, "\n") + "\n"
+	return &text
+}
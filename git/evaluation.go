@@ -0,0 +1,651 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EvalFixture is a change set together with the commits a person grouped it into, for measuring
+// how well clustering recovers that grouping
+type EvalFixture struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Source      string          `json:"source,omitempty"`  // Repository and range a captured fixture was replayed from
+	History     [][]string      `json:"history,omitempty"` // Files of earlier commits, oldest first, replayed for co-change history
+	Changes     []FixtureChange `json:"changes"`
+}
+
+// FixtureChange is one changed file of a fixture
+type FixtureChange struct {
+	Path   string  `json:"path"`             // Relative to the repository root, with forward slashes
+	Before *string `json:"before,omitempty"` // Content before the change; absent for added files
+	After  *string `json:"after,omitempty"`  // Content after the change; absent for deleted files
+	Group  string  `json:"group"`            // The ideal commit of the file
+}
+
+// EvalOptions selects what RunEvaluation measures
+type EvalOptions struct {
+	Methods []string // Clustering methods run on their own
+	Presets []string // Presets run through the multi-layered clustering
+	Backend string   // Embedding backend of the semantic methods; empty keeps the configured one
+	Target  int      // Number of clusters requested; 0 lets clustering decide, as getmsgs --group does
+}
+
+// EvalResult is how one strategy grouped one fixture
+type EvalResult struct {
+	Fixture      string        `json:"fixture"`
+	Strategy     string        `json:"strategy"` // A method name or "preset:<name>"
+	Files        int           `json:"files"`
+	IdealCommits int           `json:"idealCommits"`
+	Commits      int           `json:"commits"`
+	ARI          float64       `json:"adjustedRandIndex"`
+	Purity       float64       `json:"purity"`
+	Duration     time.Duration `json:"duration"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// EvalSummary averages the results of one strategy over the fixtures it grouped
+type EvalSummary struct {
+	Strategy    string        `json:"strategy"`
+	Fixtures    int           `json:"fixtures"`
+	Failures    int           `json:"failures"`
+	MeanARI     float64       `json:"meanAdjustedRandIndex"`
+	MeanPurity  float64       `json:"meanPurity"`
+	CommitError float64       `json:"meanCommitError"` // Mean absolute difference from the ideal number of commits
+	Duration    time.Duration `json:"duration"`
+}
+
+// EvalReport holds the results of RunEvaluation
+type EvalReport struct {
+	Backend   string        `json:"backend"`
+	Results   []EvalResult  `json:"results"`
+	Summaries []EvalSummary `json:"summaries"`
+}
+
+// EvalMethods are the clustering methods RunEvaluation runs on their own. Semantic runs before
+// cached, so the cached method finds what semantic clustering stored.
+var EvalMethods = []string{
+	string(config.DirectoryMethod),
+	string(config.PatternMethod),
+	string(config.DependencyMethod),
+	string(config.HistoryMethod),
+	string(config.SemanticMethod),
+	string(config.CachedMethod),
+}
+
+// Captured files larger than this are left out of fixtures
+const maxFixtureFileSize = 256 * 1024
+
+// evalStrategy is a clustering configuration and the method to run with it; an empty method runs
+// the multi-layered clustering
+type evalStrategy struct {
+	name     string
+	method   string
+	settings *config.ClusteringConfig
+}
+
+// RunEvaluation groups the changes of each fixture with every method and preset of options (all
+// of them when neither is given) and scores the grouping against the fixture's ideal commits.
+// Fixtures are set up in temporary repositories, so the configured root folders are not touched.
+func RunEvaluation(fixtures []EvalFixture, options EvalOptions) (*EvalReport, error) {
+	if len(fixtures) == 0 {
+		return nil, utils.NewValidationError("No fixtures to evaluate", nil, nil)
+	}
+	strategies, backend, err := evalStrategies(options)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &EvalReport{Backend: backend}
+	for _, fixture := range fixtures {
+		root, files, truth, err := materializeFixture(fixture)
+		if err != nil {
+			return nil, err
+		}
+		utils.Debug(fmt.Sprintf("[GIT.EVAL]: Fixture %s: %d files in %d commits at %s",
+			fixture.Name, len(files), countLabels(truth), root))
+
+		for _, strategy := range strategies {
+			report.Results = append(report.Results, runEvalStrategy(fixture.Name, root, files, truth, strategy, options.Target))
+		}
+		removeFixtureRepository(root)
+	}
+
+	report.Summaries = summarizeEvaluation(report.Results, strategies)
+	return report, nil
+}
+
+// evalStrategies resolves the methods and presets of options to clustering configurations that
+// embed with the requested backend
+func evalStrategies(options EvalOptions) ([]evalStrategy, string, error) {
+	methods, presets := options.Methods, options.Presets
+	if len(methods) == 0 && len(presets) == 0 {
+		methods, presets = EvalMethods, config.ClusteringPresets
+	}
+
+	current := config.GetClusteringConfig().Methods.Semantic
+	backend := options.Backend
+	if backend == "" {
+		backend = current.Backend
+	}
+	switch backend {
+	case config.GeminiEmbeddingBackend, config.LocalEmbeddingBackend, config.HTTPEmbeddingBackend:
+	default:
+		return nil, "", utils.NewValidationError(
+			"Unknown embedding backend",
+			nil,
+			map[string]interface{}{
+				"backend": backend,
+				"valid":   []string{config.GeminiEmbeddingBackend, config.LocalEmbeddingBackend, config.HTTPEmbeddingBackend},
+			},
+		)
+	}
+
+	var strategies []evalStrategy
+	for _, method := range methods {
+		settings := config.GetClusteringConfig()
+		if !enableMethod(settings, method) {
+			return nil, "", utils.NewValidationError(
+				"Unknown clustering method",
+				nil,
+				map[string]interface{}{
+					"method": method,
+					"valid":  EvalMethods,
+				},
+			)
+		}
+		strategies = append(strategies, evalStrategy{name: method, method: method, settings: settings})
+	}
+	for _, preset := range presets {
+		settings, err := config.GetClusteringPreset(preset)
+		if err != nil {
+			return nil, "", utils.NewValidationError(
+				"Unknown clustering preset",
+				err,
+				map[string]interface{}{
+					"preset": preset,
+					"valid":  config.ClusteringPresets,
+				},
+			)
+		}
+		strategies = append(strategies, evalStrategy{name: "preset:" + preset, settings: settings})
+	}
+
+	// Presets start from the defaults; every strategy embeds the same way
+	for _, strategy := range strategies {
+		semantic := &strategy.settings.Methods.Semantic
+		semantic.Backend = backend
+		semantic.Endpoint, semantic.Model, semantic.Dimensions = current.Endpoint, current.Model, current.Dimensions
+	}
+	return strategies, backend, nil
+}
+
+// enableMethod turns method on in settings, reporting false for an unknown method
+func enableMethod(settings *config.ClusteringConfig, method string) bool {
+	switch config.ClusteringMethod(method) {
+	case config.DirectoryMethod:
+		settings.Methods.Directory.Enabled = true
+	case config.PatternMethod:
+		settings.Methods.Pattern.Enabled = true
+	case config.DependencyMethod:
+		settings.Methods.Dependency.Enabled = true
+	case config.HistoryMethod:
+		settings.Methods.History.Enabled = true
+	case config.CachedMethod:
+		settings.Methods.Cached.Enabled = true
+	case config.SemanticMethod:
+		settings.Methods.Semantic.Enabled = true
+	default:
+		return false
+	}
+	return true
+}
+
+// runEvalStrategy clusters the files of a materialized fixture with one strategy and scores the
+// result
+func runEvalStrategy(fixture, root string, files []string, truth []int, strategy evalStrategy, target int) EvalResult {
+	config.OverrideClusteringConfig(strategy.settings)
+	defer config.OverrideClusteringConfig(nil)
+
+	result := EvalResult{Fixture: fixture, Strategy: strategy.name, Files: len(files), IdealCommits: countLabels(truth)}
+	startTime := time.Now()
+	var clusters []FileCluster
+	var err error
+	if strategy.method != "" {
+		clusters, err = executeSpecificMethod(files, root, target, strategy.method, false)
+	} else {
+		clusters, err = SmartClusterFiles(files, root, target)
+	}
+	result.Duration = time.Since(startTime)
+	if err != nil {
+		utils.Warning(fmt.Sprintf("[GIT.EVAL]: %s failed on %s: %v", strategy.name, fixture, err))
+		result.Error = err.Error()
+		return result
+	}
+
+	labels := clusterLabels(files, ClusterFileLists(clusters))
+	result.Commits = countLabels(labels)
+	result.ARI = AdjustedRandIndex(labels, truth)
+	result.Purity = labelPurity(labels, truth)
+	return result
+}
+
+// clusterLabels returns the cluster of each file; files missing from the clusters get one each
+func clusterLabels(files []string, clusters [][]string) []int {
+	clusterOf := make(map[string]int)
+	for label, cluster := range clusters {
+		for _, file := range cluster {
+			clusterOf[file] = label
+		}
+	}
+	labels := make([]int, len(files))
+	next := len(clusters)
+	for i, file := range files {
+		if label, ok := clusterOf[file]; ok {
+			labels[i] = label
+		} else {
+			labels[i] = next
+			next++
+		}
+	}
+	return labels
+}
+
+// AdjustedRandIndex compares two groupings of the same files: 1 when they are identical, about 0
+// for an unrelated grouping and negative for one worse than chance
+func AdjustedRandIndex(labels, truth []int) float64 {
+	pairs := func(count int) float64 { return float64(count) * float64(count-1) / 2 }
+
+	joint := make(map[[2]int]int)
+	byLabel := make(map[int]int)
+	byTruth := make(map[int]int)
+	for i := range labels {
+		joint[[2]int{labels[i], truth[i]}]++
+		byLabel[labels[i]]++
+		byTruth[truth[i]]++
+	}
+
+	index, labelPairs, truthPairs := 0.0, 0.0, 0.0
+	for _, count := range joint {
+		index += pairs(count)
+	}
+	for _, count := range byLabel {
+		labelPairs += pairs(count)
+	}
+	for _, count := range byTruth {
+		truthPairs += pairs(count)
+	}
+
+	total := pairs(len(labels))
+	if total == 0 {
+		return 1
+	}
+	expected := labelPairs * truthPairs / total
+	maximum := (labelPairs + truthPairs) / 2
+	if maximum == expected {
+		return 1 // Both groupings are all singletons or a single group
+	}
+	return (index - expected) / (maximum - expected)
+}
+
+// summarizeEvaluation averages the results per strategy, in the order the strategies ran
+func summarizeEvaluation(results []EvalResult, strategies []evalStrategy) []EvalSummary {
+	summaries := make([]EvalSummary, 0, len(strategies))
+	for _, strategy := range strategies {
+		summary := EvalSummary{Strategy: strategy.name}
+		for _, result := range results {
+			if result.Strategy != strategy.name {
+				continue
+			}
+			summary.Fixtures++
+			summary.Duration += result.Duration
+			if result.Error != "" {
+				summary.Failures++
+				continue
+			}
+			summary.MeanARI += result.ARI
+			summary.MeanPurity += result.Purity
+			difference := result.Commits - result.IdealCommits
+			if difference < 0 {
+				difference = -difference
+			}
+			summary.CommitError += float64(difference)
+		}
+		if scored := summary.Fixtures - summary.Failures; scored > 0 {
+			summary.MeanARI /= float64(scored)
+			summary.MeanPurity /= float64(scored)
+			summary.CommitError /= float64(scored)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// materializeFixture creates a repository with the fixture's history and before state committed
+// and its after state in the working tree. It returns the repository, the changed files as
+// absolute paths and the ideal commit of each.
+func materializeFixture(fixture EvalFixture) (string, []string, []int, error) {
+	if err := validateFixture(fixture); err != nil {
+		return "", nil, nil, err
+	}
+	root, err := os.MkdirTemp("", "gitcury-eval-")
+	if err != nil {
+		return "", nil, nil, utils.NewSystemError("Failed to create a fixture repository", err, map[string]interface{}{"fixture": fixture.Name})
+	}
+	fail := func(err error) (string, []string, []int, error) {
+		os.RemoveAll(root) //nolint:errcheck
+		return "", nil, nil, utils.NewSystemError(
+			"Failed to set up the fixture repository",
+			err,
+			map[string]interface{}{
+				"fixture": fixture.Name,
+			},
+		)
+	}
+	commit := func(message string, env map[string]string) error {
+		if _, err := RunGitCmd(root, nil, "add", "-A"); err != nil {
+			return err
+		}
+		_, err := RunGitCmd(root, env, "-c", "user.name=GitCury Eval", "-c", "user.email=eval@gitcury.invalid",
+			"-c", "commit.gpgsign=false", "commit", "-q", "--no-verify", "--allow-empty", "-m", message)
+		return err
+	}
+
+	if _, err := RunGitCmd(root, nil, "init", "-q"); err != nil {
+		return fail(err)
+	}
+	for i, files := range fixture.History {
+		for _, file := range files {
+			if err := writeFixtureFile(root, file, "history "+strconv.Itoa(i+1)+"\n"); err != nil {
+				return fail(err)
+			}
+		}
+		if err := commit("history "+strconv.Itoa(i+1), nil); err != nil {
+			return fail(err)
+		}
+	}
+
+	// The base commit holds exactly the before state
+	if len(fixture.History) > 0 {
+		if _, err := RunGitCmd(root, nil, "rm", "-rqf", "--ignore-unmatch", "."); err != nil {
+			return fail(err)
+		}
+	}
+	for _, change := range fixture.Changes {
+		if change.Before != nil {
+			if err := writeFixtureFile(root, change.Path, *change.Before); err != nil {
+				return fail(err)
+			}
+		}
+	}
+	// Co-change history decays with age; dated decades ago, the base commit does not count as
+	// every file changing together
+	baseDate := map[string]string{"GIT_AUTHOR_DATE": "2000-01-01T00:00:00Z", "GIT_COMMITTER_DATE": "2000-01-01T00:00:00Z"}
+	if err := commit("base", baseDate); err != nil {
+		return fail(err)
+	}
+
+	groups := make(map[string]int)
+	files := make([]string, 0, len(fixture.Changes))
+	truth := make([]int, 0, len(fixture.Changes))
+	for _, change := range fixture.Changes {
+		path := filepath.Join(root, filepath.FromSlash(change.Path))
+		if change.After != nil {
+			if err := writeFixtureFile(root, change.Path, *change.After); err != nil {
+				return fail(err)
+			}
+		} else if err := os.Remove(path); err != nil {
+			return fail(err)
+		}
+		if _, ok := groups[change.Group]; !ok {
+			groups[change.Group] = len(groups)
+		}
+		files = append(files, path)
+		truth = append(truth, groups[change.Group])
+	}
+
+	// Refresh the status cache used to detect deleted files
+	if _, err := GetAllChangedFiles(root); err != nil {
+		return fail(err)
+	}
+	return root, files, truth, nil
+}
+
+func writeFixtureFile(root, path, content string) error {
+	local := filepath.FromSlash(path)
+	if !filepath.IsLocal(local) {
+		return fmt.Errorf("fixture path %q is outside the repository", path)
+	}
+	target := filepath.Join(root, local)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(target, []byte(content), 0644) //nolint:gosec // Fixture files are ordinary source files
+}
+
+// removeFixtureRepository deletes a materialized fixture and the file embeddings stored for it
+func removeFixtureRepository(root string) {
+	if store, err := OpenEmbeddingStore(); err == nil {
+		if keys, err := store.Keys(rootStoreKey(root)); err == nil && len(keys) > 0 {
+			if err := store.Delete(keys...); err != nil {
+				utils.Debug("[GIT.EVAL]: Could not remove fixture embeddings: " + err.Error())
+			}
+		}
+	}
+	if err := os.RemoveAll(root); err != nil {
+		utils.Debug("[GIT.EVAL]: Could not remove fixture repository " + root + ": " + err.Error())
+	}
+}
+
+func validateFixture(fixture EvalFixture) error {
+	invalid := func(reason string, details map[string]interface{}) error {
+		details["fixture"] = fixture.Name
+		return utils.NewValidationError("Invalid fixture: "+reason, nil, details)
+	}
+	if len(fixture.Changes) == 0 {
+		return invalid("it has no changes", map[string]interface{}{})
+	}
+	seen := make(map[string]bool)
+	for _, change := range fixture.Changes {
+		switch {
+		case change.Path == "" || !filepath.IsLocal(filepath.FromSlash(change.Path)):
+			return invalid("a change has no path inside the repository", map[string]interface{}{"path": change.Path})
+		case seen[change.Path]:
+			return invalid("a file is changed twice", map[string]interface{}{"path": change.Path})
+		case change.Group == "":
+			return invalid("a change has no group", map[string]interface{}{"path": change.Path})
+		case change.Before == nil && change.After == nil:
+			return invalid("a change has neither before nor after content", map[string]interface{}{"path": change.Path})
+		}
+		seen[change.Path] = true
+	}
+	return nil
+}
+
+// LoadEvalFixtures reads fixtures from JSON files and from the *.json files of directories
+func LoadEvalFixtures(paths ...string) ([]EvalFixture, error) {
+	var fixtures []EvalFixture
+	for _, path := range paths {
+		files := []string{path}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			files, _ = filepath.Glob(filepath.Join(path, "*.json"))
+			sort.Strings(files)
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file) //nolint:gosec // Fixture paths are given by the user
+			if err != nil {
+				return nil, utils.NewSystemError("Failed to read fixture", err, map[string]interface{}{"path": file})
+			}
+			var fixture EvalFixture
+			if err := json.Unmarshal(data, &fixture); err != nil {
+				return nil, utils.NewValidationError("Failed to parse fixture", err, map[string]interface{}{"path": file})
+			}
+			if fixture.Name == "" {
+				fixture.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			}
+			if err := validateFixture(fixture); err != nil {
+				return nil, err
+			}
+			fixtures = append(fixtures, fixture)
+		}
+	}
+	return fixtures, nil
+}
+
+// SaveEvalFixture writes fixture as indented JSON
+func SaveEvalFixture(fixture *EvalFixture, path string) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return utils.NewSystemError("Failed to encode fixture", err, map[string]interface{}{"fixture": fixture.Name})
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return utils.NewSystemError("Failed to create fixture directory", err, map[string]interface{}{"path": dir})
+		}
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil { //nolint:gosec // Fixtures are meant to be shared
+		return utils.NewSystemError("Failed to write fixture", err, map[string]interface{}{"path": path})
+	}
+	return nil
+}
+
+// CaptureEvalFixture replays the commits of spec in dir as one combined change. Each file's
+// ideal group is the last commit of the range that changed it. Up to historyCommits commits
+// before the range, small enough to count as co-changes, are kept as history.
+func CaptureEvalFixture(dir, spec string, historyCommits int) (*EvalFixture, error) {
+	from, to, symmetric, err := ParseRange(spec)
+	if err != nil {
+		return nil, utils.NewValidationError(
+			"Invalid commit range",
+			err,
+			map[string]interface{}{
+				"range":      spec,
+				"suggestion": "Use <from>..<to>, e.g. HEAD~5..HEAD or v1.2.0..v1.3.0",
+			},
+		)
+	}
+	for _, ref := range []string{from, to} {
+		if _, _, err := RunGitCmdWithOutput(dir, nil, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+			return nil, utils.NewValidationError(
+				"Unknown ref in commit range",
+				err,
+				map[string]interface{}{
+					"directory": dir,
+					"ref":       ref,
+				},
+			)
+		}
+	}
+	if symmetric {
+		base, err := RunGitCmd(dir, nil, "merge-base", from, to)
+		if err != nil {
+			return nil, utils.NewGitError("Failed to find the merge base of the range", err, map[string]interface{}{"directory": dir, "range": spec})
+		}
+		from = strings.TrimSpace(base)
+	}
+
+	logOutput, err := RunGitCmd(dir, nil, "log", "--reverse", "--no-merges", "--no-renames", "--name-only",
+		"--format=%x1e%h %s", from+".."+to)
+	if err != nil {
+		return nil, utils.NewGitError("Failed to list commits in range", err, map[string]interface{}{"directory": dir, "range": spec})
+	}
+	groupOf := make(map[string]string)
+	var order []string
+	for _, files := range parseNameOnlyLog(logOutput) {
+		for _, file := range files[1:] {
+			if _, seen := groupOf[file]; !seen {
+				order = append(order, file)
+			}
+			groupOf[file] = files[0]
+		}
+	}
+	if len(order) == 0 {
+		return nil, utils.NewValidationError("The range has no file changes", nil, map[string]interface{}{"directory": dir, "range": spec})
+	}
+
+	fixture := &EvalFixture{
+		Name:   filepath.Base(dir) + " " + spec,
+		Source: dir + " " + from + ".." + to,
+	}
+	skipped := 0
+	for _, file := range order {
+		before, beforeOK := fileAtRevision(dir, from, file)
+		after, afterOK := fileAtRevision(dir, to, file)
+		if !beforeOK || !afterOK {
+			skipped++
+			continue
+		}
+		if (before == nil && after == nil) || (before != nil && after != nil && *before == *after) {
+			continue // Added and removed, or changed and reverted, within the range
+		}
+		fixture.Changes = append(fixture.Changes, FixtureChange{Path: file, Before: before, After: after, Group: groupOf[file]})
+	}
+	if len(fixture.Changes) == 0 {
+		return nil, utils.NewValidationError("The range has no text file changes", nil, map[string]interface{}{"directory": dir, "range": spec})
+	}
+
+	if historyCommits > 0 {
+		historyOutput, err := RunGitCmd(dir, nil, "log", "--no-merges", "--no-renames", "--name-only",
+			"--format=%x1e", "-n", strconv.Itoa(historyCommits), from)
+		if err != nil {
+			return nil, utils.NewGitError("Failed to read history before the range", err, map[string]interface{}{"directory": dir, "range": spec})
+		}
+		maxFiles := config.GetClusteringConfig().Methods.History.MaxCommitFiles
+		records := parseNameOnlyLog(historyOutput)
+		for i := len(records) - 1; i >= 0; i-- {
+			if files := records[i][1:]; len(files) > 1 && (maxFiles <= 0 || len(files) <= maxFiles) {
+				fixture.History = append(fixture.History, files)
+			}
+		}
+	}
+
+	utils.Debug(fmt.Sprintf("[GIT.EVAL]: Captured %d file(s) in %d group(s) with %d history commit(s) from %s, %d binary or large file(s) skipped",
+		len(fixture.Changes), len(distinctGroups(fixture.Changes)), len(fixture.History), spec, skipped))
+	return fixture, nil
+}
+
+// parseNameOnlyLog splits git log --name-only output with records starting with \x1e into the
+// header line followed by the files of each commit
+func parseNameOnlyLog(output string) [][]string {
+	var records [][]string
+	for _, record := range strings.Split(output, "\x1e")[1:] {
+		lines := strings.Split(record, "\n")
+		entry := []string{strings.TrimSpace(lines[0])}
+		for _, line := range lines[1:] {
+			if line = strings.TrimSpace(line); line != "" {
+				entry = append(entry, line)
+			}
+		}
+		records = append(records, entry)
+	}
+	return records
+}
+
+// fileAtRevision returns the content of path at rev, nil when it does not exist there. ok is
+// false for binary and large files, which fixtures leave out.
+func fileAtRevision(dir, rev, path string) (*string, bool) {
+	content, _, err := RunGitCmdWithOutput(dir, nil, "show", rev+":"+path)
+	if err != nil {
+		return nil, true
+	}
+	if len(content) > maxFixtureFileSize || strings.Contains(content, "\x00") {
+		return nil, false
+	}
+	return &content, true
+}
+
+func distinctGroups(changes []FixtureChange) map[string]bool {
+	groups := make(map[string]bool)
+	for _, change := range changes {
+		groups[change.Group] = true
+	}
+	return groups
+}
@@ -0,0 +1,211 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/output"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Rules recorded on FileCluster
+const (
+	RuleSingleFile    = "single file"
+	RuleTestImplPair  = "test/impl pair"
+	RuleSameDirectory = "same directory"
+	RuleSameExtension = "same extension"
+	RuleSimilarDiffs  = "similar changes"
+	RuleMerged        = "merged to reach the cluster count"
+)
+
+// newFileClusters explains the clusters produced by method. Each file's similarity is measured
+// against the cluster centroid: the mean embedding when embeddings are available, otherwise
+// the directory and extension that most files of the cluster share.
+func newFileClusters(method string, clusters [][]string, confidence float64, rootFolder string, fileEmbeddings map[string][]float32) []FileCluster {
+	created := time.Now()
+	result := make([]FileCluster, 0, len(clusters))
+	for _, files := range clusters {
+		if len(files) == 0 {
+			continue
+		}
+
+		useEmbeddings := len(files) > 1 && hasEmbeddings(files, fileEmbeddings)
+		cluster := FileCluster{
+			Files:       files,
+			ClusterType: method,
+			Created:     created,
+			Method:      method,
+			Confidence:  confidence,
+			Rule:        clusterRule(files, rootFolder, useEmbeddings),
+		}
+		if useEmbeddings {
+			cluster.FileSimilarity = embeddingCentroidSimilarity(files, fileEmbeddings)
+		} else {
+			cluster.FileSimilarity = featureCentroidSimilarity(files, rootFolder)
+		}
+
+		total := 0.0
+		for _, similarity := range cluster.FileSimilarity {
+			total += similarity
+		}
+		if len(cluster.FileSimilarity) > 0 {
+			cluster.Similarity = total / float64(len(cluster.FileSimilarity))
+		}
+		result = append(result, cluster)
+	}
+	return result
+}
+
+// ClusterFileLists returns just the files of each cluster
+func ClusterFileLists(clusters []FileCluster) [][]string {
+	lists := make([][]string, len(clusters))
+	for i, cluster := range clusters {
+		lists[i] = cluster.Files
+	}
+	return lists
+}
+
+// hasEmbeddings reports whether any file of the cluster has an embedding
+func hasEmbeddings(files []string, fileEmbeddings map[string][]float32) bool {
+	for _, file := range files {
+		if len(fileEmbeddings[file]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterRule names the rule that holds the files of a cluster together
+func clusterRule(files []string, rootFolder string, useEmbeddings bool) string {
+	switch {
+	case len(files) == 1:
+		return RuleSingleFile
+	case useEmbeddings:
+		return RuleSimilarDiffs
+	case len(testImplPartners(files)) == len(files):
+		return RuleTestImplPair
+	case calculateDirectorySimilarity(files, rootFolder) == 1.0:
+		return RuleSameDirectory
+	case calculateExtensionSimilarity(files) == 1.0:
+		return RuleSameExtension
+	default:
+		return RuleMerged
+	}
+}
+
+// testImplPartners returns the files of a cluster that are part of a test/impl pair
+func testImplPartners(files []string) map[string]bool {
+	partners := make(map[string]bool)
+	for testFile, implFile := range findTestImplementationRelations(files) {
+		partners[testFile] = true
+		partners[implFile] = true
+	}
+	return partners
+}
+
+// embeddingCentroidSimilarity returns the cosine similarity of each file's embedding to the
+// mean embedding of the cluster. Files without an embedding are left out.
+func embeddingCentroidSimilarity(files []string, fileEmbeddings map[string][]float32) map[string]float64 {
+	var centroid []float32
+	count := 0
+	for _, file := range files {
+		embedding := fileEmbeddings[file]
+		if len(embedding) == 0 {
+			continue
+		}
+		if centroid == nil {
+			centroid = make([]float32, len(embedding))
+		}
+		if len(embedding) != len(centroid) {
+			continue
+		}
+		for i, value := range embedding {
+			centroid[i] += value
+		}
+		count++
+	}
+	for i := range centroid {
+		centroid[i] /= float32(count)
+	}
+
+	similarities := make(map[string]float64)
+	for _, file := range files {
+		if embedding := fileEmbeddings[file]; len(embedding) == len(centroid) && len(embedding) > 0 {
+			similarities[file] = advancedCosineSimilarity(embedding, centroid, false)
+		}
+	}
+	return similarities
+}
+
+// featureCentroidSimilarity scores each file by whether it shares the cluster's most common
+// directory and extension. Files in a test/impl pair count as fully similar.
+func featureCentroidSimilarity(files []string, rootFolder string) map[string]float64 {
+	dirs := make(map[string]int)
+	exts := make(map[string]int)
+	for _, file := range files {
+		dirs[relativeDir(file, rootFolder)]++
+		exts[strings.ToLower(filepath.Ext(file))]++
+	}
+	commonDir, commonExt := mostCommon(dirs), mostCommon(exts)
+	partners := testImplPartners(files)
+
+	similarities := make(map[string]float64, len(files))
+	for _, file := range files {
+		if len(files) == 1 || partners[file] {
+			similarities[file] = 1.0
+			continue
+		}
+		score := 0.0
+		if relativeDir(file, rootFolder) == commonDir {
+			score += 0.5
+		}
+		if strings.ToLower(filepath.Ext(file)) == commonExt {
+			score += 0.5
+		}
+		similarities[file] = score
+	}
+	return similarities
+}
+
+// relativeDir returns the directory of file relative to rootFolder
+func relativeDir(file, rootFolder string) string {
+	relPath, err := filepath.Rel(rootFolder, file)
+	if err != nil {
+		relPath = file
+	}
+	return filepath.Dir(relPath)
+}
+
+// mostCommon returns the most frequent key, preferring the smallest on ties
+func mostCommon(counts map[string]int) string {
+	best, bestCount := "", 0
+	for key, count := range counts {
+		if count > bestCount || (count == bestCount && key < best) {
+			best, bestCount = key, count
+		}
+	}
+	return best
+}
+
+// recordClusters stores the explanation of the clusters that received a commit message next
+// to the messages in the output store
+func recordClusters(rootFolder string, clusters []FileCluster) {
+	var records []output.Cluster
+	for _, cluster := range clusters {
+		message := output.Get(cluster.Files[0], rootFolder)
+		if message == "" {
+			continue
+		}
+		records = append(records, output.Cluster{
+			Files:          cluster.Files,
+			Name:           cluster.Name,
+			Message:        message,
+			Method:         cluster.Method,
+			Rule:           cluster.Rule,
+			Confidence:     cluster.Confidence,
+			Similarity:     cluster.Similarity,
+			FileSimilarity: cluster.FileSimilarity,
+			Created:        cluster.Created,
+		})
+	}
+	output.SetClusters(rootFolder, records)
+}
@@ -2,7 +2,9 @@ package git
 
 import (
 	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/di"
 	"github.com/lakshyajain-0291/gitcury/embeddings"
+	"github.com/lakshyajain-0291/gitcury/interfaces"
 	"github.com/lakshyajain-0291/gitcury/output"
 	"github.com/lakshyajain-0291/gitcury/utils"
 	"bytes"
@@ -10,6 +12,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -106,12 +109,30 @@ func isRateLimit(err error) bool {
 }
 
 func RunGitCmd(dir string, envVars map[string]string, args ...string) (string, error) {
+	stdout, stderr, err := RunGitCmdWithOutput(dir, envVars, args...)
+	if err != nil {
+		utils.Error(fmt.Sprintf(
+			"[GIT.EXEC.FAIL]: Command failed: %s\nStdout: %s\nStderr: %s\n",
+			err,
+			stdout,
+			stderr,
+		))
+		return "", err
+	}
+
+	utils.Debug("[GIT.EXEC.SUCCESS]: Command executed successfully in directory '" + dir + "': git " + strings.Join(args, " "))
+	return stdout, nil
+}
+
+// RunGitCmdWithOutput runs git like RunGitCmd but returns stdout and stderr separately,
+// including on failure, so callers can report what git (or a hook) printed.
+func RunGitCmdWithOutput(dir string, envVars map[string]string, args ...string) (string, string, error) {
 	cmd := exec.Command("git", args...)
 	cmd.Dir = dir
 
 	// Append custom environment variables to the existing environment
 	if envVars != nil {
-		env := cmd.Env
+		env := os.Environ()
 		for key, value := range envVars {
 			env = append(env, fmt.Sprintf("%s=%s", key, value))
 		}
@@ -122,18 +143,8 @@ func RunGitCmd(dir string, envVars map[string]string, args ...string) (string, e
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
-		utils.Error(fmt.Sprintf(
-			"[GIT.EXEC.FAIL]: Command failed: %s\nStdout: %s\nStderr: %s\n",
-			err,
-			stdout.String(),
-			stderr.String(),
-		))
-		return "", err
-	}
-
-	utils.Debug("[GIT.EXEC.SUCCESS]: Command executed successfully in directory '" + dir + "': git " + strings.Join(args, " "))
-	return stdout.String(), nil
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
 }
 
 var changedFilesCache = make(map[string]string)
@@ -190,7 +201,22 @@ func GetAllChangedFiles(dir string) ([]string, error) {
 			return nil, err
 		}
 
+		if info.IsDir() && status != "??" {
+			// Tracked directories are submodules; only a moved pointer is a change of this repository
+			if SubmodulePointerChanged(dir, abs) {
+				changedFiles = append(changedFiles, abs)
+			} else {
+				utils.Debug("[GIT.SUBMODULE]: Skipping submodule with only uncommitted content: " + abs)
+			}
+			continue
+		}
+
 		if info.IsDir() && status == "??" {
+			if isRepositoryRoot(abs) {
+				utils.Debug("[GIT.NESTED]: Skipping nested repository: " + abs)
+				continue
+			}
+
 			innerOutput, err := RunGitCmd(dir, nil, "ls-files", "--others", "--exclude-standard", relativePath)
 			if err != nil {
 				utils.Error("[GIT.UNTRACKED.FAIL]: Failed to list files in untracked dir '" + relativePath + "': " + err.Error())
@@ -201,6 +227,11 @@ func GetAllChangedFiles(dir string) ([]string, error) {
 				if strings.TrimSpace(inner) == "" {
 					continue
 				}
+				if strings.HasSuffix(inner, "/") {
+					// Repositories inside untracked directories are listed as "<path>/"
+					utils.Debug("[GIT.NESTED]: Skipping nested repository: " + inner)
+					continue
+				}
 				fullPath := filepath.Join(dir, inner)
 				absInner, err := filepath.Abs(fullPath)
 				if err == nil {
@@ -218,15 +249,44 @@ func GetAllChangedFiles(dir string) ([]string, error) {
 }
 
 func GenCommitMessage(files []string, dir string) (string, error) {
-	contextData := make(map[string]map[string]string)
+	return genCommitMessage(files, dir, false)
+}
+
+// GenIndexCommitMessage works like GenCommitMessage but only looks at the staged version of
+// each file, which is what git records when it commits the index.
+func GenIndexCommitMessage(files []string, dir string) (string, error) {
+	return genCommitMessage(files, dir, true)
+}
 
-	apiKey := config.Get("GEMINI_API_KEY")
+// sendToGemini generates a commit message through the injected Gemini runner
+func sendToGemini(contextData map[string]map[string]string, apiKey string, instructions string) (string, error) {
+	if runner := di.GetGeminiRunner(); runner != nil {
+		return runner.SendToGemini(contextData, apiKey, instructions)
+	}
+	return utils.SendToGemini(contextData, apiKey, instructions)
+}
+
+// geminiAPIKey returns the Gemini API key from the config or environment. GEMINI_API_KEY may
+// hold a comma-separated list that batch processing spreads over a GeminiPool; a single request
+// uses the first key, since the whole list is not a valid key.
+func geminiAPIKey() (string, error) {
+	apiKey, _ := config.Get("GEMINI_API_KEY").(string)
 	if apiKey == "" {
 		apiKey = os.Getenv("GEMINI_API_KEY")
 		if apiKey == "" {
 			return "", fmt.Errorf("Gemini API key not found in config or env")
 		}
 	}
+	return strings.TrimSpace(strings.Split(apiKey, ",")[0]), nil
+}
+
+func genCommitMessage(files []string, dir string, indexOnly bool) (string, error) {
+	contextData := make(map[string]map[string]string)
+
+	apiKey, err := geminiAPIKey()
+	if err != nil {
+		return "", err
+	}
 
 	for _, file := range files {
 		var fileType, diffOutput string
@@ -235,7 +295,13 @@ func GenCommitMessage(files []string, dir string) (string, error) {
 		status, cached := changedFilesCache[file]
 		cacheMu.RUnlock()
 
-		if cached && strings.HasPrefix(status, "D") {
+		if isSubmodule(dir, file) {
+			contextData[file] = submoduleBumpContext(dir, file, indexOnly)
+			utils.Debug("[GIT.COMMIT.MSG]: Processed submodule '" + file + "'")
+			continue
+		}
+
+		if !indexOnly && cached && strings.HasPrefix(status, "D") {
 			fileType = "deleted"
 			contextData[file] = map[string]string{
 				"type": fileType,
@@ -245,6 +311,34 @@ func GenCommitMessage(files []string, dir string) (string, error) {
 			continue
 		}
 
+		if indexOnly {
+			diffOutput, err := RunGitCmd(dir, nil, "diff", "--cached", "--", file)
+			if err != nil {
+				utils.Error(fmt.Sprintf("[GIT.DIFF.FAIL]: Error running git diff --cached for '%s': %s", file, err.Error()))
+				return "", err
+			}
+
+			fileType = "updated"
+			if strings.Contains(diffOutput, "\ndeleted file mode") {
+				fileType = "deleted"
+			} else if strings.Contains(diffOutput, "\nnew file mode") {
+				fileType = "new"
+			}
+
+			diffOutput = sanitizeUTF8(diffOutput)
+			if !utf8.ValidString(diffOutput) || strings.TrimSpace(diffOutput) == "" {
+				utils.Debug(fmt.Sprintf("[GIT.COMMIT.MSG]: Skipping staged file '%s' due to empty or invalid diff", file))
+				continue
+			}
+
+			contextData[file] = map[string]string{
+				"type": fileType,
+				"diff": diffOutput,
+			}
+			utils.Debug("[GIT.COMMIT.MSG]: Processed staged file '" + file + "' as " + fileType)
+			continue
+		}
+
 		diffOutput, err := RunGitCmd(dir, nil, "diff", "--", file)
 		if err != nil {
 			utils.Error(fmt.Sprintf("[GIT.DIFF.FAIL]: Error running git diff for '%s': %s", file, err.Error()))
@@ -296,8 +390,10 @@ func GenCommitMessage(files []string, dir string) (string, error) {
 		return "", fmt.Errorf("no valid diffs found to send to Gemini")
 	}
 
-	// 🚀 Call Gemini with sanitized data
-	message, err := utils.SendToGemini(contextData, apiKey.(string))
+	// 🚀 Call Gemini with sanitized data. getmsgs --instructions stores its instructions under
+	// commit_instructions, so they apply to these messages as well.
+	instructions, _ := config.Get("commit_instructions").(string)
+	message, err := sendToGemini(contextData, apiKey, instructions)
 	if err != nil {
 		utils.Error("[GEMINI.FAIL]: Error generating group commit message: " + err.Error())
 		return "", err
@@ -381,6 +477,9 @@ func GenCommitMessageWithKey(files []string, dir string, apiKey string) (string,
 
 func BatchProcessGetMessages(allChangedFiles []string, rootFolder string) error {
 	utils.Debug("[GIT.BATCH]: Starting batch processing of commit messages")
+
+	// Each file gets its own message, so earlier clusters no longer apply
+	output.SetClusters(rootFolder, nil)
 	
 	// Separate binary and text files
 	var binaryFiles []string
@@ -509,26 +608,58 @@ func CommitBatch(rootFolder output.Folder, env ...[]string) error {
 		}
 	}
 
-	messageToFiles := make(map[string][]string)
+	commitConfig := config.GetCommitConfig(rootFolder.Name, env...)
+	if err := applyIdentity(rootFolder.Name, envMap, &commitConfig); err != nil {
+		utils.Error("[GIT.COMMIT.FAIL]: Invalid commit identity: " + err.Error())
+		return err
+	}
+
+	var plannedFiles []string
 	for _, entry := range commitMessagesList {
-		utils.Debug("[GIT.COMMIT]: Staging file for grouping: " + entry.Name + " with message: " + entry.Message)
-		messageToFiles[entry.Message] = append(messageToFiles[entry.Message], entry.Name)
+		plannedFiles = append(plannedFiles, entry.Name)
+	}
+	WarnAboutStagedChanges(rootFolder.Name, plannedFiles)
+
+	// Whatever gets committed, including a partial batch, can be undone with gitcury undo
+	journal := beginJournalEntry(rootFolder.Name, "commit", rootFolder.Files, env...)
+	defer journal.finish()
+
+	groups := CommitGroups(rootFolder)
+	dates, err := scheduledDates(envMap, len(groups))
+	if err != nil {
+		return err
 	}
 
-	for message, files := range messageToFiles {
+	var committedFiles []string
+	for i, group := range groups {
+		message, files := group.Message, group.Files
 		for _, file := range files {
 			utils.Debug("[GIT.COMMIT]: Adding file to commit: " + file)
 			if _, err := RunGitCmd(rootFolder.Name, envMap, "add", file); err != nil {
 				utils.Error("[GIT.COMMIT.FAIL]: Failed to add file to commit: " + err.Error())
+				forgetCommittedFiles(rootFolder.Name, committedFiles)
 				return fmt.Errorf("failed to add file to commit: %s", err.Error())
 			}
 		}
 
+		commitEnv := envMap
+		if len(dates) > 0 {
+			commitEnv = withCommitDate(envMap, dates[i])
+		}
+
 		utils.Debug(fmt.Sprintf("[GIT.COMMIT]: Committing %d file(s) with message: %s", len(files), message))
-		if _, err := RunGitCmd(rootFolder.Name, envMap, "commit", "-m", message); err != nil {
+		if err := runCommit(rootFolder.Name, commitEnv, commitConfig, message, files); err != nil {
 			utils.Error("[GIT.COMMIT.FAIL]: Failed to commit files with message '" + message + "': " + err.Error())
-			return fmt.Errorf("failed to commit files: %s", err.Error())
+
+			// Leave the index as it was so the failed group can simply be retried
+			if _, _, resetErr := RunGitCmdWithOutput(rootFolder.Name, envMap, append([]string{"reset", "-q", "--"}, files...)...); resetErr != nil {
+				utils.Debug("[GIT.COMMIT]: Could not unstage failed group: " + resetErr.Error())
+			}
+			forgetCommittedFiles(rootFolder.Name, committedFiles)
+			return err
 		}
+
+		committedFiles = append(committedFiles, files...)
 	}
 
 	output.RemoveFolder(rootFolder.Name)
@@ -536,16 +667,25 @@ func CommitBatch(rootFolder output.Folder, env ...[]string) error {
 	return nil
 }
 
-func PushBranch(rootFolderName string, branch string) error {
-	if branch == "" {
-		utils.Debug("[GIT.PUSH]: Branch name is empty, defaulting to 'main'")
-		branch = "main"
+// forgetCommittedFiles drops already committed files from the output store after a partial
+// batch, so running the commit again only retries the groups that did not go through.
+func forgetCommittedFiles(rootFolder string, files []string) {
+	if len(files) == 0 {
+		return
 	}
 
+	for _, file := range files {
+		output.Delete(file, rootFolder)
+	}
+	utils.Info(fmt.Sprintf("[GIT.COMMIT]: %d file(s) were committed before the failure; re-run the commit to retry the remaining groups", len(files)))
+}
+
+// PushBranch pushes branch, or the current branch when empty, using SmartPush
+func PushBranch(rootFolderName string, branch string, opts ...interfaces.PushOptions) error {
 	utils.Debug("[GIT.PUSH]: Pushing branch: " + branch + " in folder: " + rootFolderName)
-	if _, err := RunGitCmd(rootFolderName, nil, "push", "origin", branch); err != nil {
+	if err := SmartPush(rootFolderName, branch, opts...); err != nil {
 		utils.Error("[GIT.PUSH.FAIL]: Failed to push branch: " + err.Error())
-		return fmt.Errorf("failed to push branch: %s", err.Error())
+		return err
 	}
 
 	utils.Info("[GIT.PUSH.SUCCESS]: Branch pushed successfully")
@@ -586,6 +726,18 @@ func sanitizeUTF8(input string) string {
 	return string([]rune(input)) // Drops invalid byte sequences
 }
 
+// truncateAtRune returns at most limit bytes of text, backing off to the start of a rune so a
+// multi-byte character is never split
+func truncateAtRune(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return text[:limit]
+}
+
 func BatchProcessWithEmbeddings(allChangedFiles []string, rootFolder string, numClusters int, promptChan chan utils.PromptRequest) error {
 	utils.Debug("[GIT.BATCH]: Starting batch processing with embeddings and clustering")
 
@@ -675,8 +827,14 @@ func BatchProcessWithEmbeddings(allChangedFiles []string, rootFolder string, num
 	
 	clustersData, err := executeSpecificMethod(textFiles, rootFolder, numClusters, methodStr, false)
 	if err == nil && len(clustersData) > 0 {
-		for idx, group := range clustersData {
-			utils.Debug(fmt.Sprintf("[GIT.SMART]: Generating commit message for group %d with %d files", idx, len(group)))
+		clustersData, err = reviewClusters(rootFolder, clustersData)
+		if err != nil {
+			return err
+		}
+
+		for idx, cluster := range clustersData {
+			group := cluster.Files
+			utils.Debug(fmt.Sprintf("[GIT.SMART]: Generating commit message for group %d with %d files (%s, confidence %.2f)", idx, len(group), cluster.Rule, cluster.Confidence))
 			
 			// message, err := GenCommitMessage(group, rootFolder)
 			message, err := pool.Dispatch(group, rootFolder)
@@ -690,6 +848,7 @@ func BatchProcessWithEmbeddings(allChangedFiles []string, rootFolder string, num
 				utils.Debug("[GIT.SMART.SUCCESS]: Generated commit message for file: " + file + " - " + message)
 			}
 		}
+		recordClusters(rootFolder, clustersData)
 		utils.Success("✅ Smart clustering completed with commit messages.")
 		return nil
 	}	
@@ -700,6 +859,11 @@ func BatchProcessWithEmbeddings(allChangedFiles []string, rootFolder string, num
 	var fileErrors []error
 	var fileMu sync.Mutex
 
+	var hunkCache *HunkEmbeddingCache
+	if useHunkEmbeddings() {
+		hunkCache = LoadHunkEmbeddingCache(rootFolder)
+	}
+
 	for _, file := range textFiles {
 		diff, err := GetFileDiff(file, rootFolder)
 		if err != nil || strings.TrimSpace(diff) == "" {
@@ -711,7 +875,12 @@ func BatchProcessWithEmbeddings(allChangedFiles []string, rootFolder string, num
 			continue
 		}
 		diff = sanitizeUTF8(diff)
-		embed, err := embeddings.GenerateEmbedding(diff)
+		var embed []float32
+		if hunkCache != nil {
+			embed, err = hunkCache.EmbedFile(file)
+		} else {
+			embed, err = embeddings.GenerateEmbedding(diff)
+		}
 		if err != nil {
 			utils.Error("[GIT.BATCH]: Could not generate embedding for file: " + file)
 			fileMu.Lock()
@@ -726,6 +895,10 @@ func BatchProcessWithEmbeddings(allChangedFiles []string, rootFolder string, num
 		})
 	}
 
+	if hunkCache != nil {
+		hunkCache.Save()
+	}
+
 	if len(fileData) == 0 {
 		return fmt.Errorf("no valid diffs or embeddings generated")
 	}
@@ -741,28 +914,38 @@ func BatchProcessWithEmbeddings(allChangedFiles []string, rootFolder string, num
 		return fmt.Errorf("clustering failed: %v", err)
 	}
 
-	groupMap := make(map[int][]FileEmbedding)
+	groupMap := make(map[int][]string)
 	for i, label := range labels {
-		groupMap[label] = append(groupMap[label], fileData[i])
+		groupMap[label] = append(groupMap[label], fileData[i].Path)
 	}
 
-	type CommitGroup struct {
-		Message string   `json:"message"`
-		Files   []string `json:"files"`
+	// Keep the clusters in label order so the review and explanation are stable
+	fileEmbeddings := make(map[string][]float32, len(fileData))
+	for _, f := range fileData {
+		fileEmbeddings[f.Path] = f.Embedding
 	}
+	labelOrder := make([]int, 0, len(groupMap))
+	for label := range groupMap {
+		labelOrder = append(labelOrder, label)
+	}
+	sort.Ints(labelOrder)
+	var labelClusters [][]string
+	for _, label := range labelOrder {
+		labelClusters = append(labelClusters, groupMap[label])
+	}
+	confidence := calculateEmbeddingClusterConfidence(labelClusters, fileEmbeddings)
+	semanticClusters := newFileClusters(string(config.SemanticMethod), labelClusters, confidence, rootFolder, fileEmbeddings)
 
-	var commitGroups []CommitGroup
-	var commitMu sync.Mutex
-	var fileWg sync.WaitGroup
+	semanticClusters, err = reviewClusters(rootFolder, semanticClusters)
+	if err != nil {
+		return err
+	}
 
-	for label, group := range groupMap {
+	var fileWg sync.WaitGroup
+	for _, cluster := range semanticClusters {
 		fileWg.Add(1)
-		go func(label int, group []FileEmbedding) {
+		go func(filePaths []string) {
 			defer fileWg.Done()
-			var filePaths []string
-			for _, f := range group {
-				filePaths = append(filePaths, f.Path)
-			}
 			// message, err := GenCommitMessage(filePaths, rootFolder)
 			message, err := pool.Dispatch(filePaths, rootFolder)
 			if err != nil {
@@ -771,16 +954,15 @@ func BatchProcessWithEmbeddings(allChangedFiles []string, rootFolder string, num
 				fileMu.Unlock()
 				return
 			}
-			commitMu.Lock()
-			commitGroups = append(commitGroups, CommitGroup{Message: message, Files: filePaths})
-			commitMu.Unlock()
-			for _, f := range group {
-				output.Set(f.Path, rootFolder, message)
+			for _, file := range filePaths {
+				output.Set(file, rootFolder, message)
 			}
-		}(label, group)
+		}(cluster.Files)
 	}
 
 	fileWg.Wait()
+	recordClusters(rootFolder, semanticClusters)
+
 	if len(fileErrors) > 0 {
 		return fmt.Errorf("one or more errors occurred while preparing commit messages")
 	}
@@ -796,7 +978,7 @@ func RunGitCmdWithTimeout(dir string, envVars map[string]string, timeout time.Du
 
 	// Append custom environment variables to the existing environment
 	if envVars != nil {
-		env := cmd.Env
+		env := os.Environ()
 		for key, value := range envVars {
 			env = append(env, fmt.Sprintf("%s=%s", key, value))
 		}
@@ -0,0 +1,350 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RuleCoChange marks clusters of files that changed together in past commits
+const RuleCoChange = "changed together before"
+
+// CoChangeHistory is the co-change matrix mined from the git log of a root folder. Weights are
+// decayed to DecayedAt, so older commits count less; files are relative to the root folder.
+type CoChangeHistory struct {
+	RootFolder string                        `json:"rootFolder"`
+	LastCommit string                        `json:"lastCommit"` // Newest commit already counted
+	DecayedAt  time.Time                     `json:"decayedAt"`
+	Commits    int                           `json:"commits"` // Commits counted, after skipping large ones
+	Changes    map[string]float64            `json:"changes"` // File -> decayed number of commits touching it
+	Pairs      map[string]map[string]float64 `json:"pairs"`   // File -> file -> decayed number of shared commits
+}
+
+var (
+	historyMu    sync.Mutex
+	historyCache = make(map[string]*CoChangeHistory)
+)
+
+// historyPath returns the cache file of the co-change history of rootFolder. It lives in the
+// git directory; root folders sharing a repository get their own file.
+func historyPath(rootFolder string) (string, error) {
+	hash := sha256.Sum256([]byte(filepath.Clean(rootFolder)))
+	return gitPath(rootFolder, "gitcury/cochange-"+hex.EncodeToString(hash[:])[:12]+".json")
+}
+
+// LoadCoChangeHistory returns the co-change history of rootFolder, mining only the commits
+// made since it was last cached. A rewritten history is mined again from scratch.
+func LoadCoChangeHistory(rootFolder string) (*CoChangeHistory, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	settings := config.GetClusteringConfig().Methods.History
+	head := revParse(rootFolder, "HEAD")
+
+	history := historyCache[rootFolder]
+	if history == nil {
+		history = readCoChangeHistory(rootFolder)
+	}
+	if head == "" || head == history.LastCommit {
+		historyCache[rootFolder] = history
+		return history, nil
+	}
+
+	revisionRange := "HEAD"
+	if history.LastCommit != "" {
+		// A failing ancestry check is expected after a rebase, so it is not logged as an error
+		if _, _, err := RunGitCmdWithOutput(rootFolder, nil, "merge-base", "--is-ancestor", history.LastCommit, head); err == nil {
+			revisionRange = history.LastCommit + "..HEAD"
+		} else {
+			utils.Debug("[GIT.HISTORY]: History was rewritten, mining co-changes again for " + rootFolder)
+			history = newCoChangeHistory(rootFolder)
+		}
+	}
+
+	logOutput, err := RunGitCmd(rootFolder, nil, "log", "--no-merges", "--relative", "--name-only",
+		"--format=%x1e%H %ct", "-n", strconv.Itoa(settings.MaxCommits), revisionRange)
+	if err != nil {
+		return history, utils.NewGitError("Failed to read the commit history", err, map[string]interface{}{
+			"rootFolder": rootFolder,
+		})
+	}
+
+	added := history.addLog(logOutput, settings, time.Now())
+	history.LastCommit = head
+	history.prune(settings.MaxFiles)
+	historyCache[rootFolder] = history
+
+	utils.Debug(fmt.Sprintf("[GIT.HISTORY]: Counted %d new commits for %s, %d files in the co-change matrix",
+		added, rootFolder, len(history.Changes)))
+	writeCoChangeHistory(history)
+	return history, nil
+}
+
+func newCoChangeHistory(rootFolder string) *CoChangeHistory {
+	return &CoChangeHistory{
+		RootFolder: rootFolder,
+		DecayedAt:  time.Now(),
+		Changes:    make(map[string]float64),
+		Pairs:      make(map[string]map[string]float64),
+	}
+}
+
+// readCoChangeHistory loads the cached history, or an empty one when there is none
+func readCoChangeHistory(rootFolder string) *CoChangeHistory {
+	path, err := historyPath(rootFolder)
+	if err != nil {
+		return newCoChangeHistory(rootFolder)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // Cache file path controlled by application
+	if err != nil {
+		return newCoChangeHistory(rootFolder)
+	}
+
+	var history CoChangeHistory
+	if err := json.Unmarshal(data, &history); err != nil || history.Changes == nil || history.Pairs == nil {
+		utils.Warning("[GIT.HISTORY]: Ignoring an unreadable co-change cache: " + path)
+		return newCoChangeHistory(rootFolder)
+	}
+	history.RootFolder = rootFolder
+	return &history
+}
+
+// writeCoChangeHistory caches the history; failures only cost a full mining run next time
+func writeCoChangeHistory(history *CoChangeHistory) {
+	path, err := historyPath(history.RootFolder)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(history)
+	if err != nil {
+		utils.Warning(fmt.Sprintf("[GIT.HISTORY]: Failed to encode the co-change cache: %v", err))
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		utils.Warning(fmt.Sprintf("[GIT.HISTORY]: Failed to create the cache directory: %v", err))
+		return
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		utils.Warning(fmt.Sprintf("[GIT.HISTORY]: Failed to save the co-change cache: %v", err))
+	}
+}
+
+// addLog counts the commits of a `git log --name-only --format=%x1e%H %ct` output and returns
+// how many were counted. Existing weights are decayed to now first.
+func (history *CoChangeHistory) addLog(logOutput string, settings config.HistoryConfig, now time.Time) int {
+	halfLife := time.Duration(settings.HalfLifeDays) * 24 * time.Hour
+	decay := func(age time.Duration) float64 {
+		if halfLife <= 0 || age <= 0 {
+			return 1
+		}
+		return math.Pow(0.5, float64(age)/float64(halfLife))
+	}
+
+	if factor := decay(now.Sub(history.DecayedAt)); factor < 1 {
+		for file := range history.Changes {
+			history.Changes[file] *= factor
+		}
+		for _, partners := range history.Pairs {
+			for partner := range partners {
+				partners[partner] *= factor
+			}
+		}
+	}
+	history.DecayedAt = now
+
+	added := 0
+	for _, record := range strings.Split(logOutput, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		header := strings.Fields(lines[0])
+		if len(header) != 2 {
+			continue
+		}
+		var files []string
+		for _, line := range lines[1:] {
+			if line = strings.TrimSpace(line); line != "" {
+				files = append(files, line)
+			}
+		}
+		// Commits touching many files say little about which files belong together
+		if len(files) == 0 || (settings.MaxCommitFiles > 0 && len(files) > settings.MaxCommitFiles) {
+			continue
+		}
+
+		seconds, _ := strconv.ParseInt(header[1], 10, 64)
+		weight := decay(now.Sub(time.Unix(seconds, 0)))
+		for i, file := range files {
+			history.Changes[file] += weight
+			for _, partner := range files[i+1:] {
+				history.addPair(file, partner, weight)
+				history.addPair(partner, file, weight)
+			}
+		}
+		added++
+	}
+	history.Commits += added
+	return added
+}
+
+func (history *CoChangeHistory) addPair(file, partner string, weight float64) {
+	if history.Pairs[file] == nil {
+		history.Pairs[file] = make(map[string]float64)
+	}
+	history.Pairs[file][partner] += weight
+}
+
+// prune keeps the maxFiles files that changed most, weighted by recency
+func (history *CoChangeHistory) prune(maxFiles int) {
+	if maxFiles <= 0 || len(history.Changes) <= maxFiles {
+		return
+	}
+
+	files := make([]string, 0, len(history.Changes))
+	for file := range history.Changes {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return history.Changes[files[i]] > history.Changes[files[j]]
+	})
+
+	for _, file := range files[maxFiles:] {
+		delete(history.Changes, file)
+		for partner := range history.Pairs[file] {
+			delete(history.Pairs[partner], file)
+		}
+		delete(history.Pairs, file)
+	}
+}
+
+// key returns the history key of a changed file
+func (history *CoChangeHistory) key(file string) string {
+	if filepath.IsAbs(file) {
+		if relative, err := filepath.Rel(history.RootFolder, file); err == nil {
+			file = relative
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(file))
+}
+
+// Known reports whether file changed in the mined history
+func (history *CoChangeHistory) Known(file string) bool {
+	return history.Changes[history.key(file)] > 0
+}
+
+// Similarity is the share of the commits touching either file that touched both (0 to 1)
+func (history *CoChangeHistory) Similarity(a, b string) float64 {
+	a, b = history.key(a), history.key(b)
+	shared := history.Pairs[a][b]
+	union := history.Changes[a] + history.Changes[b] - shared
+	if shared <= 0 || union <= 0 {
+		return 0
+	}
+	return math.Min(1, shared/union)
+}
+
+// coChangeClusterSimilarity is the mean co-change similarity of the file pairs of a cluster
+// that both appear in the history. It is not ok when fewer than two files are known or the
+// history method is disabled.
+func coChangeClusterSimilarity(files []string, rootFolder string) (float64, bool) {
+	if !config.IsMethodEnabled(config.HistoryMethod) {
+		return 0, false
+	}
+	history, err := LoadCoChangeHistory(rootFolder)
+	if err != nil {
+		return 0, false
+	}
+
+	var known []string
+	for _, file := range files {
+		if history.Known(file) {
+			known = append(known, file)
+		}
+	}
+	if len(known) < 2 {
+		return 0, false
+	}
+
+	total, pairs := 0.0, 0
+	for i, file := range known {
+		for _, other := range known[i+1:] {
+			total += history.Similarity(file, other)
+			pairs++
+		}
+	}
+	return total / float64(pairs), true
+}
+
+// historyBasedClustering groups files that often changed together in past commits. Confidence
+// is the share of the files linked to another one by their co-changes, so files that merely
+// have history do not make this method win on their own.
+func historyBasedClustering(files []string, rootFolder string, targetClusters int) ([]FileCluster, float64) {
+	history, err := LoadCoChangeHistory(rootFolder)
+	if err != nil {
+		utils.Debug("[GIT.CLUSTER]: History-based clustering unavailable: " + err.Error())
+		return newFileClusters(string(config.HistoryMethod), createSingleFileClusters(files), 0, rootFolder, nil), 0
+	}
+
+	threshold := config.GetSimilarityThreshold(config.HistoryMethod)
+	edges := make(map[string]map[string]bool)
+	linked := make(map[string]bool)
+	for i, file := range files {
+		if !history.Known(file) {
+			continue
+		}
+		for _, other := range files[i+1:] {
+			if history.Similarity(file, other) >= threshold {
+				if edges[file] == nil {
+					edges[file] = make(map[string]bool)
+				}
+				edges[file][other] = true
+				linked[file], linked[other] = true, true
+			}
+		}
+	}
+
+	components, componentOf := connectedComponents(files, edges)
+	merged := components
+	if targetClusters > 0 && len(components) > targetClusters {
+		merged = mergeClusters(components, targetClusters)
+	}
+
+	confidence := 0.0
+	if len(files) > 0 {
+		confidence = float64(len(linked)) / float64(len(files))
+	}
+
+	clusters := newFileClusters(string(config.HistoryMethod), merged, confidence, rootFolder, nil)
+	for i := range clusters {
+		if len(clusters[i].Files) < 2 || !isComponent(clusters[i].Files, componentOf) {
+			continue
+		}
+		clusters[i].Rule = RuleCoChange
+		clusters[i].FileSimilarity = make(map[string]float64, len(clusters[i].Files))
+		clusters[i].Similarity = 0
+		for _, file := range clusters[i].Files {
+			total := 0.0
+			for _, other := range clusters[i].Files {
+				if other != file {
+					total += history.Similarity(file, other)
+				}
+			}
+			similarity := total / float64(len(clusters[i].Files)-1)
+			clusters[i].FileSimilarity[file] = similarity
+			clusters[i].Similarity += similarity / float64(len(clusters[i].Files))
+		}
+	}
+
+	utils.Debug(fmt.Sprintf("[GIT.CLUSTER]: History-based clustering: %d files -> %d clusters, %d linked, confidence: %.2f",
+		len(files), len(clusters), len(linked), confidence))
+	return clusters, confidence
+}
@@ -0,0 +1,262 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PrepareCommitMsgHook is the name of the git hook GitCury installs
+const PrepareCommitMsgHook = "prepare-commit-msg"
+
+// hookMarker identifies hook scripts written by GitCury so foreign hooks are never overwritten
+const hookMarker = "# gitcury-managed-hook"
+
+// hookBackupSuffix is appended to a pre-existing hook that was moved aside during installation
+const hookBackupSuffix = ".gitcury-backup"
+
+// prepareCommitMsgScript is the hook body. Git passes the message file, the message source
+// and (for amends) a commit SHA. Merges, squashes, amends/-c/-C and messages supplied with
+// -m or -F already have a message, so the hook leaves them untouched.
+const prepareCommitMsgScript = `#!/bin/sh
+` + hookMarker + `
+# Installed by 'gitcury hooks install'. Remove with 'gitcury hooks uninstall'.
+# Set GITCURY_SKIP_HOOK=1 to commit without a generated message.
+
+COMMIT_MSG_FILE="$1"
+COMMIT_SOURCE="$2"
+
+[ -n "$GITCURY_SKIP_HOOK" ] && exit 0
+
+case "$COMMIT_SOURCE" in
+	merge|squash|commit|message) exit 0 ;;
+esac
+
+GITCURY=%s
+if [ ! -x "$GITCURY" ]; then
+	GITCURY="$(command -v gitcury 2>/dev/null)" || exit 0
+fi
+
+"$GITCURY" hooks prepare-commit-msg "$COMMIT_MSG_FILE" "$COMMIT_SOURCE" || true
+exit 0
+`
+
+// GetHooksDir returns the directory git reads hooks from for the repository in dir.
+// core.hooksPath is honoured; relative values are resolved against the work tree root as git does.
+func GetHooksDir(dir string) (string, error) {
+	// git config exits non-zero when the key is unset, which is the common case
+	hooksPath, _, err := RunGitCmdWithOutput(dir, nil, "config", "--get", "core.hooksPath")
+	if err == nil && strings.TrimSpace(hooksPath) != "" {
+		hooksPath = strings.TrimSpace(hooksPath)
+		if strings.HasPrefix(hooksPath, "~/") {
+			hooksPath = filepath.Join(os.Getenv("HOME"), hooksPath[2:])
+		}
+		if !filepath.IsAbs(hooksPath) {
+			topLevel, err := RunGitCmd(dir, nil, "rev-parse", "--show-toplevel")
+			if err != nil {
+				return "", err
+			}
+			hooksPath = filepath.Join(strings.TrimSpace(topLevel), hooksPath)
+		}
+		utils.Debug("[GIT.HOOKS]: Using core.hooksPath: " + hooksPath)
+		return hooksPath, nil
+	}
+
+	// --git-path resolves to the common git dir, so linked worktrees share the hooks
+	gitPath, err := RunGitCmd(dir, nil, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", utils.NewGitError(
+			"Failed to locate hooks directory",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+			},
+		)
+	}
+
+	gitPath = strings.TrimSpace(gitPath)
+	if !filepath.IsAbs(gitPath) {
+		gitPath = filepath.Join(dir, gitPath)
+	}
+	return filepath.Clean(gitPath), nil
+}
+
+// shellQuote quotes s for safe use in a POSIX shell script
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isManagedHook reports whether the hook at path was written by GitCury
+func isManagedHook(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(content), hookMarker)
+}
+
+// InstallPrepareCommitMsgHook writes the GitCury prepare-commit-msg hook for the repository in dir
+// and returns the path it was written to. A hook that was not written by GitCury is only
+// replaced when force is set, in which case it is kept next to the new one as a backup.
+func InstallPrepareCommitMsgHook(dir string, force bool) (string, error) {
+	hooksDir, err := GetHooksDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return "", utils.NewSystemError(
+			"Failed to create hooks directory",
+			err,
+			map[string]interface{}{
+				"hooksDir": hooksDir,
+			},
+		)
+	}
+
+	hookPath := filepath.Join(hooksDir, PrepareCommitMsgHook)
+	if _, err := os.Stat(hookPath); err == nil && !isManagedHook(hookPath) {
+		if !force {
+			return "", utils.NewValidationError(
+				"A prepare-commit-msg hook already exists",
+				nil,
+				map[string]interface{}{
+					"hookPath":   hookPath,
+					"suggestion": "Use --force to back it up and install the GitCury hook",
+				},
+			)
+		}
+
+		backupPath := hookPath + hookBackupSuffix
+		if err := os.Rename(hookPath, backupPath); err != nil {
+			return "", utils.NewSystemError(
+				"Failed to back up existing hook",
+				err,
+				map[string]interface{}{
+					"hookPath":   hookPath,
+					"backupPath": backupPath,
+				},
+			)
+		}
+		utils.Warning("Existing hook moved to " + backupPath)
+	}
+
+	// Point the hook at this binary so it works even when gitcury is not on PATH
+	executable, err := os.Executable()
+	if err != nil {
+		executable = "gitcury"
+	}
+
+	script := fmt.Sprintf(prepareCommitMsgScript, shellQuote(executable))
+	if err := os.WriteFile(hookPath, []byte(script), 0755); err != nil {
+		return "", utils.NewSystemError(
+			"Failed to write hook",
+			err,
+			map[string]interface{}{
+				"hookPath": hookPath,
+			},
+		)
+	}
+
+	utils.Debug("[GIT.HOOKS]: Installed prepare-commit-msg hook at " + hookPath)
+	return hookPath, nil
+}
+
+// UninstallPrepareCommitMsgHook removes the GitCury hook from the repository in dir and restores
+// any hook that was backed up during installation. Hooks not written by GitCury are left alone.
+func UninstallPrepareCommitMsgHook(dir string) (string, error) {
+	hooksDir, err := GetHooksDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	hookPath := filepath.Join(hooksDir, PrepareCommitMsgHook)
+	if _, err := os.Stat(hookPath); os.IsNotExist(err) {
+		utils.Debug("[GIT.HOOKS]: No prepare-commit-msg hook installed in " + hooksDir)
+		return "", nil
+	}
+
+	if !isManagedHook(hookPath) {
+		return "", utils.NewValidationError(
+			"The prepare-commit-msg hook was not installed by GitCury",
+			nil,
+			map[string]interface{}{
+				"hookPath": hookPath,
+			},
+		)
+	}
+
+	if err := os.Remove(hookPath); err != nil {
+		return "", utils.NewSystemError(
+			"Failed to remove hook",
+			err,
+			map[string]interface{}{
+				"hookPath": hookPath,
+			},
+		)
+	}
+
+	backupPath := hookPath + hookBackupSuffix
+	if _, err := os.Stat(backupPath); err == nil {
+		if err := os.Rename(backupPath, hookPath); err != nil {
+			utils.Warning("Failed to restore backed up hook " + backupPath + ": " + err.Error())
+		} else {
+			utils.Info("Restored previous hook from " + backupPath)
+		}
+	}
+
+	utils.Debug("[GIT.HOOKS]: Removed prepare-commit-msg hook from " + hooksDir)
+	return hookPath, nil
+}
+
+// PrepareCommitMsg fills the commit message file with a message generated for the staged diff
+// of the repository in dir. It is the entry point used by the installed hook; source is the
+// message source git passed to the hook.
+func PrepareCommitMsg(dir, messageFile, source string) error {
+	switch source {
+	case "merge", "squash", "commit", "message":
+		utils.Debug("[GIT.HOOKS]: Skipping message generation for source: " + source)
+		return nil
+	}
+
+	state, err := GetIndexState(dir)
+	if err != nil {
+		return err
+	}
+	if !state.HasStagedChanges() {
+		utils.Debug("[GIT.HOOKS]: Nothing staged, leaving message untouched")
+		return nil
+	}
+
+	existing, err := os.ReadFile(messageFile)
+	if err != nil {
+		return utils.NewSystemError(
+			"Failed to read commit message file",
+			err,
+			map[string]interface{}{
+				"messageFile": messageFile,
+			},
+		)
+	}
+
+	message, err := GenIndexCommitMessage(state.Staged, dir)
+	if err != nil {
+		return err
+	}
+
+	// Keep git's comment block (and any template) below the generated message
+	content := strings.TrimSpace(message) + "\n" + string(existing)
+	if err := os.WriteFile(messageFile, []byte(content), 0644); err != nil {
+		return utils.NewSystemError(
+			"Failed to write commit message file",
+			err,
+			map[string]interface{}{
+				"messageFile": messageFile,
+			},
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,356 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/embeddings"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxHunkCacheEntries bounds the hunk embedding cache; the least recently used hunks go first
+const maxHunkCacheEntries = 5000
+
+// maxUntrackedHunkBytes is how much of a new file is embedded as its single hunk
+const maxUntrackedHunkBytes = 10000
+
+// DiffHunk is one normalized change of a file. Line numbers are left out, so a hunk keeps its
+// hash when unrelated edits move it.
+type DiffHunk struct {
+	File    string `json:"file"`
+	Text    string `json:"text"`    // Section heading and changed lines, whitespace collapsed
+	Hash    string `json:"hash"`    // sha256 of Text
+	Changes int    `json:"changes"` // Added and removed lines, used to weigh the hunk
+}
+
+// HunkCacheEntry is the cached embedding of one hunk
+type HunkCacheEntry struct {
+	Embedding []float32 `json:"embedding"`
+	Backend   string    `json:"backend"`
+	LastUsed  time.Time `json:"lastUsed"`
+}
+
+// HunkEmbeddingCache caches hunk embeddings by hunk hash and embedding backend, so cache hits
+// survive edits elsewhere in the same file. Entries live in the embedding store, shared by all
+// root folders; Entries only holds the ones used by this process.
+type HunkEmbeddingCache struct {
+	RootFolder  string                    `json:"rootFolder"`
+	Entries     map[string]HunkCacheEntry `json:"entries"` // "<backend>:<hunk hash>" -> entry
+	LastUpdated time.Time                 `json:"lastUpdated"`
+	Hits        int                       `json:"-"`
+	Misses      int                       `json:"-"`
+
+	lastRequest time.Time
+	store       *embeddings.VectorStore
+	stored      map[string]time.Time // Key -> when the entry was last written to the store
+}
+
+// useHunkEmbeddings reports whether files are embedded hunk by hunk
+func useHunkEmbeddings() bool {
+	return config.GetClusteringConfig().Methods.Semantic.EmbedInput == config.EmbedDiffHunks
+}
+
+// FileDiffHunks returns the normalized hunks of file's changes since HEAD. A file git does not
+// track yet is one hunk of added lines.
+func FileDiffHunks(file, rootFolder string) ([]DiffHunk, error) {
+	diff, _, err := RunGitCmdWithOutput(rootFolder, nil, "diff", "-U0", "--no-color", "--no-ext-diff", "HEAD", "--", file)
+	if err == nil && strings.TrimSpace(diff) != "" {
+		return parseDiffHunks(file, diff), nil
+	}
+
+	content, readErr := os.ReadFile(file) //nolint:gosec // Changed file reported by git
+	if readErr != nil {
+		return nil, utils.NewGitError("Failed to read the changes of a file", readErr, map[string]interface{}{
+			"file": file,
+		})
+	}
+	// Cut at a rune boundary so a multi-byte character does not make the text look binary
+	text := truncateAtRune(string(content), maxUntrackedHunkBytes)
+	if !utf8.ValidString(text) {
+		return nil, nil // Binary files have nothing to embed
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, "+"+line)
+	}
+	if hunk, ok := newDiffHunk(file, "", lines); ok {
+		return []DiffHunk{hunk}, nil
+	}
+	return nil, nil
+}
+
+// parseDiffHunks splits a unified diff of one file into normalized hunks
+func parseDiffHunks(file, diff string) []DiffHunk {
+	var hunks []DiffHunk
+	var heading string
+	var lines []string
+	inHunk := false
+	flush := func() {
+		if hunk, ok := newDiffHunk(file, heading, lines); ok {
+			hunks = append(hunks, hunk)
+		}
+		lines = nil
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			if inHunk {
+				flush()
+			}
+			inHunk = true
+			heading = ""
+			// The text after the second @@ names the enclosing function or section
+			if end := strings.Index(line[2:], "@@"); end >= 0 {
+				heading = strings.TrimSpace(line[end+4:])
+			}
+		case !inHunk:
+			// diff --git, index, ---/+++ headers
+		case strings.HasPrefix(line, "+"), strings.HasPrefix(line, "-"):
+			lines = append(lines, line)
+		}
+	}
+	if inHunk {
+		flush()
+	}
+	return hunks
+}
+
+// newDiffHunk normalizes the changed lines of a hunk; hunks that only change whitespace are dropped
+func newDiffHunk(file, heading string, lines []string) (DiffHunk, bool) {
+	var builder strings.Builder
+	if heading != "" {
+		builder.WriteString("@@ " + strings.Join(strings.Fields(heading), " ") + "\n")
+	}
+	changes := 0
+	for _, line := range lines {
+		content := strings.Join(strings.Fields(line[1:]), " ")
+		if content == "" {
+			continue
+		}
+		builder.WriteString(line[:1] + content + "\n")
+		changes++
+	}
+	if changes == 0 {
+		return DiffHunk{}, false
+	}
+
+	text := builder.String()
+	hash := sha256.Sum256([]byte(text))
+	return DiffHunk{File: file, Text: text, Hash: hex.EncodeToString(hash[:]), Changes: changes}, true
+}
+
+// hunkRefreshAge is how old the stored copy of a used hunk may get before it is written again,
+// which keeps recently used hunks from being pruned without rewriting every hit
+const hunkRefreshAge = 24 * time.Hour
+
+// LoadHunkEmbeddingCache opens the hunk cache for rootFolder. Without the embedding store the
+// cache only lasts for this process.
+func LoadHunkEmbeddingCache(rootFolder string) *HunkEmbeddingCache {
+	cache := &HunkEmbeddingCache{
+		RootFolder: rootFolder,
+		Entries:    make(map[string]HunkCacheEntry),
+		stored:     make(map[string]time.Time),
+	}
+
+	store, err := OpenEmbeddingStore()
+	if err != nil {
+		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to open the embedding store: %v", err))
+		return cache
+	}
+	entries, err := store.Entries(hunkStorePrefix)
+	if err != nil {
+		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to read cached hunk embeddings: %v", err))
+		return cache
+	}
+	cache.store = store
+	for _, entry := range entries {
+		cache.stored[strings.TrimPrefix(entry.Key, hunkStorePrefix)] = entry.Updated
+	}
+	return cache
+}
+
+// Save writes new and recently used hunks to the embedding store, dropping the least recently
+// used hunks beyond maxHunkCacheEntries
+func (cache *HunkEmbeddingCache) Save() {
+	cache.LastUpdated = time.Now()
+	if cache.store == nil {
+		return
+	}
+
+	var changed []embeddings.StoredVector
+	for key, entry := range cache.Entries {
+		if stored, ok := cache.stored[key]; ok && entry.LastUsed.Sub(stored) < hunkRefreshAge {
+			continue
+		}
+		changed = append(changed, embeddings.StoredVector{
+			Key: hunkStorePrefix + key, Vector: entry.Embedding, Backend: entry.Backend, Updated: entry.LastUsed,
+		})
+	}
+	if err := cache.store.Put(changed...); err != nil {
+		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to save hunk embeddings: %v", err))
+		return
+	}
+	for _, vector := range changed {
+		cache.stored[strings.TrimPrefix(vector.Key, hunkStorePrefix)] = vector.Updated
+	}
+
+	if removed, err := cache.store.Prune(hunkStorePrefix, maxHunkCacheEntries); err != nil {
+		utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Failed to prune hunk embeddings: %v", err))
+	} else if removed > 0 {
+		utils.Debug(fmt.Sprintf("[GIT.CLUSTER]: Pruned %d least recently used hunk embeddings", removed))
+	}
+}
+
+// lookup returns a cached hunk embedding, reading it from the store on first use
+func (cache *HunkEmbeddingCache) lookup(key string) (HunkCacheEntry, bool) {
+	if entry, ok := cache.Entries[key]; ok && len(entry.Embedding) > 0 {
+		return entry, true
+	}
+	if _, ok := cache.stored[key]; !ok || cache.store == nil {
+		return HunkCacheEntry{}, false
+	}
+	vector, found, err := cache.store.Get(hunkStorePrefix + key)
+	if err != nil || !found || len(vector.Vector) == 0 {
+		return HunkCacheEntry{}, false
+	}
+	entry := HunkCacheEntry{Embedding: vector.Vector, Backend: vector.Backend, LastUsed: vector.Updated}
+	cache.Entries[key] = entry
+	return entry, true
+}
+
+// HitRatio is the share of hunk lookups answered from the cache
+func (cache *HunkEmbeddingCache) HitRatio() float64 {
+	if cache.Hits+cache.Misses == 0 {
+		return 0
+	}
+	return float64(cache.Hits) / float64(cache.Hits+cache.Misses)
+}
+
+// embedHunk returns the embedding of a hunk, from the cache when possible. Requests to a
+// metered API are spaced by the configured rate limit delay.
+func (cache *HunkEmbeddingCache) embedHunk(hunk DiffHunk) ([]float32, error) {
+	backend := embeddings.BackendName()
+	key := backend + ":" + hunk.Hash
+	if entry, ok := cache.lookup(key); ok {
+		cache.Hits++
+		entry.LastUsed = time.Now()
+		cache.Entries[key] = entry
+		return entry.Embedding, nil
+	}
+	cache.Misses++
+
+	if embeddings.IsRateLimited() && !cache.lastRequest.IsZero() {
+		delay := time.Duration(config.GetClusteringConfig().Methods.Semantic.RateLimitDelay) * time.Millisecond
+		if wait := delay - time.Since(cache.lastRequest); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+	cache.lastRequest = time.Now()
+
+	embedding, err := embeddings.GenerateEmbedding(hunk.Text)
+	if err != nil {
+		return nil, err
+	}
+	cache.Entries[key] = HunkCacheEntry{Embedding: embedding, Backend: backend, LastUsed: time.Now()}
+	return embedding, nil
+}
+
+// EmbedFile embeds each changed hunk of file and returns their mean, weighted by the number of
+// changed lines and normalized to unit length
+func (cache *HunkEmbeddingCache) EmbedFile(file string) ([]float32, error) {
+	hunks, err := FileDiffHunks(file, cache.RootFolder)
+	if err != nil {
+		return nil, err
+	}
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("no changes to embed in %s", file)
+	}
+
+	var mean []float32
+	for _, hunk := range hunks {
+		embedding, err := cache.embedHunk(hunk)
+		if err != nil {
+			return nil, err
+		}
+		if mean == nil {
+			mean = make([]float32, len(embedding))
+		}
+		if len(embedding) != len(mean) {
+			continue
+		}
+		for i, value := range embedding {
+			mean[i] += value * float32(hunk.Changes)
+		}
+	}
+	return normalizeVector(mean), nil
+}
+
+// cachedHunkRatio is the share of the hunks of files that already have a cached embedding
+func (cache *HunkEmbeddingCache) cachedHunkRatio(files []string) float64 {
+	backend := embeddings.BackendName()
+	total, cached := 0, 0
+	for _, file := range files {
+		hunks, err := FileDiffHunks(file, cache.RootFolder)
+		if err != nil {
+			continue
+		}
+		for _, hunk := range hunks {
+			total++
+			key := backend + ":" + hunk.Hash
+			if _, ok := cache.Entries[key]; ok {
+				cached++
+			} else if _, ok := cache.stored[key]; ok {
+				cached++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(cached) / float64(total)
+}
+
+// hunkEmbeddingClustering is cachedEmbeddingClustering for hunk embeddings: it only runs when
+// enough hunks are cached already
+func hunkEmbeddingClustering(files []string, rootFolder string, targetClusters int) ([][]string, float64, float64, map[string][]float32) {
+	cache := LoadHunkEmbeddingCache(rootFolder)
+	cacheHitRatio := cache.cachedHunkRatio(files)
+	if cacheHitRatio < 0.3 {
+		return createSingleFileClusters(files), 0.0, cacheHitRatio, nil
+	}
+
+	fileEmbeddings := hunkFileEmbeddings(files, cache)
+	cache.Save()
+	if len(fileEmbeddings) < 2 {
+		return createSingleFileClusters(files), 0.0, cacheHitRatio, fileEmbeddings
+	}
+
+	clusters := performEmbeddingBasedClustering(fileEmbeddings, targetClusters)
+	confidence := calculateEmbeddingClusterConfidence(clusters, fileEmbeddings)
+	utils.Debug(fmt.Sprintf("[GIT.CLUSTER]: Hunk embedding clustering: %d files -> %d clusters, cached hunks: %.2f, confidence: %.2f",
+		len(files), len(clusters), cacheHitRatio, confidence))
+	return clusters, confidence, cacheHitRatio, fileEmbeddings
+}
+
+// hunkFileEmbeddings embeds every file hunk by hunk, skipping files that fail
+func hunkFileEmbeddings(files []string, cache *HunkEmbeddingCache) map[string][]float32 {
+	fileEmbeddings := make(map[string][]float32)
+	for _, file := range files {
+		embedding, err := cache.EmbedFile(file)
+		if err != nil {
+			utils.Warning(fmt.Sprintf("[GIT.CLUSTER]: Could not embed the changes of %s - %v", file, err))
+			continue
+		}
+		fileEmbeddings[file] = embedding
+	}
+	utils.Debug(fmt.Sprintf("[GIT.CLUSTER]: Hunk embeddings for %d files, hunk cache hit ratio: %.2f",
+		len(fileEmbeddings), cache.HitRatio()))
+	return fileEmbeddings
+}
@@ -0,0 +1,120 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// emailPattern is a deliberately loose check that catches typos such as missing @
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// ResolveIdentity validates the identity configured for dir and returns the name and email
+// commits will use, falling back to git config for values the profile does not set.
+func ResolveIdentity(dir string, identity config.Identity) (string, string, error) {
+	name := identity.Name
+	if name == "" {
+		name = gitConfigValue(dir, "user.name")
+	}
+	email := identity.Email
+	if email == "" {
+		email = gitConfigValue(dir, "user.email")
+	}
+
+	if name == "" || email == "" {
+		return name, email, utils.NewValidationError(
+			"Commit identity is incomplete",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"profile":    identity.Profile,
+				"name":       name,
+				"email":      email,
+				"suggestion": "Set name and email in the identity profile or in git config",
+			},
+		)
+	}
+	if !emailPattern.MatchString(email) {
+		return name, email, utils.NewValidationError(
+			"Commit email is not a valid address",
+			nil,
+			map[string]interface{}{
+				"directory": dir,
+				"profile":   identity.Profile,
+				"email":     email,
+			},
+		)
+	}
+
+	return name, email, nil
+}
+
+// ValidateIdentity checks the identity configured for dir without committing anything
+func ValidateIdentity(dir string) error {
+	identity, err := config.GetIdentity(dir)
+	if err != nil || (!identity.IsSet() && len(identity.EmailDomains) == 0) {
+		return err
+	}
+	_, _, err = ResolveIdentity(dir, identity)
+	return err
+}
+
+// matchesEmailDomain reports whether email belongs to one of domains or their subdomains
+func matchesEmailDomain(email string, domains []string) bool {
+	domain := strings.ToLower(email[strings.LastIndex(email, "@")+1:])
+	for _, allowed := range domains {
+		allowed = strings.ToLower(strings.TrimPrefix(allowed, "@"))
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyIdentity validates the identity of dir and sets it as author and committer in envMap.
+// Identities set explicitly in the environment are left alone. A profile signing key signs
+// the commits unless a key was chosen for this run.
+func applyIdentity(dir string, envMap map[string]string, commitConfig *config.CommitConfig) error {
+	identity, err := config.GetIdentity(dir)
+	if err != nil {
+		return err
+	}
+	if !identity.IsSet() && len(identity.EmailDomains) == 0 {
+		return nil
+	}
+
+	name, email, err := ResolveIdentity(dir, identity)
+	if err != nil {
+		return err
+	}
+
+	if len(identity.EmailDomains) > 0 && !matchesEmailDomain(email, identity.EmailDomains) {
+		utils.Warning("⚠️ Committing in " + dir + " as " + email + ", which is not an address of " +
+			strings.Join(identity.EmailDomains, ", ") + ". Check the identity profile for this root folder.")
+	}
+
+	for _, role := range []string{"AUTHOR", "COMMITTER"} {
+		setUnlessExplicit(envMap, "GIT_"+role+"_NAME", name)
+		setUnlessExplicit(envMap, "GIT_"+role+"_EMAIL", email)
+	}
+	if identity.SigningKey != "" && commitConfig.SigningKey == "" {
+		commitConfig.SigningKey = identity.SigningKey
+		commitConfig.Sign = true
+	}
+
+	utils.Debug("[GIT.IDENTITY]: Committing in " + dir + " as " + name + " <" + email + ">")
+	return nil
+}
+
+// setUnlessExplicit sets key in envMap unless it was passed in or set in the process environment
+func setUnlessExplicit(envMap map[string]string, key, value string) {
+	if _, ok := envMap[key]; ok {
+		return
+	}
+	if _, ok := os.LookupEnv(key); ok {
+		return
+	}
+	envMap[key] = value
+}
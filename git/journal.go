@@ -0,0 +1,283 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxJournalEntries is the number of operations kept in a repository's journal
+const maxJournalEntries = 50
+
+// JournalEntry records one GitCury operation in a root folder so that it can be undone
+type JournalEntry struct {
+	ID        string             `json:"id"`        // Shared by all root folders of one command run
+	Operation string             `json:"operation"` // e.g. "commit" or "commit-staged"
+	Time      time.Time          `json:"time"`
+	Directory string             `json:"directory"`
+	Branch    string             `json:"branch"`
+	PreHead   string             `json:"preHead"` // Empty when the branch had no commits yet
+	Commits   []string           `json:"commits"` // Created commits, oldest first
+	Messages  []output.FileEntry `json:"messages"`
+	Pushed    bool               `json:"pushed"`
+	Undone    bool               `json:"undone"`
+}
+
+// journalMu serialises journal reads and writes within the process
+var journalMu sync.Mutex
+
+// journalPath returns the journal file of the repository in dir; it lives in the git
+// directory so it follows the repository and is never committed
+func journalPath(dir string) (string, error) {
+	return gitPath(dir, "gitcury/journal.json")
+}
+
+// ReadJournal returns the recorded operations of dir, oldest first
+func ReadJournal(dir string) ([]JournalEntry, error) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	return readJournal(dir)
+}
+
+func readJournal(dir string) ([]JournalEntry, error) {
+	path, err := journalPath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, utils.NewSystemError("Failed to read the operation journal", err, map[string]interface{}{"path": path})
+	}
+
+	var entries []JournalEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, utils.NewSystemError("Failed to decode the operation journal", err, map[string]interface{}{"path": path})
+	}
+	return entries, nil
+}
+
+func writeJournal(dir string, entries []JournalEntry) error {
+	path, err := journalPath(dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) > maxJournalEntries {
+		entries = entries[len(entries)-maxJournalEntries:]
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return utils.NewSystemError("Failed to encode the operation journal", err, map[string]interface{}{"path": path})
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return utils.NewSystemError("Failed to create the journal directory", err, map[string]interface{}{"path": path})
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return utils.NewSystemError("Failed to write the operation journal", err, map[string]interface{}{"path": path})
+	}
+	return nil
+}
+
+// updateJournal applies update to the journal of dir and saves it
+func updateJournal(dir string, update func([]JournalEntry) []JournalEntry) error {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	entries, err := readJournal(dir)
+	if err != nil {
+		return err
+	}
+	return writeJournal(dir, update(entries))
+}
+
+// revParse resolves rev in dir, returning "" when it does not exist (e.g. an unborn HEAD)
+func revParse(dir, rev string) string {
+	hash, err := RunGitCmd(dir, nil, "rev-parse", "--verify", "-q", rev+"^{commit}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(hash)
+}
+
+// beginJournalEntry captures the state of dir before an operation that creates commits
+func beginJournalEntry(dir, operation string, messages []output.FileEntry, env ...[]string) *JournalEntry {
+	entry := &JournalEntry{
+		ID:        config.GetOperationID(env...),
+		Operation: operation,
+		Time:      time.Now(),
+		Directory: dir,
+		PreHead:   revParse(dir, "HEAD"),
+		Messages:  append([]output.FileEntry{}, messages...),
+	}
+	entry.Branch, _ = CurrentBranch(dir)
+	return entry
+}
+
+// finish records the commits created since the entry began. Nothing is written when the
+// operation created no commits. Journal failures never fail the operation itself.
+func (entry *JournalEntry) finish() {
+	revRange := "HEAD"
+	if entry.PreHead != "" {
+		revRange = entry.PreHead + "..HEAD"
+	}
+	revs, err := RunGitCmd(entry.Directory, nil, "rev-list", "--reverse", revRange)
+	if err != nil || strings.TrimSpace(revs) == "" {
+		return
+	}
+	entry.Commits = strings.Fields(revs)
+
+	err = updateJournal(entry.Directory, func(entries []JournalEntry) []JournalEntry {
+		return append(entries, *entry)
+	})
+	if err != nil {
+		utils.Warning("⚠️ Could not record the operation for undo: " + err.Error())
+		return
+	}
+	utils.Debug(fmt.Sprintf("[GIT.JOURNAL]: Recorded %s with %d commit(s) in %s", entry.Operation, len(entry.Commits), entry.Directory))
+}
+
+// isPushed reports whether any of commits is reachable from a remote-tracking branch
+func isPushed(dir string, commits []string) bool {
+	if len(commits) == 0 {
+		return false
+	}
+	local, err := RunGitCmd(dir, nil, append(append([]string{"rev-list"}, commits...), "--not", "--remotes")...)
+	if err != nil {
+		return false
+	}
+	unpushed := make(map[string]bool)
+	for _, hash := range strings.Fields(local) {
+		unpushed[hash] = true
+	}
+	for _, hash := range commits {
+		if !unpushed[hash] {
+			return true
+		}
+	}
+	return false
+}
+
+// markJournalPushed flags the recorded operations of dir whose commits reached a remote
+func markJournalPushed(dir string) {
+	err := updateJournal(dir, func(entries []JournalEntry) []JournalEntry {
+		for i := range entries {
+			if !entries[i].Pushed && !entries[i].Undone && isPushed(dir, entries[i].Commits) {
+				entries[i].Pushed = true
+			}
+		}
+		return entries
+	})
+	if err != nil {
+		utils.Debug("[GIT.JOURNAL]: Could not mark pushed operations: " + err.Error())
+	}
+}
+
+// LastJournalEntry returns the most recent operation of dir that has not been undone, or nil
+func LastJournalEntry(dir string) (*JournalEntry, error) {
+	entries, err := ReadJournal(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].Undone {
+			return &entries[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// UndoJournalEntry moves the branch of dir back to the state before entry, keeping the
+// changes of the created commits in the working tree. Pushed commits are only reset with
+// force. It returns the backup ref that holds the undone commits.
+func UndoJournalEntry(dir string, entry JournalEntry, force bool) (string, error) {
+	head := revParse(dir, "HEAD")
+	if len(entry.Commits) == 0 || head != entry.Commits[len(entry.Commits)-1] {
+		return "", utils.NewValidationError(
+			"The branch changed since the operation",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"operation":  entry.Operation,
+				"suggestion": "Only the latest operation can be undone while HEAD is still its last commit",
+			},
+		)
+	}
+	if branch, _ := CurrentBranch(dir); branch != entry.Branch {
+		return "", utils.NewValidationError(
+			"The operation was made on another branch",
+			nil,
+			map[string]interface{}{
+				"directory": dir,
+				"branch":    entry.Branch,
+			},
+		)
+	}
+	if operation := operationInProgress(dir); operation != "" {
+		return "", utils.NewValidationError(
+			"A "+operation+" is in progress",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"suggestion": "Finish or abort it before undoing",
+			},
+		)
+	}
+
+	if (entry.Pushed || isPushed(dir, entry.Commits)) && !force {
+		return "", utils.NewValidationError(
+			"Refusing to undo commits that are already pushed",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"commits":    len(entry.Commits),
+				"suggestion": "Use --force to reset anyway; the remote will then need a force push",
+			},
+		)
+	}
+
+	backupRef, err := CreateBackupRef(dir, "undo")
+	if err != nil {
+		return "", err
+	}
+
+	err = SafeGitOperation(dir, "undo", func() error {
+		if entry.PreHead != "" {
+			_, err := RunGitCmd(dir, nil, "reset", "-q", "--mixed", entry.PreHead)
+			return err
+		}
+		// The operation created the first commit; make the branch unborn again
+		if _, err := RunGitCmd(dir, nil, "update-ref", "-d", "HEAD"); err != nil {
+			return err
+		}
+		_, err := RunGitCmd(dir, nil, "rm", "-r", "-q", "--cached", "--ignore-unmatch", ".")
+		return err
+	})
+	if err != nil {
+		return backupRef, utils.NewGitError("Failed to reset the branch", err, map[string]interface{}{"directory": dir, "backupRef": backupRef})
+	}
+
+	err = updateJournal(dir, func(entries []JournalEntry) []JournalEntry {
+		for i := range entries {
+			if entries[i].ID == entry.ID && entries[i].Time.Equal(entry.Time) {
+				entries[i].Undone = true
+			}
+		}
+		return entries
+	})
+	if err != nil {
+		utils.Warning("⚠️ The operation was undone but the journal could not be updated: " + err.Error())
+	}
+
+	utils.Info(fmt.Sprintf("[GIT.UNDO.SUCCESS]: Reset %d commit(s) in %s (backup: %s)", len(entry.Commits), dir, backupRef))
+	return backupRef, nil
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"github.com/lakshyajain-0291/gitcury/interfaces"
 	"github.com/lakshyajain-0291/gitcury/output"
 	"github.com/lakshyajain-0291/gitcury/utils"
 	"fmt"
@@ -93,10 +94,14 @@ func ProgressCommitBatch(rootFolder output.Folder, env ...[]string) error {
 }
 
 // ProgressPushBranch is an enhanced version of PushBranch that includes progress reporting
-func ProgressPushBranch(rootFolderName string, branch string) error {
+func ProgressPushBranch(rootFolderName string, branch string, opts ...interfaces.PushOptions) error {
 	if branch == "" {
-		utils.Debug("[GIT.PUSH]: Branch name is empty, defaulting to 'main'")
-		branch = "main"
+		current, err := CurrentBranch(rootFolderName)
+		if err != nil {
+			return err
+		}
+		utils.Debug("[GIT.PUSH]: Branch name is empty, using current branch '" + current + "'")
+		branch = current
 	}
 
 	// Start stats tracking
@@ -138,8 +143,7 @@ func ProgressPushBranch(rootFolderName string, branch string) error {
 
 	// Use SafeGitOperation to handle index.lock and other recovery scenarios
 	err := SafeGitOperation(rootFolderName, "push", func() error {
-		_, gitErr := RunGitCmd(rootFolderName, nil, "push", "origin", branch)
-		return gitErr
+		return SmartPush(rootFolderName, branch, opts...)
 	})
 
 	if err != nil {
@@ -151,7 +155,7 @@ func ProgressPushBranch(rootFolderName string, branch string) error {
 		}
 
 		utils.Error("[GIT.PUSH.FAIL]: Failed to push branch: " + err.Error())
-		return fmt.Errorf("failed to push branch: %s", err.Error())
+		return err
 	}
 
 	// Update final progress
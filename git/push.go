@@ -0,0 +1,317 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/interfaces"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// PushPlan describes what a push will do once the branch, upstream and options are resolved
+type PushPlan struct {
+	LocalBranch    string `json:"localBranch"`
+	Remote         string `json:"remote"`
+	RemoteBranch   string `json:"remoteBranch"`
+	HasUpstream    bool   `json:"hasUpstream"`
+	SetUpstream    bool   `json:"setUpstream"`
+	ForceWithLease bool   `json:"forceWithLease"`
+	Rebase         bool   `json:"rebase"`
+	Ahead          int    `json:"ahead"`
+	Behind         int    `json:"behind"`
+	RemoteKnown    bool   `json:"remoteKnown"` // Whether the remote-tracking branch exists locally
+}
+
+// CurrentBranch returns the branch checked out in dir, or an error when HEAD is detached
+func CurrentBranch(dir string) (string, error) {
+	branch, _, err := RunGitCmdWithOutput(dir, nil, "symbolic-ref", "--short", "-q", "HEAD")
+	branch = strings.TrimSpace(branch)
+	if err != nil || branch == "" {
+		return "", utils.NewGitError(
+			"HEAD is detached; specify the branch to push",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+			},
+		)
+	}
+	return branch, nil
+}
+
+// gitConfigValue reads a git config value in dir, returning "" when it is unset
+func gitConfigValue(dir, key string) string {
+	value, _, err := RunGitCmdWithOutput(dir, nil, "config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+// AheadBehind counts the commits in local that are not in remoteRef and vice versa
+func AheadBehind(dir, local, remoteRef string) (int, int, error) {
+	counts, err := RunGitCmd(dir, nil, "rev-list", "--left-right", "--count", local+"..."+remoteRef)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fields := strings.Fields(counts)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", counts)
+	}
+
+	ahead, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	behind, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
+
+// IsProtectedBranch reports whether branch matches one of the protected names or globs
+func IsProtectedBranch(branch string, protected []string) bool {
+	for _, pattern := range protected {
+		if pattern == branch {
+			return true
+		}
+		if matched, err := path.Match(pattern, branch); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolvePushPlan works out where branch should be pushed. An empty branch means the current
+// one. The branch's upstream (@{u}) wins over the configured remote unless a remote is given in opts.
+func ResolvePushPlan(dir, branch string, opts ...interfaces.PushOptions) (PushPlan, error) {
+	var options interfaces.PushOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	pushConfig := config.GetPushConfig(dir)
+
+	if branch == "" {
+		current, err := CurrentBranch(dir)
+		if err != nil {
+			return PushPlan{}, err
+		}
+		branch = current
+	}
+
+	plan := PushPlan{
+		LocalBranch:    branch,
+		Remote:         pushConfig.Remote,
+		RemoteBranch:   branch,
+		SetUpstream:    options.SetUpstream || pushConfig.SetUpstream,
+		ForceWithLease: options.ForceWithLease || pushConfig.ForceWithLease,
+		Rebase:         options.Rebase || pushConfig.PullRebase,
+	}
+
+	// Read the upstream from branch config rather than parsing "remote/branch", remote names may contain slashes
+	upstreamRemote := gitConfigValue(dir, "branch."+branch+".remote")
+	upstreamMerge := gitConfigValue(dir, "branch."+branch+".merge")
+	if upstreamRemote != "" && upstreamRemote != "." && upstreamMerge != "" {
+		plan.HasUpstream = true
+		plan.Remote = upstreamRemote
+		plan.RemoteBranch = strings.TrimPrefix(upstreamMerge, "refs/heads/")
+	}
+
+	if options.Remote != "" && options.Remote != plan.Remote {
+		plan.Remote = options.Remote
+		plan.RemoteBranch = branch
+		plan.HasUpstream = false
+	}
+
+	if plan.HasUpstream {
+		// Nothing to record when the upstream already points where we push
+		plan.SetUpstream = false
+	}
+
+	utils.Debug(fmt.Sprintf("[GIT.PUSH]: Plan for %s: %s -> %s/%s (upstream=%t)",
+		dir, plan.LocalBranch, plan.Remote, plan.RemoteBranch, plan.HasUpstream))
+	return plan, nil
+}
+
+// checkProtectedBranch applies the protected branch policy of dir to plan
+func checkProtectedBranch(dir string, plan PushPlan) error {
+	pushConfig := config.GetPushConfig(dir)
+	if !IsProtectedBranch(plan.RemoteBranch, pushConfig.ProtectedBranches) {
+		return nil
+	}
+
+	if pushConfig.ProtectedPolicy == config.ProtectedPolicyConfirm {
+		details := []string{
+			"Repository: " + dir,
+			"Target: " + plan.Remote + "/" + plan.RemoteBranch,
+			"This branch is listed in push.protectedBranches.",
+		}
+		if utils.ConfirmActionWithDetails("Push to protected branch '"+plan.RemoteBranch+"'?", details, false) {
+			return nil
+		}
+	}
+
+	return utils.NewValidationError(
+		"Refusing to push to protected branch",
+		nil,
+		map[string]interface{}{
+			"directory":  dir,
+			"branch":     plan.RemoteBranch,
+			"remote":     plan.Remote,
+			"suggestion": "Push to a feature branch, or change push.protectedBranches / push.protectedPolicy",
+		},
+	)
+}
+
+// SmartPush pushes branch (or the current branch) of the repository in dir. It honours the
+// upstream, fetches to check ahead/behind, optionally rebases onto the remote first and
+// refuses protected branches.
+func SmartPush(dir, branch string, opts ...interfaces.PushOptions) error {
+	plan, err := ResolvePushPlan(dir, branch, opts...)
+	if err != nil {
+		return err
+	}
+
+	if err := checkProtectedBranch(dir, plan); err != nil {
+		return err
+	}
+
+	remoteRef := plan.Remote + "/" + plan.RemoteBranch
+
+	// The lease is what the user last saw of the remote branch. It is resolved before fetching,
+	// which moves the remote-tracking ref and would make a bare --force-with-lease always match.
+	// Without a tracking ref the lease is empty, so the push only goes through while the remote
+	// branch does not exist.
+	lease := ""
+	if plan.ForceWithLease {
+		if sha, _, err := RunGitCmdWithOutput(dir, nil, "rev-parse", "--verify", "--quiet", "refs/remotes/"+remoteRef); err == nil {
+			lease = strings.TrimSpace(sha)
+		}
+	}
+
+	if config.GetPushConfig(dir).FetchBeforePush {
+		if _, stderr, err := RunGitCmdWithOutput(dir, nil, "fetch", "--quiet", plan.Remote, plan.RemoteBranch); err != nil {
+			// A branch that does not exist on the remote yet is the normal first-push case
+			utils.Debug("[GIT.PUSH]: Fetch of " + remoteRef + " failed: " + strings.TrimSpace(stderr))
+		}
+	}
+
+	if _, _, err := RunGitCmdWithOutput(dir, nil, "rev-parse", "--verify", "--quiet", "refs/remotes/"+remoteRef); err == nil {
+		plan.RemoteKnown = true
+		plan.Ahead, plan.Behind, err = AheadBehind(dir, plan.LocalBranch, remoteRef)
+		if err != nil {
+			return utils.NewGitError(
+				"Failed to compare branch with remote",
+				err,
+				map[string]interface{}{
+					"directory": dir,
+					"branch":    plan.LocalBranch,
+					"remoteRef": remoteRef,
+				},
+			)
+		}
+	}
+
+	if plan.RemoteKnown && plan.Behind > 0 && !plan.ForceWithLease {
+		if !plan.Rebase {
+			return utils.NewGitError(
+				fmt.Sprintf("Branch is %d commit(s) behind %s", plan.Behind, remoteRef),
+				nil,
+				map[string]interface{}{
+					"directory":  dir,
+					"ahead":      plan.Ahead,
+					"behind":     plan.Behind,
+					"suggestion": "Use --rebase to pull --rebase first, or --force-with-lease to overwrite the remote branch",
+				},
+			)
+		}
+
+		if err := pullRebase(dir, plan); err != nil {
+			return err
+		}
+		plan.Behind = 0
+	}
+
+	if plan.RemoteKnown && plan.Ahead == 0 && plan.Behind == 0 && !plan.SetUpstream {
+		utils.Info("[GIT.PUSH]: " + plan.LocalBranch + " is up to date with " + remoteRef)
+		return nil
+	}
+
+	args := []string{"push"}
+	if plan.SetUpstream {
+		args = append(args, "--set-upstream")
+	}
+	if plan.ForceWithLease {
+		args = append(args, "--force-with-lease=refs/heads/"+plan.RemoteBranch+":"+lease)
+	}
+	args = append(args, plan.Remote, plan.LocalBranch+":"+plan.RemoteBranch)
+
+	utils.Debug("[GIT.PUSH]: git " + strings.Join(args, " "))
+	stdout, stderr, err := RunGitCmdWithOutput(dir, nil, args...)
+	if err != nil {
+		utils.Error("[GIT.PUSH.FAIL]: " + strings.TrimSpace(stderr))
+		return utils.NewGitError(
+			"Failed to push branch",
+			err,
+			map[string]interface{}{
+				"directory":    dir,
+				"branch":       plan.LocalBranch,
+				"remote":       plan.Remote,
+				"remoteBranch": plan.RemoteBranch,
+				"stdout":       strings.TrimSpace(stdout),
+				"stderr":       strings.TrimSpace(stderr),
+			},
+		)
+	}
+
+	// Every push goes through here, so the journal learns which operations can no longer be undone safely
+	markJournalPushed(dir)
+
+	if !plan.HasUpstream && !plan.SetUpstream {
+		utils.Info("💡 " + plan.LocalBranch + " has no upstream; use --set-upstream to track " + remoteRef)
+	}
+
+	if plan.RemoteKnown {
+		utils.Info(fmt.Sprintf("[GIT.PUSH.SUCCESS]: Pushed %s to %s (%d commit(s))", plan.LocalBranch, remoteRef, plan.Ahead))
+	} else {
+		utils.Info(fmt.Sprintf("[GIT.PUSH.SUCCESS]: Pushed new branch %s to %s", plan.LocalBranch, remoteRef))
+	}
+	return nil
+}
+
+// pullRebase rebases the local branch onto its remote counterpart, aborting cleanly on conflicts
+func pullRebase(dir string, plan PushPlan) error {
+	current, err := CurrentBranch(dir)
+	if err != nil || current != plan.LocalBranch {
+		return utils.NewValidationError(
+			"Can only rebase the checked out branch before pushing",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+				"branch":    plan.LocalBranch,
+				"current":   current,
+			},
+		)
+	}
+
+	utils.Info(fmt.Sprintf("⬇️ %s is %d commit(s) behind, rebasing onto %s/%s", plan.LocalBranch, plan.Behind, plan.Remote, plan.RemoteBranch))
+	_, stderr, err := RunGitCmdWithOutput(dir, nil, "pull", "--rebase", "--autostash", plan.Remote, plan.RemoteBranch)
+	if err != nil {
+		if _, _, abortErr := RunGitCmdWithOutput(dir, nil, "rebase", "--abort"); abortErr != nil {
+			utils.Debug("[GIT.PUSH]: rebase --abort failed: " + abortErr.Error())
+		}
+		return utils.NewGitError(
+			"pull --rebase failed; the rebase was aborted",
+			err,
+			map[string]interface{}{
+				"directory":  dir,
+				"stderr":     strings.TrimSpace(stderr),
+				"suggestion": "Resolve the divergence manually, then push again",
+			},
+		)
+	}
+	return nil
+}
@@ -62,7 +62,7 @@ func CheckRepositoryHealth(dir string) error {
 	}
 
 	// Check if the git index is locked
-	indexLockPath := filepath.Join(dir, ".git", "index.lock")
+	indexLockPath := indexLockPath(dir)
 	if _, err := os.Stat(indexLockPath); err == nil {
 		// Index is locked, this might indicate a problem
 		lockFileInfo, err := os.Stat(indexLockPath)
@@ -113,6 +113,15 @@ func CheckRepositoryHealth(dir string) error {
 	return nil
 }
 
+// indexLockPath returns the index lock file of dir. In worktrees and submodules .git is a
+// file, so the lock lives in the git directory it points to.
+func indexLockPath(dir string) string {
+	if path, err := gitPath(dir, "index.lock"); err == nil {
+		return path
+	}
+	return filepath.Join(dir, ".git", "index.lock")
+}
+
 // RecoverFromGitError attempts to recover from common git errors
 func RecoverFromGitError(dir string, err error) GitOperationResult {
 	if err == nil {
@@ -126,7 +135,7 @@ func RecoverFromGitError(dir string, err error) GitOperationResult {
 
 	// Check for index.lock issues
 	if strings.Contains(errMsg, "index.lock") {
-		indexLockPath := filepath.Join(dir, ".git", "index.lock")
+		indexLockPath := indexLockPath(dir)
 		if _, statErr := os.Stat(indexLockPath); statErr == nil {
 			// Index lock exists, try to remove it
 			utils.Warning("[GIT.RECOVERY]: Found index.lock file, attempting to remove it")
@@ -0,0 +1,254 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// RegroupCommit is a proposed commit of a regrouped range
+type RegroupCommit struct {
+	Message string   `json:"message"`
+	Files   []string `json:"files"` // Paths relative to the repository root
+}
+
+// RegroupPlan is the proposed new history for a range of local commits. While a plan is
+// pending, HEAD sits at Base with the combined changes in the working tree.
+type RegroupPlan struct {
+	Directory string          `json:"directory"`
+	Base      string          `json:"base"`
+	BackupRef string          `json:"backupRef"` // Original HEAD
+	Original  []string        `json:"original"`  // Original commits as "<hash> <subject>", oldest first
+	Commits   []RegroupCommit `json:"commits"`
+}
+
+// hasTrackedChanges reports whether dir has staged or unstaged changes to tracked files
+func hasTrackedChanges(dir string) (bool, error) {
+	status, err := RunGitCmd(dir, nil, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(status) != "", nil
+}
+
+// PrepareRegroup resets the commits of spec (keeping their changes in the working tree),
+// clusters the combined changes and generates a message per cluster. The caller must either
+// ApplyRegroup or CancelRegroup the returned plan. numClusters <= 0 lets clustering decide.
+func PrepareRegroup(dir, spec string, numClusters int) (*RegroupPlan, error) {
+	base, hashes, err := ResolveRewriteRange(dir, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if operation := operationInProgress(dir); operation != "" {
+		return nil, utils.NewValidationError(
+			"A "+operation+" is in progress",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"suggestion": "Finish or abort it before regrouping",
+			},
+		)
+	}
+	if dirty, err := hasTrackedChanges(dir); err != nil || dirty {
+		return nil, utils.NewValidationError(
+			"The working tree has uncommitted changes",
+			err,
+			map[string]interface{}{
+				"directory":  dir,
+				"suggestion": "Commit or stash your changes before regrouping",
+			},
+		)
+	}
+
+	plan := &RegroupPlan{Directory: dir, Base: base}
+	for _, hash := range hashes {
+		subject, _ := RunGitCmd(dir, nil, "log", "-1", "--format=%h %s", hash)
+		plan.Original = append(plan.Original, strings.TrimSpace(subject))
+	}
+
+	changed, err := RunGitCmd(dir, nil, "diff", "--name-only", "--no-renames", base, "HEAD")
+	if err != nil {
+		return nil, utils.NewGitError("Failed to list changed files in range", err, map[string]interface{}{"directory": dir, "range": spec})
+	}
+	var files []string
+	for _, file := range strings.Split(changed, "\n") {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, filepath.Join(dir, file))
+		}
+	}
+	if len(files) == 0 {
+		return nil, utils.NewValidationError("The range has no file changes", nil, map[string]interface{}{"directory": dir, "range": spec})
+	}
+
+	plan.BackupRef, err = CreateBackupRef(dir, "regroup")
+	if err != nil {
+		return nil, err
+	}
+
+	err = SafeGitOperation(dir, "regroup reset", func() error {
+		_, err := RunGitCmd(dir, nil, "reset", "-q", "--mixed", base)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := planRegroupCommits(plan, files, numClusters); err != nil {
+		if cancelErr := CancelRegroup(plan); cancelErr != nil {
+			utils.Error("[GIT.REGROUP]: Failed to restore " + plan.BackupRef + ": " + cancelErr.Error())
+		}
+		return nil, err
+	}
+	return plan, nil
+}
+
+// planRegroupCommits clusters files and generates a message for each cluster
+func planRegroupCommits(plan *RegroupPlan, files []string, numClusters int) error {
+	// Refresh the status cache used to detect deleted files when generating messages
+	if _, err := GetAllChangedFiles(plan.Directory); err != nil {
+		return err
+	}
+
+	clusters, err := SmartClusterFiles(files, plan.Directory, numClusters)
+	if err != nil {
+		return err
+	}
+
+	for _, cluster := range ClusterFileLists(clusters) {
+		if len(cluster) == 0 {
+			continue
+		}
+		message, err := GenCommitMessage(cluster, plan.Directory)
+		if err != nil {
+			return err
+		}
+
+		commit := RegroupCommit{Message: strings.TrimSpace(message)}
+		for _, file := range cluster {
+			relative, err := filepath.Rel(plan.Directory, file)
+			if err != nil {
+				relative = file
+			}
+			commit.Files = append(commit.Files, relative)
+		}
+		plan.Commits = append(plan.Commits, commit)
+	}
+
+	utils.Debug(fmt.Sprintf("[GIT.REGROUP]: %d commit(s) regrouped into %d", len(plan.Original), len(plan.Commits)))
+	return nil
+}
+
+// ApplyRegroup commits the groups of plan on top of its base. If any commit fails, the
+// original branch is restored.
+func ApplyRegroup(plan *RegroupPlan, env ...[]string) error {
+	dir := plan.Directory
+	commitConfig := config.GetCommitConfig(dir, env...)
+	envMap := make(map[string]string)
+	if len(env) > 0 {
+		for _, pair := range env[0] {
+			if parts := strings.SplitN(pair, "=", 2); len(parts) == 2 {
+				envMap[parts[0]] = parts[1]
+			}
+		}
+	}
+
+	if err := applyIdentity(dir, envMap, &commitConfig); err != nil {
+		return restoreAfterFailure(plan, err)
+	}
+
+	for _, commit := range plan.Commits {
+		args := append([]string{"add", "-A", "--"}, commit.Files...)
+		if _, err := RunGitCmd(dir, envMap, args...); err != nil {
+			return restoreAfterFailure(plan, utils.NewGitError("Failed to stage files", err, map[string]interface{}{"directory": dir, "files": commit.Files}))
+		}
+		if err := runCommit(dir, envMap, commitConfig, commit.Message, commit.Files); err != nil {
+			return restoreAfterFailure(plan, err)
+		}
+	}
+
+	if !sameTree(dir, plan.BackupRef, "HEAD") {
+		return restoreAfterFailure(plan, utils.NewGitError(
+			"The regrouped commits do not match the original content",
+			nil,
+			map[string]interface{}{
+				"directory": dir,
+				"backupRef": plan.BackupRef,
+			},
+		))
+	}
+
+	utils.Info(fmt.Sprintf("[GIT.REGROUP.SUCCESS]: Regrouped %d commit(s) into %d in %s (backup: %s)",
+		len(plan.Original), len(plan.Commits), dir, plan.BackupRef))
+	return nil
+}
+
+// restoreAfterFailure puts the original branch back and returns err
+func restoreAfterFailure(plan *RegroupPlan, err error) error {
+	if restoreErr := CancelRegroup(plan); restoreErr != nil {
+		utils.Error("[GIT.REGROUP]: Failed to restore " + plan.BackupRef + ": " + restoreErr.Error())
+	} else {
+		utils.Warning("⚠️ Regroup failed; the original commits were restored")
+	}
+	return err
+}
+
+// CancelRegroup restores the original commits of a pending plan. The working tree already
+// holds their content, so only HEAD and the index move.
+func CancelRegroup(plan *RegroupPlan) error {
+	_, err := RunGitCmd(plan.Directory, nil, "reset", "-q", "--mixed", plan.BackupRef)
+	return err
+}
+
+// LatestBackupRef returns the most recent backup ref of operation in dir, or ""
+func LatestBackupRef(dir, operation string) (string, error) {
+	ref, err := RunGitCmd(dir, nil, "for-each-ref", "--sort=-refname", "--count=1", "--format=%(refname)", BackupRefPrefix+operation+"/")
+	return strings.TrimSpace(ref), err
+}
+
+// UndoRegroup moves the branch back to the commits saved before the last regroup. It refuses
+// when the content has changed since, so no work can be lost.
+func UndoRegroup(dir string) (string, error) {
+	ref, err := LatestBackupRef(dir, "regroup")
+	if err != nil || ref == "" {
+		return "", utils.NewValidationError(
+			"No regroup to undo",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+			},
+		)
+	}
+
+	if dirty, err := hasTrackedChanges(dir); err != nil || dirty {
+		return "", utils.NewValidationError(
+			"The working tree has uncommitted changes",
+			err,
+			map[string]interface{}{
+				"directory":  dir,
+				"suggestion": "Commit or stash your changes before undoing",
+			},
+		)
+	}
+	if !sameTree(dir, ref, "HEAD") {
+		return "", utils.NewValidationError(
+			"The branch changed since the regroup",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"backupRef":  ref,
+				"suggestion": "Restore it manually with: git reset --keep " + ref,
+			},
+		)
+	}
+
+	if _, err := RunGitCmd(dir, nil, "reset", "-q", "--soft", ref); err != nil {
+		return "", utils.NewGitError("Failed to restore the original commits", err, map[string]interface{}{"directory": dir, "backupRef": ref})
+	}
+	if _, err := RunGitCmd(dir, nil, "update-ref", "-d", ref); err != nil {
+		utils.Debug("[GIT.REGROUP]: Could not delete " + ref + ": " + err.Error())
+	}
+	return ref, nil
+}
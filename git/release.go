@@ -0,0 +1,445 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version bumps, from smallest to largest
+const (
+	BumpNone  = "none"
+	BumpPatch = "patch"
+	BumpMinor = "minor"
+	BumpMajor = "major"
+)
+
+var bumpRank = map[string]int{BumpNone: 0, BumpPatch: 1, BumpMinor: 2, BumpMajor: 3}
+
+// ErrNothingToRelease is the cause of PlanRelease's error when no commit since the last version
+// tag calls for a new version
+var ErrNothingToRelease = errors.New("no feat, fix or breaking commits since the last version tag")
+
+// SemVer is a semantic version. Build metadata is ignored.
+type SemVer struct {
+	Major      int    `json:"major"`
+	Minor      int    `json:"minor"`
+	Patch      int    `json:"patch"`
+	Prerelease string `json:"prerelease,omitempty"`
+}
+
+var semverPattern = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// ParseSemVer parses a version such as 1.4.0 or 2.0.0-rc.1, after removing prefix
+func ParseSemVer(version, prefix string) (SemVer, bool) {
+	if !strings.HasPrefix(version, prefix) {
+		return SemVer{}, false
+	}
+	match := semverPattern.FindStringSubmatch(strings.TrimPrefix(version, prefix))
+	if match == nil {
+		return SemVer{}, false
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	patch, _ := strconv.Atoi(match[3])
+	return SemVer{Major: major, Minor: minor, Patch: patch, Prerelease: match[4]}, true
+}
+
+// String formats the version without prefix
+func (v SemVer) String() string {
+	version := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		version += "-" + v.Prerelease
+	}
+	return version
+}
+
+// Less reports whether v has lower precedence than other
+func (v SemVer) Less(other SemVer) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	if v.Patch != other.Patch {
+		return v.Patch < other.Patch
+	}
+	// A pre-release sorts before the release
+	if v.Prerelease == "" || other.Prerelease == "" {
+		return v.Prerelease != "" && other.Prerelease == ""
+	}
+	return prereleaseLess(v.Prerelease, other.Prerelease)
+}
+
+// prereleaseLess compares pre-release versions as SemVer 2.0 §11 requires: dot-separated
+// identifiers from left to right, numeric ones numerically and before alphanumeric ones, and a
+// shorter list first when all its identifiers are equal
+func prereleaseLess(a, b string) bool {
+	left, right := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(left) && i < len(right); i++ {
+		if left[i] == right[i] {
+			continue
+		}
+		leftNumber, leftErr := strconv.ParseUint(left[i], 10, 64)
+		rightNumber, rightErr := strconv.ParseUint(right[i], 10, 64)
+		switch {
+		case leftErr == nil && rightErr == nil:
+			return leftNumber < rightNumber
+		case leftErr == nil || rightErr == nil:
+			return leftErr == nil
+		default:
+			return left[i] < right[i]
+		}
+	}
+	return len(left) < len(right)
+}
+
+// Bump returns the next version for bump. Before 1.0.0 breaking changes bump the minor
+// version, as the public API is not considered stable yet.
+func (v SemVer) Bump(bump string) SemVer {
+	if v.Prerelease != "" {
+		// Releasing a pre-release finalises it, unless a larger part must change
+		release := SemVer{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+		if bump == BumpMajor && (v.Minor != 0 || v.Patch != 0) ||
+			bump == BumpMinor && v.Patch != 0 {
+			return release.Bump(bump)
+		}
+		return release
+	}
+
+	switch bump {
+	case BumpMajor:
+		if v.Major == 0 {
+			return SemVer{Major: 0, Minor: v.Minor + 1}
+		}
+		return SemVer{Major: v.Major + 1}
+	case BumpMinor:
+		return SemVer{Major: v.Major, Minor: v.Minor + 1}
+	case BumpPatch:
+		return SemVer{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+	return v
+}
+
+// ConventionalCommit is a commit classified by its conventional commit header
+type ConventionalCommit struct {
+	Hash         string `json:"hash"`
+	Type         string `json:"type"` // Lowercased type, "other" when the subject is not conventional
+	Scope        string `json:"scope,omitempty"`
+	Description  string `json:"description"`
+	Breaking     bool   `json:"breaking"`
+	BreakingNote string `json:"breakingNote,omitempty"`
+}
+
+var conventionalPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+var breakingTrailerPattern = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s*(.+)$`)
+
+// ParseConventionalCommit classifies a commit from its subject and body. A "!" after the
+// type/scope or a BREAKING CHANGE / BREAKING-CHANGE trailer marks it as breaking.
+func ParseConventionalCommit(hash, subject, body string) ConventionalCommit {
+	commit := ConventionalCommit{Hash: hash, Type: "other", Description: strings.TrimSpace(subject)}
+
+	if match := conventionalPattern.FindStringSubmatch(strings.TrimSpace(subject)); match != nil {
+		commit.Type = strings.ToLower(match[1])
+		commit.Scope = match[2]
+		commit.Breaking = match[3] == "!"
+		commit.Description = match[4]
+	}
+
+	if match := breakingTrailerPattern.FindStringSubmatch(body); match != nil {
+		commit.Breaking = true
+		commit.BreakingNote = strings.TrimSpace(match[1])
+	}
+	return commit
+}
+
+// BumpFor returns the version bump a commit calls for
+func (c ConventionalCommit) BumpFor() string {
+	switch {
+	case c.Breaking:
+		return BumpMajor
+	case c.Type == "feat":
+		return BumpMinor
+	case c.Type == "fix" || c.Type == "perf":
+		return BumpPatch
+	}
+	return BumpNone
+}
+
+// ReleasePlan is the proposed next release of a repository
+type ReleasePlan struct {
+	Directory       string               `json:"directory"`
+	PreviousTag     string               `json:"previousTag,omitempty"`
+	PreviousVersion string               `json:"previousVersion"`
+	Bump            string               `json:"bump"`
+	NextVersion     string               `json:"nextVersion"`
+	NextTag         string               `json:"nextTag"`
+	Commits         []ConventionalCommit `json:"commits"`
+	Notes           string               `json:"notes"`
+}
+
+// LatestVersionTag returns the highest semver tag reachable from HEAD, or "" when there is none
+func LatestVersionTag(dir, prefix string) (string, SemVer, error) {
+	tags, err := RunGitCmd(dir, nil, "tag", "--merged", "HEAD", "--list")
+	if err != nil {
+		return "", SemVer{}, err
+	}
+
+	var latestTag string
+	var latest SemVer
+	for _, tag := range strings.Split(tags, "\n") {
+		tag = strings.TrimSpace(tag)
+		version, ok := ParseSemVer(tag, prefix)
+		if !ok {
+			continue
+		}
+		if latestTag == "" || latest.Less(version) {
+			latestTag, latest = tag, version
+		}
+	}
+	return latestTag, latest, nil
+}
+
+// PlanRelease walks the commits since the last version tag of dir and proposes the next
+// version. bumpOverride forces a bump ("major", "minor" or "patch") instead of the computed one.
+func PlanRelease(dir, bumpOverride string) (*ReleasePlan, error) {
+	if bumpOverride != "" {
+		if _, ok := bumpRank[bumpOverride]; !ok || bumpOverride == BumpNone {
+			return nil, utils.NewValidationError(
+				"Invalid version bump",
+				nil,
+				map[string]interface{}{
+					"bump":       bumpOverride,
+					"suggestion": "Use major, minor or patch",
+				},
+			)
+		}
+	}
+
+	releaseConfig := config.GetReleaseConfig(dir)
+	previousTag, previous, err := LatestVersionTag(dir, releaseConfig.TagPrefix)
+	if err != nil {
+		return nil, utils.NewGitError(
+			"Failed to list version tags",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+			},
+		)
+	}
+	if previousTag == "" {
+		initial, ok := ParseSemVer(releaseConfig.InitialVersion, "")
+		if !ok {
+			return nil, utils.NewConfigError(
+				"Invalid release.initialVersion",
+				nil,
+				map[string]interface{}{
+					"initialVersion": releaseConfig.InitialVersion,
+				},
+			)
+		}
+		previous = initial
+	}
+
+	logRange := "HEAD"
+	if previousTag != "" {
+		logRange = previousTag + "..HEAD"
+	}
+	logOutput, err := RunGitCmd(dir, nil, "log", "--no-merges", "--format=%h%x1f%s%x1f%b%x1e", logRange)
+	if err != nil {
+		return nil, utils.NewGitError(
+			"Failed to list commits since the last release",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+				"range":     logRange,
+			},
+		)
+	}
+
+	plan := &ReleasePlan{
+		Directory:       dir,
+		PreviousTag:     previousTag,
+		PreviousVersion: previous.String(),
+		Bump:            BumpNone,
+	}
+	for _, record := range strings.Split(logOutput, "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x1f", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		body := ""
+		if len(fields) == 3 {
+			body = fields[2]
+		}
+		commit := ParseConventionalCommit(fields[0], fields[1], body)
+		plan.Commits = append(plan.Commits, commit)
+		if bumpRank[commit.BumpFor()] > bumpRank[plan.Bump] {
+			plan.Bump = commit.BumpFor()
+		}
+	}
+
+	if bumpOverride != "" {
+		plan.Bump = bumpOverride
+	}
+	if plan.Bump == BumpNone {
+		return nil, utils.NewValidationError(
+			"Nothing to release",
+			ErrNothingToRelease,
+			map[string]interface{}{
+				"directory":   dir,
+				"previousTag": previousTag,
+				"commits":     len(plan.Commits),
+				"suggestion":  "Force a version with --bump major, minor or patch",
+			},
+		)
+	}
+	next := previous.Bump(plan.Bump)
+	plan.NextVersion = next.String()
+	plan.NextTag = releaseConfig.TagPrefix + plan.NextVersion
+	plan.Notes = RenderReleaseNotes(plan, time.Now())
+
+	utils.Debug(fmt.Sprintf("[GIT.RELEASE]: %s: %s -> %s (%s, %d commit(s))",
+		dir, plan.PreviousVersion, plan.NextVersion, plan.Bump, len(plan.Commits)))
+	return plan, nil
+}
+
+// releaseSections orders the release note sections by commit type
+var releaseSections = []struct {
+	title string
+	types []string
+}{
+	{"Features", []string{"feat"}},
+	{"Bug Fixes", []string{"fix"}},
+	{"Performance", []string{"perf"}},
+	{"Reverts", []string{"revert"}},
+	{"Documentation", []string{"docs"}},
+	{"Refactoring", []string{"refactor"}},
+	{"Other Changes", nil}, // Everything else
+}
+
+// RenderReleaseNotes renders the plan's commits as Markdown grouped by type
+func RenderReleaseNotes(plan *ReleasePlan, date time.Time) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## %s (%s)\n", plan.NextTag, date.Format("2006-01-02")))
+
+	if len(plan.Commits) == 0 {
+		builder.WriteString("\nNo changes since " + plan.PreviousTag + ".\n")
+		return builder.String()
+	}
+
+	var breaking []string
+	for _, commit := range plan.Commits {
+		if commit.Breaking {
+			note := commit.BreakingNote
+			if note == "" {
+				note = commit.Description
+			}
+			breaking = append(breaking, formatReleaseEntry(commit, note))
+		}
+	}
+	if len(breaking) > 0 {
+		builder.WriteString("\n### ⚠ BREAKING CHANGES\n\n")
+		builder.WriteString(strings.Join(breaking, ""))
+	}
+
+	known := make(map[string]bool)
+	for _, section := range releaseSections {
+		for _, commitType := range section.types {
+			known[commitType] = true
+		}
+	}
+
+	for _, section := range releaseSections {
+		var entries []string
+		for _, commit := range plan.Commits {
+			matches := false
+			if section.types == nil {
+				matches = !known[commit.Type]
+			}
+			for _, commitType := range section.types {
+				matches = matches || commit.Type == commitType
+			}
+			if matches {
+				entries = append(entries, formatReleaseEntry(commit, commit.Description))
+			}
+		}
+		if len(entries) > 0 {
+			builder.WriteString("\n### " + section.title + "\n\n")
+			builder.WriteString(strings.Join(entries, ""))
+		}
+	}
+	return builder.String()
+}
+
+func formatReleaseEntry(commit ConventionalCommit, text string) string {
+	if commit.Scope != "" {
+		text = "**" + commit.Scope + ":** " + text
+	}
+	return "- " + text + " (" + commit.Hash + ")\n"
+}
+
+// BuildTagArgs returns the git arguments that create the annotated tag with notes as message,
+// signed with the commit signing key and format when sign is set
+func BuildTagArgs(commitConfig config.CommitConfig, sign bool, tag, notes string) []string {
+	var args []string
+
+	// gpg.format has to be set before the subcommand, as for commits
+	if sign && commitConfig.SigningFormat != "" {
+		args = append(args, "-c", "gpg.format="+commitConfig.SigningFormat)
+	}
+
+	switch {
+	case sign && commitConfig.SigningKey != "":
+		args = append(args, "tag", "-u", commitConfig.SigningKey)
+	case sign:
+		args = append(args, "tag", "-s")
+	default:
+		args = append(args, "tag", "-a")
+	}
+
+	// Verbatim cleanup keeps the Markdown headings, which git would otherwise strip as comments
+	return append(args, "--cleanup=verbatim", tag, "-m", notes)
+}
+
+// CreateReleaseTag creates the annotated tag of plan at HEAD with the release notes as message.
+// The tag is signed when release.signTags or commit.sign is set.
+func CreateReleaseTag(dir string, plan *ReleasePlan) error {
+	if _, _, err := RunGitCmdWithOutput(dir, nil, "rev-parse", "--verify", "--quiet", "refs/tags/"+plan.NextTag); err == nil {
+		return utils.NewValidationError(
+			"Tag already exists",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"tag":        plan.NextTag,
+				"suggestion": "Use --bump to choose a different version",
+			},
+		)
+	}
+
+	commitConfig := config.GetCommitConfig(dir)
+	sign := config.GetReleaseConfig(dir).SignTags || commitConfig.Sign
+	args := BuildTagArgs(commitConfig, sign, plan.NextTag, plan.Notes)
+
+	_, stderr, err := RunGitCmdWithOutput(dir, nil, args...)
+	if err != nil {
+		return utils.NewGitError(
+			"Failed to create release tag",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+				"tag":       plan.NextTag,
+				"stderr":    strings.TrimSpace(stderr),
+			},
+		)
+	}
+
+	utils.Info("[GIT.RELEASE.SUCCESS]: Tagged " + dir + " as " + plan.NextTag)
+	return nil
+}
@@ -0,0 +1,301 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxSubmoduleLogEntries limits how many submodule commits describe a pointer bump
+const maxSubmoduleLogEntries = 30
+
+// GitDir returns the absolute git directory of the repository in dir. For worktrees and
+// submodules this is not <dir>/.git, which is only a file pointing elsewhere.
+func GitDir(dir string) (string, error) {
+	gitDir, err := RunGitCmd(dir, nil, "rev-parse", "--git-dir")
+	if err != nil {
+		return "", utils.NewGitError("Failed to locate the git directory", err, map[string]interface{}{"directory": dir})
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	return gitDir, nil
+}
+
+// gitPath resolves a path inside the git directory of dir, e.g. "index.lock". Worktree
+// specific files resolve to the worktree's own git directory.
+func gitPath(dir, name string) (string, error) {
+	path, err := RunGitCmd(dir, nil, "rev-parse", "--git-path", name)
+	if err != nil {
+		return "", utils.NewGitError("Failed to locate the git directory", err, map[string]interface{}{"directory": dir})
+	}
+	path = strings.TrimSpace(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return path, nil
+}
+
+// isRepositoryRoot reports whether path is the top level of its own repository (a submodule,
+// nested repository or worktree), as opposed to a plain directory
+func isRepositoryRoot(path string) bool {
+	_, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil
+}
+
+// Submodules returns the absolute paths of the initialised submodules of dir
+func Submodules(dir string) ([]string, error) {
+	entries, err := RunGitCmd(dir, nil, "ls-files", "--stage")
+	if err != nil {
+		return nil, utils.NewGitError("Failed to list submodules", err, map[string]interface{}{"directory": dir})
+	}
+
+	var submodules []string
+	for _, line := range strings.Split(entries, "\n") {
+		// <mode> <object> <stage>\t<path>; gitlinks have mode 160000
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 || !strings.HasPrefix(fields[0], "160000 ") {
+			continue
+		}
+		path := filepath.Join(dir, fields[1])
+		if isRepositoryRoot(path) {
+			submodules = append(submodules, path)
+		}
+	}
+	return submodules, nil
+}
+
+// NestedRepositories returns the absolute paths of untracked repositories inside dir
+func NestedRepositories(dir string) ([]string, error) {
+	// Embedded repositories are listed as a single "<path>/" entry
+	untracked, err := RunGitCmd(dir, nil, "ls-files", "--others", "--exclude-standard", "--directory")
+	if err != nil {
+		return nil, utils.NewGitError("Failed to list untracked files", err, map[string]interface{}{"directory": dir})
+	}
+
+	var nested []string
+	for _, entry := range strings.Split(untracked, "\n") {
+		entry = strings.TrimSpace(entry)
+		if !strings.HasSuffix(entry, "/") {
+			continue
+		}
+		path := filepath.Join(dir, entry)
+		if isRepositoryRoot(path) {
+			nested = append(nested, path)
+			continue
+		}
+		// An untracked directory may still contain repositories further down
+		inner, err := RunGitCmd(dir, nil, "ls-files", "--others", "--exclude-standard", "--", entry)
+		if err != nil {
+			continue
+		}
+		for _, file := range strings.Split(inner, "\n") {
+			file = strings.TrimSpace(file)
+			if strings.HasSuffix(file, "/") && isRepositoryRoot(filepath.Join(dir, file)) {
+				nested = append(nested, filepath.Join(dir, file))
+			}
+		}
+	}
+	return nested, nil
+}
+
+// DiscoverCommitUnits returns the repositories that are committed separately for the root
+// folder: the root itself, followed by its submodules and nested repositories when enabled
+// in the repositories configuration. Deeper repositories come after their parents.
+func DiscoverCommitUnits(root string) []string {
+	repoConfig := config.GetRepositoryConfig(root)
+	units := []string{root}
+	if !repoConfig.Submodules && !repoConfig.NestedRepos {
+		return units
+	}
+
+	seen := map[string]bool{filepath.Clean(root): true}
+	level := []string{root}
+	for depth := 0; depth < repoConfig.MaxDepth && len(level) > 0; depth++ {
+		var next []string
+		for _, dir := range level {
+			var found []string
+			if repoConfig.Submodules {
+				submodules, err := Submodules(dir)
+				if err != nil {
+					utils.Debug("[GIT.UNITS]: " + err.Error())
+				}
+				found = append(found, submodules...)
+			}
+			if repoConfig.NestedRepos {
+				nested, err := NestedRepositories(dir)
+				if err != nil {
+					utils.Debug("[GIT.UNITS]: " + err.Error())
+				}
+				found = append(found, nested...)
+			}
+
+			sort.Strings(found)
+			for _, unit := range found {
+				if clean := filepath.Clean(unit); !seen[clean] {
+					seen[clean] = true
+					next = append(next, clean)
+				}
+			}
+		}
+		units = append(units, next...)
+		level = next
+	}
+
+	utils.Debug(fmt.Sprintf("[GIT.UNITS]: %s has %d commit unit(s)", root, len(units)))
+	return units
+}
+
+// Superproject returns the working tree of the repository that has dir as a submodule, or ""
+func Superproject(dir string) string {
+	super, err := RunGitCmd(dir, nil, "rev-parse", "--show-superproject-working-tree")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(super)
+}
+
+// submodulePointers returns the commit recorded for the submodule at path in HEAD and in the
+// index of dir, and the commit checked out in the submodule. Missing values are "".
+func submodulePointers(dir, path string) (string, string, string) {
+	relative, err := filepath.Rel(dir, path)
+	if err != nil {
+		relative = path
+	}
+
+	var recorded, staged string
+	if tree, err := RunGitCmd(dir, nil, "ls-tree", "HEAD", "--", relative); err == nil {
+		if fields := strings.Fields(tree); len(fields) >= 3 && fields[0] == "160000" {
+			recorded = fields[2]
+		}
+	}
+	if stage, err := RunGitCmd(dir, nil, "ls-files", "--stage", "--", relative); err == nil {
+		if fields := strings.Fields(stage); len(fields) >= 2 && fields[0] == "160000" {
+			staged = fields[1]
+		}
+	}
+	checkedOut := revParse(path, "HEAD")
+	return recorded, staged, checkedOut
+}
+
+// SubmodulePointerChanged reports whether the submodule at path has moved to a commit that
+// the superproject in dir has not recorded yet
+func SubmodulePointerChanged(dir, path string) bool {
+	recorded, staged, checkedOut := submodulePointers(dir, path)
+	return checkedOut != "" && (checkedOut != recorded || staged != recorded)
+}
+
+// isSubmodule reports whether file is a submodule of the repository in dir
+func isSubmodule(dir, file string) bool {
+	relative, err := filepath.Rel(dir, file)
+	if err != nil {
+		return false
+	}
+	stage, err := RunGitCmd(dir, nil, "ls-files", "--stage", "--", relative)
+	return err == nil && strings.HasPrefix(stage, "160000 ")
+}
+
+// submoduleBumpContext describes a submodule pointer bump by the commits it brings in.
+// With indexOnly the staged pointer is described instead of the checked out commit.
+func submoduleBumpContext(dir, path string, indexOnly bool) map[string]string {
+	recorded, staged, checkedOut := submodulePointers(dir, path)
+	target := checkedOut
+	if indexOnly {
+		target = staged
+	}
+
+	var summary strings.Builder
+	switch {
+	case recorded == "":
+		summary.WriteString(fmt.Sprintf("Submodule added at commit %s\n", shortHash(target)))
+	case target == recorded:
+		summary.WriteString("Submodule pointer unchanged\n")
+	default:
+		summary.WriteString(fmt.Sprintf("Submodule updated from %s to %s\n", shortHash(recorded), shortHash(target)))
+		if log, err := RunGitCmd(path, nil, "log", "--format=%h %s", fmt.Sprintf("-%d", maxSubmoduleLogEntries), recorded+".."+target); err == nil && strings.TrimSpace(log) != "" {
+			summary.WriteString("New commits in the submodule:\n" + log)
+		}
+		if rewound, err := RunGitCmd(path, nil, "rev-list", "--count", target+".."+recorded); err == nil && strings.TrimSpace(rewound) != "0" {
+			summary.WriteString(fmt.Sprintf("%s commit(s) of the previous pointer are no longer included\n", strings.TrimSpace(rewound)))
+		}
+	}
+
+	fileType := "submodule"
+	if recorded == "" {
+		fileType = "new submodule"
+	}
+	return map[string]string{
+		"type": fileType,
+		"diff": summary.String(),
+	}
+}
+
+// shortHash abbreviates a commit hash for messages
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// FindRepositories walks dir up to depth levels deep and returns the git repositories found,
+// sorted by path. Ignored directories are not entered, nor are the repositories themselves.
+func FindRepositories(dir string, depth int, rootsConfig config.RootsConfig) ([]string, error) {
+	absDir, err := filepath.Abs(config.ExpandHome(dir))
+	if err != nil {
+		return nil, utils.NewValidationError("Invalid directory", err, map[string]interface{}{"directory": dir})
+	}
+	if info, err := os.Stat(absDir); err != nil || !info.IsDir() {
+		return nil, utils.NewValidationError("Directory does not exist", err, map[string]interface{}{"directory": absDir})
+	}
+
+	var repositories []string
+	var walk func(path string, level int)
+	walk = func(path string, level int) {
+		if isRepositoryRoot(path) {
+			repositories = append(repositories, path)
+			return
+		}
+		if level >= depth {
+			return
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			utils.Debug("[GIT.DISCOVER]: Cannot read " + path + ": " + err.Error())
+			return
+		}
+		for _, entry := range entries {
+			child := filepath.Join(path, entry.Name())
+			if !entry.IsDir() || rootsConfig.IsIgnoredDirectory(child) {
+				continue
+			}
+			walk(child, level+1)
+		}
+	}
+	walk(absDir, 0)
+
+	sort.Strings(repositories)
+	utils.Debug(fmt.Sprintf("[GIT.DISCOVER]: Found %d repositories in %s", len(repositories), absDir))
+	return repositories, nil
+}
+
+// PendingChanges returns the number of changed, staged and untracked paths in dir
+func PendingChanges(dir string) (int, error) {
+	status, err := RunGitCmd(dir, nil, "status", "--porcelain")
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, line := range strings.Split(status, "\n") {
+		if strings.TrimSpace(line) != "" {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,210 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"path/filepath"
+)
+
+// RuleEdited marks clusters changed during review
+const RuleEdited = "edited in review"
+
+// ClusterReview lets the user adjust the proposed clusters of a root folder before commit
+// messages are generated for them
+type ClusterReview func(rootFolder string, clusters []FileCluster) ([]FileCluster, error)
+
+// clusterReview is applied to the clusters of grouped message generation when set
+var clusterReview ClusterReview
+
+// SetClusterReview sets the review step of grouped message generation; nil removes it
+func SetClusterReview(review ClusterReview) {
+	clusterReview = review
+}
+
+// ClusterPlan is the non-interactive equivalent of a cluster review. Files of a root folder
+// that the plan does not mention stay in the cluster they were proposed in.
+type ClusterPlan struct {
+	Folders []ClusterPlanFolder `json:"folders"`
+}
+
+// ClusterPlanFolder holds the planned clusters of one root folder
+type ClusterPlanFolder struct {
+	RootFolder string           `json:"rootFolder"`
+	Clusters   []PlannedCluster `json:"clusters"`
+}
+
+// PlannedCluster is one cluster of a plan; files may be relative to the root folder
+type PlannedCluster struct {
+	Name  string   `json:"name,omitempty"`
+	Files []string `json:"files"`
+}
+
+// reviewClusters runs the review step, if any, with the progress loader paused
+func reviewClusters(rootFolder string, clusters []FileCluster) ([]FileCluster, error) {
+	if clusterReview == nil {
+		return clusters, nil
+	}
+
+	utils.StopCreativeLoader()
+	reviewed, err := clusterReview(rootFolder, clusters)
+	utils.StartCreativeLoader("Generating messages for the reviewed clusters", utils.BrailleAnimation)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonEmpty []FileCluster
+	for _, cluster := range reviewed {
+		if len(cluster.Files) > 0 {
+			nonEmpty = append(nonEmpty, cluster)
+		}
+	}
+	utils.Debug(fmt.Sprintf("[GIT.REVIEW]: %d cluster(s) after review in %s", len(nonEmpty), rootFolder))
+	return nonEmpty, nil
+}
+
+// markEdited refreshes the explanation of a cluster changed during review
+func markEdited(cluster FileCluster, rootFolder string) FileCluster {
+	cluster.Rule = RuleEdited
+	cluster.FileSimilarity = featureCentroidSimilarity(cluster.Files, rootFolder)
+	cluster.Similarity = 0
+	for _, similarity := range cluster.FileSimilarity {
+		cluster.Similarity += similarity / float64(len(cluster.FileSimilarity))
+	}
+	return cluster
+}
+
+// copyClusters copies the clusters so edits do not change the caller's slices
+func copyClusters(clusters []FileCluster) []FileCluster {
+	copied := make([]FileCluster, len(clusters))
+	for i, cluster := range clusters {
+		cluster.Files = append([]string{}, cluster.Files...)
+		copied[i] = cluster
+	}
+	return copied
+}
+
+// removeFile returns files without file
+func removeFile(files []string, file string) []string {
+	kept := make([]string, 0, len(files))
+	for _, existing := range files {
+		if existing != file {
+			kept = append(kept, existing)
+		}
+	}
+	return kept
+}
+
+// dropEmptyClusters removes clusters left without files
+func dropEmptyClusters(clusters []FileCluster) []FileCluster {
+	kept := clusters[:0]
+	for _, cluster := range clusters {
+		if len(cluster.Files) > 0 {
+			kept = append(kept, cluster)
+		}
+	}
+	return kept
+}
+
+// MoveFile moves file into the cluster at index to; an index of len(clusters) starts a new cluster
+func MoveFile(clusters []FileCluster, file string, to int, rootFolder string) []FileCluster {
+	edited := copyClusters(clusters)
+	for i := range edited {
+		if len(removeFile(edited[i].Files, file)) != len(edited[i].Files) {
+			edited[i].Files = removeFile(edited[i].Files, file)
+			edited[i] = markEdited(edited[i], rootFolder)
+		}
+	}
+	if to >= len(edited) {
+		edited = append(edited, FileCluster{Method: "review"})
+		to = len(edited) - 1
+	}
+	edited[to].Files = append(edited[to].Files, file)
+	edited[to] = markEdited(edited[to], rootFolder)
+	return dropEmptyClusters(edited)
+}
+
+// SplitCluster moves files out of the cluster at index into a new cluster
+func SplitCluster(clusters []FileCluster, index int, files []string, rootFolder string) []FileCluster {
+	edited := copyClusters(clusters)
+	split := FileCluster{Method: edited[index].Method}
+	for _, file := range files {
+		if len(removeFile(edited[index].Files, file)) != len(edited[index].Files) {
+			edited[index].Files = removeFile(edited[index].Files, file)
+			split.Files = append(split.Files, file)
+		}
+	}
+	if len(split.Files) == 0 {
+		return edited
+	}
+	edited[index] = markEdited(edited[index], rootFolder)
+	edited = append(edited, markEdited(split, rootFolder))
+	return dropEmptyClusters(edited)
+}
+
+// MergeClusters moves the files of the cluster at from into the cluster at into
+func MergeClusters(clusters []FileCluster, into, from int, rootFolder string) []FileCluster {
+	if into == from {
+		return clusters
+	}
+	edited := copyClusters(clusters)
+	edited[into].Files = append(edited[into].Files, edited[from].Files...)
+	edited[into] = markEdited(edited[into], rootFolder)
+	edited[from].Files = nil
+	return dropEmptyClusters(edited)
+}
+
+// RenameCluster names the cluster at index
+func RenameCluster(clusters []FileCluster, index int, name string) []FileCluster {
+	edited := copyClusters(clusters)
+	edited[index].Name = name
+	return edited
+}
+
+// ApplyClusterPlan rearranges the proposed clusters of rootFolder as planned. Planned files
+// that are not among the proposed ones are ignored with a warning.
+func ApplyClusterPlan(clusters []FileCluster, planned []PlannedCluster, rootFolder string) []FileCluster {
+	proposed := make(map[string]bool)
+	for _, cluster := range clusters {
+		for _, file := range cluster.Files {
+			proposed[file] = true
+		}
+	}
+
+	planClusters := make([]FileCluster, 0, len(planned))
+	moved := make(map[string]bool)
+	for _, plan := range planned {
+		cluster := FileCluster{Name: plan.Name, Method: "plan"}
+		for _, file := range plan.Files {
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(rootFolder, file)
+			}
+			if !proposed[file] {
+				utils.Warning("[GIT.REVIEW]: Ignoring planned file that has no changes: " + file)
+				continue
+			}
+			if moved[file] {
+				utils.Warning("[GIT.REVIEW]: Ignoring file planned in more than one cluster: " + file)
+				continue
+			}
+			moved[file] = true
+			cluster.Files = append(cluster.Files, file)
+		}
+		if len(cluster.Files) > 0 {
+			planClusters = append(planClusters, markEdited(cluster, rootFolder))
+		}
+	}
+
+	// Whatever the plan leaves out keeps its proposed grouping
+	remaining := copyClusters(clusters)
+	for i := range remaining {
+		for _, file := range clusters[i].Files {
+			if moved[file] {
+				remaining[i].Files = removeFile(remaining[i].Files, file)
+			}
+		}
+		if len(remaining[i].Files) != len(clusters[i].Files) && len(remaining[i].Files) > 0 {
+			remaining[i] = markEdited(remaining[i], rootFolder)
+		}
+	}
+	return append(planClusters, dropEmptyClusters(remaining)...)
+}
@@ -0,0 +1,348 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// RewordCommit is a commit whose message may be replaced
+type RewordCommit struct {
+	Hash       string   `json:"hash"`
+	ShortHash  string   `json:"shortHash"`
+	OldMessage string   `json:"oldMessage"`
+	NewMessage string   `json:"newMessage"`
+	Files      []string `json:"files"`
+	Approved   bool     `json:"approved"`
+}
+
+// RewordPlan lists the commits of a range with their regenerated messages
+type RewordPlan struct {
+	Directory string         `json:"directory"`
+	Base      string         `json:"base"` // Parent of the oldest commit; the rebase starts here
+	Commits   []RewordCommit `json:"commits"`
+}
+
+// ResolveRewriteRange validates that spec ends at HEAD, contains no merges and only commits
+// that are not on any remote. It returns the base commit and the commits oldest first.
+func ResolveRewriteRange(dir, spec string) (string, []string, error) {
+	from, to, symmetric, err := ParseRange(spec)
+	if err != nil || symmetric {
+		return "", nil, utils.NewValidationError(
+			"Invalid commit range",
+			err,
+			map[string]interface{}{
+				"range":      spec,
+				"suggestion": "Use <base>..HEAD, e.g. HEAD~3..HEAD or origin/main..",
+			},
+		)
+	}
+
+	head, err := RunGitCmd(dir, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return "", nil, utils.NewGitError("Failed to resolve HEAD", err, map[string]interface{}{"directory": dir})
+	}
+	target, err := RunGitCmd(dir, nil, "rev-parse", "--verify", to+"^{commit}")
+	if err != nil || strings.TrimSpace(target) != strings.TrimSpace(head) {
+		return "", nil, utils.NewValidationError(
+			"Only ranges ending at HEAD can be rewritten",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+				"range":     spec,
+			},
+		)
+	}
+	if _, err := CurrentBranch(dir); err != nil {
+		return "", nil, err
+	}
+
+	base, err := RunGitCmd(dir, nil, "rev-parse", "--verify", from+"^{commit}")
+	if err != nil {
+		return "", nil, utils.NewValidationError(
+			"Unknown ref in commit range",
+			err,
+			map[string]interface{}{
+				"directory": dir,
+				"ref":       from,
+			},
+		)
+	}
+	base = strings.TrimSpace(base)
+
+	revs, err := RunGitCmd(dir, nil, "rev-list", "--reverse", base+"..HEAD")
+	if err != nil {
+		return "", nil, utils.NewGitError("Failed to list commits in range", err, map[string]interface{}{"directory": dir, "range": spec})
+	}
+	commits := strings.Fields(revs)
+	if len(commits) == 0 {
+		return "", nil, utils.NewValidationError("No commits in range", nil, map[string]interface{}{"directory": dir, "range": spec})
+	}
+
+	if merges, _ := RunGitCmd(dir, nil, "rev-list", "--merges", base+"..HEAD"); strings.TrimSpace(merges) != "" {
+		return "", nil, utils.NewValidationError(
+			"The range contains merge commits",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"merges":     strings.Fields(merges),
+				"suggestion": "Choose a range after the last merge",
+			},
+		)
+	}
+
+	// Commits reachable from any remote-tracking branch (including the upstream) are published
+	local, err := RunGitCmd(dir, nil, "rev-list", base+"..HEAD", "--not", "--remotes")
+	if err != nil {
+		return "", nil, utils.NewGitError("Failed to check which commits are pushed", err, map[string]interface{}{"directory": dir})
+	}
+	unpushed := make(map[string]bool)
+	for _, hash := range strings.Fields(local) {
+		unpushed[hash] = true
+	}
+	var pushed []string
+	for _, hash := range commits {
+		if !unpushed[hash] {
+			pushed = append(pushed, hash[:7])
+		}
+	}
+	if len(pushed) > 0 {
+		return "", nil, utils.NewValidationError(
+			"Refusing to rewrite commits that are already pushed",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"pushed":     pushed,
+				"suggestion": "Limit the range to commits after the upstream, e.g. @{u}..HEAD",
+			},
+		)
+	}
+
+	return base, commits, nil
+}
+
+// commitDiffContext builds the SendToGemini context for a commit from git show
+func commitDiffContext(dir, hash string) (map[string]map[string]string, []string, error) {
+	nameStatus, err := RunGitCmd(dir, nil, "show", "--format=", "--name-status", "-M", hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contextData := make(map[string]map[string]string)
+	var files []string
+	for _, line := range strings.Split(nameStatus, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		file := fields[len(fields)-1]
+		files = append(files, file)
+
+		fileType := "updated"
+		switch fields[0][:1] {
+		case "A":
+			fileType = "new"
+		case "D":
+			contextData[file] = map[string]string{"type": "deleted", "diff": "file deleted"}
+			continue
+		}
+
+		diff, err := RunGitCmd(dir, nil, "show", "--format=", "-M", hash, "--", file)
+		if err != nil {
+			return nil, nil, err
+		}
+		diff = sanitizeUTF8(diff)
+		if limit := getOptimalDiffSize(file); len(diff) > limit {
+			diff = truncateAtRune(diff, limit) + "... [truncated]"
+		}
+		if !utf8.ValidString(diff) || strings.TrimSpace(diff) == "" {
+			continue
+		}
+		contextData[file] = map[string]string{"type": fileType, "diff": diff}
+	}
+	return contextData, files, nil
+}
+
+// PlanReword regenerates the messages of the commits in spec without changing anything
+func PlanReword(dir, spec string) (*RewordPlan, error) {
+	base, hashes, err := ResolveRewriteRange(dir, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := geminiAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	instructions, _ := config.Get("commit_instructions").(string)
+
+	plan := &RewordPlan{Directory: dir, Base: base}
+	for _, hash := range hashes {
+		oldMessage, err := RunGitCmd(dir, nil, "log", "-1", "--format=%B", hash)
+		if err != nil {
+			return nil, utils.NewGitError("Failed to read commit message", err, map[string]interface{}{"directory": dir, "commit": hash})
+		}
+
+		contextData, files, err := commitDiffContext(dir, hash)
+		if err != nil {
+			return nil, utils.NewGitError("Failed to read commit diff", err, map[string]interface{}{"directory": dir, "commit": hash})
+		}
+
+		commit := RewordCommit{
+			Hash:       hash,
+			ShortHash:  hash[:7],
+			OldMessage: strings.TrimSpace(oldMessage),
+			Files:      files,
+		}
+		if len(contextData) == 0 {
+			// Nothing to describe (e.g. an empty commit); keep the message
+			commit.NewMessage = commit.OldMessage
+		} else {
+			message, err := sendToGemini(contextData, apiKey, instructions)
+			if err != nil {
+				return nil, err
+			}
+			commit.NewMessage = strings.TrimSpace(message)
+		}
+		utils.Debug(fmt.Sprintf("[GIT.REWORD]: %s: %q -> %q", commit.ShortHash, commit.OldMessage, commit.NewMessage))
+		plan.Commits = append(plan.Commits, commit)
+	}
+	return plan, nil
+}
+
+// ApplyReword rewrites the approved messages of plan with a scripted interactive rebase.
+// HEAD is saved to a backup ref first, which is returned so the rewrite can be undone.
+func ApplyReword(dir string, plan *RewordPlan) (string, error) {
+	changed := 0
+	for _, commit := range plan.Commits {
+		if commit.Approved && commit.NewMessage != "" && commit.NewMessage != commit.OldMessage {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return "", nil
+	}
+
+	if operation := operationInProgress(dir); operation != "" {
+		return "", utils.NewValidationError(
+			"A "+operation+" is in progress",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"suggestion": "Finish or abort it before rewording",
+			},
+		)
+	}
+
+	// The range must still be what was planned; HEAD may have moved while reviewing
+	base, hashes, err := ResolveRewriteRange(dir, plan.Base+"..HEAD")
+	if err != nil {
+		return "", err
+	}
+	if base != plan.Base || len(hashes) != len(plan.Commits) || hashes[len(hashes)-1] != plan.Commits[len(plan.Commits)-1].Hash {
+		return "", utils.NewValidationError(
+			"The branch changed since the messages were generated",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"suggestion": "Run reword again",
+			},
+		)
+	}
+
+	backupRef, err := CreateBackupRef(dir, "reword")
+	if err != nil {
+		return "", err
+	}
+
+	workDir, err := os.MkdirTemp("", "gitcury-reword-")
+	if err != nil {
+		return backupRef, utils.NewSystemError("Failed to create a temporary directory", err, map[string]interface{}{})
+	}
+	defer os.RemoveAll(workDir)
+
+	todoPath, err := writeRewordTodo(workDir, plan)
+	if err != nil {
+		return backupRef, err
+	}
+
+	env := map[string]string{
+		// The sequence editor replaces git's todo list with ours; no editor is ever opened
+		"GIT_SEQUENCE_EDITOR": "cp " + shellQuote(todoPath),
+		"GIT_EDITOR":          "true",
+	}
+
+	err = SafeGitOperation(dir, "reword", func() error {
+		_, stderr, err := RunGitCmdWithOutput(dir, env, "rebase", "-i", "--autostash", plan.Base)
+		if err != nil {
+			if _, _, abortErr := RunGitCmdWithOutput(dir, nil, "rebase", "--abort"); abortErr != nil {
+				utils.Debug("[GIT.REWORD]: rebase --abort failed: " + abortErr.Error())
+			}
+			return utils.NewGitError(
+				"Rebase failed; it was aborted",
+				err,
+				map[string]interface{}{
+					"directory": dir,
+					"stderr":    strings.TrimSpace(stderr),
+				},
+			)
+		}
+		return nil
+	})
+	if err != nil {
+		return backupRef, err
+	}
+
+	if !sameTree(dir, backupRef, "HEAD") {
+		utils.Error("[GIT.REWORD]: The rewritten branch differs in content from " + backupRef)
+		return backupRef, utils.NewGitError(
+			"Rewording changed the branch content",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"backupRef":  backupRef,
+				"suggestion": "Restore the branch with: git reset --hard " + backupRef,
+			},
+		)
+	}
+
+	utils.Info(fmt.Sprintf("[GIT.REWORD.SUCCESS]: Reworded %d commit(s) in %s (backup: %s)", changed, dir, backupRef))
+	return backupRef, nil
+}
+
+// writeRewordTodo writes the rebase todo list and message files for plan, returning the todo path.
+// Each approved commit is picked and then amended with its new message.
+func writeRewordTodo(workDir string, plan *RewordPlan) (string, error) {
+	// Amends follow the root's commit settings, like CommitBatch: hooks run unless noVerify is
+	// set, and signing uses the configured key and format
+	var amend strings.Builder
+	amend.WriteString("git")
+	for _, arg := range commitOptionArgs(config.GetCommitConfig(plan.Directory)) {
+		amend.WriteString(" " + shellQuote(arg))
+	}
+	amend.WriteString(" --amend --allow-empty")
+
+	var todo strings.Builder
+	for i, commit := range plan.Commits {
+		todo.WriteString("pick " + commit.Hash + "\n")
+		if !commit.Approved || commit.NewMessage == "" || commit.NewMessage == commit.OldMessage {
+			continue
+		}
+
+		messagePath := filepath.Join(workDir, fmt.Sprintf("message-%d", i))
+		if err := os.WriteFile(messagePath, []byte(commit.NewMessage+"\n"), 0600); err != nil {
+			return "", utils.NewSystemError("Failed to write commit message file", err, map[string]interface{}{"path": messagePath})
+		}
+		todo.WriteString("exec " + amend.String() + " --cleanup=verbatim -F " + shellQuote(messagePath) + "\n")
+	}
+
+	todoPath := filepath.Join(workDir, "git-rebase-todo")
+	if err := os.WriteFile(todoPath, []byte(todo.String()), 0600); err != nil {
+		return "", utils.NewSystemError("Failed to write rebase todo", err, map[string]interface{}{"path": todoPath})
+	}
+	return todoPath, nil
+}
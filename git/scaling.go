@@ -0,0 +1,245 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/embeddings"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultScalingSizes are the change set sizes 'clusters benchmark --scaling' times the clustering
+// algorithms on, up to the size of a formatting sweep or code generation run
+var DefaultScalingSizes = []int{250, 500, 1000, 2000}
+
+// Synthetic change sets have scalingDims-dimensional embeddings and about scalingClusterSize
+// files per group
+const (
+	scalingDims        = 64
+	scalingClusterSize = 20
+	scalingEps         = 0.4 // Same as the default semantic similarity threshold
+
+	// The exact algorithms are only timed up to these sizes; beyond them they take minutes
+	maxHierarchicalPoints = 500
+	maxAllPairsPoints     = 2000
+)
+
+// scalingAlgorithm is one clustering algorithm timed by RunScalingBenchmarks
+type scalingAlgorithm struct {
+	name      string
+	maxPoints int // 0 for no limit
+	run       func(data [][]float32, k int) (comparisons int, labels []int, err error)
+}
+
+var scalingAlgorithms = []scalingAlgorithm{
+	{"hierarchical", maxHierarchicalPoints, func(data [][]float32, k int) (int, []int, error) {
+		labels, err := embeddings.HierarchicalClustering(data, 1, scalingEps)
+		return len(data) * (len(data) - 1) / 2, labels, err
+	}},
+	{"all-pairs-dbscan", maxAllPairsPoints, func(data [][]float32, k int) (int, []int, error) {
+		graph, comparisons := allPairsNeighborGraph(data, scalingEps)
+		return comparisons, embeddings.DBSCANGraph(graph, 2), nil
+	}},
+	{"lsh-dbscan", 0, func(data [][]float32, k int) (int, []int, error) {
+		labels, metrics := embeddings.ScalableDBSCAN(data, scalingEps, 2)
+		return metrics.Comparisons, labels, nil
+	}},
+	{"kmeans", 0, func(data [][]float32, k int) (int, []int, error) {
+		labels, err := embeddings.KMeansOptimized(data, k, 20)
+		return 0, labels, err
+	}},
+	{"minibatch-kmeans", 0, func(data [][]float32, k int) (int, []int, error) {
+		labels, err := embeddings.MiniBatchKMeans(data, k, 0, 200)
+		return 0, labels, err
+	}},
+}
+
+// RunScalingBenchmarks times the exact and the scalable clustering algorithms on synthetic change
+// sets of the given sizes. ScalingExponents of the result holds the fitted b of time ∝ nᵇ per
+// algorithm: about 2 or more for the exact algorithms, close to 1 for the scalable ones.
+func RunScalingBenchmarks(sizes []int) BenchmarkResult {
+	wasEnabled := clusteringBenchmarksEnabled
+	if !wasEnabled {
+		EnableBenchmarking()
+		defer DisableBenchmarking()
+	}
+
+	for _, n := range sizes {
+		data, truth := syntheticEmbeddings(n, scalingDims)
+		k := (n + scalingClusterSize - 1) / scalingClusterSize
+
+		for _, algorithm := range scalingAlgorithms {
+			if algorithm.maxPoints > 0 && n > algorithm.maxPoints {
+				continue
+			}
+			startTime := time.Now()
+			comparisons, labels, err := algorithm.run(data, k)
+			if err != nil {
+				utils.Warning(fmt.Sprintf("[BENCHMARK]: %s on %d points failed: %v", algorithm.name, n, err))
+				continue
+			}
+			executionTime := time.Since(startTime)
+
+			recordBenchmark(ClusteringBenchmark{
+				TestName:        fmt.Sprintf("scaling-%s-%d", algorithm.name, n),
+				FileCount:       n,
+				TargetClusters:  k,
+				ActualClusters:  countLabels(labels),
+				Method:          algorithm.name,
+				ExecutionTime:   executionTime,
+				ConfidenceScore: labelPurity(labels, truth),
+				Comparisons:     comparisons,
+			})
+		}
+	}
+
+	result := GetBenchmarkResults()
+	result.ScalingExponents = scalingExponents(result.Benchmarks)
+	result.Summary += ScalingSummary(result.ScalingExponents)
+	return result
+}
+
+// ScalingSummary describes the fitted exponents for a benchmark summary, sorted by method
+func ScalingSummary(exponents map[string]float64) string {
+	if len(exponents) == 0 {
+		return ""
+	}
+	methods := make([]string, 0, len(exponents))
+	for method := range exponents {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	parts := make([]string, 0, len(methods))
+	for _, method := range methods {
+		parts = append(parts, fmt.Sprintf("%s n^%.2f", method, exponents[method]))
+	}
+	return ". Scaling: " + strings.Join(parts, ", ")
+}
+
+// syntheticEmbeddings returns n unit vectors in groups of about scalingClusterSize around random
+// centres, as a change set of related edits would embed, and the group of each vector
+func syntheticEmbeddings(n, dims int) ([][]float32, []int) {
+	rng := rand.New(rand.NewSource(int64(n))) //nolint:gosec // Non-cryptographic use, synthetic data
+	groups := (n + scalingClusterSize - 1) / scalingClusterSize
+	centres := make([][]float32, groups)
+	for g := range centres {
+		centres[g] = make([]float32, dims)
+		for j := range centres[g] {
+			centres[g][j] = float32(rng.NormFloat64())
+		}
+		centres[g] = normalizeVector(centres[g])
+	}
+
+	data := make([][]float32, n)
+	truth := make([]int, n)
+	spread := 0.12 / math.Sqrt(float64(dims)) // Keeps group members well within scalingEps
+	for i := range data {
+		group := rng.Intn(groups)
+		point := make([]float32, dims)
+		for j := range point {
+			point[j] = centres[group][j] + float32(rng.NormFloat64()*spread)
+		}
+		data[i] = normalizeVector(point)
+		truth[i] = group
+	}
+	return data, truth
+}
+
+// allPairsNeighborGraph is the exact neighbor graph the LSH index approximates
+func allPairsNeighborGraph(data [][]float32, eps float64) ([][]int, int) {
+	graph := make([][]int, len(data))
+	comparisons := 0
+	for i := range data {
+		for j := i + 1; j < len(data); j++ {
+			comparisons++
+			if euclideanDistance(data[i], data[j]) <= eps {
+				graph[i] = append(graph[i], j)
+				graph[j] = append(graph[j], i)
+			}
+		}
+	}
+	return graph, comparisons
+}
+
+func euclideanDistance(a, b []float32) float64 {
+	sum := 0.0
+	for i := range a {
+		diff := float64(a[i] - b[i])
+		sum += diff * diff
+	}
+	return math.Sqrt(sum)
+}
+
+func countLabels(labels []int) int {
+	distinct := make(map[int]bool)
+	for _, label := range labels {
+		distinct[label] = true
+	}
+	return len(distinct)
+}
+
+// labelPurity is the share of points whose cluster's most common true group is their own
+func labelPurity(labels, truth []int) float64 {
+	if len(labels) == 0 {
+		return 0
+	}
+	counts := make(map[int]map[int]int)
+	for i, label := range labels {
+		if counts[label] == nil {
+			counts[label] = make(map[int]int)
+		}
+		counts[label][truth[i]]++
+	}
+	majority := 0
+	for _, groups := range counts {
+		best := 0
+		for _, count := range groups {
+			if count > best {
+				best = count
+			}
+		}
+		majority += best
+	}
+	return float64(majority) / float64(len(labels))
+}
+
+// scalingExponents fits log(time) = b·log(n) + c per method over the scaling benchmarks
+func scalingExponents(benchmarks []ClusteringBenchmark) map[string]float64 {
+	type sample struct{ x, y float64 }
+	samples := make(map[string][]sample)
+	for _, benchmark := range benchmarks {
+		if !strings.HasPrefix(benchmark.TestName, "scaling-") || benchmark.ExecutionTime <= 0 {
+			continue
+		}
+		samples[benchmark.Method] = append(samples[benchmark.Method], sample{
+			x: math.Log(float64(benchmark.FileCount)),
+			y: math.Log(float64(benchmark.ExecutionTime)),
+		})
+	}
+
+	exponents := make(map[string]float64)
+	for method, points := range samples {
+		if len(points) < 2 {
+			continue
+		}
+		meanX, meanY := 0.0, 0.0
+		for _, point := range points {
+			meanX += point.x
+			meanY += point.y
+		}
+		meanX /= float64(len(points))
+		meanY /= float64(len(points))
+		covariance, variance := 0.0, 0.0
+		for _, point := range points {
+			covariance += (point.x - meanX) * (point.y - meanY)
+			variance += (point.x - meanX) * (point.x - meanX)
+		}
+		if variance > 0 {
+			exponents[method] = covariance / variance
+		}
+	}
+	return exponents
+}
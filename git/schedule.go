@@ -0,0 +1,83 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"strings"
+	"time"
+)
+
+// CommitGroup is one commit of a batch: the files that share a generated message
+type CommitGroup struct {
+	Message string   `json:"message"`
+	Files   []string `json:"files"`
+}
+
+// CommitGroups returns the commits CommitBatch creates for folder, in order. Groups keep the
+// order in which messages first appear so a failure always stops at the same place.
+func CommitGroups(folder output.Folder) []CommitGroup {
+	var groups []CommitGroup
+	index := make(map[string]int)
+	for _, entry := range folder.Files {
+		utils.Debug("[GIT.COMMIT]: Staging file for grouping: " + entry.Name + " with message: " + entry.Message)
+		i, exists := index[entry.Message]
+		if !exists {
+			i = len(groups)
+			index[entry.Message] = i
+			groups = append(groups, CommitGroup{Message: entry.Message})
+		}
+		groups[i].Files = append(groups[i].Files, entry.Name)
+	}
+	return groups
+}
+
+// scheduledDates returns the per-commit dates passed in envMap, one per group, or nil when
+// the commits are not scheduled
+func scheduledDates(envMap map[string]string, groups int) ([]string, error) {
+	schedule := strings.TrimSpace(envMap[config.CommitDatesEnv])
+	if schedule == "" {
+		return nil, nil
+	}
+
+	dates := strings.Split(schedule, ",")
+	if len(dates) != groups {
+		return nil, utils.NewValidationError(
+			"The commit schedule does not match the commits to create",
+			nil,
+			map[string]interface{}{
+				"scheduled":  len(dates),
+				"commits":    groups,
+				"suggestion": "Preview the schedule again; the generated messages may have changed",
+			},
+		)
+	}
+	return dates, nil
+}
+
+// withCommitDate returns a copy of envMap that dates the commit at date
+func withCommitDate(envMap map[string]string, date string) map[string]string {
+	commitEnv := make(map[string]string, len(envMap)+2)
+	for key, value := range envMap {
+		commitEnv[key] = value
+	}
+	commitEnv["GIT_AUTHOR_DATE"] = date
+	commitEnv["GIT_COMMITTER_DATE"] = date
+	return commitEnv
+}
+
+// HeadCommitTime returns the committer date of HEAD in dir; ok is false for an unborn branch
+func HeadCommitTime(dir string) (time.Time, bool, error) {
+	if revParse(dir, "HEAD") == "" {
+		return time.Time{}, false, nil
+	}
+	date, err := RunGitCmd(dir, nil, "log", "-1", "--format=%cI", "HEAD")
+	if err != nil {
+		return time.Time{}, false, utils.NewGitError("Failed to read the date of HEAD", err, map[string]interface{}{"directory": dir})
+	}
+	headTime, err := time.Parse(time.RFC3339, strings.TrimSpace(date))
+	if err != nil {
+		return time.Time{}, false, utils.NewGitError("Failed to parse the date of HEAD", err, map[string]interface{}{"directory": dir, "date": date})
+	}
+	return headTime, true, nil
+}
@@ -0,0 +1,227 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IndexState describes what is currently recorded in the git index of a repository
+type IndexState struct {
+	Staged          []string `json:"staged"`          // Files with staged changes
+	PartiallyStaged []string `json:"partiallyStaged"` // Files with both staged and unstaged changes
+}
+
+// HasStagedChanges reports whether the index contains any staged hunks
+func (s IndexState) HasStagedChanges() bool {
+	return len(s.Staged) > 0
+}
+
+// GetIndexState inspects the index of the repository in dir and returns the staged files
+// as absolute paths. Files that also carry unstaged modifications are reported as partially staged.
+func GetIndexState(dir string) (IndexState, error) {
+	state := IndexState{}
+
+	// -z keeps paths unquoted, so names with spaces or non-ASCII characters are read as they are
+	statusOutput, err := RunGitCmd(dir, nil, "status", "--porcelain", "-z")
+	if err != nil {
+		utils.Error("[GIT.INDEX.FAIL]: Failed to get git status: " + err.Error())
+		return state, err
+	}
+
+	entries := strings.Split(statusOutput, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+
+		indexStatus := entry[0]
+		worktreeStatus := entry[1]
+		// Renames and copies are followed by their source path, the entry itself is what gets committed
+		if indexStatus == 'R' || indexStatus == 'C' {
+			i++
+		}
+		if indexStatus == ' ' || indexStatus == '?' || indexStatus == '!' {
+			continue
+		}
+
+		relativePath := entry[3:]
+		absolutePath, err := filepath.Abs(filepath.Join(dir, relativePath))
+		if err != nil {
+			utils.Error("[GIT.PATH.FAIL]: Failed to resolve absolute path for '" + relativePath + "': " + err.Error())
+			continue
+		}
+
+		state.Staged = append(state.Staged, absolutePath)
+		if worktreeStatus != ' ' {
+			state.PartiallyStaged = append(state.PartiallyStaged, absolutePath)
+		}
+	}
+
+	sort.Strings(state.Staged)
+	sort.Strings(state.PartiallyStaged)
+
+	utils.Debug(fmt.Sprintf("[GIT.INDEX]: %d staged file(s), %d partially staged in %s",
+		len(state.Staged), len(state.PartiallyStaged), dir))
+	return state, nil
+}
+
+// WarnAboutStagedChanges warns when the index already holds staged hunks that are not part of
+// the files GitCury is about to commit. `git commit` records the whole index, so those hunks
+// would be swept into whichever generated commit happens to run first.
+func WarnAboutStagedChanges(dir string, files []string) {
+	state, err := GetIndexState(dir)
+	if err != nil || !state.HasStagedChanges() {
+		return
+	}
+
+	planned := make(map[string]bool, len(files))
+	for _, file := range files {
+		if abs, err := filepath.Abs(file); err == nil {
+			planned[abs] = true
+		}
+	}
+
+	var foreign []string
+	for _, file := range state.Staged {
+		if !planned[file] {
+			foreign = append(foreign, file)
+		}
+	}
+
+	if len(foreign) > 0 {
+		utils.Warning(fmt.Sprintf("⚠️ %d file(s) in %s are already staged but not part of the generated commits:", len(foreign), dir))
+		for _, file := range foreign {
+			utils.Warning("  • " + relativeTo(dir, file))
+		}
+		utils.Warning("💡 These changes will be included in the first commit. Use --staged-only to commit the index as-is, or unstage them first.")
+	}
+
+	if len(state.PartiallyStaged) > 0 {
+		utils.Warning(fmt.Sprintf("⚠️ %d file(s) in %s have both staged and unstaged changes; both will be committed together:", len(state.PartiallyStaged), dir))
+		for _, file := range state.PartiallyStaged {
+			utils.Warning("  • " + relativeTo(dir, file))
+		}
+	}
+}
+
+// GenStagedCommitMessage generates a single commit message for exactly what is in the index.
+// It returns the message along with the staged files it describes.
+func GenStagedCommitMessage(dir string) (string, []string, error) {
+	state, err := GetIndexState(dir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !state.HasStagedChanges() {
+		return "", nil, utils.NewValidationError(
+			"No staged changes found",
+			nil,
+			map[string]interface{}{
+				"directory":  dir,
+				"suggestion": "Stage changes with 'git add' before using --staged-only",
+			},
+		)
+	}
+
+	message, err := GenIndexCommitMessage(state.Staged, dir)
+	if err != nil {
+		utils.Error("[GEMINI.FAIL]: Error generating staged commit message: " + err.Error())
+		return "", nil, err
+	}
+
+	return message, state.Staged, nil
+}
+
+// CommitStaged commits the index exactly as it is, without staging anything else.
+// All entries of rootFolder must carry the same message, as produced by GenStagedCommitMessage.
+func CommitStaged(rootFolder output.Folder, env ...[]string) error {
+	if len(rootFolder.Files) == 0 {
+		return fmt.Errorf("no commit messages found for root folder: %s", rootFolder.Name)
+	}
+
+	message := rootFolder.Files[0].Message
+	generatedFor := make(map[string]bool, len(rootFolder.Files))
+	for _, entry := range rootFolder.Files {
+		if entry.Message != message {
+			return utils.NewValidationError(
+				"Staged-only commit expects a single message for the whole index",
+				nil,
+				map[string]interface{}{
+					"folder":     rootFolder.Name,
+					"suggestion": "Regenerate messages with 'gitcury getmsgs --staged-only'",
+				},
+			)
+		}
+		generatedFor[entry.Name] = true
+	}
+
+	state, err := GetIndexState(rootFolder.Name)
+	if err != nil {
+		return err
+	}
+
+	if !state.HasStagedChanges() {
+		return utils.NewValidationError(
+			"No staged changes to commit",
+			nil,
+			map[string]interface{}{
+				"directory": rootFolder.Name,
+			},
+		)
+	}
+
+	// The index may have changed between message generation and commit
+	changed := len(state.Staged) != len(generatedFor)
+	for _, file := range state.Staged {
+		if !generatedFor[file] {
+			changed = true
+			break
+		}
+	}
+	if changed {
+		utils.Warning("⚠️ The index in " + rootFolder.Name + " changed since the message was generated; the message may not describe the commit accurately.")
+	}
+
+	envMap := make(map[string]string)
+	if len(env) > 0 {
+		for _, pair := range env[0] {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) == 2 {
+				envMap[parts[0]] = parts[1]
+			}
+		}
+	}
+
+	utils.Debug(fmt.Sprintf("[GIT.COMMIT.STAGED]: Committing %d staged file(s) in %s", len(state.Staged), rootFolder.Name))
+	commitConfig := config.GetCommitConfig(rootFolder.Name, env...)
+	if err := applyIdentity(rootFolder.Name, envMap, &commitConfig); err != nil {
+		utils.Error("[GIT.COMMIT.FAIL]: Invalid commit identity: " + err.Error())
+		return err
+	}
+	journal := beginJournalEntry(rootFolder.Name, "commit-staged", rootFolder.Files, env...)
+	defer journal.finish()
+	if err := runCommit(rootFolder.Name, envMap, commitConfig, message, state.Staged); err != nil {
+		utils.Error("[GIT.COMMIT.FAIL]: Failed to commit staged changes: " + err.Error())
+		return err
+	}
+
+	output.RemoveFolder(rootFolder.Name)
+	utils.Info("[GIT.COMMIT.SUCCESS]: Staged changes committed successfully: " + rootFolder.Name)
+	return nil
+}
+
+// relativeTo returns file relative to dir when possible, for friendlier messages
+func relativeTo(dir, file string) string {
+	if absDir, err := filepath.Abs(dir); err == nil {
+		if rel, err := filepath.Rel(absDir, file); err == nil {
+			return rel
+		}
+	}
+	return file
+}
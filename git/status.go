@@ -0,0 +1,65 @@
+package git
+
+import (
+	"github.com/lakshyajain-0291/gitcury/interfaces"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"strconv"
+	"strings"
+)
+
+// GetRepositoryStatus summarises the branch, upstream and working tree of dir from a single
+// `git status --porcelain=v2 --branch`
+func GetRepositoryStatus(dir string) (interfaces.RepositoryStatus, error) {
+	status := interfaces.RepositoryStatus{Directory: dir}
+
+	output, err := RunGitCmd(dir, nil, "status", "--porcelain=v2", "--branch", "--untracked-files=all")
+	if err != nil {
+		return status, utils.NewGitError("Failed to get repository status", err, map[string]interface{}{"directory": dir})
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "#":
+			parseBranchHeader(&status, fields[1:])
+		case "1", "2":
+			// Ordinary and renamed entries: XY is the index and working tree status
+			xy := fields[1]
+			if xy[0] != '.' {
+				status.Staged++
+			}
+			if len(xy) > 1 && xy[1] != '.' {
+				status.Modified++
+			}
+		case "u":
+			status.Conflicted++
+		case "?":
+			status.Untracked++
+		}
+	}
+	return status, nil
+}
+
+// parseBranchHeader reads a "# branch.<key> <value>" header of porcelain v2 status
+func parseBranchHeader(status *interfaces.RepositoryStatus, fields []string) {
+	if len(fields) < 2 {
+		return
+	}
+	switch fields[0] {
+	case "branch.head":
+		if fields[1] != "(detached)" {
+			status.Branch = fields[1]
+		}
+	case "branch.upstream":
+		status.Upstream = fields[1]
+	case "branch.ab":
+		if len(fields) >= 3 {
+			status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "+"))
+			status.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "-"))
+		}
+	}
+}
@@ -5,6 +5,7 @@ go 1.24.1
 require (
 	github.com/google/generative-ai-go v0.19.0
 	github.com/spf13/cobra v1.9.1
+	golang.org/x/sys v0.31.0
 	google.golang.org/api v0.228.0
 	google.golang.org/genai v1.5.0
 	google.golang.org/grpc v1.71.0
@@ -38,7 +39,6 @@ require (
 	golang.org/x/net v0.37.0 // indirect
 	golang.org/x/oauth2 v0.28.0 // indirect
 	golang.org/x/sync v0.12.0 // indirect
-	golang.org/x/sys v0.31.0 // indirect
 	golang.org/x/text v0.23.0 // indirect
 	golang.org/x/time v0.11.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20250106144421-5f5ef82da422 // indirect
@@ -0,0 +1,7 @@
+package interfaces
+
+// EmbeddingBackend turns text, usually a diff, into a vector for semantic clustering
+type EmbeddingBackend interface {
+	Name() string // Recorded with cached vectors; vectors of different backends never mix
+	Embed(text string) ([]float32, error)
+}
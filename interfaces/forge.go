@@ -0,0 +1,22 @@
+package interfaces
+
+// PullRequest is a pull request (GitHub, Gitea) or merge request (GitLab)
+type PullRequest struct {
+	Number int    `json:"number"` // PR number, or the MR iid on GitLab
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	Head   string `json:"head"` // Source branch
+	Base   string `json:"base"` // Target branch
+	Draft  bool   `json:"draft"`
+}
+
+// Forge defines the operations GitCury needs from a code hosting service
+type Forge interface {
+	Name() string
+	DefaultBranch() (string, error)
+	// FindPullRequest returns the open pull request from head into base, or nil when there is none
+	FindPullRequest(head, base string) (*PullRequest, error)
+	CreatePullRequest(pr PullRequest) (*PullRequest, error)
+	UpdatePullRequest(pr PullRequest) (*PullRequest, error)
+}
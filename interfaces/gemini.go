@@ -1,6 +1,16 @@
 package interfaces
 
+// GenerationRequest is a free-form request to the model, used for output other than commit messages
+type GenerationRequest struct {
+	SystemInstruction string  `json:"systemInstruction"`
+	Prompt            string  `json:"prompt"`
+	MaxOutputTokens   int32   `json:"maxOutputTokens"` // Defaults to 100 when zero
+	Temperature       float32 `json:"temperature"`     // Defaults to 0.5 when zero
+	JSON              bool    `json:"json"`            // Ask for an application/json response
+}
+
 // GeminiRunner defines the interface for Gemini API operations
 type GeminiRunner interface {
 	SendToGemini(contextData map[string]map[string]string, apiKey string, customInstructions ...string) (string, error)
+	GenerateText(request GenerationRequest, apiKey string) (string, error)
 }
@@ -21,6 +21,29 @@ type OutputData struct {
 	Folders []Folder `json:"folders"`
 }
 
+// PushOptions holds per-invocation push overrides; zero values defer to the push configuration
+type PushOptions struct {
+	Remote         string `json:"remote"`         // Remote to push to instead of the upstream/configured one
+	SetUpstream    bool   `json:"setUpstream"`    // Record the pushed branch as upstream (-u)
+	ForceWithLease bool   `json:"forceWithLease"` // Overwrite the remote branch if it has not moved
+	Rebase         bool   `json:"rebase"`         // pull --rebase first when the branch is behind
+	PullRequest    bool   `json:"pullRequest"`    // Create or update a pull request after a successful push
+	Base           string `json:"base"`           // Target branch of the pull request
+}
+
+// RepositoryStatus summarises the working tree and branch state of a repository
+type RepositoryStatus struct {
+	Directory  string `json:"directory"`
+	Branch     string `json:"branch"`   // Empty when HEAD is detached
+	Upstream   string `json:"upstream"` // Empty when the branch has no upstream
+	Ahead      int    `json:"ahead"`
+	Behind     int    `json:"behind"`
+	Staged     int    `json:"staged"`
+	Modified   int    `json:"modified"`
+	Untracked  int    `json:"untracked"`
+	Conflicted int    `json:"conflicted"`
+}
+
 // GitRunner defines the interface for git operations
 type GitRunner interface {
 	RunGitCmd(dir string, envVars map[string]string, args ...string) (string, error)
@@ -28,6 +51,7 @@ type GitRunner interface {
 	CommitBatch(folder Folder, env ...[]string) error
 	GetChangedFiles(rootFolders []string, maxConcurrency int, env ...[]string) ([]Folder, error)
 	Status(rootPaths []string) ([]Folder, error)
+	RepositoryStatus(dir string) (RepositoryStatus, error)
 	ProcessOneFile(filePath, commitMessage string, env ...[]string) error
 	GetDiff(filePath string, env ...[]string) (string, error)
 	IsGitRepository(path string) bool
@@ -37,9 +61,12 @@ type GitRunner interface {
 	GetAllChangedFiles(dir string) ([]string, error)
 	BatchProcessGetMessages(allChangedFiles []string, rootFolder string) error
 	BatchProcessWithEmbeddings(allChangedFiles []string, rootFolder string, numClusters int) error
+	// Staged-only methods
+	GenStagedCommitMessage(dir string) (string, []string, error)
+	CommitStaged(folder Folder, env ...[]string) error
 	// Progress tracking methods
 	ProgressCommitBatch(folder Folder, env ...[]string) error
-	ProgressPushBranch(rootFolderName string, branch string) error
+	ProgressPushBranch(rootFolderName string, branch string, opts ...PushOptions) error
 }
 
 // OutputManager defines the interface for output operations
@@ -0,0 +1,49 @@
+package output
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"time"
+)
+
+// Cluster records why a group of files shares one generated commit message
+type Cluster struct {
+	Files          []string           `json:"files"`
+	Name           string             `json:"name,omitempty"`
+	Message        string             `json:"message"`
+	Method         string             `json:"method"`
+	Rule           string             `json:"rule"`
+	Confidence     float64            `json:"confidence"`
+	Similarity     float64            `json:"similarity"`
+	FileSimilarity map[string]float64 `json:"fileSimilarity,omitempty"`
+	Created        time.Time          `json:"created"`
+}
+
+// SetClusters replaces the recorded clusters of rootFolder
+func SetClusters(rootFolder string, clusters []Cluster) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(clusters) == 0 {
+		if folder := findFolder(rootFolder); folder != nil {
+			folder.Clusters = nil
+		}
+		return
+	}
+
+	utils.Debug("[" + config.Aliases.Output + "]: Recording clusters for folder: " + rootFolder)
+	folder := findOrCreateFolder(rootFolder)
+	folder.Clusters = append([]Cluster{}, clusters...)
+}
+
+// GetClusters returns the recorded clusters of rootFolder
+func GetClusters(rootFolder string) []Cluster {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	folder := findFolder(rootFolder)
+	if folder == nil {
+		return nil
+	}
+	return append([]Cluster{}, folder.Clusters...)
+}
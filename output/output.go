@@ -14,8 +14,9 @@ type FileEntry struct {
 }
 
 type Folder struct {
-	Name  string      `json:"name"`
-	Files []FileEntry `json:"files"`
+	Name     string      `json:"name"`
+	Files    []FileEntry `json:"files"`
+	Clusters []Cluster   `json:"clusters,omitempty"` // How the files were grouped, when they were
 }
 
 type OutputData struct {
@@ -113,8 +114,9 @@ func GetAll() OutputData {
 	copy := OutputData{Folders: make([]Folder, len(outputData.Folders))}
 	for i, folder := range outputData.Folders {
 		copy.Folders[i] = Folder{
-			Name:  folder.Name,
-			Files: append([]FileEntry{}, folder.Files...),
+			Name:     folder.Name,
+			Files:    append([]FileEntry{}, folder.Files...),
+			Clusters: append([]Cluster{}, folder.Clusters...),
 		}
 	}
 	return copy
@@ -122,10 +124,10 @@ func GetAll() OutputData {
 
 func Delete(file string, rootFolder string) {
 	mu.Lock()
-	defer mu.Unlock()
 
 	folder := findFolder(rootFolder)
 	if folder == nil {
+		mu.Unlock()
 		utils.Error("[" + config.Aliases.Output + "]: ⚠️ Folder not found: " + rootFolder)
 		return
 	}
@@ -137,10 +139,14 @@ func Delete(file string, rootFolder string) {
 		}
 	}
 
-	if len(folder.Files) == 0 {
+	empty := len(folder.Files) == 0
+
+	// RemoveFolder and SaveToFile take the read lock themselves
+	mu.Unlock()
+	if empty {
 		RemoveFolder(rootFolder)
+		return
 	}
-
 	SaveToFile()
 	utils.Debug("[" + config.Aliases.Output + "]: File deleted and output saved.")
 }
@@ -0,0 +1,207 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/embeddings"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVectorStoreRoundTripAndCompaction(t *testing.T) {
+	dir := t.TempDir()
+	vector := []float32{0.5, -0.25, 0.125, 0, -1}
+
+	for _, encoding := range []embeddings.VectorEncoding{embeddings.Float32Vectors, embeddings.Int8Vectors} {
+		store, err := embeddings.OpenVectorStore(filepath.Join(dir, encoding.String()), encoding)
+		if err != nil {
+			t.Fatalf("OpenVectorStore(%s) failed: %v", encoding, err)
+		}
+		if err := store.Put(embeddings.StoredVector{Key: "file:a", Vector: vector, Backend: "local", Meta: "hash-1"}); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+
+		// A second handle, as another process would open it, sees the vector
+		reopened, err := embeddings.OpenVectorStore(store.Dir(), encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored, found, err := reopened.Get("file:a")
+		if err != nil || !found {
+			t.Fatalf("Get after reopening failed: found=%v err=%v", found, err)
+		}
+		if stored.Meta != "hash-1" || stored.Backend != "local" || len(stored.Vector) != len(vector) {
+			t.Fatalf("Unexpected entry: %+v", stored)
+		}
+		tolerance := 0.0
+		if encoding == embeddings.Int8Vectors {
+			tolerance = 1.0 / 127
+		}
+		for i := range vector {
+			if math.Abs(float64(stored.Vector[i]-vector[i])) > tolerance {
+				t.Errorf("%s: component %d is %v, want %v", encoding, i, stored.Vector[i], vector[i])
+			}
+		}
+	}
+
+	// Overwrites and deletes leave dead records until the store is compacted
+	store, err := embeddings.OpenVectorStore(filepath.Join(dir, "compact"), embeddings.Float32Vectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := store.Put(embeddings.StoredVector{Key: "hunk:local:x", Vector: []float32{float32(i)}, Backend: "local"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Put(embeddings.StoredVector{Key: "hunk:local:y", Vector: vector, Backend: "local"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("hunk:local:y"); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := store.Stats()
+	if err := store.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	after, _ := store.Stats()
+	if after.Entries != 1 || after.DataBytes >= before.DataBytes || after.Generation == before.Generation {
+		t.Fatalf("Compaction did not shrink the store: before %+v, after %+v", before, after)
+	}
+	if stored, found, _ := store.Get("hunk:local:x"); !found || stored.Vector[0] != 19 {
+		t.Fatalf("Compaction lost the newest vector: %+v", stored)
+	}
+
+	// A record torn by a crash is dropped, keeping the ones before it
+	data, err := os.OpenFile(filepath.Join(store.Dir(), "vectors.dat"), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.Write([]byte{200, 0, 0, 0, 1, 2, 3}) //nolint:errcheck
+	data.Close()
+	recovered, err := embeddings.OpenVectorStore(store.Dir(), embeddings.Float32Vectors)
+	if err != nil {
+		t.Fatalf("Opening a torn store failed: %v", err)
+	}
+	if keys, _ := recovered.Keys(""); len(keys) != 1 {
+		t.Fatalf("Expected one key after recovery, got %v", keys)
+	}
+	if err := recovered.Put(embeddings.StoredVector{Key: "hunk:local:z", Vector: vector, Backend: "local"}); err != nil {
+		t.Fatalf("Put after recovery failed: %v", err)
+	}
+	if _, found, _ := recovered.Get("hunk:local:z"); !found {
+		t.Fatal("Vector written after recovery is missing")
+	}
+}
+
+func TestVectorStoreConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for writer := 0; writer < 4; writer++ {
+		wg.Add(1)
+		go func(writer int) {
+			defer wg.Done()
+			// Each writer has its own handle, like a separate process
+			store, err := embeddings.OpenVectorStore(dir, embeddings.Float32Vectors)
+			if err != nil {
+				errs <- err
+				return
+			}
+			for i := 0; i < 25; i++ {
+				key := fmt.Sprintf("hunk:local:%d-%d", writer, i)
+				if err := store.Put(embeddings.StoredVector{Key: key, Vector: []float32{float32(writer), float32(i)}, Backend: "local"}); err != nil {
+					errs <- err
+					return
+				}
+			}
+			if writer == 0 {
+				errs <- store.Compact()
+			}
+		}(writer)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Concurrent writer failed: %v", err)
+		}
+	}
+
+	store, err := embeddings.OpenVectorStore(dir, embeddings.Float32Vectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors, err := store.GetPrefix("hunk:")
+	if err != nil || len(vectors) != 100 {
+		t.Fatalf("Expected 100 vectors from 4 writers, got %d (%v)", len(vectors), err)
+	}
+}
+
+func TestJSONEmbeddingCachesAreMigrated(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cacheDir := filepath.Join(home, ".gitcury")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := map[string]interface{}{
+		"embedding_cache_0123456789ab.json": map[string]interface{}{
+			"rootFolder": env.TempDir,
+			"embeddings": map[string]interface{}{
+				"main.go": map[string]interface{}{
+					"filePath": "main.go", "embedding": []float32{0.6, 0.8}, "contentHash": "abc",
+					"lastUpdated": time.Now(),
+				},
+			},
+		},
+		"hunk_cache_0123456789ab.json": map[string]interface{}{
+			"rootFolder": env.TempDir,
+			"entries": map[string]interface{}{
+				"local:deadbeef": map[string]interface{}{"embedding": []float32{1, 0}, "backend": "local", "lastUsed": time.Now()},
+			},
+		},
+	}
+	for name, content := range legacy {
+		data, _ := json.Marshal(content)
+		if err := os.WriteFile(filepath.Join(cacheDir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := git.OpenEmbeddingStore()
+	if err != nil {
+		t.Fatalf("OpenEmbeddingStore failed: %v", err)
+	}
+	stats, err := store.Stats()
+	if err != nil || stats.ByKind["file"] != 1 || stats.ByKind["hunk"] != 1 {
+		t.Fatalf("Expected one file and one hunk vector after migration, got %+v (%v)", stats, err)
+	}
+	// The file entry came from the gemini era, when backends were not recorded
+	if stats.ByBackend["gemini"] != 1 || stats.ByBackend["local"] != 1 {
+		t.Errorf("Unexpected backends after migration: %v", stats.ByBackend)
+	}
+	for name := range legacy {
+		if _, err := os.Stat(filepath.Join(cacheDir, name+".migrated")); err != nil {
+			t.Errorf("%s was not retired: %v", name, err)
+		}
+	}
+
+	// Nothing is left to import a second time
+	if files, _, err := git.MigrateJSONCaches(store); err != nil || files != 0 {
+		t.Fatalf("Second migration imported %d files (%v)", files, err)
+	}
+}
@@ -0,0 +1,103 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/embeddings"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClusterCountIsChosenFromTheData(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+
+	data, truth := plantedClusters(80, 4, 32)
+	for _, criterion := range []string{config.SilhouetteCriterion, config.ElbowCriterion, config.GapCriterion, config.VoteCriterion} {
+		selection := embeddings.SelectK(data, 1, 10, criterion)
+		if selection.K != 4 {
+			t.Errorf("%s chose %d clusters for 4 planted groups: %+v", criterion, selection.K, selection)
+		}
+		if len(selection.Candidates) != 10 {
+			t.Errorf("%s: expected k from 1 to 10 to be scored, got %d candidates", criterion, len(selection.Candidates))
+		}
+	}
+
+	// A change without separate groups stays in one commit when the bounds allow it
+	single, _ := plantedClusters(40, 1, 32)
+	if selection := embeddings.SelectK(single, 1, 8, config.VoteCriterion); selection.K != 1 {
+		t.Errorf("Chose %d clusters for a single group: %+v", selection.K, selection)
+	}
+
+	// The bounds are respected even when the data suggests otherwise
+	if selection := embeddings.SelectK(data, 5, 8, config.VoteCriterion); selection.K < 5 || selection.K > 8 {
+		t.Errorf("Chose %d clusters outside the bounds 5-8", selection.K)
+	}
+
+	// Without a target, AutoCluster chooses the number and records the decision
+	utils.EnableStats()
+	labels, err := embeddings.AutoCluster(data, 0, 10)
+	if err != nil {
+		t.Fatalf("AutoCluster failed: %v", err)
+	}
+	if !sameGrouping(labels, truth) {
+		t.Error("AutoCluster did not recover the planted groups")
+	}
+	counts := utils.GetClusterCounts()
+	if len(counts) != 1 || counts[0].K != 4 || counts[0].Points != 80 || counts[0].Criterion != config.VoteCriterion {
+		t.Errorf("Unexpected cluster count decisions in stats: %+v", counts)
+	}
+
+	if err := config.SetClusteringConfigByKey("cluster_count_min", "0"); err == nil {
+		t.Error("cluster_count_min accepted 0")
+	}
+	if err := config.SetClusteringConfigByKey("cluster_count_criterion", "gap"); err != nil {
+		t.Fatalf("Setting the criterion failed: %v", err)
+	}
+	if criterion := config.GetClusteringConfig().ClusterCount.Criterion; criterion != config.GapCriterion {
+		t.Errorf("Criterion is %q after setting it to gap", criterion)
+	}
+}
+
+func TestAutoClusterCountWinsOverPersistedNumFilesToCommit(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// The default numFilesToCommit the loader writes into every config, with auto selection on
+	persisted := `{"GEMINI_API_KEY": "test-api-key", "root_folders": ["` + env.TempDir + `"], "numFilesToCommit": 5,
+		"clustering": {"clusterCount": {"auto": true}}}`
+	if err := os.MkdirAll(filepath.Join(home, ".gitcury"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".gitcury", "config.json"), []byte(persisted), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if clusters := core.GroupClusterCount(); clusters != 0 {
+		t.Errorf("Expected the number of commits to be chosen from the changes, got %d", clusters)
+	}
+	if clusters := core.GroupClusterCount(3); clusters != 3 {
+		t.Errorf("Expected --num to override the automatic choice, got %d", clusters)
+	}
+
+	if err := config.SetClusteringConfigByKey("cluster_count_auto", "false"); err != nil {
+		t.Fatalf("Turning off the automatic count failed: %v", err)
+	}
+	if clusters := core.GroupClusterCount(); clusters != 5 {
+		t.Errorf("Expected numFilesToCommit without the automatic choice, got %d", clusters)
+	}
+}
@@ -0,0 +1,159 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClusteringEvaluationScoresStrategies(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	t.Setenv("HOME", t.TempDir())
+
+	if ari := git.AdjustedRandIndex([]int{2, 2, 0, 0, 1}, []int{0, 0, 1, 1, 2}); ari != 1 {
+		t.Errorf("Relabelled ideal grouping scored ARI %.2f", ari)
+	}
+	if ari := git.AdjustedRandIndex([]int{0, 0, 0, 0, 0, 0}, []int{0, 0, 1, 1, 2, 2}); ari != 0 {
+		t.Errorf("A single commit scored ARI %.2f", ari)
+	}
+
+	backend := config.GetClusteringConfig().Methods.Semantic.Backend
+	fixtures := git.BuiltinEvalFixtures()
+	report, err := git.RunEvaluation(fixtures, git.EvalOptions{
+		Methods: []string{"directory", "history"},
+		Presets: []string{"speed"},
+		Backend: "local",
+	})
+	if err != nil {
+		t.Fatalf("RunEvaluation failed: %v", err)
+	}
+	if len(report.Results) != 3*len(fixtures) || len(report.Summaries) != 3 {
+		t.Fatalf("Expected 3 strategies on %d fixtures, got %d results and %d summaries",
+			len(fixtures), len(report.Results), len(report.Summaries))
+	}
+	for _, result := range report.Results {
+		if result.Error != "" {
+			t.Errorf("%s on %s failed: %s", result.Strategy, result.Fixture, result.Error)
+		}
+		if result.ARI < -1 || result.ARI > 1 || result.Purity <= 0 || result.Purity > 1 || result.Commits < 1 {
+			t.Errorf("Scores out of range for %s on %s: %+v", result.Strategy, result.Fixture, result)
+		}
+		// Co-change history ties the renamed files together apart from the logging fix
+		if result.Fixture == "rename-across-layers" && result.Strategy == "history" && result.ARI != 1 {
+			t.Errorf("History clustering did not recover the ideal grouping: %+v", result)
+		}
+	}
+	if after := config.GetClusteringConfig().Methods.Semantic.Backend; after != backend {
+		t.Errorf("Evaluation left the embedding backend at %q instead of %q", after, backend)
+	}
+
+	if _, err := git.RunEvaluation(fixtures, git.EvalOptions{Methods: []string{"telepathy"}}); err == nil {
+		t.Error("RunEvaluation accepted an unknown method")
+	}
+}
+
+func TestClusteringFixtureIsCapturedFromHistory(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	t.Setenv("HOME", t.TempDir())
+	initTestRepo(t, env.TempDir)
+
+	commit := func(message string, files map[string]string) {
+		for path, content := range files {
+			full := filepath.Join(env.TempDir, path)
+			if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		runGit(t, env.TempDir, "add", "-A")
+		runGit(t, env.TempDir, "commit", "-q", "-m", message)
+	}
+	commit("feat: add a parser", map[string]string{"parser/parse.go": "package parser\n", "parser/parse_test.go": "package parser\n"})
+	commit("docs: describe the parser", map[string]string{"docs/parser.md": "# Parser\n"})
+	commit("fix: handle empty input", map[string]string{"parser/parse.go": "package parser\n\n// empty input\n", "main.go": "package main\n\nfunc main() {}\n"})
+
+	fixture, err := git.CaptureEvalFixture(env.TempDir, "v0.1.0..HEAD", 10)
+	if err != nil {
+		t.Fatalf("CaptureEvalFixture failed: %v", err)
+	}
+	if len(fixture.Changes) != 4 {
+		t.Fatalf("Expected 4 changed files, got %+v", fixture.Changes)
+	}
+	groups := make(map[string]string)
+	for _, change := range fixture.Changes {
+		groups[change.Path] = change.Group
+	}
+	if !strings.HasSuffix(groups["parser/parse.go"], "fix: handle empty input") || groups["parser/parse.go"] != groups["main.go"] {
+		t.Errorf("A file changed twice belongs to the last commit that changed it: %v", groups)
+	}
+	if !strings.HasSuffix(groups["parser/parse_test.go"], "feat: add a parser") || !strings.HasSuffix(groups["docs/parser.md"], "docs: describe the parser") {
+		t.Errorf("Unexpected groups: %v", groups)
+	}
+
+	path := filepath.Join(t.TempDir(), "parser.json")
+	if err := git.SaveEvalFixture(fixture, path); err != nil {
+		t.Fatalf("SaveEvalFixture failed: %v", err)
+	}
+	loaded, err := git.LoadEvalFixtures(filepath.Dir(path))
+	if err != nil || len(loaded) != 1 || len(loaded[0].Changes) != 4 {
+		t.Fatalf("Loading the saved fixture failed: %v %+v", err, loaded)
+	}
+
+	report, err := git.RunEvaluation(loaded, git.EvalOptions{Methods: []string{"directory"}, Backend: "local"})
+	if err != nil {
+		t.Fatalf("RunEvaluation failed: %v", err)
+	}
+	if result := report.Results[0]; result.Error != "" || result.Files != 4 || result.IdealCommits != 3 {
+		t.Errorf("Unexpected result for the captured fixture: %+v", result)
+	}
+}
+
+// BenchmarkClusteringStrategies runs each method and preset over the built-in fixtures and
+// reports its mean scores next to the timings
+func BenchmarkClusteringStrategies(b *testing.B) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		b.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	b.Setenv("HOME", b.TempDir())
+
+	fixtures := git.BuiltinEvalFixtures()
+	strategies := append([]string{}, git.EvalMethods...)
+	for _, preset := range config.ClusteringPresets {
+		strategies = append(strategies, "preset:"+preset)
+	}
+	for _, strategy := range strategies {
+		options := git.EvalOptions{Backend: "local", Methods: []string{strategy}}
+		if preset, ok := strings.CutPrefix(strategy, "preset:"); ok {
+			options = git.EvalOptions{Backend: "local", Presets: []string{preset}}
+		}
+		b.Run(strategy, func(b *testing.B) {
+			var summary git.EvalSummary
+			for i := 0; i < b.N; i++ {
+				report, err := git.RunEvaluation(fixtures, options)
+				if err != nil {
+					b.Fatalf("RunEvaluation failed: %v", err)
+				}
+				summary = report.Summaries[0]
+			}
+			b.ReportMetric(summary.MeanARI, "ARI")
+			b.ReportMetric(summary.MeanPurity, "purity")
+			b.ReportMetric(summary.CommitError, "commits-off")
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClustersExplainGrouping(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	config.Set("clustering", map[string]interface{}{"defaultMethod": "pattern", "enableFallbackMethods": false})
+
+	var files []string
+	for _, name := range []string{"api/handler.go", "api/handler_test.go", "docs/api.md"} {
+		path := filepath.Join(env.TempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+
+	clusters, err := git.SmartClusterFiles(files, env.TempDir, 0)
+	if err != nil {
+		t.Fatalf("SmartClusterFiles failed: %v", err)
+	}
+	rules := map[string]string{}
+	for _, cluster := range clusters {
+		if cluster.Method != "pattern" || cluster.Confidence <= 0 || len(cluster.FileSimilarity) != len(cluster.Files) {
+			t.Errorf("Expected an explained pattern cluster, got %+v", cluster)
+		}
+		for _, file := range cluster.Files {
+			rules[filepath.Base(file)] = cluster.Rule
+		}
+	}
+	if rules["handler.go"] != git.RuleTestImplPair || rules["handler_test.go"] != git.RuleTestImplPair || rules["api.md"] != git.RuleSingleFile {
+		t.Errorf("Unexpected rules: %v", rules)
+	}
+
+	// Recorded clusters survive a reload of the output store
+	var records []output.Cluster
+	for _, cluster := range clusters {
+		records = append(records, output.Cluster{Files: cluster.Files, Message: "feat: update", Method: cluster.Method,
+			Rule: cluster.Rule, Confidence: cluster.Confidence, FileSimilarity: cluster.FileSimilarity})
+	}
+	output.SetClusters(env.TempDir, records)
+	output.SaveToFile()
+	output.LoadOutput()
+
+	explanations := core.ExplainClusters(env.TempDir)
+	if len(explanations) != 1 || len(explanations[0].Clusters) != len(clusters) {
+		t.Fatalf("Expected %d recorded clusters, got %+v", len(clusters), explanations)
+	}
+	if text := core.FormatClusterExplanations(explanations); !strings.Contains(text, "rule: "+git.RuleTestImplPair) {
+		t.Errorf("Expected the explanation to name the rule, got:\n%s", text)
+	}
+}
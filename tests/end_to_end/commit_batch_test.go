@@ -0,0 +1,91 @@
+package end_to_end
+
+import (
+	"errors"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommitBatchResumesAfterRejectedGroup(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	t.Setenv("HOME", t.TempDir())
+	initTestRepo(t, env.TempDir)
+	runGit(t, env.TempDir, "config", "user.name", "Test")
+	runGit(t, env.TempDir, "config", "user.email", "test@example.com")
+
+	// The hook rejects any commit that contains lint.go
+	hook := "#!/bin/sh\n" +
+		"if git diff --cached --name-only | grep -q lint.go; then\n" +
+		"\techo 'lint: lint.go has 2 problems'\n" +
+		"\techo 'lint.go:1: missing doc comment' >&2\n" +
+		"\texit 1\n" +
+		"fi\n"
+	hookPath := filepath.Join(env.TempDir, ".git", "hooks", "pre-commit")
+	if err := os.WriteFile(hookPath, []byte(hook), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// api.go is committed before the hook rejects lint.go, so the batch fails part way through
+	output.Clear()
+	for _, entry := range [][2]string{{"api.go", "feat: add api"}, {"lint.go", "feat: add lint"}, {"docs.md", "docs: add docs"}} {
+		path := filepath.Join(env.TempDir, entry[0])
+		if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		output.Set(path, env.TempDir, entry[1])
+	}
+	folder := output.GetFolder(env.TempDir)
+
+	err = git.CommitBatch(folder)
+	var structured *utils.StructuredError
+	if !errors.As(err, &structured) {
+		t.Fatalf("Expected a structured error from the rejected commit, got %v", err)
+	}
+	// git sends the stdout of hooks to stderr, so both lines are expected there
+	hookOutput := structured.Context["stdout"].(string) + "\n" + structured.Context["stderr"].(string)
+	for _, line := range []string{"lint: lint.go has 2 problems", "lint.go:1: missing doc comment"} {
+		if !strings.Contains(hookOutput, line) {
+			t.Errorf("Hook output %q missing from the error context: %v", line, structured.Context)
+		}
+	}
+	if hooks, _ := structured.Context["hooks"].([]string); len(hooks) == 0 || hooks[0] != "pre-commit" {
+		t.Errorf("Expected the pre-commit hook to be named, got %v", structured.Context["hooks"])
+	}
+
+	// Only the groups that did not go through are left to commit
+	remaining := output.GetFolder(env.TempDir)
+	for _, entry := range remaining.Files {
+		if filepath.Base(entry.Name) == "api.go" {
+			t.Error("api.go was committed but is still in the output")
+		}
+	}
+	if len(remaining.Files) != 2 {
+		t.Fatalf("Expected lint.go and docs.md to remain, got %+v", remaining.Files)
+	}
+	if staged, _ := exec.Command("git", "-C", env.TempDir, "diff", "--cached", "--name-only").Output(); len(strings.TrimSpace(string(staged))) != 0 {
+		t.Errorf("The failed group was left staged: %s", staged)
+	}
+
+	if err := os.Remove(hookPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := git.CommitBatch(remaining); err != nil {
+		t.Fatalf("Re-running the commit failed: %v", err)
+	}
+	log, _ := exec.Command("git", "-C", env.TempDir, "log", "--format=%s").Output()
+	subjects := strings.Split(strings.TrimSpace(string(log)), "\n")
+	if len(subjects) != 4 || strings.Count(string(log), "feat: add api") != 1 {
+		t.Errorf("Expected each group committed once after the initial commit, got %q", subjects)
+	}
+}
@@ -1,10 +1,13 @@
 package end_to_end
 
 import (
+	"encoding/json"
 	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/git"
 	"github.com/lakshyajain-0291/gitcury/tests/testutils"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -172,3 +175,88 @@ func TestConfigValidation(t *testing.T) {
 		t.Error("Expected app_name to exist in config")
 	}
 }
+
+func TestCommitConfigPerRootOverrides(t *testing.T) {
+	// Set up test environment
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+
+	config.Set("commit", map[string]interface{}{
+		"sign":          true,
+		"signingFormat": "ssh",
+		"signingKey":    "~/.ssh/id_ed25519.pub",
+		"roots": map[string]interface{}{
+			env.TempDir: map[string]interface{}{
+				"noVerify": true,
+				"sign":     false,
+			},
+		},
+	})
+
+	global := config.GetCommitConfig("/some/other/repo")
+	if !global.Sign || global.NoVerify || global.SigningFormat != "ssh" {
+		t.Errorf("Unexpected global commit config: %+v", global)
+	}
+
+	root := config.GetCommitConfig(env.TempDir)
+	if root.Sign || !root.NoVerify || root.SigningKey != "~/.ssh/id_ed25519.pub" {
+		t.Errorf("Expected root override to disable signing and hooks, got %+v", root)
+	}
+
+	// Command line overrides arrive as environment pairs
+	overridden := config.GetCommitConfig(env.TempDir, []string{config.SigningKeyEnv + "=ABC123", config.NoVerifyEnv + "=0"})
+	if !overridden.Sign || overridden.SigningKey != "ABC123" || overridden.NoVerify {
+		t.Errorf("Expected environment overrides to win, got %+v", overridden)
+	}
+
+	args := git.BuildCommitArgs(overridden, "feat: test")
+	expected := []string{"-c", "gpg.format=ssh", "commit", "-SABC123", "-m", "feat: test"}
+	if len(args) != len(expected) {
+		t.Fatalf("Expected args %v, got %v", expected, args)
+	}
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Errorf("Expected args %v, got %v", expected, args)
+			break
+		}
+	}
+}
+
+func TestConfigViewHidesForgeTokens(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+
+	config.Set("forge", map[string]interface{}{
+		"provider": "github",
+		"token":    "ghp_globalsecret0123456789",
+		"roots": map[string]interface{}{
+			env.TempDir: map[string]interface{}{"provider": "gitea", "token": "rootsecret"},
+		},
+	})
+
+	conf := config.GetAll()
+	config.MaskForgeTokens(conf)
+	shown, err := json.MarshalIndent(conf, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{"ghp_globalsecret0123456789", "globalsecret", "rootsecret"} {
+		if strings.Contains(string(shown), token) {
+			t.Errorf("Printed config contains the token %q:\n%s", token, shown)
+		}
+	}
+
+	// The settings themselves keep the tokens
+	if token := config.GetForgeConfig(env.TempDir).Token; token != "rootsecret" {
+		t.Errorf("Masking changed the configured root token to %q", token)
+	}
+	if token := config.GetForgeConfig("").Token; token != "ghp_globalsecret0123456789" {
+		t.Errorf("Masking changed the configured token to %q", token)
+	}
+}
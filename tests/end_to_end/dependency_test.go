@@ -0,0 +1,70 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDependencyClusteringAcrossDirectories(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	config.Set("clustering", map[string]interface{}{
+		"defaultMethod": "dependency", "enableFallbackMethods": false,
+		"methods": map[string]interface{}{"dependency": map[string]interface{}{"enabled": true}},
+	})
+
+	sources := map[string]string{
+		"go.mod":           "module example.com/shop\n\ngo 1.21\n",
+		"model/order.go":   "package model\n\ntype Order struct{ ID int }\n",
+		"service/order.go": "package service\n\nimport \"example.com/shop/model\"\n\nfunc Load() model.Order { return model.Order{ID: 1} }\n",
+		"docs/notes.go":    "package docs\n\nconst Notes = \"unrelated\"\n",
+		"web/app.js":       "import { format } from './format';\nconsole.log(format(1));\n",
+		"web/format.js":    "export function format(v) { return String(v); }\n",
+	}
+	var files []string
+	for name, content := range sources {
+		path := filepath.Join(env.TempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if name != "go.mod" {
+			files = append(files, path)
+		}
+	}
+
+	clusters, err := git.SmartClusterFiles(files, env.TempDir, 0)
+	if err != nil {
+		t.Fatalf("SmartClusterFiles failed: %v", err)
+	}
+	clusterOf := map[string]int{}
+	for i, cluster := range clusters {
+		for _, file := range cluster.Files {
+			rel, _ := filepath.Rel(env.TempDir, file)
+			clusterOf[rel] = i
+		}
+		if len(cluster.Files) > 1 && cluster.Rule != git.RuleDependency {
+			t.Errorf("Expected the dependency rule for %v, got %q", cluster.Files, cluster.Rule)
+		}
+	}
+
+	if clusterOf["model/order.go"] != clusterOf["service/order.go"] {
+		t.Errorf("Expected the new type to be grouped with its caller, got %+v", clusters)
+	}
+	if clusterOf["web/app.js"] != clusterOf["web/format.js"] {
+		t.Errorf("Expected the JavaScript import to connect both files, got %+v", clusters)
+	}
+	if clusterOf["docs/notes.go"] == clusterOf["model/order.go"] || clusterOf["docs/notes.go"] == clusterOf["web/app.js"] {
+		t.Errorf("Expected the unrelated file on its own, got %+v", clusters)
+	}
+}
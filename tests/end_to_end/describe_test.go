@@ -0,0 +1,143 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// runGit runs a git command in dir for test setup
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// initTestRepo creates a repository in dir with an initial commit tagged v0.1.0
+func initTestRepo(t *testing.T, dir string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	runGit(t, dir, "init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "chore: initial commit")
+	runGit(t, dir, "tag", "v0.1.0")
+}
+
+func TestDescribeRangeBuildsBudgetedPrompt(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+
+	large := strings.Repeat("// generated line\n", 2000)
+	if err := os.WriteFile(filepath.Join(env.TempDir, "big.go"), []byte(large), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, env.TempDir, "add", ".")
+	runGit(t, env.TempDir, "commit", "-q", "-m", "feat: add generated table", "-m", "Needed by the parser")
+
+	config.Set("describe", map[string]interface{}{"maxPromptChars": 4000})
+	env.GeminiMock.SetupGeneratedText("```json\n" + `{"title": "Add generated table", "summary": "Adds big.go", "motivation": "Parser needs it", "risk": "Low", "testNotes": "go test"}` + "\n```")
+
+	description, err := core.DescribeRange(env.TempDir, "v0.1.0..HEAD", git.DescribePullRequest, "")
+	if err != nil {
+		t.Fatalf("DescribeRange failed: %v", err)
+	}
+
+	prompt := env.GeminiMock.LastRequest.Prompt
+	if !strings.Contains(prompt, "feat: add generated table") || !strings.Contains(prompt, "Needed by the parser") {
+		t.Errorf("Prompt is missing the commit message: %q", prompt[:200])
+	}
+	if len(prompt) > 5000 || !description.Range.Files[0].Truncated {
+		t.Errorf("Diff was not trimmed to the budget (prompt length %d)", len(prompt))
+	}
+	if !env.GeminiMock.LastRequest.JSON {
+		t.Error("Expected a JSON response to be requested")
+	}
+
+	markdown := description.Markdown()
+	if !strings.HasPrefix(markdown, "# Add generated table") || !strings.Contains(markdown, "## Risk\n\nLow") {
+		t.Errorf("Unexpected Markdown: %q", markdown)
+	}
+}
+
+func TestDescribeChangelogEntry(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+
+	if err := os.WriteFile(filepath.Join(env.TempDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, env.TempDir, "commit", "-q", "-am", "fix: add missing main function")
+
+	env.GeminiMock.SetupGeneratedText(`{"added": [], "fixed": ["Add the missing main function"]}`)
+
+	description, err := core.DescribeRange(env.TempDir, "v0.1.0", git.DescribeChangelog, "")
+	if err != nil {
+		t.Fatalf("DescribeRange failed: %v", err)
+	}
+
+	markdown := description.Markdown()
+	if !strings.HasPrefix(markdown, "## [Unreleased]\n") || !strings.Contains(markdown, "### Fixed\n\n- Add the missing main function") {
+		t.Errorf("Unexpected changelog: %q", markdown)
+	}
+	if strings.Contains(markdown, "### Added") {
+		t.Errorf("Empty sections should be omitted: %q", markdown)
+	}
+}
+
+func TestDescribeTruncatesDiffsAtRuneBoundaries(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+
+	// Same-length headers with contents offset by one byte, so one of the cuts lands inside a
+	// two-byte character
+	for name, prefix := range map[string]string{"a.md": "", "b.md": "x"} {
+		content := prefix + strings.Repeat("é", 5000) + "\n"
+		if err := os.WriteFile(filepath.Join(env.TempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, env.TempDir, "add", ".")
+	runGit(t, env.TempDir, "commit", "-q", "-m", "docs: add notes")
+
+	commitRange, err := git.CollectRange(env.TempDir, "v0.1.0..HEAD", 100000)
+	if err != nil {
+		t.Fatalf("CollectRange failed: %v", err)
+	}
+	for _, file := range commitRange.Files {
+		if !file.Truncated {
+			t.Errorf("Expected the diff of %s to be truncated", file.Path)
+		}
+		if !utf8.ValidString(file.Diff) {
+			t.Errorf("Truncated diff of %s is not valid UTF-8", file.Path)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/embeddings"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLocalEmbeddingBackend(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	config.Set("clustering", map[string]interface{}{
+		"defaultMethod":         "semantic",
+		"enableFallbackMethods": false,
+		"methods":               map[string]interface{}{"semantic": map[string]interface{}{"backend": "local", "dimensions": 256}},
+	})
+
+	if name := embeddings.BackendName(); name != config.LocalEmbeddingBackend+":256" {
+		t.Fatalf("Expected the configured local backend, got %s", name)
+	}
+
+	login := "+func validateLoginToken(token string) error {\n+\treturn checkSessionToken(token)\n+}\n"
+	session := "+func checkSessionToken(token string) error {\n+\treturn refreshLoginSession(token)\n+}\n"
+	chart := "+.chart-legend { color: #333; margin: 4px; }\n"
+	vectors := map[string][]float32{}
+	for name, text := range map[string]string{"login": login, "session": session, "chart": chart} {
+		vector, err := embeddings.GenerateEmbedding(text)
+		if err != nil {
+			t.Fatalf("Local embedding failed: %v", err)
+		}
+		if len(vector) != 256 {
+			t.Fatalf("Expected 256 dimensions, got %d", len(vector))
+		}
+		vectors[name] = vector
+	}
+	again, _ := embeddings.GenerateEmbedding(login)
+	if !reflect.DeepEqual(again, vectors["login"]) {
+		t.Error("Expected the local backend to be deterministic")
+	}
+	if related, unrelated := embeddings.CosineSimilarity(vectors["login"], vectors["session"]),
+		embeddings.CosineSimilarity(vectors["login"], vectors["chart"]); related <= unrelated {
+		t.Errorf("Expected related changes to be closer: %.3f <= %.3f", related, unrelated)
+	}
+
+	// Semantic clustering works without the Gemini API
+	initTestRepo(t, env.TempDir)
+	var files []string
+	for _, name := range []string{"auth/login.go", "auth/session.go", "web/chart.css"} {
+		path := filepath.Join(env.TempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+	clusters, err := git.SmartClusterFiles(files, env.TempDir, 2)
+	if err != nil {
+		t.Fatalf("Semantic clustering with the local backend failed: %v", err)
+	}
+	total := 0
+	for _, cluster := range clusters {
+		if cluster.Method != "semantic" {
+			t.Errorf("Expected semantic clusters, got %+v", cluster)
+		}
+		total += len(cluster.Files)
+	}
+	if total != len(files) {
+		t.Errorf("Expected every file to be clustered, got %+v", clusters)
+	}
+}
+
+func TestHTTPEmbeddingBackend(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Invalid request body: %v", err)
+		}
+		w.Write([]byte(`{"data": [{"embedding": [0.5, 0.25, 0.125]}]}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	backend := embeddings.NewHTTPBackend(server.URL, "nomic-embed-text", 5)
+	embeddings.SetBackend(backend)
+	defer embeddings.SetBackend(nil)
+
+	vector, err := embeddings.GenerateEmbedding("+added line")
+	if err != nil {
+		t.Fatalf("HTTP embedding failed: %v", err)
+	}
+	if !reflect.DeepEqual(vector, []float32{0.5, 0.25, 0.125}) {
+		t.Errorf("Unexpected vector %v", vector)
+	}
+	if request["model"] != "nomic-embed-text" || request["input"] != "+added line" {
+		t.Errorf("Unexpected request %v", request)
+	}
+	if embeddings.IsRateLimited() {
+		t.Error("Expected a local embedding server not to be rate limited")
+	}
+}
@@ -0,0 +1,141 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/forge"
+	"github.com/lakshyajain-0291/gitcury/interfaces"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGitHubPullRequestCreateThenUpdate(t *testing.T) {
+	const token = "secret-token"
+	var pulls []map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/widgets/pulls":
+			if r.URL.Query().Get("head") != "acme:feature/login" {
+				t.Errorf("Unexpected head filter: %s", r.URL.Query().Get("head"))
+			}
+			json.NewEncoder(w).Encode(pulls)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/widgets/pulls":
+			var request map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&request)
+			pull := map[string]interface{}{
+				"number":   7,
+				"html_url": "https://github.example/acme/widgets/pull/7",
+				"title":    request["title"],
+				"body":     request["body"],
+				"head":     map[string]interface{}{"ref": request["head"]},
+				"base":     map[string]interface{}{"ref": request["base"]},
+			}
+			pulls = append(pulls, pull)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(pull)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/acme/widgets/pulls/7":
+			var request map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&request)
+			pulls[0]["body"] = request["body"]
+			json.NewEncoder(w).Encode(pulls[0])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := forge.NewGitHub(server.URL, "acme", "widgets", token, nil)
+
+	existing, err := client.FindPullRequest("feature/login", "main")
+	if err != nil || existing != nil {
+		t.Fatalf("Expected no open pull request, got %v (err %v)", existing, err)
+	}
+
+	commits := []forge.Commit{{Hash: "abc123", Subject: "Add login form"}}
+	created, err := client.CreatePullRequest(interfaces.PullRequest{
+		Title: forge.PullRequestTitle("feature/login", commits),
+		Body:  forge.PullRequestBody(commits),
+		Head:  "feature/login",
+		Base:  "main",
+	})
+	if err != nil {
+		t.Fatalf("CreatePullRequest failed: %v", err)
+	}
+	if created.Number != 7 || created.Title != "Add login form" {
+		t.Errorf("Unexpected pull request: %+v", created)
+	}
+
+	// A reviewer edits the description; the update must keep their text
+	pulls[0]["body"] = "Reviewer notes\n\n" + created.Body
+	existing, err = client.FindPullRequest("feature/login", "main")
+	if err != nil || existing == nil {
+		t.Fatalf("Expected to find the pull request, got %v (err %v)", existing, err)
+	}
+
+	commits = append(commits, forge.Commit{Hash: "def456", Subject: "Validate password length"})
+	existing.Body = forge.MergeBody(existing.Body, forge.PullRequestBody(commits))
+	updated, err := client.UpdatePullRequest(*existing)
+	if err != nil {
+		t.Fatalf("UpdatePullRequest failed: %v", err)
+	}
+	if !strings.HasPrefix(updated.Body, "Reviewer notes") || !strings.Contains(updated.Body, "def456") {
+		t.Errorf("Update lost manual edits or new commits: %q", updated.Body)
+	}
+	if strings.Count(updated.Body, "abc123") != 1 {
+		t.Errorf("Generated section was duplicated: %q", updated.Body)
+	}
+
+	// Errors must never contain the token
+	_, err = forge.NewGitHub(server.URL, "acme", "widgets", "wrong-"+token, nil).FindPullRequest("x", "main")
+	if err == nil || strings.Contains(err.Error(), token) {
+		t.Errorf("Expected an authorization error without the token, got %v", err)
+	}
+}
+
+func TestGitLabMergeRequestUsesProjectPath(t *testing.T) {
+	var requestedPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.EscapedPath()
+		if r.Header.Get("PRIVATE-TOKEN") != "gl-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var request map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&request)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"iid":           3,
+			"web_url":       "https://gitlab.example/group/sub/app/-/merge_requests/3",
+			"title":         request["title"],
+			"description":   request["description"],
+			"source_branch": request["source_branch"],
+			"target_branch": request["target_branch"],
+		})
+	}))
+	defer server.Close()
+
+	remote, err := forge.ParseRemoteURL("git@gitlab.example:group/sub/app.git")
+	if err != nil {
+		t.Fatalf("ParseRemoteURL failed: %v", err)
+	}
+	if remote.Owner != "group/sub" || remote.Repo != "app" {
+		t.Fatalf("Unexpected remote: %+v", remote)
+	}
+
+	client := forge.NewGitLab(server.URL, remote.Project(), "gl-token", nil)
+	created, err := client.CreatePullRequest(interfaces.PullRequest{Title: "Add app", Head: "feature", Base: "main", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePullRequest failed: %v", err)
+	}
+	if requestedPath != "/projects/group%2Fsub%2Fapp/merge_requests" {
+		t.Errorf("Unexpected request path: %s", requestedPath)
+	}
+	if created.Number != 3 || created.Title != "Draft: Add app" {
+		t.Errorf("Unexpected merge request: %+v", created)
+	}
+}
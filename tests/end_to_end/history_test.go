@@ -0,0 +1,124 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistoryClusteringFromCoChanges(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	config.Set("clustering", map[string]interface{}{
+		"defaultMethod": "history", "enableFallbackMethods": false,
+		"methods": map[string]interface{}{"history": map[string]interface{}{"enabled": true}},
+	})
+
+	write := func(name, content string) string {
+		path := filepath.Join(env.TempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// The schema and its migration always change together, the readme on its own
+	for i := 0; i < 3; i++ {
+		write("db/schema.sql", fmt.Sprintf("-- version %d\n", i))
+		write("migrations/latest.go", fmt.Sprintf("package migrations // %d\n", i))
+		runGit(t, env.TempDir, "add", ".")
+		runGit(t, env.TempDir, "commit", "-q", "-m", fmt.Sprintf("feat: schema v%d", i))
+
+		write("README.md", fmt.Sprintf("revision %d\n", i))
+		runGit(t, env.TempDir, "add", ".")
+		runGit(t, env.TempDir, "commit", "-q", "-m", fmt.Sprintf("docs: revision %d", i))
+	}
+
+	files := []string{
+		write("db/schema.sql", "-- version 3\n"),
+		write("migrations/latest.go", "package migrations // 3\n"),
+		write("README.md", "revision 3\n"),
+	}
+	clusters, err := git.SmartClusterFiles(files, env.TempDir, 0)
+	if err != nil {
+		t.Fatalf("SmartClusterFiles failed: %v", err)
+	}
+	if len(clusters) != 2 {
+		t.Fatalf("Expected the co-changed files together and the readme alone, got %+v", clusters)
+	}
+	for _, cluster := range clusters {
+		if len(cluster.Files) == 2 && (cluster.Rule != git.RuleCoChange || cluster.Similarity < 0.99) {
+			t.Errorf("Expected a co-change cluster, got %+v", cluster)
+		}
+	}
+
+	// New commits are mined on top of the cached matrix
+	history, err := git.LoadCoChangeHistory(env.TempDir)
+	if err != nil {
+		t.Fatalf("LoadCoChangeHistory failed: %v", err)
+	}
+	counted := history.Commits
+	runGit(t, env.TempDir, "add", ".")
+	runGit(t, env.TempDir, "commit", "-q", "-m", "feat: schema v3")
+	history, err = git.LoadCoChangeHistory(env.TempDir)
+	if err != nil {
+		t.Fatalf("LoadCoChangeHistory failed: %v", err)
+	}
+	if history.Commits != counted+1 {
+		t.Errorf("Expected one more commit to be counted, got %d after %d", history.Commits, counted)
+	}
+}
+
+func TestHistoryWithoutCoChangesLeavesGroupingToDirectories(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	t.Setenv("HOME", t.TempDir())
+	initTestRepo(t, env.TempDir)
+	config.Set("clustering", map[string]interface{}{
+		"methods": map[string]interface{}{"history": map[string]interface{}{"enabled": true}},
+	})
+
+	// Every file has history, but none changed together with another
+	var files []string
+	for _, name := range []string{"api/users.go", "api/orders.go", "api/auth.go", "docs/x.md"} {
+		path := filepath.Join(env.TempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("v1\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, env.TempDir, "add", ".")
+		runGit(t, env.TempDir, "commit", "-q", "-m", "add "+name)
+		files = append(files, path)
+	}
+	for _, path := range files {
+		if err := os.WriteFile(path, []byte("v2\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, target := range []int{0, 2} {
+		clusters, err := git.SmartClusterFiles(files, env.TempDir, target)
+		if err != nil {
+			t.Fatalf("SmartClusterFiles failed: %v", err)
+		}
+		if len(clusters) == 0 || clusters[0].Method == string(config.HistoryMethod) {
+			t.Errorf("Target %d: history won without any co-changes: %+v", target, clusters)
+		}
+	}
+}
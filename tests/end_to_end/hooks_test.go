@@ -0,0 +1,155 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHooksInstallAndUninstall(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+
+	if err := core.InstallHooks(false, env.TempDir); err != nil {
+		t.Fatalf("InstallHooks failed: %v", err)
+	}
+	hookPath := filepath.Join(env.TempDir, ".git", "hooks", git.PrepareCommitMsgHook)
+	content, err := os.ReadFile(hookPath)
+	if err != nil {
+		t.Fatalf("Hook was not written: %v", err)
+	}
+	if !strings.Contains(string(content), "hooks prepare-commit-msg") {
+		t.Errorf("Unexpected hook script:\n%s", content)
+	}
+
+	// Installing again replaces our own hook without --force
+	if err := core.InstallHooks(false, env.TempDir); err != nil {
+		t.Errorf("Reinstalling the hook failed: %v", err)
+	}
+
+	if err := core.UninstallHooks(env.TempDir); err != nil {
+		t.Fatalf("UninstallHooks failed: %v", err)
+	}
+	if _, err := os.Stat(hookPath); !os.IsNotExist(err) {
+		t.Error("Hook still exists after uninstalling")
+	}
+	if removed, err := git.UninstallPrepareCommitMsgHook(env.TempDir); err != nil || removed != "" {
+		t.Errorf("Uninstalling without a hook should do nothing, got %q, %v", removed, err)
+	}
+}
+
+func TestHooksHonourHooksPath(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	runGit(t, env.TempDir, "config", "core.hooksPath", "tools/hooks")
+
+	hookPath, err := git.InstallPrepareCommitMsgHook(env.TempDir, false)
+	if err != nil {
+		t.Fatalf("InstallPrepareCommitMsgHook failed: %v", err)
+	}
+	want := filepath.Join(env.TempDir, "tools", "hooks", git.PrepareCommitMsgHook)
+	if resolved, _ := filepath.EvalSymlinks(filepath.Dir(hookPath)); resolved != mustEvalSymlinks(t, filepath.Dir(want)) {
+		t.Errorf("Hook installed at %s, expected %s", hookPath, want)
+	}
+	if _, err := os.Stat(filepath.Join(env.TempDir, ".git", "hooks", git.PrepareCommitMsgHook)); !os.IsNotExist(err) {
+		t.Error("Hook was written to .git/hooks although core.hooksPath is set")
+	}
+}
+
+func TestHooksBackUpAndRestoreForeignHook(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+
+	hookPath := filepath.Join(env.TempDir, ".git", "hooks", git.PrepareCommitMsgHook)
+	foreign := "#!/bin/sh\necho team hook\n"
+	if err := os.WriteFile(hookPath, []byte(foreign), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := git.InstallPrepareCommitMsgHook(env.TempDir, false); err == nil {
+		t.Fatal("A foreign hook was replaced without --force")
+	}
+	if _, err := git.UninstallPrepareCommitMsgHook(env.TempDir); err == nil {
+		t.Error("A foreign hook was removed by uninstall")
+	}
+
+	if _, err := git.InstallPrepareCommitMsgHook(env.TempDir, true); err != nil {
+		t.Fatalf("Forced install failed: %v", err)
+	}
+	if backup, err := os.ReadFile(hookPath + ".gitcury-backup"); err != nil || string(backup) != foreign {
+		t.Fatalf("Foreign hook was not backed up: %q, %v", backup, err)
+	}
+
+	if _, err := git.UninstallPrepareCommitMsgHook(env.TempDir); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if restored, err := os.ReadFile(hookPath); err != nil || string(restored) != foreign {
+		t.Errorf("Foreign hook was not restored: %q, %v", restored, err)
+	}
+	if _, err := os.Stat(hookPath + ".gitcury-backup"); !os.IsNotExist(err) {
+		t.Error("Backup still exists after restoring it")
+	}
+}
+
+func TestPrepareCommitMsgSkipsGivenMessages(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	env.GeminiMock.SetupMockCommitMessage("parser.go", "feat: add the parser")
+
+	if err := os.WriteFile(filepath.Join(env.TempDir, "parser.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, env.TempDir, "add", "parser.go")
+
+	template := "\n# Please enter the commit message for your changes.\n"
+	messageFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	for _, source := range []string{"merge", "squash", "message", "commit"} {
+		if err := os.WriteFile(messageFile, []byte(template), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := git.PrepareCommitMsg(env.TempDir, messageFile, source); err != nil {
+			t.Fatalf("PrepareCommitMsg failed for %s: %v", source, err)
+		}
+		if content, _ := os.ReadFile(messageFile); string(content) != template {
+			t.Errorf("Message of a %s commit was changed to %q", source, content)
+		}
+	}
+
+	// A plain commit gets the generated message above git's comments
+	if err := git.PrepareCommitMsg(env.TempDir, messageFile, ""); err != nil {
+		t.Fatalf("PrepareCommitMsg failed: %v", err)
+	}
+	content, _ := os.ReadFile(messageFile)
+	if !strings.HasPrefix(string(content), "feat: add the parser\n") || !strings.HasSuffix(string(content), template) {
+		t.Errorf("Unexpected message file:\n%s", content)
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
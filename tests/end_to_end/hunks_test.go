@@ -0,0 +1,111 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHunkEmbeddingsSurviveUnrelatedEdits(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	t.Setenv("HOME", t.TempDir())
+	initTestRepo(t, env.TempDir)
+	config.Set("clustering", map[string]interface{}{
+		"defaultMethod":         "semantic",
+		"enableFallbackMethods": false,
+		"methods":               map[string]interface{}{"semantic": map[string]interface{}{"backend": "local", "embedInput": "hunks"}},
+	})
+
+	filler := strings.Repeat("// unchanged\n", 40)
+	original := "package main\n\nfunc login() {}\n" + filler + "func render() {}\n"
+	path := filepath.Join(env.TempDir, "app.go")
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, env.TempDir, "add", ".")
+	runGit(t, env.TempDir, "commit", "-q", "-m", "feat: app")
+
+	// Only the end of the file changes
+	renderEdit := strings.Replace(original, "func render() {}", "func render() { drawChart() }", 1)
+	if err := os.WriteFile(path, []byte(renderEdit), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hunks, err := git.FileDiffHunks(path, env.TempDir)
+	if err != nil || len(hunks) != 1 {
+		t.Fatalf("Expected one hunk, got %v (%v)", hunks, err)
+	}
+	renderHash := hunks[0].Hash
+
+	cache := git.LoadHunkEmbeddingCache(env.TempDir)
+	if _, err := cache.EmbedFile(path); err != nil {
+		t.Fatalf("EmbedFile failed: %v", err)
+	}
+	cache.Save()
+
+	// An unrelated edit above moves the render hunk but keeps its hash and cached vector
+	bothEdits := strings.Replace(renderEdit, "func login() {}", "func login() {\n\tcheckToken()\n\tcheckSession()\n}", 1)
+	if err := os.WriteFile(path, []byte(bothEdits), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hunks, err = git.FileDiffHunks(path, env.TempDir)
+	if err != nil || len(hunks) != 2 {
+		t.Fatalf("Expected two hunks, got %v (%v)", hunks, err)
+	}
+	if hunks[1].Hash != renderHash {
+		t.Errorf("Expected the moved hunk to keep its hash")
+	}
+
+	cache = git.LoadHunkEmbeddingCache(env.TempDir)
+	embedding, err := cache.EmbedFile(path)
+	if err != nil || len(embedding) == 0 {
+		t.Fatalf("EmbedFile failed: %v", err)
+	}
+	if cache.Hits != 1 || cache.Misses != 1 {
+		t.Errorf("Expected the unchanged hunk from the cache, got %d hits and %d misses", cache.Hits, cache.Misses)
+	}
+
+	// Semantic clustering embeds hunks in this mode
+	other := filepath.Join(env.TempDir, "chart.go")
+	if err := os.WriteFile(other, []byte("package main\n\nfunc drawChart() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	clusters, err := git.SmartClusterFiles([]string{path, other}, env.TempDir, 0)
+	if err != nil {
+		t.Fatalf("SmartClusterFiles failed: %v", err)
+	}
+	for _, cluster := range clusters {
+		if cluster.Method != "semantic" {
+			t.Errorf("Expected semantic clusters, got %+v", cluster)
+		}
+	}
+}
+
+func TestLargeNewFileIsCutAtARuneBoundary(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+
+	// A two-byte character straddles the size limit of a new file's hunk
+	path := filepath.Join(env.TempDir, "notes.md")
+	if err := os.WriteFile(path, []byte("a"+strings.Repeat("é", 6000)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hunks, err := git.FileDiffHunks(path, env.TempDir)
+	if err != nil || len(hunks) != 1 {
+		t.Fatalf("Expected the new text file as one hunk, got %v (%v)", hunks, err)
+	}
+	if !strings.Contains(hunks[0].Text, "é") {
+		t.Errorf("Unexpected hunk text: %.40q", hunks[0].Text)
+	}
+}
@@ -0,0 +1,64 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIdentityProfilePerRootFolder(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+
+	config.Set("identity", map[string]interface{}{
+		"profiles": map[string]interface{}{
+			"work": map[string]interface{}{"name": "Jo Work", "email": "jo@corp.example"},
+			"oss":  map[string]interface{}{"name": "Jo", "email": "jo@users.example.org"},
+		},
+		"profile": "oss",
+		"roots": map[string]interface{}{
+			env.TempDir: map[string]interface{}{"profile": "work", "emailDomain": "corp.example"},
+		},
+	})
+
+	if identity, err := config.GetIdentity(env.TempDir); err != nil || identity.Email != "jo@corp.example" {
+		t.Fatalf("Expected the work profile for the root folder, got %+v (err %v)", identity, err)
+	}
+	if identity, _ := config.GetIdentity("/elsewhere"); identity.Email != "jo@users.example.org" {
+		t.Errorf("Expected the default profile elsewhere, got %+v", identity)
+	}
+
+	file := filepath.Join(env.TempDir, "api.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	folder := output.Folder{Name: env.TempDir, Files: []output.FileEntry{{Name: file, Message: "feat: add api"}}}
+	if err := git.CommitBatch(folder); err != nil {
+		t.Fatalf("CommitBatch failed: %v", err)
+	}
+
+	identity, _ := exec.Command("git", "-C", env.TempDir, "log", "-1", "--format=%an <%ae>|%cn <%ce>").Output()
+	if got := strings.TrimSpace(string(identity)); got != "Jo Work <jo@corp.example>|Jo Work <jo@corp.example>" {
+		t.Errorf("Expected the profile as author and committer, got %q", got)
+	}
+
+	// An unknown profile is rejected before anything is committed
+	config.Set("identity", map[string]interface{}{"profile": "missing"})
+	if err := git.ValidateIdentity(env.TempDir); err == nil {
+		t.Error("Expected an unknown profile to be rejected")
+	}
+	config.Set("identity", map[string]interface{}{"email": "not-an-email", "name": "Jo"})
+	if err := git.ValidateIdentity(env.TempDir); err == nil {
+		t.Error("Expected an invalid email to be rejected")
+	}
+}
@@ -3,7 +3,13 @@ package end_to_end
 import (
 	"github.com/lakshyajain-0291/gitcury/config"
 	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/interfaces"
 	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -86,3 +92,109 @@ func TestPushOperationWithInvalidConfig(t *testing.T) {
 		t.Errorf("Expected error with invalid root_folders config, but got no error")
 	}
 }
+
+func TestPushOptionsArePassedThrough(t *testing.T) {
+	// Set up test environment
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+
+	env.GitMock.SetupMockPushResult(env.TempDir, true)
+
+	opts := interfaces.PushOptions{Remote: "upstream", SetUpstream: true, ForceWithLease: true}
+	err = core.PushOneRoot(env.TempDir, "feature", opts)
+	if err != nil {
+		t.Fatalf("Push operation failed: %v", err)
+	}
+
+	if env.GitMock.LastPushOptions != opts {
+		t.Errorf("Expected push options %+v, got %+v", opts, env.GitMock.LastPushOptions)
+	}
+
+	lastCall := env.GitMock.CommandCalls[len(env.GitMock.CommandCalls)-1]
+	expected := []string{"push", "--set-upstream", "--force-with-lease", "upstream", "feature"}
+	if len(lastCall.Args) != len(expected) {
+		t.Fatalf("Expected push args %v, got %v", expected, lastCall.Args)
+	}
+	for i := range expected {
+		if lastCall.Args[i] != expected[i] {
+			t.Errorf("Expected push args %v, got %v", expected, lastCall.Args)
+			break
+		}
+	}
+}
+
+func TestProtectedBranchMatching(t *testing.T) {
+	protected := []string{"main", "release/*"}
+
+	cases := map[string]bool{
+		"main":        true,
+		"release/1.2": true,
+		"feature/x":   false,
+		"mainline":    false,
+	}
+
+	for branch, want := range cases {
+		if got := git.IsProtectedBranch(branch, protected); got != want {
+			t.Errorf("IsProtectedBranch(%q) = %v, want %v", branch, got, want)
+		}
+	}
+}
+
+func TestForceWithLeaseKeepsCommitsPushedByOthers(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	t.Setenv("HOME", t.TempDir())
+
+	remote := filepath.Join(env.TempDir, "remote.git")
+	mine := filepath.Join(env.TempDir, "mine")
+	theirs := filepath.Join(env.TempDir, "theirs")
+	for _, dir := range []string{remote, mine, theirs} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, remote, "init", "-q", "--bare")
+	initTestRepo(t, mine)
+	runGit(t, mine, "remote", "add", "origin", remote)
+	runGit(t, mine, "checkout", "-q", "-b", "feature")
+	runGit(t, mine, "push", "-q", "-u", "origin", "feature")
+	runGit(t, theirs, "clone", "-q", "-b", "feature", remote, ".")
+
+	commit := func(dir, name string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, dir, "add", name)
+		runGit(t, dir, "commit", "-q", "-m", "add "+name)
+	}
+
+	// Someone else pushes while the local branch is rewritten
+	commit(theirs, "theirs.txt")
+	runGit(t, theirs, "push", "-q", "origin", "feature")
+	commit(mine, "mine.txt")
+
+	config.Set("push", map[string]interface{}{"fetchBeforePush": true})
+	if err := git.SmartPush(mine, "", interfaces.PushOptions{ForceWithLease: true}); err == nil {
+		t.Fatal("--force-with-lease overwrote a commit the local clone had never seen")
+	}
+	log, _ := exec.Command("git", "-C", remote, "log", "--format=%s", "feature").Output()
+	if !strings.Contains(string(log), "add theirs.txt") {
+		t.Errorf("The remote lost the other commit:\n%s", log)
+	}
+
+	// Once the other commit has been seen, overwriting it is deliberate
+	runGit(t, mine, "fetch", "-q", "origin")
+	if err := git.SmartPush(mine, "", interfaces.PushOptions{ForceWithLease: true}); err != nil {
+		t.Fatalf("Push with an up-to-date lease failed: %v", err)
+	}
+	log, _ = exec.Command("git", "-C", remote, "log", "-1", "--format=%s", "feature").Output()
+	if strings.TrimSpace(string(log)) != "add mine.txt" {
+		t.Errorf("Expected the local commit on the remote, got %q", log)
+	}
+}
@@ -0,0 +1,74 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegroupAndUndo(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	for _, key := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(key+"_NAME", "Test")
+		t.Setenv(key+"_EMAIL", "test@example.com")
+	}
+	config.Set("clustering", map[string]interface{}{"defaultMethod": "directory", "enableFallbackMethods": false})
+
+	// Two commits that each mix changes from two directories
+	for i, files := range [][]string{{"api/handler.go", "docs/api.md"}, {"api/router.go", "docs/usage.md"}} {
+		for _, name := range files {
+			path := filepath.Join(env.TempDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		runGit(t, env.TempDir, "add", ".")
+		runGit(t, env.TempDir, "commit", "-q", "-m", "wip "+string(rune('1'+i)))
+	}
+	headBefore, _ := exec.Command("git", "-C", env.TempDir, "rev-parse", "HEAD").Output()
+	treeBefore, _ := exec.Command("git", "-C", env.TempDir, "rev-parse", "HEAD^{tree}").Output()
+
+	if err := core.RegroupCommits(env.TempDir, "HEAD~2", 2, true, false); err != nil {
+		t.Fatalf("RegroupCommits failed: %v", err)
+	}
+
+	treeAfter, _ := exec.Command("git", "-C", env.TempDir, "rev-parse", "HEAD^{tree}").Output()
+	if string(treeAfter) != string(treeBefore) {
+		t.Error("Regrouping changed the tree")
+	}
+	for _, rev := range []string{"HEAD", "HEAD~1"} {
+		files, _ := exec.Command("git", "-C", env.TempDir, "show", "--format=", "--name-only", rev).Output()
+		dirs := map[string]bool{}
+		for _, file := range strings.Fields(string(files)) {
+			dirs[filepath.Dir(file)] = true
+		}
+		if len(dirs) != 1 {
+			t.Errorf("Expected %s to touch a single directory, got %q", rev, files)
+		}
+	}
+
+	if err := core.UndoRegroup(env.TempDir); err != nil {
+		t.Fatalf("UndoRegroup failed: %v", err)
+	}
+	headRestored, _ := exec.Command("git", "-C", env.TempDir, "rev-parse", "HEAD").Output()
+	if string(headRestored) != string(headBefore) {
+		t.Error("Undo did not restore the original commits")
+	}
+	status, _ := exec.Command("git", "-C", env.TempDir, "status", "--porcelain").Output()
+	if len(strings.TrimSpace(string(status))) != 0 {
+		t.Errorf("Expected a clean tree after undo, got %q", status)
+	}
+}
@@ -0,0 +1,168 @@
+package end_to_end
+
+import (
+	"errors"
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"github.com/lakshyajain-0291/gitcury/utils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConventionalCommitClassification(t *testing.T) {
+	tests := []struct {
+		subject, body string
+		bump          string
+	}{
+		{"feat(api): add endpoint", "", git.BumpMinor},
+		{"fix: handle empty input", "", git.BumpPatch},
+		{"refactor!: drop legacy flags", "", git.BumpMajor},
+		{"chore: update deps", "BREAKING-CHANGE: requires Go 1.24", git.BumpMajor},
+		{"docs: fix typo", "", git.BumpNone},
+		{"Update readme", "", git.BumpNone},
+	}
+
+	for _, test := range tests {
+		commit := git.ParseConventionalCommit("abc123", test.subject, test.body)
+		if commit.BumpFor() != test.bump {
+			t.Errorf("%q: expected %s bump, got %s", test.subject, test.bump, commit.BumpFor())
+		}
+	}
+
+	v, _ := git.ParseSemVer("v0.4.2", "v")
+	if next := v.Bump(git.BumpMajor).String(); next != "0.5.0" {
+		t.Errorf("Breaking change before 1.0.0 should bump minor, got %s", next)
+	}
+	rc, _ := git.ParseSemVer("2.0.0-rc.1", "")
+	if next := rc.Bump(git.BumpMinor).String(); next != "2.0.0" {
+		t.Errorf("Releasing a pre-release should finalise it, got %s", next)
+	}
+
+	// Pre-releases compare identifier by identifier, in the order of the SemVer 2.0 example
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0-rc.2", "1.0.0-rc.10", "1.0.0"}
+	for i := 0; i+1 < len(ordered); i++ {
+		lower, _ := git.ParseSemVer(ordered[i], "")
+		higher, _ := git.ParseSemVer(ordered[i+1], "")
+		if !lower.Less(higher) || higher.Less(lower) {
+			t.Errorf("Expected %s < %s", ordered[i], ordered[i+1])
+		}
+	}
+}
+
+func TestReleaseWithoutReleasableCommits(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	runGit(t, env.TempDir, "tag", "v1.4.0")
+
+	if err := os.WriteFile(filepath.Join(env.TempDir, "README.md"), []byte("# test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, env.TempDir, "add", ".")
+	runGit(t, env.TempDir, "commit", "-q", "-m", "docs: add readme")
+
+	plan, err := git.PlanRelease(env.TempDir, "")
+	if !errors.Is(err, git.ErrNothingToRelease) {
+		t.Fatalf("Expected nothing to release, got plan %+v and error %v", plan, err)
+	}
+	var structured *utils.StructuredError
+	if !errors.As(err, &structured) || structured.Type != utils.ValidationError {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+
+	// Folders without releasable changes are skipped, not reported as failures
+	plans, err := core.PlanReleases("", env.TempDir)
+	if err != nil || len(plans) != 0 {
+		t.Errorf("Expected the folder to be skipped, got %d plan(s) and error %v", len(plans), err)
+	}
+
+	// A forced bump still releases
+	if plan, err := git.PlanRelease(env.TempDir, git.BumpPatch); err != nil || plan.NextTag != "v1.4.1" {
+		t.Errorf("Expected a forced patch release, got %+v (%v)", plan, err)
+	}
+}
+
+func TestReleasePlanAndTag(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	runGit(t, env.TempDir, "tag", "v1.4.0")
+
+	for i, subject := range []string{"fix: close files on error", "feat(cli): add --json flag", "chore: bump deps"} {
+		file := filepath.Join(env.TempDir, "file"+string(rune('a'+i))+".go")
+		if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, env.TempDir, "add", ".")
+		runGit(t, env.TempDir, "commit", "-q", "-m", subject)
+	}
+
+	plans, err := core.PlanReleases("", env.TempDir)
+	if err != nil {
+		t.Fatalf("PlanReleases failed: %v", err)
+	}
+	plan := plans[0]
+	if plan.PreviousTag != "v1.4.0" || plan.NextTag != "v1.5.0" || plan.Bump != git.BumpMinor {
+		t.Fatalf("Unexpected plan: %s -> %s (%s)", plan.PreviousTag, plan.NextTag, plan.Bump)
+	}
+	if !strings.Contains(plan.Notes, "### Features\n\n- **cli:** add --json flag") ||
+		!strings.Contains(plan.Notes, "### Bug Fixes\n\n- close files on error") {
+		t.Errorf("Unexpected release notes:\n%s", plan.Notes)
+	}
+
+	// The tagger identity comes from the environment, the test repository has no user config
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	if err := core.TagReleases(plans); err != nil {
+		t.Fatalf("TagReleases failed: %v", err)
+	}
+	out, err := exec.Command("git", "-C", env.TempDir, "tag", "-l", "--format=%(objecttype)%0a%(contents)", "v1.5.0").Output()
+	if err != nil || !strings.HasPrefix(string(out), "tag\n## v1.5.0") || !strings.Contains(string(out), "### Features") {
+		t.Errorf("Expected an annotated tag with the notes, got %q (err %v)", out, err)
+	}
+
+	// Tagging the same version again must be refused
+	if err := core.TagReleases(plans); err == nil {
+		t.Error("Expected an error when the tag already exists")
+	}
+}
+
+func TestReleaseTagUsesSigningFormat(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	key := filepath.Join(t.TempDir(), "release_key")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "release", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen failed: %v\n%s", err, out)
+	}
+	config.Set("commit", map[string]interface{}{"sign": true, "signingFormat": "ssh", "signingKey": key + ".pub"})
+
+	if err := git.CreateReleaseTag(env.TempDir, &git.ReleasePlan{NextTag: "v1.0.0", Notes: "## v1.0.0\n"}); err != nil {
+		t.Fatalf("Signed tag with an SSH key failed: %v", err)
+	}
+	out, err := exec.Command("git", "-C", env.TempDir, "cat-file", "tag", "v1.0.0").Output()
+	if err != nil || !strings.Contains(string(out), "-----BEGIN SSH SIGNATURE-----") {
+		t.Errorf("Expected an SSH signature on the tag, got %q (err %v)", out, err)
+	}
+}
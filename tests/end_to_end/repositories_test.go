@@ -0,0 +1,139 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorktreeIndexLockIsDetected(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+
+	worktree := filepath.Join(t.TempDir(), "feature")
+	runGit(t, env.TempDir, "worktree", "add", "-q", "-b", "feature", worktree)
+
+	gitDir, err := git.GitDir(worktree)
+	if err != nil || gitDir == filepath.Join(worktree, ".git") {
+		t.Fatalf("Expected the worktree git directory to be resolved, got %q (err %v)", gitDir, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(gitDir, "index.lock"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := git.CheckRepositoryHealth(worktree); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("Expected the worktree index lock to be reported, got %v", err)
+	}
+}
+
+func TestSubmoduleCommittedAsSeparateUnit(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	submodule := initSuperproject(t, env.TempDir)
+
+	if units := git.DiscoverCommitUnits(env.TempDir); len(units) != 2 || units[1] != submodule {
+		t.Fatalf("Expected the submodule as a second commit unit, got %v", units)
+	}
+
+	// Uncommitted content of the submodule belongs to the submodule, not the superproject
+	feature := filepath.Join(submodule, "feature.go")
+	if err := os.WriteFile(feature, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := git.GetAllChangedFiles(env.TempDir)
+	if err != nil || len(changed) != 0 {
+		t.Fatalf("Expected no changes in the superproject, got %v (err %v)", changed, err)
+	}
+
+	core.SetGitRunner(&git.DefaultGitRunner{})
+	output.Set(feature, submodule, "feat: add feature")
+	if err := core.CommitAllRoots(); err != nil {
+		t.Fatalf("CommitAllRoots failed: %v", err)
+	}
+
+	// The pointer bump is committed in the superproject and described by the submodule's commits
+	files, _ := exec.Command("git", "-C", env.TempDir, "show", "--format=", "--name-only", "HEAD").Output()
+	if strings.TrimSpace(string(files)) != "lib" {
+		t.Errorf("Expected the superproject to record the submodule update, got %q", files)
+	}
+	if context := env.GeminiMock.LastContextData[submodule]; !strings.Contains(context["diff"], "feat: add feature") {
+		t.Errorf("Expected the bump to be described by the submodule commits, got %v", context)
+	}
+	status, _ := exec.Command("git", "-C", env.TempDir, "status", "--porcelain").Output()
+	if strings.TrimSpace(string(status)) != "" {
+		t.Errorf("Expected a clean superproject, got %q", status)
+	}
+}
+
+func TestSubmoduleBumpAfterScheduledCommit(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	submodule := initSuperproject(t, env.TempDir)
+	core.SetGitRunner(&git.DefaultGitRunner{})
+	defer core.SetGitRunner(env.GitMock)
+
+	for _, name := range []string{"api.go", "db.go"} {
+		path := filepath.Join(submodule, name)
+		if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		output.Set(path, submodule, "feat: add "+name)
+	}
+
+	// commit with-date commits each root folder on its own
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	timeline := []core.ScheduledCommit{{Root: submodule, Date: start}, {Root: submodule, Date: start.Add(time.Hour)}}
+	if err := core.CommitOnSchedule(timeline); err != nil {
+		t.Fatalf("CommitOnSchedule failed: %v", err)
+	}
+
+	// The bump follows the last submodule commit
+	bump, _ := exec.Command("git", "-C", env.TempDir, "show", "--name-only", "--format=%aI", "HEAD").Output()
+	fields := strings.Fields(string(bump))
+	if len(fields) != 2 || fields[1] != "lib" {
+		t.Fatalf("Expected the superproject to record the submodule update, got %q", bump)
+	}
+	if date, _ := time.Parse(time.RFC3339, fields[0]); !date.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected the bump to be dated %s, got %s", start.Add(time.Hour), fields[0])
+	}
+	status, _ := exec.Command("git", "-C", env.TempDir, "status", "--porcelain").Output()
+	if strings.TrimSpace(string(status)) != "" {
+		t.Errorf("Expected a clean superproject, got %q", status)
+	}
+}
+
+// initSuperproject makes dir a repository with the submodule "lib" and returns its path
+func initSuperproject(t *testing.T, dir string) string {
+	t.Helper()
+	for _, key := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(key+"_NAME", "Test")
+		t.Setenv(key+"_EMAIL", "test@example.com")
+	}
+	initTestRepo(t, dir)
+
+	library := t.TempDir()
+	initTestRepo(t, library)
+	runGit(t, dir, "-c", "protocol.file.allow=always", "submodule", "add", "-q", library, "lib")
+	runGit(t, dir, "commit", "-q", "-m", "chore: add lib")
+
+	config.Set("repositories", map[string]interface{}{"submodules": true})
+	return filepath.Join(dir, "lib")
+}
@@ -0,0 +1,66 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClusterReviewEditsAndPlan(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+
+	path := func(name string) string { return filepath.Join(env.TempDir, name) }
+	proposed := []git.FileCluster{
+		{Files: []string{path("api/handler.go"), path("docs/api.md")}, Method: "semantic", Rule: git.RuleSimilarDiffs},
+		{Files: []string{path("api/router.go")}, Method: "semantic", Rule: git.RuleSingleFile},
+	}
+
+	// Moving the only file out of a cluster removes it; the original slices stay untouched
+	moved := git.MoveFile(proposed, path("api/router.go"), 0, env.TempDir)
+	if len(moved) != 1 || len(moved[0].Files) != 3 || moved[0].Rule != git.RuleEdited || len(proposed[0].Files) != 2 {
+		t.Fatalf("Unexpected clusters after moving a file: %+v", moved)
+	}
+	split := git.SplitCluster(moved, 0, []string{path("docs/api.md")}, env.TempDir)
+	if len(split) != 2 || !reflect.DeepEqual(split[1].Files, []string{path("docs/api.md")}) {
+		t.Fatalf("Unexpected clusters after splitting: %+v", split)
+	}
+	if merged := git.MergeClusters(split, 1, 0, env.TempDir); len(merged) != 1 || len(merged[0].Files) != 3 {
+		t.Fatalf("Unexpected clusters after merging: %+v", merged)
+	}
+	if renamed := git.RenameCluster(split, 1, "docs"); renamed[1].Name != "docs" || split[1].Name != "" {
+		t.Errorf("Expected only the copy to be renamed, got %+v", renamed)
+	}
+
+	// A plan regroups the files it mentions and leaves the rest as proposed
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	plan := `{"folders": [{"rootFolder": "` + env.TempDir + `", "clusters": [
+		{"name": "api", "files": ["api/handler.go", "api/router.go", "missing.go"]}]}]}`
+	if err := os.WriteFile(planPath, []byte(plan), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := core.LoadClusterPlan(planPath)
+	if err != nil {
+		t.Fatalf("LoadClusterPlan failed: %v", err)
+	}
+	reviewed, err := core.ClusterPlanReview(loaded)(env.TempDir, proposed)
+	if err != nil {
+		t.Fatalf("Applying the plan failed: %v", err)
+	}
+	expected := [][]string{{path("api/handler.go"), path("api/router.go")}, {path("docs/api.md")}}
+	if !reflect.DeepEqual(git.ClusterFileLists(reviewed), expected) || reviewed[0].Name != "api" {
+		t.Errorf("Expected %v, got %+v", expected, reviewed)
+	}
+
+	// Other root folders keep their proposed clusters
+	if other, _ := core.ClusterPlanReview(loaded)("/elsewhere", proposed); !reflect.DeepEqual(other, proposed) {
+		t.Errorf("Expected an unplanned root folder to be left alone, got %+v", other)
+	}
+}
@@ -0,0 +1,153 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRewordUnpushedCommits(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	if err := os.WriteFile(filepath.Join(env.TempDir, "README.md"), []byte("# test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, env.TempDir, "add", "README.md")
+	runGit(t, env.TempDir, "commit", "-q", "-m", "docs: add readme")
+
+	remote := t.TempDir()
+	runGit(t, remote, "init", "-q", "--bare")
+	runGit(t, env.TempDir, "remote", "add", "origin", remote)
+	runGit(t, env.TempDir, "push", "-q", "origin", "HEAD")
+	runGit(t, env.TempDir, "fetch", "-q", "origin")
+
+	for _, name := range []string{"parser.go", "lexer.go"} {
+		if err := os.WriteFile(filepath.Join(env.TempDir, name), []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, env.TempDir, "add", name)
+		runGit(t, env.TempDir, "commit", "-q", "-m", "wip")
+	}
+	treeBefore, _ := exec.Command("git", "-C", env.TempDir, "rev-parse", "HEAD^{tree}").Output()
+
+	// The readme commit is on origin and must not be rewritten
+	if err := core.RewordCommits(env.TempDir, "HEAD~3", true, false); err == nil ||
+		!strings.Contains(err.Error(), "already pushed") {
+		t.Fatalf("Expected pushed commits to be refused, got %v", err)
+	}
+
+	if err := core.RewordCommits(env.TempDir, "HEAD~2..HEAD", true, false); err != nil {
+		t.Fatalf("RewordCommits failed: %v", err)
+	}
+
+	log, _ := exec.Command("git", "-C", env.TempDir, "log", "--format=%s", "-3").Output()
+	if got := strings.TrimSpace(string(log)); got != "feat: update lexer.go\nfeat: update parser.go\ndocs: add readme" {
+		t.Errorf("Unexpected history after reword:\n%s", got)
+	}
+
+	treeAfter, _ := exec.Command("git", "-C", env.TempDir, "rev-parse", "HEAD^{tree}").Output()
+	if string(treeAfter) != string(treeBefore) {
+		t.Error("Rewording changed the tree")
+	}
+
+	refs, _ := exec.Command("git", "-C", env.TempDir, "for-each-ref", "--format=%(refname)", "refs/gitcury/backups/reword/").Output()
+	if strings.TrimSpace(string(refs)) == "" {
+		t.Error("Expected a backup ref to be created")
+	}
+}
+
+func TestRewordFollowsCommitConfig(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	if err := os.WriteFile(filepath.Join(env.TempDir, "parser.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, env.TempDir, "add", "parser.go")
+	runGit(t, env.TempDir, "commit", "-q", "-m", "wip")
+
+	// The commit-msg hook records every message it sees
+	hookLog := filepath.Join(t.TempDir(), "hook.log")
+	hook := "#!/bin/sh\nhead -n 1 \"$1\" >> " + hookLog + "\n"
+	if err := os.WriteFile(filepath.Join(env.TempDir, ".git", "hooks", "commit-msg"), []byte(hook), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	signed := false
+	if _, err := exec.LookPath("ssh-keygen"); err == nil {
+		key := filepath.Join(t.TempDir(), "commit_key")
+		if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "commit", "-f", key).CombinedOutput(); err != nil {
+			t.Fatalf("ssh-keygen failed: %v\n%s", err, out)
+		}
+		config.Set("commit", map[string]interface{}{"sign": true, "signingFormat": "ssh", "signingKey": key + ".pub"})
+		signed = true
+	}
+
+	if err := core.RewordCommits(env.TempDir, "HEAD~1..HEAD", true, false); err != nil {
+		t.Fatalf("RewordCommits failed: %v", err)
+	}
+
+	if seen, _ := os.ReadFile(hookLog); !strings.Contains(string(seen), "feat: update parser.go") {
+		t.Errorf("The commit-msg hook did not run for the reworded commit, saw %q", seen)
+	}
+	if signed {
+		out, _ := exec.Command("git", "-C", env.TempDir, "cat-file", "commit", "HEAD").Output()
+		if !strings.Contains(string(out), "-----BEGIN SSH SIGNATURE-----") {
+			t.Errorf("Expected the reworded commit to be signed, got:\n%s", out)
+		}
+	}
+}
+
+func TestRewordTruncatesDiffsAtRuneBoundaries(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+
+	// Same-length headers with contents offset by one byte, so one of the cuts lands inside a
+	// two-byte character
+	for name, prefix := range map[string]string{"a.md": "", "b.md": "x"} {
+		content := prefix + strings.Repeat("é", 5000) + "\n"
+		if err := os.WriteFile(filepath.Join(env.TempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, env.TempDir, "add", ".")
+	runGit(t, env.TempDir, "commit", "-q", "-m", "wip")
+
+	if err := core.RewordCommits(env.TempDir, "HEAD~1..HEAD", true, true); err != nil {
+		t.Fatalf("RewordCommits failed: %v", err)
+	}
+	if len(env.GeminiMock.LastContextData) != 2 {
+		t.Fatalf("Expected both files in the context, got %d", len(env.GeminiMock.LastContextData))
+	}
+	for file, data := range env.GeminiMock.LastContextData {
+		if !strings.HasSuffix(data["diff"], "... [truncated]") {
+			t.Errorf("Expected the diff of %s to be truncated", file)
+		}
+		if !utf8.ValidString(data["diff"]) {
+			t.Errorf("Truncated diff of %s is not valid UTF-8", file)
+		}
+	}
+}
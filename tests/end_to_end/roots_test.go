@@ -0,0 +1,88 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/config"
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverRootsInWorkspace(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+
+	workspace := t.TempDir()
+	for _, repository := range []string{"api", "web", "team/tools", "node_modules/dep", "vendor/lib", "archive/old"} {
+		dir := filepath.Join(workspace, repository)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		initTestRepo(t, dir)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "web", "index.html"), []byte("<html></html>\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config.Set("roots", map[string]interface{}{"ignore": []interface{}{"archive"}})
+	config.Set("root_folders", []interface{}{filepath.Join(workspace, "a*")})
+
+	roots, err := core.DiscoverRoots(workspace, 2)
+	if err != nil {
+		t.Fatalf("DiscoverRoots failed: %v", err)
+	}
+
+	expected := []string{"api", "team/tools", "web"}
+	if len(roots) != len(expected) {
+		t.Fatalf("Expected %v, got %+v", expected, roots)
+	}
+	for i, root := range roots {
+		if root.Path != filepath.Join(workspace, expected[i]) {
+			t.Errorf("Expected %s, got %s", expected[i], root.Path)
+		}
+	}
+	if !roots[0].Configured || roots[2].Configured {
+		t.Error("Expected only the repository matched by the root_folders glob to be configured")
+	}
+	if roots[2].Pending != 1 {
+		t.Errorf("Expected one pending change in web, got %d", roots[2].Pending)
+	}
+
+	added := core.AddDiscoveredRoots(roots, true)
+	if len(added) != 2 || len(config.GetRootFolderEntries()) != 3 {
+		t.Errorf("Expected two new root folders, added %v, configured %v", added, config.GetRootFolderEntries())
+	}
+}
+
+func TestStagedOnlyExpandsRootGlobs(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+
+	workspace := t.TempDir()
+	for _, repository := range []string{"api", "web"} {
+		dir := filepath.Join(workspace, repository)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		initTestRepo(t, dir)
+		env.GitMock.SetupMockStagedFiles(dir, []string{"main.go"})
+	}
+	config.Set("root_folders", []interface{}{filepath.Join(workspace, "*")})
+
+	if err := core.GetAllStagedMsgs(); err != nil {
+		t.Fatalf("GetAllStagedMsgs failed: %v", err)
+	}
+	for _, repository := range []string{"api", "web"} {
+		if folder := output.GetFolder(filepath.Join(workspace, repository)); len(folder.Files) != 1 {
+			t.Errorf("Expected a staged message for %s, got %+v", repository, folder.Files)
+		}
+	}
+}
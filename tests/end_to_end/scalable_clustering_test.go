@@ -0,0 +1,154 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/embeddings"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// plantedClusters returns n unit vectors around k well separated centres and their centre
+func plantedClusters(n, k, dims int) ([][]float32, []int) {
+	rng := rand.New(rand.NewSource(7))
+	centres := make([][]float32, k)
+	for c := range centres {
+		centres[c] = make([]float32, dims)
+		for j := range centres[c] {
+			centres[c][j] = float32(rng.NormFloat64())
+		}
+	}
+	data := make([][]float32, n)
+	truth := make([]int, n)
+	for i := range data {
+		truth[i] = i % k
+		point := make([]float32, dims)
+		norm := 0.0
+		for j := range point {
+			point[j] = centres[truth[i]][j] + float32(rng.NormFloat64()*0.015)
+			norm += float64(point[j] * point[j])
+		}
+		for j := range point {
+			point[j] /= float32(math.Sqrt(norm))
+		}
+		data[i] = point
+	}
+	return data, truth
+}
+
+// sameGrouping reports whether labels partition the points exactly like truth
+func sameGrouping(labels, truth []int) bool {
+	forward, backward := make(map[int]int), make(map[int]int)
+	for i := range labels {
+		if label, ok := forward[truth[i]]; ok && label != labels[i] {
+			return false
+		}
+		if group, ok := backward[labels[i]]; ok && group != truth[i] {
+			return false
+		}
+		forward[truth[i]], backward[labels[i]] = labels[i], truth[i]
+	}
+	return true
+}
+
+func TestScalableClusteringRecoversGroupsSubquadratically(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+
+	const n, k = 2000, 40
+	data, truth := plantedClusters(n, k, 64)
+
+	labels, metrics := embeddings.ScalableDBSCAN(data, 0.4, 2)
+	if !sameGrouping(labels, truth) {
+		t.Errorf("LSH-DBSCAN did not recover the %d planted groups, found %d clusters", k, metrics.ActualClusters)
+	}
+	if allPairs := n * (n - 1) / 2; metrics.Comparisons*10 > allPairs {
+		t.Errorf("LSH-DBSCAN made %d distance computations, not far below the %d of all pairs", metrics.Comparisons, allPairs)
+	}
+
+	labels, err = embeddings.MiniBatchKMeans(data, k, 0, 200)
+	if err != nil {
+		t.Fatalf("MiniBatchKMeans failed: %v", err)
+	}
+	// k-means may split a group and merge two others, but must get nearly all of them
+	clusters := make(map[int]bool)
+	for _, label := range labels {
+		clusters[label] = true
+	}
+	if len(clusters) < k-2 {
+		t.Errorf("Mini-batch K-means found %d clusters, want about %d", len(clusters), k)
+	}
+
+	// Above the configured size the semantic methods switch to the scalable algorithms
+	if !embeddings.UseScalableClustering(n) || embeddings.UseScalableClustering(10) {
+		t.Error("UseScalableClustering should switch at the default of 200 files")
+	}
+
+	result := git.RunScalingBenchmarks([]int{250, 500})
+	for _, method := range []string{"hierarchical", "all-pairs-dbscan", "lsh-dbscan", "kmeans", "minibatch-kmeans"} {
+		if _, ok := result.ScalingExponents[method]; !ok {
+			t.Errorf("No scaling exponent for %s in %v", method, result.ScalingExponents)
+		}
+	}
+	for _, benchmark := range result.Benchmarks {
+		if benchmark.Method == "lsh-dbscan" && benchmark.ConfidenceScore < 0.95 {
+			t.Errorf("%s: purity %.2f on synthetic groups", benchmark.TestName, benchmark.ConfidenceScore)
+		}
+	}
+}
+
+func TestClusteringBenchmarkRunsScalingOnRequest(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	defer func(sizes []int) { git.DefaultScalingSizes = sizes }(git.DefaultScalingSizes)
+	git.DefaultScalingSizes = []int{250, 500}
+	defer git.ResetBenchmarks()
+
+	for _, name := range []string{"api/users.go", "api/orders.go", "docs/guide.md"} {
+		path := filepath.Join(env.TempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package api\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A plain benchmark only times the repository's changes
+	result, err := core.BenchmarkClustering(env.TempDir, false)
+	if err != nil {
+		t.Fatalf("BenchmarkClustering failed: %v", err)
+	}
+	if len(result.Benchmarks) != 4 || len(result.ScalingExponents) != 0 {
+		t.Errorf("Expected only the four method runs, got %d benchmark(s) and exponents %v",
+			len(result.Benchmarks), result.ScalingExponents)
+	}
+
+	// With scaling the synthetic runs add exponents but stay out of the method results
+	result, err = core.BenchmarkClustering(env.TempDir, true)
+	if err != nil {
+		t.Fatalf("BenchmarkClustering with scaling failed: %v", err)
+	}
+	if result.BestMethod != "threshold" && result.BestMethod != "target" {
+		t.Errorf("Expected a clustering method as the best one, got %q", result.BestMethod)
+	}
+	for _, benchmark := range result.Benchmarks {
+		if benchmark.FileCount != 3 {
+			t.Errorf("Synthetic run %s is part of the method results", benchmark.TestName)
+		}
+	}
+	if len(result.ScalingExponents) == 0 {
+		t.Error("Expected the scaling exponents next to the method results")
+	}
+}
@@ -0,0 +1,88 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommitScheduleWithinWorkingHours(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	for _, key := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(key+"_NAME", "Test")
+		t.Setenv(key+"_EMAIL", "test@example.com")
+	}
+	core.SetGitRunner(&git.DefaultGitRunner{})
+	defer core.SetGitRunner(env.GitMock)
+
+	names := []string{"api.go", "db.go", "ui.go", "cli.go"}
+	for _, name := range names {
+		path := filepath.Join(env.TempDir, name)
+		if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		output.Set(path, env.TempDir, "feat: add "+name)
+	}
+
+	// A window before the initial commit would put the history out of order
+	past := core.ScheduleOptions{Start: time.Now().AddDate(-1, 0, 0), End: time.Now().AddDate(-1, 0, 7), Spread: core.SpreadEven}
+	if _, err := core.PlanSchedule(past, env.TempDir); err == nil {
+		t.Fatal("Expected a window before HEAD to be rejected")
+	}
+
+	// Monday to Friday of a week next year, 09:00-17:00
+	monday := time.Date(time.Now().Year()+1, time.January, 1, 0, 0, 0, 0, time.Local)
+	for monday.Weekday() != time.Monday {
+		monday = monday.AddDate(0, 0, 1)
+	}
+	opts := core.ScheduleOptions{
+		Start:        monday.AddDate(0, 0, -2), // Saturday
+		End:          monday.AddDate(0, 0, 5),
+		Spread:       core.SpreadRealistic,
+		WorkStart:    9 * 60,
+		WorkEnd:      17 * 60,
+		WeekdaysOnly: true,
+		Seed:         42,
+	}
+	timeline, err := core.PlanSchedule(opts, env.TempDir)
+	if err != nil || len(timeline) != len(names) {
+		t.Fatalf("Expected %d scheduled commits, got %d (err %v)", len(names), len(timeline), err)
+	}
+	if err := core.CommitOnSchedule(timeline); err != nil {
+		t.Fatalf("CommitOnSchedule failed: %v", err)
+	}
+
+	log, _ := exec.Command("git", "-C", env.TempDir, "log", "--reverse", "-4", "--format=%aI|%cI|%s").Output()
+	lines := strings.Split(strings.TrimSpace(string(log)), "\n")
+	if len(lines) != len(names) {
+		t.Fatalf("Expected %d commits, got %q", len(names), log)
+	}
+	var previous time.Time
+	for i, line := range lines {
+		fields := strings.Split(line, "|")
+		authored, _ := time.Parse(time.RFC3339, fields[0])
+		if fields[0] != fields[1] || fields[2] != "feat: add "+names[i] {
+			t.Errorf("Commit %d does not follow the plan: %q", i, line)
+		}
+		local := authored.In(time.Local)
+		if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday || local.Hour() < 9 || local.Hour() >= 17 {
+			t.Errorf("Commit %d is outside working hours: %s", i, local)
+		}
+		if !authored.After(previous) {
+			t.Errorf("Commit %d is not after the previous one: %s", i, authored)
+		}
+		previous = authored
+	}
+}
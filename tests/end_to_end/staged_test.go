@@ -0,0 +1,114 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStagedOnlyWorkflow(t *testing.T) {
+	// Set up test environment
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+
+	// Only part of the changed files are staged
+	env.CreateTestFiles([]string{"src/main.go", "src/staged.go", "docs/README.md"})
+	env.GitMock.SetupMockChangedFiles(env.TempDir, []string{"src/main.go", "src/staged.go", "docs/README.md"})
+	env.GitMock.SetupMockStagedFiles(env.TempDir, []string{"src/staged.go", "docs/README.md"})
+	env.GeminiMock.SetupDefaultMessage("docs: describe staged changes")
+
+	// Leftover messages from a regular run must not survive
+	output.Set(env.TempDir+"/src/main.go", env.TempDir, "feat: stale message")
+
+	err = core.GetStagedMsgsForRootFolder(env.TempDir)
+	if err != nil {
+		t.Fatalf("Staged message generation failed: %v", err)
+	}
+
+	folder := output.GetFolder(env.TempDir)
+	if len(folder.Files) != 2 {
+		t.Fatalf("Expected 2 staged files in output, got %d", len(folder.Files))
+	}
+	for _, file := range folder.Files {
+		if file.Message != folder.Files[0].Message {
+			t.Errorf("Expected a single message for all staged files, got %q and %q", folder.Files[0].Message, file.Message)
+		}
+	}
+
+	// Commit the index as-is
+	err = core.CommitStagedRoot(env.TempDir)
+	if err != nil {
+		t.Fatalf("Staged commit failed: %v", err)
+	}
+
+	if env.GitMock.LastCommitFolder.Name != env.TempDir {
+		t.Errorf("Expected commit on folder %s, but was %s", env.TempDir, env.GitMock.LastCommitFolder.Name)
+	}
+	if len(env.GitMock.LastCommitFolder.Files) != 2 {
+		t.Errorf("Expected 2 files in staged commit, got %d", len(env.GitMock.LastCommitFolder.Files))
+	}
+}
+
+func TestStagedOnlyWithEmptyIndex(t *testing.T) {
+	// Set up test environment
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+
+	err = core.GetStagedMsgsForRootFolder(env.TempDir)
+	if err == nil {
+		t.Fatal("Expected an error when nothing is staged")
+	}
+
+	err = core.CommitStagedRoot(env.TempDir)
+	if err == nil {
+		t.Fatal("Expected an error when committing without staged messages")
+	}
+}
+
+func TestIndexStateReadsQuotedPaths(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+
+	// git quotes these names in plain porcelain output
+	for _, name := range []string{"release notes.md", "résumé.go"} {
+		if err := os.WriteFile(filepath.Join(env.TempDir, name), []byte("content\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, env.TempDir, "mv", "main.go", "entry point.go")
+	runGit(t, env.TempDir, "add", "release notes.md", "résumé.go")
+	if err := os.WriteFile(filepath.Join(env.TempDir, "résumé.go"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := git.GetIndexState(env.TempDir)
+	if err != nil {
+		t.Fatalf("GetIndexState failed: %v", err)
+	}
+	want := map[string]bool{"entry point.go": true, "release notes.md": true, "résumé.go": true}
+	if len(state.Staged) != len(want) {
+		t.Fatalf("Expected %d staged files, got %v", len(want), state.Staged)
+	}
+	for _, file := range state.Staged {
+		if !want[filepath.Base(file)] {
+			t.Errorf("Unexpected staged path %q", file)
+		}
+	}
+	if len(state.PartiallyStaged) != 1 || filepath.Base(state.PartiallyStaged[0]) != "résumé.go" {
+		t.Errorf("Expected résumé.go to be partially staged, got %v", state.PartiallyStaged)
+	}
+}
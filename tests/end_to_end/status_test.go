@@ -0,0 +1,61 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStatusDashboard(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+
+	remote := t.TempDir()
+	runGit(t, remote, "init", "-q", "--bare")
+	runGit(t, env.TempDir, "remote", "add", "origin", remote)
+	runGit(t, env.TempDir, "push", "-q", "-u", "origin", "HEAD")
+
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(env.TempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("util.go", "package main\n")
+	runGit(t, env.TempDir, "add", "util.go")
+	runGit(t, env.TempDir, "commit", "-q", "-m", "feat: add util")
+	write("staged.go", "package main\n")
+	runGit(t, env.TempDir, "add", "staged.go")
+	write("main.go", "package main\n\nfunc main() {}\n")
+	write("notes.txt", "todo\n")
+	output.Set(filepath.Join(env.TempDir, "main.go"), env.TempDir, "fix: add main function")
+
+	core.SetGitRunner(&git.DefaultGitRunner{})
+	statuses, err := core.CollectStatus(env.TempDir)
+	if err != nil || len(statuses) != 1 {
+		t.Fatalf("CollectStatus failed: %v (%d rows)", err, len(statuses))
+	}
+
+	status := statuses[0]
+	if status.Upstream == "" || status.Ahead != 1 || status.Behind != 0 {
+		t.Errorf("Expected one commit ahead of the upstream, got %+v", status.RepositoryStatus)
+	}
+	if status.Staged != 1 || status.Modified != 1 || status.Untracked != 1 || status.Conflicted != 0 {
+		t.Errorf("Unexpected file counts: %+v", status.RepositoryStatus)
+	}
+	if status.PendingMessages != 1 || status.Health != "ok" {
+		t.Errorf("Expected one pending message and a healthy repository, got %d and %q", status.PendingMessages, status.Health)
+	}
+
+	if table := core.FormatStatusTable(statuses); !strings.Contains(table, "1/0") {
+		t.Errorf("Expected ahead/behind in the table:\n%s", table)
+	}
+}
@@ -0,0 +1,78 @@
+package end_to_end
+
+import (
+	"github.com/lakshyajain-0291/gitcury/core"
+	"github.com/lakshyajain-0291/gitcury/git"
+	"github.com/lakshyajain-0291/gitcury/interfaces"
+	"github.com/lakshyajain-0291/gitcury/output"
+	"github.com/lakshyajain-0291/gitcury/tests/testutils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUndoCommitRestoresMessages(t *testing.T) {
+	env, err := testutils.SetupTestEnv()
+	if err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+	defer env.Cleanup()
+	initTestRepo(t, env.TempDir)
+	for _, key := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(key+"_NAME", "Test")
+		t.Setenv(key+"_EMAIL", "test@example.com")
+	}
+
+	remote := t.TempDir()
+	runGit(t, remote, "init", "-q", "--bare")
+	runGit(t, env.TempDir, "remote", "add", "origin", remote)
+	headBefore, _ := exec.Command("git", "-C", env.TempDir, "rev-parse", "HEAD").Output()
+
+	folder := output.Folder{Name: env.TempDir}
+	for _, name := range []string{"api.go", "db.go"} {
+		path := filepath.Join(env.TempDir, name)
+		if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		folder.Files = append(folder.Files, output.FileEntry{Name: path, Message: "feat: add " + name})
+	}
+	if err := git.CommitBatch(folder); err != nil {
+		t.Fatalf("CommitBatch failed: %v", err)
+	}
+
+	entry, err := git.LastJournalEntry(env.TempDir)
+	if err != nil || entry == nil || len(entry.Commits) != 2 || entry.PreHead != strings.TrimSpace(string(headBefore)) {
+		t.Fatalf("Expected a journal entry with two commits, got %+v (err %v)", entry, err)
+	}
+
+	// Once pushed, the commits are only undone with force
+	if err := git.SmartPush(env.TempDir, "", interfaces.PushOptions{Remote: "origin", SetUpstream: true}); err != nil {
+		t.Fatalf("SmartPush failed: %v", err)
+	}
+	if entry, _ := git.LastJournalEntry(env.TempDir); entry == nil || !entry.Pushed {
+		t.Errorf("Expected the push to be recorded in the journal, got %+v", entry)
+	}
+	if err := core.UndoLastOperation(env.TempDir, false, true); err == nil {
+		t.Fatal("Expected pushed commits to be refused")
+	}
+	if err := core.UndoLastOperation(env.TempDir, true, true); err != nil {
+		t.Fatalf("UndoLastOperation failed: %v", err)
+	}
+
+	headAfter, _ := exec.Command("git", "-C", env.TempDir, "rev-parse", "HEAD").Output()
+	if string(headAfter) != string(headBefore) {
+		t.Error("Undo did not reset the branch to the recorded HEAD")
+	}
+	if _, err := os.Stat(filepath.Join(env.TempDir, "api.go")); err != nil {
+		t.Error("Undo must keep the committed changes in the working tree")
+	}
+	if got := output.Get(filepath.Join(env.TempDir, "db.go"), env.TempDir); got != "feat: add db.go" {
+		t.Errorf("Expected the generated message to be restored, got %q", got)
+	}
+
+	if entry, _ := git.LastJournalEntry(env.TempDir); entry != nil {
+		t.Errorf("Expected no operation left to undo, got %+v", entry)
+	}
+}
@@ -18,6 +18,8 @@ type MockGeminiAPI struct {
 	LastPrompt      string                       // Last prompt sent to API
 	LastContextData map[string]map[string]string // Last context data sent to API
 	CallCount       int                          // Number of times the API was called
+	GeneratedText   string                       // Response returned by GenerateText
+	LastRequest     interfaces.GenerationRequest // Last request sent to GenerateText
 }
 
 // NewMockGeminiAPI creates a new instance with default testing values
@@ -50,6 +52,11 @@ func (m *MockGeminiAPI) SetupResponseDelay(milliseconds int) {
 	m.ResponseDelay = milliseconds
 }
 
+// SetupGeneratedText configures the response returned by GenerateText
+func (m *MockGeminiAPI) SetupGeneratedText(text string) {
+	m.GeneratedText = text
+}
+
 // SetupShouldFail configures whether API calls should fail
 func (m *MockGeminiAPI) SetupShouldFail(shouldFail bool, message string) {
 	m.ShouldFail = shouldFail
@@ -105,3 +112,15 @@ func (m *MockGeminiAPI) SendToGemini(contextData map[string]map[string]string, a
 		return fmt.Sprintf("feat: update %d files", fileCount), nil
 	}
 }
+
+// GenerateText mocks the GenerateText function for testing
+func (m *MockGeminiAPI) GenerateText(request interfaces.GenerationRequest, apiKey string) (string, error) {
+	m.CallCount++
+	m.LastRequest = request
+	m.LastPrompt = request.Prompt
+
+	if m.ShouldFail {
+		return "", fmt.Errorf("%s", m.FailureMessage)
+	}
+	return m.GeneratedText, nil
+}
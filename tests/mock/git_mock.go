@@ -16,6 +16,7 @@ import (
 type MockGitRunner struct {
 	// State for testing
 	ChangedFiles           map[string][]string // map[rootPath][]filePaths
+	StagedFiles            map[string][]string // map[rootPath][]filePaths already in the index
 	CommitResults          map[string]bool     // map[folderName]success
 	PushResults            map[string]bool     // map[folderName]success
 	DiffResults            map[string]string   // map[filePath]diffOutput
@@ -26,6 +27,7 @@ type MockGitRunner struct {
 	CommandCalls           []CommandCall       // Record of all commands called
 	LastCommitFolder       interfaces.Folder   // Last folder committed
 	LastPushBranch         string              // Last branch pushed
+	LastPushOptions        interfaces.PushOptions // Options of the last push
 	ShouldFailBatchProcess bool                // Control batch processing failures
 }
 
@@ -41,6 +43,7 @@ type CommandCall struct {
 func NewMockGitRunner() *MockGitRunner {
 	return &MockGitRunner{
 		ChangedFiles:           make(map[string][]string),
+		StagedFiles:            make(map[string][]string),
 		CommitResults:          make(map[string]bool),
 		PushResults:            make(map[string]bool),
 		DiffResults:            make(map[string]string),
@@ -58,6 +61,11 @@ func (m *MockGitRunner) SetupMockChangedFiles(rootFolder string, files []string)
 	m.ChangedFiles[rootFolder] = files
 }
 
+// SetupMockStagedFiles configures the files already staged in a repository for testing
+func (m *MockGitRunner) SetupMockStagedFiles(rootFolder string, files []string) {
+	m.StagedFiles[rootFolder] = files
+}
+
 // SetupMockCommitResult configures mock commit results for testing
 func (m *MockGitRunner) SetupMockCommitResult(folderName string, success bool) {
 	m.CommitResults[folderName] = success
@@ -231,6 +239,20 @@ func (m *MockGitRunner) Status(rootPaths []string) ([]interfaces.Folder, error)
 	return m.GetChangedFiles(rootPaths, 1)
 }
 
+// RepositoryStatus implements the GitRunner.RepositoryStatus interface method
+func (m *MockGitRunner) RepositoryStatus(dir string) (interfaces.RepositoryStatus, error) {
+	if err, ok := m.CommandErrors["status"]; ok && err != nil {
+		return interfaces.RepositoryStatus{}, err
+	}
+
+	return interfaces.RepositoryStatus{
+		Directory: dir,
+		Branch:    "main",
+		Staged:    len(m.StagedFiles[dir]),
+		Modified:  len(m.ChangedFiles[dir]),
+	}, nil
+}
+
 // ProcessOneFile implements the GitRunner.ProcessOneFile interface method
 func (m *MockGitRunner) ProcessOneFile(filePath, commitMessage string, env ...[]string) error {
 	// Check if the file is in our mock changed files
@@ -296,13 +318,28 @@ func (m *MockGitRunner) ProgressCommitBatch(folder interfaces.Folder, env ...[]s
 }
 
 // ProgressPushBranch implements the GitRunner.ProgressPushBranch interface method
-func (m *MockGitRunner) ProgressPushBranch(rootFolderName string, branch string) error {
+func (m *MockGitRunner) ProgressPushBranch(rootFolderName string, branch string, opts ...interfaces.PushOptions) error {
 	m.LastPushBranch = branch
 
+	remote := "origin"
+	args := []string{"push"}
+	if len(opts) > 0 {
+		m.LastPushOptions = opts[0]
+		if opts[0].Remote != "" {
+			remote = opts[0].Remote
+		}
+		if opts[0].SetUpstream {
+			args = append(args, "--set-upstream")
+		}
+		if opts[0].ForceWithLease {
+			args = append(args, "--force-with-lease")
+		}
+	}
+
 	// Record the command call
 	m.CommandCalls = append(m.CommandCalls, CommandCall{
 		Dir:  rootFolderName,
-		Args: []string{"push", "origin", branch},
+		Args: append(args, remote, branch),
 	})
 
 	// Check if we have a specific result for this folder
@@ -424,5 +461,42 @@ func (m *MockGitRunner) BatchProcessWithEmbeddings(allChangedFiles []string, roo
 	return nil
 }
 
+// GenStagedCommitMessage implements the GitRunner.GenStagedCommitMessage interface method
+func (m *MockGitRunner) GenStagedCommitMessage(dir string) (string, []string, error) {
+	files, ok := m.StagedFiles[dir]
+	if !ok || len(files) == 0 {
+		return "", nil, errors.New("mock: no staged changes found in " + dir)
+	}
+
+	contextData := make(map[string]map[string]string)
+	var absolutePaths []string
+	for _, file := range files {
+		absolutePath := filepath.Join(dir, file)
+		absolutePaths = append(absolutePaths, absolutePath)
+		contextData[absolutePath] = map[string]string{
+			"type": "modified",
+			"diff": fmt.Sprintf("mock staged diff for %s", file),
+		}
+	}
+
+	message, err := di.GetGeminiRunner().SendToGemini(contextData, "test-api-key")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return message, absolutePaths, nil
+}
+
+// CommitStaged implements the GitRunner.CommitStaged interface method
+func (m *MockGitRunner) CommitStaged(folder interfaces.Folder, env ...[]string) error {
+	m.LastCommitFolder = folder
+
+	if result, ok := m.CommitResults[folder.Name]; ok && !result {
+		return errors.New("mock commit error for folder: " + folder.Name)
+	}
+
+	return nil
+}
+
 // Ensure MockGitRunner implements GitRunner interface
 var _ interfaces.GitRunner = (*MockGitRunner)(nil)
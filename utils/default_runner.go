@@ -24,3 +24,8 @@ func init() {
 func (r *DefaultGeminiRunner) SendToGemini(contextData map[string]map[string]string, apiKey string, customInstructions ...string) (string, error) {
 	return SendToGemini(contextData, apiKey, customInstructions...)
 }
+
+// GenerateText delegates to the real GenerateText function
+func (r *DefaultGeminiRunner) GenerateText(request interfaces.GenerationRequest, apiKey string) (string, error) {
+	return GenerateText(request, apiKey)
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"github.com/lakshyajain-0291/gitcury/api"
+	"github.com/lakshyajain-0291/gitcury/interfaces"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -45,6 +46,129 @@ func SetTimeoutVar(retries, delay int) {
 }
 
 func SendToGemini(contextData map[string]map[string]string, apiKey string, customInstructions ...string) (string, error) {
+	// Build the system instruction with default guidelines
+	baseInstruction := `
+	Generate and return only a commit message as JSON with the key "message".
+	Follow these guidelines for the commit message:
+	• Capitalize the first word, omit final punctuation. If using conventional commits, use lowercase for the commit type.
+	• Use imperative mood in the subject line.
+	• Include a commit type (e.g. fix, update, refactor, bump).
+	• Limit the first line to ≤ 50 characters, subsequent lines ≤ 72.
+	• Be concise and direct; avoid filler words.
+	• Do not include newline characters (\n) or similar formatting.
+
+	The commit type can include the following:
+	feat – a new feature
+	fix – a bug fix
+	chore – non-source changes
+	refactor – refactored code
+	docs – documentation updates
+	style – formatting changes
+	test – tests
+	perf – performance improvements
+	ci – continuous integration
+	build – build system changes
+	revert – revert a previous commit
+	`
+
+	// Check for user-provided custom instructions
+	if len(customInstructions) > 0 && customInstructions[0] != "" {
+		userInstructions := customInstructions[0]
+		Debug("[GEMINI]: 📝 Found custom commit instructions")
+
+		// Sanitize the instructions to prevent misuse (warnings handled inside function)
+		sanitized := SanitizeUserInstructions(userInstructions)
+
+		// Add user instructions at the beginning of the base instruction
+		baseInstruction = `
+	Generate and return only a commit message as JSON with the key "message".
+	
+	CUSTOM INSTRUCTIONS FROM USER:
+	` + sanitized + `
+	 
+	Additionally, follow these guidelines strictly for the commit message:
+	• Limit the first line to ≤ 50 characters, subsequent lines ≤ 72.
+	• Be concise and direct; avoid filler words.
+	• Do not include newline characters (\n) or similar formatting.
+	`
+	}
+
+	// Save the instruction for testing
+	lastSystemInstruction = baseInstruction
+
+	var promptBuilder strings.Builder
+	promptBuilder.WriteString("Summarize the following file changes:\n\n")
+	for file, data := range contextData {
+		promptBuilder.WriteString(fmt.Sprintf("File: %s\nType: %s\nDiff:\n%s\n\n", file, data["type"], data["diff"]))
+	}
+	prompt := promptBuilder.String()
+
+	Debug(fmt.Sprintf("[GEMINI]: 📤 Context data files: %d", len(contextData)))
+	respMessage, err := GenerateText(interfaces.GenerationRequest{
+		SystemInstruction: baseInstruction,
+		Prompt:            prompt,
+		MaxOutputTokens:   100,
+		JSON:              true,
+	}, apiKey)
+	if err != nil {
+		return "", err
+	}
+
+	// Step 1: Try parsing as a simple JSON object with "message"
+	// First try to parse as JSON object with a "message" key
+	var single map[string]string
+	if err := json.Unmarshal([]byte(respMessage), &single); err == nil {
+		if msg, ok := single["message"]; ok {
+			return msg, nil
+		}
+		Debug("[GEMINI]: JSON object does not contain 'message' key, checking for array")
+	} else {
+		Debug("[GEMINI]: Failed to parse as single message object: " + err.Error())
+	}
+
+	// Try to parse as an array of message objects
+	var multiple []map[string]string
+	if err := json.Unmarshal([]byte(respMessage), &multiple); err == nil {
+		var combined []string
+		for _, m := range multiple {
+			if msg, ok := m["message"]; ok {
+				combined = append(combined, msg)
+			}
+		}
+		if len(combined) > 0 {
+			// Join all commit messages into one string (adjust separator as needed)
+			return strings.Join(combined, "\n"), nil
+		}
+		Debug("[GEMINI]: Parsed array but found no 'message' keys")
+	} else {
+		Debug("[GEMINI]: Failed to parse as array of message objects: " + err.Error())
+	}
+
+	// Step 2: Handle raw string or misformatted response
+	trimmed := strings.TrimSpace(respMessage)
+	if strings.HasPrefix(trimmed, "[") {
+		// Try parsing as raw JSON array of objects
+		var arr []map[string]string
+		if err := json.Unmarshal([]byte(trimmed), &arr); err == nil && len(arr) > 0 {
+			if msg, ok := arr[0]["message"]; ok {
+				return msg, nil
+			}
+		}
+	}
+
+	if trimmed == "" {
+		Error("[GEMINI]: ❌ Empty response after trimming.")
+		return "", NewAPIError("Empty response after trimming", nil, map[string]interface{}{
+			"raw_response": respMessage,
+		})
+	}
+
+	return trimmed, nil
+}
+
+// GenerateText sends a prompt to Gemini with the shared retry handling and returns the raw
+// response text. SendToGemini and other generators (e.g. describe) build on it.
+func GenerateText(request interfaces.GenerationRequest, apiKey string) (string, error) {
 	// Validate API key with helpful guidance
 	if apiKey == "" {
 		Error("[GEMINI]: ❌ API key is empty")
@@ -88,10 +212,20 @@ func SendToGemini(contextData map[string]map[string]string, apiKey string, custo
 
 	Debug("[GEMINI]: ⚙️ Configuring Gemini model...")
 	model := client.GenerativeModel("gemini-2.0-flash")
-	model.SetTemperature(0.5)
-	model.SetMaxOutputTokens(100)
-	model.ResponseMIMEType = "application/json"
-	Debug("[GEMINI]: ⚙️ Model configuration: temperature=0.5, max_tokens=100, mime=application/json")
+	temperature := request.Temperature
+	if temperature <= 0 {
+		temperature = 0.5
+	}
+	maxTokens := request.MaxOutputTokens
+	if maxTokens <= 0 {
+		maxTokens = 100
+	}
+	model.SetTemperature(temperature)
+	model.SetMaxOutputTokens(maxTokens)
+	if request.JSON {
+		model.ResponseMIMEType = "application/json"
+	}
+	Debug(fmt.Sprintf("[GEMINI]: ⚙️ Model configuration: temperature=%.2f, max_tokens=%d, json=%t", temperature, maxTokens, request.JSON))
 
 	// Configure safety settings to be more permissive for code content
 	model.SafetySettings = []*genai.SafetySetting{
@@ -113,68 +247,11 @@ func SendToGemini(contextData map[string]map[string]string, apiKey string, custo
 		},
 	}
 
-	// Build the system instruction with default guidelines
-	baseInstruction := `
-	Generate and return only a commit message as JSON with the key "message".
-	Follow these guidelines for the commit message:
-	• Capitalize the first word, omit final punctuation. If using conventional commits, use lowercase for the commit type.
-	• Use imperative mood in the subject line.
-	• Include a commit type (e.g. fix, update, refactor, bump).
-	• Limit the first line to ≤ 50 characters, subsequent lines ≤ 72.
-	• Be concise and direct; avoid filler words.
-	• Do not include newline characters (\n) or similar formatting.
-
-	The commit type can include the following:
-	feat – a new feature
-	fix – a bug fix
-	chore – non-source changes
-	refactor – refactored code
-	docs – documentation updates
-	style – formatting changes
-	test – tests
-	perf – performance improvements
-	ci – continuous integration
-	build – build system changes
-	revert – revert a previous commit
-	`
-
-	// Check for user-provided custom instructions
-	if len(customInstructions) > 0 && customInstructions[0] != "" {
-		userInstructions := customInstructions[0]
-		Debug("[GEMINI]: 📝 Found custom commit instructions")
-
-		// Sanitize the instructions to prevent misuse (warnings handled inside function)
-		sanitized := SanitizeUserInstructions(userInstructions)
-
-		// Add user instructions at the beginning of the base instruction
-		baseInstruction = `
-	Generate and return only a commit message as JSON with the key "message".
-	
-	CUSTOM INSTRUCTIONS FROM USER:
-	` + sanitized + `
-	 
-	Additionally, follow these guidelines strictly for the commit message:
-	• Limit the first line to ≤ 50 characters, subsequent lines ≤ 72.
-	• Be concise and direct; avoid filler words.
-	• Do not include newline characters (\n) or similar formatting.
-	`
-	}
-
-	// Save the instruction for testing
-	lastSystemInstruction = baseInstruction
-
-	model.SystemInstruction = genai.NewUserContent(genai.Text(baseInstruction))
-
-	var promptBuilder strings.Builder
-	promptBuilder.WriteString("Summarize the following file changes:\n\n")
-	for file, data := range contextData {
-		promptBuilder.WriteString(fmt.Sprintf("File: %s\nType: %s\nDiff:\n%s\n\n", file, data["type"], data["diff"]))
-	}
-	prompt := promptBuilder.String()
+	model.SystemInstruction = genai.NewUserContent(genai.Text(request.SystemInstruction))
+	prompt := request.Prompt
 
 	// Debug logging for API request
 	Debug(fmt.Sprintf("[GEMINI]: 📤 Making API request with prompt length: %d characters", len(prompt)))
-	Debug(fmt.Sprintf("[GEMINI]: 📤 Context data files: %d", len(contextData)))
 
 	Debug("[GEMINI]: Max retries set to: " + fmt.Sprintf("%d", maxRetries))
 
@@ -242,14 +319,14 @@ func SendToGemini(contextData map[string]map[string]string, apiKey string, custo
 	if resp == nil {
 		Error("[GEMINI]: ❌ Received nil response from Gemini API.")
 		return "", NewAPIError("Received nil response from Gemini API", nil, map[string]interface{}{
-			"context_files": len(contextData),
+			"prompt_length": len(prompt),
 		})
 	}
 
 	if len(resp.Candidates) == 0 {
 		Error("[GEMINI]: ❌ No candidates returned by Gemini.")
 		return "", NewAPIError("No candidates returned by Gemini API", nil, map[string]interface{}{
-			"context_files":   len(contextData),
+			"prompt_length":   len(prompt),
 			"prompt_feedback": fmt.Sprintf("%+v", resp.PromptFeedback),
 		})
 	}
@@ -286,57 +363,7 @@ func SendToGemini(contextData map[string]map[string]string, apiKey string, custo
 	}
 
 	Debug("[GEMINI]: ✨ Response received: " + respMessage)
-
-	// Step 1: Try parsing as a simple JSON object with "message"
-	// First try to parse as JSON object with a "message" key
-	var single map[string]string
-	if err := json.Unmarshal([]byte(respMessage), &single); err == nil {
-		if msg, ok := single["message"]; ok {
-			return msg, nil
-		}
-		Debug("[GEMINI]: JSON object does not contain 'message' key, checking for array")
-	} else {
-		Debug("[GEMINI]: Failed to parse as single message object: " + err.Error())
-	}
-
-	// Try to parse as an array of message objects
-	var multiple []map[string]string
-	if err := json.Unmarshal([]byte(respMessage), &multiple); err == nil {
-		var combined []string
-		for _, m := range multiple {
-			if msg, ok := m["message"]; ok {
-				combined = append(combined, msg)
-			}
-		}
-		if len(combined) > 0 {
-			// Join all commit messages into one string (adjust separator as needed)
-			return strings.Join(combined, "\n"), nil
-		}
-		Debug("[GEMINI]: Parsed array but found no 'message' keys")
-	} else {
-		Debug("[GEMINI]: Failed to parse as array of message objects: " + err.Error())
-	}
-
-	// Step 2: Handle raw string or misformatted response
-	trimmed := strings.TrimSpace(respMessage)
-	if strings.HasPrefix(trimmed, "[") {
-		// Try parsing as raw JSON array of objects
-		var arr []map[string]string
-		if err := json.Unmarshal([]byte(trimmed), &arr); err == nil && len(arr) > 0 {
-			if msg, ok := arr[0]["message"]; ok {
-				return msg, nil
-			}
-		}
-	}
-
-	if trimmed == "" {
-		Error("[GEMINI]: ❌ Empty response after trimming.")
-		return "", NewAPIError("Empty response after trimming", nil, map[string]interface{}{
-			"raw_response": respMessage,
-		})
-	}
-
-	return trimmed, nil
+	return respMessage, nil
 }
 
 // Keep track of the last system instruction for testing
@@ -17,6 +17,7 @@ var (
 	totalOperations   int
 	completedOps      int //nolint:unused // Will be used in future stats improvements
 	clusteringInfo    *ClusteringMethodInfo // New field for clustering info
+	clusterCounts     []ClusterCountInfo    // Number of clusters chosen in each automatic selection
 )
 
 type ProgressInfo struct {
@@ -41,6 +42,19 @@ type ClusteringMethodInfo struct {
 	AdaptiveOptimization  bool               `json:"adaptiveOptimization"`
 }
 
+// ClusterCountInfo records how an automatic selection chose the number of clusters
+type ClusterCountInfo struct {
+	Points      int     `json:"points"`
+	K           int     `json:"k"`
+	Criterion   string  `json:"criterion"`
+	MinK        int     `json:"minK"`
+	MaxK        int     `json:"maxK"`
+	SilhouetteK int     `json:"silhouetteK"`
+	ElbowK      int     `json:"elbowK"`
+	GapK        int     `json:"gapK"`
+	Silhouette  float64 `json:"silhouette"` // Average silhouette at the chosen k
+}
+
 type CommandStats struct {
 	Command        string
 	StartTime      time.Time
@@ -64,6 +78,7 @@ func EnableStats() {
 	operationProgress = make(map[string]ProgressInfo)
 	totalOperations = 0
 	completedOps = 0
+	clusterCounts = nil
 	Info("📊 Statistics tracking enabled")
 }
 
@@ -218,6 +233,15 @@ func PrintStats() {
 		}
 	}
 
+	if len(clusterCounts) > 0 {
+		fmt.Printf("\n%s%s🔢 NUMBER OF COMMITS:%s\n", Yellow, Bold, Reset)
+		for _, count := range clusterCounts {
+			fmt.Printf("   %s• %d files:%s %d clusters by %s (silhouette %d, elbow %d, gap %d; range %d-%d, silhouette score %.2f)\n",
+				Cyan, count.Points, Reset, count.K, count.Criterion, count.SilhouetteK, count.ElbowK, count.GapK,
+				count.MinK, count.MaxK, count.Silhouette)
+		}
+	}
+
 	if len(operationProgress) > 0 {
 		fmt.Printf("\n%s%s📋 Operation Details:%s\n", Yellow, Bold, Reset)
 		for name, info := range operationProgress {
@@ -337,6 +361,24 @@ func GetClusteringInfo() *ClusteringMethodInfo {
 	return clusteringInfo
 }
 
+// RecordClusterCount stores an automatic choice of the number of clusters for stats display
+func RecordClusterCount(info ClusterCountInfo) {
+	if !IsStatsEnabled() {
+		return
+	}
+
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+	clusterCounts = append(clusterCounts, info)
+}
+
+// GetClusterCounts returns the recorded choices of the number of clusters
+func GetClusterCounts() []ClusterCountInfo {
+	statsMutex.RLock()
+	defer statsMutex.RUnlock()
+	return append([]ClusterCountInfo(nil), clusterCounts...)
+}
+
 // CaptureClusteringConfig captures clustering configuration from the config package
 // This function should be called when clustering operations begin
 func CaptureClusteringConfig() {